@@ -0,0 +1,18 @@
+// Command echo-adapter is the reference process channel adapter. Configure it
+// as a "process" channel with command set to the built binary.
+package main
+
+import (
+	"log"
+	"os"
+
+	"conduit/internal/channels/process/echo"
+)
+
+func main() {
+	// Stdout carries the protocol, so logs go to stderr
+	log.SetOutput(os.Stderr)
+	if err := echo.Run(os.Stdin, os.Stdout); err != nil {
+		log.Fatalf("echo adapter: %v", err)
+	}
+}
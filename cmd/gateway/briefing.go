@@ -161,15 +161,18 @@ func runBriefingGenerate(sessionID string, outputJSON bool, limit int) error {
 	}
 
 	// Convert session messages to briefing messages.
-	briefingMsgs := make([]briefing.Message, len(msgs))
-	for i, m := range msgs {
-		briefingMsgs[i] = briefing.Message{
+	briefingMsgs := make([]briefing.Message, 0, len(msgs))
+	for _, m := range msgs {
+		if m.IsToolTurn() {
+			continue
+		}
+		briefingMsgs = append(briefingMsgs, briefing.Message{
 			ID:        m.ID,
 			Role:      m.Role,
 			Content:   m.Content,
 			Timestamp: m.Timestamp,
 			Metadata:  m.Metadata,
-		}
+		})
 	}
 
 	// Generate briefing.
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"conduit/internal/scheduler"
+
+	"github.com/spf13/cobra"
+)
+
+// JobsRootCmd creates the jobs command tree.
+func JobsRootCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Inspect scheduled jobs",
+		Long: `Scheduled jobs record every run in the gateway database: when it started
+and finished, whether it failed, the model and tokens it used and an excerpt
+of its output. Retried runs record each attempt.`,
+	}
+
+	cmd.AddCommand(jobsHistoryCmd())
+
+	return cmd
+}
+
+// jobsHistoryCmd lists the recorded runs of a job.
+func jobsHistoryCmd() *cobra.Command {
+	var (
+		limit      int
+		outputJSON bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history [JOB_ID]",
+		Short: "Show the run history of a job",
+		Long: `Show the recorded runs of a scheduled job, newest first.
+
+Examples:
+  conduit jobs history a1b2c3d4
+  conduit jobs history agent_heartbeat_main --limit 50
+  conduit jobs history a1b2c3d4 --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withGatewayDB(func(db *sql.DB) error {
+				history := scheduler.NewHistory(db, scheduler.HistoryOptions{})
+				return runJobsHistory(history, args[0], limit, outputJSON)
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of runs to show (0 = all)")
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+// --- command implementations ---
+
+func runJobsHistory(history *scheduler.History, jobID string, limit int, outputJSON bool) error {
+	runs, err := history.List(jobID, limit)
+	if err != nil {
+		return err
+	}
+
+	if outputJSON {
+		if runs == nil {
+			runs = []*scheduler.Run{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(runs)
+	}
+
+	if len(runs) == 0 {
+		fmt.Printf("No recorded runs for job %s.\n", jobID)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tSTARTED\tSTATUS\tATTEMPT\tDURATION\tTRIGGER\tMODEL\tTOKENS\tOUTPUT / ERROR")
+	fmt.Fprintln(w, "---\t-------\t------\t-------\t--------\t-------\t-----\t------\t--------------")
+
+	for _, run := range runs {
+		summary := run.Output
+		if run.Error != "" {
+			summary = run.Error
+		}
+		summary = strings.Join(strings.Fields(summary), " ")
+		if len(summary) > 60 {
+			summary = summary[:57] + "..."
+		}
+
+		duration := "-"
+		if run.FinishedAt != nil {
+			duration = run.Duration().Round(time.Millisecond).String()
+		}
+
+		attempt := "-"
+		if run.Attempt > 0 {
+			attempt = strconv.Itoa(run.Attempt)
+		}
+
+		model := run.Model
+		if model == "" {
+			model = "-"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			run.ID,
+			run.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			run.Status,
+			attempt,
+			duration,
+			run.TriggeredBy,
+			model,
+			run.TotalTokens,
+			summary,
+		)
+	}
+
+	return w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(JobsRootCmd())
+}
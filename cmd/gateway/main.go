@@ -14,6 +14,7 @@ import (
 	"conduit/internal/datadir"
 	"conduit/internal/gateway"
 	internalssh "conduit/internal/ssh"
+	"conduit/internal/tools/sandbox"
 	"conduit/internal/version"
 
 	"github.com/spf13/cobra"
@@ -203,6 +204,24 @@ func runServer() error {
 		cancel()
 	}()
 
+	// SIGHUP reloads the runtime-updatable parts of the config file
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hupCh:
+				log.Printf("Received SIGHUP, reloading %s", cfgFile)
+				if err := gw.ReloadConfig(); err != nil {
+					log.Printf("Config reload failed: %v", err)
+				}
+			}
+		}
+	}()
+
 	// Start the gateway
 	log.Printf("Starting Conduit Gateway on port %d", cfg.Port)
 	if err := gw.Start(ctx); err != nil {
@@ -214,6 +233,9 @@ func runServer() error {
 }
 
 func main() {
+	// Runs the Bash tool's namespace sandbox helper when re-executed as one
+	sandbox.Init()
+
 	// Update CLI config with actual values after flags are parsed
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		// Auto-detect database path if still empty
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"conduit/internal/channels"
+	"conduit/internal/config"
+	"conduit/internal/database"
+
+	"github.com/spf13/cobra"
+)
+
+// OutboxRootCmd creates the outbox command tree.
+func OutboxRootCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "outbox",
+		Short: "Inspect and replay queued channel messages",
+		Long: `Outgoing channel messages are stored in the gateway database until an
+adapter accepts them. Failed sends are retried with backoff; messages that
+fail too often are dead-lettered and kept until replayed or purged.`,
+	}
+
+	cmd.AddCommand(
+		outboxListCmd(),
+		outboxShowCmd(),
+		outboxReplayCmd(),
+		outboxPurgeCmd(),
+	)
+
+	return cmd
+}
+
+// outboxListCmd lists queued, delivered and dead-lettered messages.
+func outboxListCmd() *cobra.Command {
+	var (
+		status     string
+		channelID  string
+		limit      int
+		outputJSON bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List outbox messages",
+		Long: `List outbox messages, newest first, with a count of each state.
+
+Examples:
+  conduit outbox list
+  conduit outbox list --status dead
+  conduit outbox list --channel telegram --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutboxStatus(status); err != nil {
+				return err
+			}
+			return withOutbox(func(outbox *channels.Outbox) error {
+				return runOutboxList(outbox, channels.OutboxFilter{Status: status, ChannelID: channelID, Limit: limit}, outputJSON)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&status, "status", "", "Only show messages in this state (pending, delivered, dead)")
+	cmd.Flags().StringVar(&channelID, "channel", "", "Only show messages for this channel")
+	cmd.Flags().IntVar(&limit, "limit", 50, "Maximum number of messages to show (0 = all)")
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+// outboxShowCmd displays a single message.
+func outboxShowCmd() *cobra.Command {
+	var outputJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "show [ID]",
+		Short: "Display an outbox message",
+		Long: `Show an outbox message with its delivery state and full text.
+
+Examples:
+  conduit outbox show 42
+  conduit outbox show 42 --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseOutboxID(args[0])
+			if err != nil {
+				return err
+			}
+			return withOutbox(func(outbox *channels.Outbox) error {
+				return runOutboxShow(outbox, id, outputJSON)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+// outboxReplayCmd queues messages for delivery again.
+func outboxReplayCmd() *cobra.Command {
+	var (
+		allDead   bool
+		channelID string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay [ID...]",
+		Short: "Queue messages for delivery again",
+		Long: `Queue dead-lettered (or delivered) messages for delivery again with a fresh
+set of attempts. A running gateway picks them up within a few seconds.
+
+Examples:
+  conduit outbox replay 42 43
+  conduit outbox replay --dead
+  conduit outbox replay --dead --channel telegram`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if allDead == (len(args) > 0) {
+				return fmt.Errorf("specify message IDs or --dead")
+			}
+			if channelID != "" && !allDead {
+				return fmt.Errorf("--channel can only be used with --dead")
+			}
+
+			var ids []int64
+			for _, arg := range args {
+				id, err := parseOutboxID(arg)
+				if err != nil {
+					return err
+				}
+				ids = append(ids, id)
+			}
+
+			return withOutbox(func(outbox *channels.Outbox) error {
+				if allDead {
+					n, err := outbox.ReplayDead(channelID)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Queued %d dead-lettered messages for delivery\n", n)
+					return nil
+				}
+
+				for _, id := range ids {
+					if err := outbox.Replay(id); err != nil {
+						return err
+					}
+					fmt.Printf("Queued message %d for delivery\n", id)
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&allDead, "dead", false, "Replay all dead-lettered messages")
+	cmd.Flags().StringVar(&channelID, "channel", "", "With --dead, only replay messages for this channel")
+
+	return cmd
+}
+
+// outboxPurgeCmd deletes delivered or dead-lettered messages.
+func outboxPurgeCmd() *cobra.Command {
+	var (
+		status    string
+		olderThan time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete delivered or dead-lettered messages",
+		Long: `Delete delivered or dead-lettered messages. Pending messages are never purged.
+Delivered messages are also purged automatically after outbox.retention_hours.
+
+Examples:
+  conduit outbox purge
+  conduit outbox purge --status delivered --older-than 1h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if status != channels.OutboxDead && status != channels.OutboxDelivered {
+				return fmt.Errorf("invalid status '%s': must be dead or delivered", status)
+			}
+			return withOutbox(func(outbox *channels.Outbox) error {
+				n, err := outbox.Purge(status, time.Now().Add(-olderThan))
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Deleted %d %s messages\n", n, status)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&status, "status", channels.OutboxDead, "State of the messages to delete (dead, delivered)")
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "Only delete messages last updated longer ago than this")
+
+	return cmd
+}
+
+// --- command implementations ---
+
+func runOutboxList(outbox *channels.Outbox, filter channels.OutboxFilter, outputJSON bool) error {
+	entries, err := outbox.List(filter)
+	if err != nil {
+		return err
+	}
+
+	if outputJSON {
+		if entries == nil {
+			entries = []*channels.OutboxEntry{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	counts, err := outbox.Counts()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Pending: %d  Delivered: %d  Dead: %d\n\n",
+		counts[channels.OutboxPending], counts[channels.OutboxDelivered], counts[channels.OutboxDead])
+
+	if len(entries) == 0 {
+		fmt.Println("No messages found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCHANNEL\tUSER\tSTATUS\tATTEMPTS\tUPDATED\tTEXT / LAST ERROR")
+	fmt.Fprintln(w, "--\t-------\t----\t------\t--------\t-------\t-----------------")
+
+	for _, e := range entries {
+		summary := e.Message.Text
+		if e.LastError != "" {
+			summary = e.LastError
+		}
+		summary = strings.Join(strings.Fields(summary), " ")
+		if len(summary) > 60 {
+			summary = summary[:57] + "..."
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			e.ID,
+			e.ChannelID,
+			e.UserID,
+			e.Status,
+			e.Attempts,
+			e.UpdatedAt.Local().Format("2006-01-02 15:04"),
+			summary,
+		)
+	}
+
+	return w.Flush()
+}
+
+func runOutboxShow(outbox *channels.Outbox, id int64, outputJSON bool) error {
+	e, err := outbox.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(e)
+	}
+
+	fmt.Printf("ID:          %d\n", e.ID)
+	fmt.Printf("Channel:     %s\n", e.ChannelID)
+	fmt.Printf("User:        %s\n", e.UserID)
+	if e.SessionKey != "" {
+		fmt.Printf("Session:     %s\n", e.SessionKey)
+	}
+	fmt.Printf("Status:      %s\n", e.Status)
+	fmt.Printf("Attempts:    %d\n", e.Attempts)
+	fmt.Printf("Created:     %s\n", e.CreatedAt.Local().Format(time.RFC3339))
+	fmt.Printf("Updated:     %s\n", e.UpdatedAt.Local().Format(time.RFC3339))
+	if e.Status == channels.OutboxPending {
+		fmt.Printf("Next try:    %s\n", e.NextAttemptAt.Local().Format(time.RFC3339))
+	}
+	if e.LastError != "" {
+		fmt.Printf("Last error:  %s\n", e.LastError)
+	}
+	if e.Message.MediaPath != "" {
+		fmt.Printf("Media:       %s\n", e.Message.MediaPath)
+	}
+	fmt.Printf("\n%s\n", e.Message.Text)
+
+	return nil
+}
+
+// --- helpers ---
+
+// withOutbox opens the gateway database named in the config and runs fn
+// with its outbox
+func withOutbox(fn func(outbox *channels.Outbox) error) error {
+	return withGatewayDB(func(db *sql.DB) error {
+		return fn(channels.NewOutbox(db, channels.OutboxOptions{}))
+	})
+}
+
+// withGatewayDB opens and migrates the gateway database named in the
+// config and runs fn with it
+func withGatewayDB(fn func(db *sql.DB) error) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	path := cfg.Database.Path
+	if path == "" {
+		path = "gateway.db"
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if err := database.ConfigureDatabase(db); err != nil {
+		return fmt.Errorf("failed to configure database: %w", err)
+	}
+
+	return fn(db)
+}
+
+func parseOutboxID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid message ID: %s", arg)
+	}
+	return id, nil
+}
+
+func validateOutboxStatus(status string) error {
+	switch status {
+	case "", channels.OutboxPending, channels.OutboxDelivered, channels.OutboxDead:
+		return nil
+	}
+	return fmt.Errorf("invalid status '%s': must be pending, delivered or dead", status)
+}
+
+func init() {
+	rootCmd.AddCommand(OutboxRootCmd())
+}
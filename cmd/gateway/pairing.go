@@ -1,6 +1,7 @@
 package main
 
 import (
+	slack "conduit/internal/channels/slack"
 	telegram "conduit/internal/channels/telegram"
 
 	"github.com/spf13/cobra"
@@ -23,5 +24,11 @@ func PairingRootCmd(databasePath string, verbose bool) *cobra.Command {
 	// Add Telegram pairing commands
 	cmd.AddCommand(telegram.TelegramPairingRootCmd(telegramConfig))
 
+	// Add Slack pairing commands
+	cmd.AddCommand(slack.SlackPairingRootCmd(&slack.PairingCLIConfig{
+		DatabasePath: databasePath,
+		Verbose:      verbose,
+	}))
+
 	return cmd
 }
@@ -75,6 +75,21 @@ func (a *ConduitAgentWithIntegration) SetTools(tools []ai.Tool) {
 	)
 }
 
+// SetModelAliases updates the model aliases listed in the system prompt
+func (a *ConduitAgentWithIntegration) SetModelAliases(modelAliases map[string]string) {
+	a.modelAliases = modelAliases
+	a.promptBuilder = NewPromptBuilder(
+		a.name,
+		a.personality,
+		a.identity,
+		a.capabilities,
+		a.tools,
+		a.workspaceContext,
+		a.skillsManager,
+		a.modelAliases,
+	)
+}
+
 // Name returns the agent name
 func (a *ConduitAgentWithIntegration) Name() string {
 	return a.name
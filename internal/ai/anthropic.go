@@ -2,6 +2,7 @@ package ai
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -189,7 +190,7 @@ func (a *AnthropicProvider) GenerateResponse(ctx context.Context, req *GenerateR
 	if resp.StatusCode != http.StatusOK {
 		// Read body for error details
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError(resp, bodyBytes)
 	}
 
 	var anthropicResp map[string]interface{}
@@ -237,6 +238,13 @@ func (a *AnthropicProvider) convertMessagesToAnthropic(messages []ChatMessage) [
 	for _, msg := range messages {
 		switch msg.Role {
 		case "user":
+			if len(msg.Blocks) > 0 {
+				result = append(result, map[string]interface{}{
+					"role":    "user",
+					"content": a.convertBlocksToAnthropic(msg),
+				})
+				continue
+			}
 			result = append(result, map[string]interface{}{
 				"role":    "user",
 				"content": msg.Content,
@@ -275,16 +283,22 @@ func (a *AnthropicProvider) convertMessagesToAnthropic(messages []ChatMessage) [
 				})
 			}
 		case "tool":
-			// Tool results must be sent as user messages with tool_result content
+			// Tool results must be sent as user messages with tool_result content.
+			// Results for one assistant turn share a single user message.
+			toolResult := map[string]interface{}{
+				"type":        "tool_result",
+				"tool_use_id": msg.ToolCallID,
+				"content":     msg.Content,
+			}
+			if n := len(result); n > 0 && result[n-1]["role"] == "user" {
+				if blocks, ok := result[n-1]["content"].([]map[string]interface{}); ok && len(blocks) > 0 && blocks[0]["type"] == "tool_result" {
+					result[n-1]["content"] = append(blocks, toolResult)
+					continue
+				}
+			}
 			result = append(result, map[string]interface{}{
-				"role": "user",
-				"content": []map[string]interface{}{
-					{
-						"type":        "tool_result",
-						"tool_use_id": msg.ToolCallID,
-						"content":     msg.Content,
-					},
-				},
+				"role":    "user",
+				"content": []map[string]interface{}{toolResult},
 			})
 		}
 	}
@@ -292,6 +306,73 @@ func (a *AnthropicProvider) convertMessagesToAnthropic(messages []ChatMessage) [
 	return result
 }
 
+// convertBlocksToAnthropic builds an Anthropic content array from a message's
+// text followed by its image and document blocks
+func (a *AnthropicProvider) convertBlocksToAnthropic(msg ChatMessage) []map[string]interface{} {
+	content := make([]map[string]interface{}, 0, len(msg.Blocks)+1)
+	if msg.Content != "" {
+		content = append(content, map[string]interface{}{
+			"type": "text",
+			"text": msg.Content,
+		})
+	}
+
+	for _, block := range msg.Blocks {
+		switch block.Type {
+		case BlockTypeText:
+			if block.Text != "" {
+				content = append(content, map[string]interface{}{
+					"type": "text",
+					"text": block.Text,
+				})
+			}
+		case BlockTypeImage, BlockTypeDocument:
+			var source map[string]interface{}
+			if block.Data == "" && block.Path == "" && block.URL != "" {
+				source = map[string]interface{}{
+					"type": "url",
+					"url":  block.URL,
+				}
+			} else {
+				data, err := blockData(block)
+				if err != nil {
+					content = append(content, map[string]interface{}{
+						"type": "text",
+						"text": unavailableBlockText(block, err),
+					})
+					continue
+				}
+				source = map[string]interface{}{
+					"type":       "base64",
+					"media_type": block.MediaType,
+					"data":       data,
+				}
+				// Plain-text documents are sent as text sources
+				if block.Type == BlockTypeDocument && isTextMediaType(block.MediaType) {
+					if raw, err := base64.StdEncoding.DecodeString(data); err == nil {
+						source = map[string]interface{}{
+							"type":       "text",
+							"media_type": "text/plain",
+							"data":       string(raw),
+						}
+					}
+				}
+			}
+
+			converted := map[string]interface{}{
+				"type":   block.Type,
+				"source": source,
+			}
+			if block.Type == BlockTypeDocument && block.Filename != "" {
+				converted["title"] = block.Filename
+			}
+			content = append(content, converted)
+		}
+	}
+
+	return content
+}
+
 // Claude Code tool names that are known to work with OAuth tokens
 var claudeCodeTools = map[string]bool{
 	"Read": true, "Write": true, "Edit": true, "Bash": true,
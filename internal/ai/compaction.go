@@ -0,0 +1,333 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"conduit/internal/config"
+	"conduit/internal/sessions"
+)
+
+// Compaction defaults applied when ai.compaction leaves them unset
+const (
+	defaultHistoryShare     = 0.5
+	defaultCompactionScan   = 500
+	defaultSummaryMaxTokens = 1024
+
+	// legacyHistoryMessages is the fixed window used when compaction is disabled
+	legacyHistoryMessages = 20
+)
+
+// compactionTarget is the share of the history budget kept live after a
+// compaction, leaving headroom so the next few turns don't trigger another
+const compactionTarget = 0.5
+
+// Token estimates. Providers don't expose tokenizers, so history is measured
+// with the usual ~4 characters per token and flat costs for media.
+const (
+	tokenChars             = 4
+	messageOverheadTokens  = 4    // Role and framing per message
+	imageBlockTokens       = 1600 // Roughly a 1092x1092 image
+	documentBlockTokens    = 3000 // A few pages of a PDF
+	toolResultSummaryRunes = 500  // Tool output kept per result in summarizer transcripts
+	summaryMessageRunes    = 4000 // Text kept per message in summarizer transcripts
+)
+
+const summarizerPrompt = `You maintain a running summary of a conversation between a user and an AI assistant. ` +
+	`Older messages are being removed from the assistant's context, and your summary is all that will remain of them.
+
+Write a concise summary that preserves:
+- Facts the user shared about themselves, their work and their preferences
+- Decisions made, tasks completed and tasks still open
+- Important tool results, file paths, names, numbers and identifiers
+- Anything the user asked the assistant to remember
+
+If a previous summary is given, merge it with the new messages into a single updated summary. ` +
+	`Write in plain prose or short bullet points. Do not address the user.`
+
+// HistoryStats describes how session history was fitted into a request
+type HistoryStats struct {
+	LiveMessages       int `json:"live_messages"`
+	LiveTokens         int `json:"live_tokens"`
+	SummarizedMessages int `json:"summarized_messages"`
+	SummaryTokens      int `json:"summary_tokens"`
+	BudgetTokens       int `json:"budget_tokens"`
+}
+
+// Session context keys holding the HistoryStats of the last request
+const (
+	ContextKeyHistoryLiveMessages       = "history_live_messages"
+	ContextKeyHistoryLiveTokens         = "history_live_tokens"
+	ContextKeyHistorySummarizedMessages = "history_summarized_messages"
+	ContextKeyHistorySummaryTokens      = "history_summary_tokens"
+	ContextKeyHistoryBudgetTokens       = "history_budget_tokens"
+)
+
+// EstimateTokens approximates the number of tokens in text
+func EstimateTokens(text string) int {
+	return (len(text) + tokenChars - 1) / tokenChars
+}
+
+// MessageTokens approximates the tokens a stored message occupies in a request
+func MessageTokens(msg sessions.Message) int {
+	tokens := messageOverheadTokens + EstimateTokens(msg.Content)
+	for _, block := range msg.Blocks {
+		switch block.Type {
+		case BlockTypeText:
+			tokens += EstimateTokens(block.Text)
+		case BlockTypeImage:
+			tokens += imageBlockTokens
+		case BlockTypeDocument:
+			if isTextMediaType(block.MediaType) && block.Data != "" {
+				// Base64 expands by 4/3
+				tokens += len(block.Data) * 3 / 4 / tokenChars
+			} else {
+				tokens += documentBlockTokens
+			}
+		}
+	}
+	for _, tc := range msg.ToolCalls {
+		args, _ := json.Marshal(tc.Args)
+		tokens += EstimateTokens(tc.Name) + EstimateTokens(string(args))
+	}
+	return tokens
+}
+
+// historyBudget returns the tokens available to history for a model
+func historyBudget(model string, share float64) int {
+	return int(float64(ContextWindowForModel(model)) * share)
+}
+
+// compactionConfig returns ai.compaction with defaults filled in
+func (r *Router) compactionConfig() config.CompactionConfig {
+	var cfg config.CompactionConfig
+	if r.compactionCfg != nil {
+		cfg = *r.compactionCfg
+	}
+	if cfg.HistoryShare <= 0 {
+		cfg.HistoryShare = defaultHistoryShare
+	}
+	if cfg.MaxMessages <= 0 {
+		cfg.MaxMessages = defaultCompactionScan
+	}
+	if cfg.SummaryMaxTokens <= 0 {
+		cfg.SummaryMaxTokens = defaultSummaryMaxTokens
+	}
+	return cfg
+}
+
+// compactedHistory returns the session summary and the live messages that
+// fit model's history budget. When the unsummarized history exceeds the
+// budget, the oldest messages are folded into the stored summary first. If
+// summarizing fails they are left out of this request and retried next time.
+func (r *Router) compactedHistory(ctx context.Context, session *sessions.Session, model string) (string, []sessions.Message, error) {
+	cfg := r.compactionConfig()
+	if cfg.Disabled || r.sessionStore == nil {
+		messages, err := r.getRecentMessages(session, legacyHistoryMessages)
+		return "", messages, err
+	}
+
+	messages, err := r.getRecentMessages(session, cfg.MaxMessages)
+	if err != nil {
+		return "", nil, err
+	}
+
+	summary, err := r.sessionStore.GetSummary(session.Key)
+	if err != nil {
+		return "", nil, err
+	}
+	if summary == nil {
+		summary = &sessions.Summary{SessionKey: session.Key}
+	} else {
+		messages = sessions.MessagesAfter(messages, summary.ThroughMessageID)
+	}
+
+	// Without an override the default provider's configured model answers
+	if model == "" {
+		model = r.models[r.default_]
+	}
+	budget := historyBudget(model, cfg.HistoryShare)
+	if split := compactionSplit(messages, budget-EstimateTokens(summary.Content)); split > 0 {
+		older := messages[:split]
+		content, err := r.summarizeHistory(ctx, cfg, summary.Content, older)
+		if err != nil {
+			log.Printf("[Compaction] Failed to summarize %d messages for %s, leaving them out of context: %v", len(older), session.Key, err)
+		} else {
+			updated := &sessions.Summary{
+				SessionKey:       session.Key,
+				Content:          content,
+				ThroughMessageID: older[len(older)-1].ID,
+				MessageCount:     summary.MessageCount + len(older),
+			}
+			if err := r.sessionStore.SaveSummary(updated); err != nil {
+				log.Printf("[Compaction] Failed to save summary for %s: %v", session.Key, err)
+			} else {
+				log.Printf("[Compaction] Summarized %d messages for %s", len(older), session.Key)
+				summary = updated
+			}
+		}
+		messages = messages[split:]
+	}
+
+	stats := HistoryStats{
+		LiveMessages:       len(messages),
+		SummarizedMessages: summary.MessageCount,
+		SummaryTokens:      EstimateTokens(summary.Content),
+		BudgetTokens:       budget,
+	}
+	for _, msg := range messages {
+		stats.LiveTokens += MessageTokens(msg)
+	}
+	r.recordHistoryStats(session.Key, stats)
+
+	return summary.Content, messages, nil
+}
+
+// compactionSplit returns how many of the oldest messages to summarize so
+// the rest fit budget, or 0 if everything fits. Once over budget, history is
+// cut to compactionTarget of it, and the cut is moved to a user message so
+// live history never opens mid-exchange.
+func compactionSplit(messages []sessions.Message, budget int) int {
+	total := 0
+	for _, msg := range messages {
+		total += MessageTokens(msg)
+	}
+	if total <= budget {
+		return 0
+	}
+
+	target := int(float64(budget) * compactionTarget)
+	split := len(messages)
+	kept := 0
+	for split > 0 {
+		tokens := MessageTokens(messages[split-1])
+		if kept+tokens > target {
+			break
+		}
+		kept += tokens
+		split--
+	}
+
+	// Prefer dropping a little more to start on a user turn; otherwise keep
+	// a little more
+	for i := split; i < len(messages); i++ {
+		if messages[i].Role == "user" {
+			return i
+		}
+	}
+	for i := split - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return i
+		}
+	}
+	return split
+}
+
+// summarizeHistory asks the summarizer model to merge messages into the
+// previous summary
+func (r *Router) summarizeHistory(ctx context.Context, cfg config.CompactionConfig, previous string, messages []sessions.Message) (string, error) {
+	providerName := cfg.Provider
+	if providerName == "" {
+		providerName = r.default_
+	}
+
+	var prompt strings.Builder
+	if previous != "" {
+		prompt.WriteString("Previous summary:\n\n")
+		prompt.WriteString(previous)
+		prompt.WriteString("\n\n")
+	}
+	prompt.WriteString("New messages:\n\n")
+	prompt.WriteString(formatTranscript(messages))
+
+	req := &GenerateRequest{
+		Messages: []ChatMessage{
+			{Role: "system", Content: summarizerPrompt},
+			{Role: "user", Content: prompt.String()},
+		},
+		Model:     cfg.Model,
+		MaxTokens: cfg.SummaryMaxTokens,
+	}
+
+	response, _, _, err := r.generateWithFailover(ctx, providerName, req, callGenerate)
+	if err != nil {
+		return "", fmt.Errorf("summary request failed: %w", err)
+	}
+
+	content := strings.TrimSpace(response.Content)
+	if content == "" {
+		return "", fmt.Errorf("summarizer returned an empty summary")
+	}
+	return content, nil
+}
+
+// formatTranscript renders messages as plain text for the summarizer
+func formatTranscript(messages []sessions.Message) string {
+	var sb strings.Builder
+	for _, msg := range messages {
+		switch msg.Role {
+		case "tool":
+			fmt.Fprintf(&sb, "Tool result: %s\n\n", truncateRunes(msg.Content, toolResultSummaryRunes))
+			continue
+		case "user":
+			sb.WriteString("User: ")
+		case "assistant":
+			sb.WriteString("Assistant: ")
+		default:
+			sb.WriteString(msg.Role + ": ")
+		}
+
+		sb.WriteString(truncateRunes(msg.Content, summaryMessageRunes))
+		for _, block := range msg.Blocks {
+			switch block.Type {
+			case BlockTypeText:
+				sb.WriteString(" " + truncateRunes(block.Text, summaryMessageRunes))
+			default:
+				fmt.Fprintf(&sb, " [%s %s]", block.Type, block.Filename)
+			}
+		}
+		for _, tc := range msg.ToolCalls {
+			args, _ := json.Marshal(tc.Args)
+			fmt.Fprintf(&sb, " [called %s %s]", tc.Name, truncateRunes(string(args), toolResultSummaryRunes))
+		}
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
+
+// summarySystemBlock wraps a session summary for the system prompt
+func summarySystemBlock(summary string) string {
+	return "## Earlier Conversation\n\n" +
+		"Older messages in this session have been summarized to save context:\n\n" + summary
+}
+
+// recordHistoryStats stores the last request's history stats in the session
+// context for /context
+func (r *Router) recordHistoryStats(sessionKey string, stats HistoryStats) {
+	values := map[string]int{
+		ContextKeyHistoryLiveMessages:       stats.LiveMessages,
+		ContextKeyHistoryLiveTokens:         stats.LiveTokens,
+		ContextKeyHistorySummarizedMessages: stats.SummarizedMessages,
+		ContextKeyHistorySummaryTokens:      stats.SummaryTokens,
+		ContextKeyHistoryBudgetTokens:       stats.BudgetTokens,
+	}
+	for key, value := range values {
+		if err := r.sessionStore.SetSessionContext(sessionKey, key, strconv.Itoa(value)); err != nil {
+			log.Printf("[Compaction] Failed to record history stats for %s: %v", sessionKey, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"conduit/internal/config"
+	"conduit/internal/sessions"
+)
+
+// newCompactionRouter builds a router backed by a real session store, with a
+// history budget of share of gpt-4's 8192-token window
+func newCompactionRouter(t *testing.T, share float64) (*Router, *MockProvider, *sessions.Store) {
+	t.Helper()
+
+	store, err := sessions.NewStore(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	provider := NewMockProvider("mock")
+	router, err := NewRouter(config.AIConfig{
+		DefaultProvider: "mock",
+		Compaction:      &config.CompactionConfig{HistoryShare: share, Model: "summarizer"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	router.RegisterProvider("mock", provider)
+	router.SetSessionStore(store)
+	return router, provider, store
+}
+
+// addTurns stores n user/assistant exchanges of roughly 100 tokens each
+func addTurns(t *testing.T, store *sessions.Store, sessionKey string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := store.AddMessage(sessionKey, "user", fmt.Sprintf("question %d %s", i, strings.Repeat("q", 400)), nil); err != nil {
+			t.Fatalf("Failed to add message: %v", err)
+		}
+		if _, err := store.AddMessage(sessionKey, "assistant", fmt.Sprintf("answer %d %s", i, strings.Repeat("a", 400)), nil); err != nil {
+			t.Fatalf("Failed to add message: %v", err)
+		}
+	}
+}
+
+func TestMessageTokens(t *testing.T) {
+	text := sessions.Message{Role: "user", Content: strings.Repeat("x", 400)}
+	if got := MessageTokens(text); got != 104 {
+		t.Errorf("Expected 104 tokens for 400 chars, got %d", got)
+	}
+
+	withMedia := sessions.Message{Role: "user", Content: "look", Blocks: []ContentBlock{
+		{Type: BlockTypeImage, Path: "/tmp/a.png"},
+		{Type: BlockTypeDocument, MediaType: "application/pdf", Path: "/tmp/a.pdf"},
+	}}
+	if got := MessageTokens(withMedia); got != 5+imageBlockTokens+documentBlockTokens {
+		t.Errorf("Unexpected media estimate: %d", got)
+	}
+
+	toolCall := sessions.Message{Role: "assistant", ToolCalls: []ToolCall{{ID: "1", Name: "Read", Args: map[string]interface{}{"path": "a.txt"}}}}
+	if got := MessageTokens(toolCall); got <= messageOverheadTokens {
+		t.Errorf("Expected tool call arguments to count, got %d", got)
+	}
+}
+
+func TestCompactionSplit(t *testing.T) {
+	msg := func(role string) sessions.Message {
+		return sessions.Message{Role: role, Content: strings.Repeat("x", 396)} // 103 tokens
+	}
+	messages := []sessions.Message{
+		msg("user"), msg("assistant"),
+		msg("user"), msg("assistant"), msg("tool"), msg("assistant"),
+		msg("user"), msg("assistant"),
+	}
+
+	if split := compactionSplit(messages, 10000); split != 0 {
+		t.Errorf("Expected no split when history fits, got %d", split)
+	}
+
+	// Target is 250 tokens: the last two messages fit, and the cut already
+	// falls on a user message
+	if split := compactionSplit(messages, 500); split != 6 {
+		t.Errorf("Expected split at 6, got %d", split)
+	}
+
+	// Target is 350 tokens: three messages fit, so the cut moves forward
+	// past the assistant reply rather than starting mid-exchange
+	if split := compactionSplit(messages, 700); split != 6 {
+		t.Errorf("Expected split moved to user message at 6, got %d", split)
+	}
+
+	// With no later user message the cut moves back to keep the current turn
+	tail := []sessions.Message{msg("user"), msg("assistant"), msg("user"), msg("assistant"), msg("tool"), msg("assistant")}
+	if split := compactionSplit(tail, 400); split != 2 {
+		t.Errorf("Expected split moved back to 2, got %d", split)
+	}
+}
+
+func TestRouter_CompactsHistoryIntoSummary(t *testing.T) {
+	router, provider, store := newCompactionRouter(t, 0.1) // 819-token budget
+	provider.AddResponse("User asked ten questions.", nil)
+
+	session, err := store.GetOrCreateSession("user1", "test")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	addTurns(t, store, session.Key, 10)
+
+	ctx := context.Background()
+	messages, err := router.buildChatMessagesWithSystemPrompt(ctx, session, "next", "gpt-4", []SystemBlock{{Type: "text", Text: "You are helpful."}})
+	if err != nil {
+		t.Fatalf("Failed to build messages: %v", err)
+	}
+
+	// One summarization call with the oldest turns in the transcript
+	if provider.GetCallCount() != 1 {
+		t.Fatalf("Expected 1 summarizer call, got %d", provider.GetCallCount())
+	}
+	summaryReq := provider.LastCall().Request
+	if summaryReq.Model != "summarizer" {
+		t.Errorf("Expected summarizer model, got %q", summaryReq.Model)
+	}
+	if !strings.Contains(summaryReq.Messages[1].Content, "User: question 0") {
+		t.Errorf("Expected oldest turn in transcript, got %q", summaryReq.Messages[1].Content[:80])
+	}
+
+	// The summary is appended to the system prompt and old turns are dropped
+	if !strings.HasPrefix(messages[0].Content, "You are helpful.") || !strings.Contains(messages[0].Content, "User asked ten questions.") {
+		t.Errorf("Expected summary in system prompt, got %q", messages[0].Content)
+	}
+	if messages[1].Role != "user" {
+		t.Errorf("Expected live history to start with a user turn, got %s", messages[1].Role)
+	}
+	live := len(messages) - 2 // system prompt and current message
+	if live >= 20 || live == 0 {
+		t.Fatalf("Expected a trimmed live window, got %d messages", live)
+	}
+
+	summary, err := store.GetSummary(session.Key)
+	if err != nil || summary == nil {
+		t.Fatalf("Expected stored summary, got %v (err %v)", summary, err)
+	}
+	if summary.MessageCount != 20-live {
+		t.Errorf("Expected %d summarized messages, got %d", 20-live, summary.MessageCount)
+	}
+
+	session, _ = store.GetSession(session.Key)
+	if session.Context[ContextKeyHistoryLiveMessages] != fmt.Sprint(live) {
+		t.Errorf("Expected live message stat %d, got %q", live, session.Context[ContextKeyHistoryLiveMessages])
+	}
+	if session.Context[ContextKeyHistorySummarizedMessages] != fmt.Sprint(summary.MessageCount) {
+		t.Errorf("Unexpected summarized stat %q", session.Context[ContextKeyHistorySummarizedMessages])
+	}
+
+	// The next request fits without summarizing again
+	if _, err := router.buildChatMessagesWithSystemPrompt(ctx, session, "next", "gpt-4", nil); err != nil {
+		t.Fatalf("Failed to build messages: %v", err)
+	}
+	if provider.GetCallCount() != 1 {
+		t.Errorf("Expected no further summarizer calls, got %d", provider.GetCallCount())
+	}
+
+	// Reset clears the summary along with the messages
+	if err := store.ClearSessionMessages(session.Key); err != nil {
+		t.Fatalf("Failed to clear messages: %v", err)
+	}
+	if summary, _ := store.GetSummary(session.Key); summary != nil {
+		t.Errorf("Expected summary cleared, got %+v", summary)
+	}
+}
+
+func TestRouter_CompactionBudgetUsesDefaultModel(t *testing.T) {
+	router, provider, store := newCompactionRouter(t, 0.1)
+	router.models["mock"] = "gpt-4"
+	provider.AddResponse("User asked ten questions.", nil)
+
+	session, err := store.GetOrCreateSession("user1", "test")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	addTurns(t, store, session.Key, 10)
+
+	// No model override: the budget comes from gpt-4's window, not the default
+	if _, err := router.buildChatMessagesWithSystemPrompt(context.Background(), session, "next", "", nil); err != nil {
+		t.Fatalf("Failed to build messages: %v", err)
+	}
+	if provider.GetCallCount() != 1 {
+		t.Errorf("Expected history over the default model's budget to be summarized, got %d summarizer calls", provider.GetCallCount())
+	}
+}
+
+func TestRouter_CompactionSummarizerFailure(t *testing.T) {
+	router, provider, store := newCompactionRouter(t, 0.1)
+	provider.AddErrorResponse(fmt.Errorf("summarizer down"))
+
+	session, err := store.GetOrCreateSession("user1", "test")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	addTurns(t, store, session.Key, 10)
+
+	messages, err := router.buildChatMessagesWithSystemPrompt(context.Background(), session, "next", "gpt-4", nil)
+	if err != nil {
+		t.Fatalf("Expected request to proceed without a summary, got %v", err)
+	}
+
+	// History is still trimmed to the budget, with nothing stored
+	if len(messages) >= 21 {
+		t.Errorf("Expected trimmed history, got %d messages", len(messages))
+	}
+	if messages[0].Role == "system" {
+		t.Errorf("Expected no summary system block, got %q", messages[0].Content)
+	}
+	if summary, _ := store.GetSummary(session.Key); summary != nil {
+		t.Errorf("Expected no stored summary, got %+v", summary)
+	}
+}
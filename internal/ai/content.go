@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"conduit/internal/sessions"
+)
+
+// ContentBlock is one part of a multimodal message (text, image or
+// document). It is shared with the session store so blocks persist and
+// replay without conversion.
+type ContentBlock = sessions.ContentBlock
+
+// Content block types, re-exported for callers building requests
+const (
+	BlockTypeText     = sessions.BlockTypeText
+	BlockTypeImage    = sessions.BlockTypeImage
+	BlockTypeDocument = sessions.BlockTypeDocument
+)
+
+// NewImageBlock creates an inline image block from raw bytes
+func NewImageBlock(mediaType string, data []byte) ContentBlock {
+	return ContentBlock{
+		Type:      BlockTypeImage,
+		MediaType: mediaType,
+		Data:      base64.StdEncoding.EncodeToString(data),
+	}
+}
+
+// NewDocumentBlock creates an inline document block from raw bytes
+func NewDocumentBlock(mediaType, filename string, data []byte) ContentBlock {
+	return ContentBlock{
+		Type:      BlockTypeDocument,
+		MediaType: mediaType,
+		Filename:  filename,
+		Data:      base64.StdEncoding.EncodeToString(data),
+	}
+}
+
+// blockData returns the base64 payload of a block, reading Path if the
+// bytes are not inlined
+func blockData(block ContentBlock) (string, error) {
+	if block.Data != "" {
+		return block.Data, nil
+	}
+	if block.Path == "" {
+		return "", fmt.Errorf("content block has no data")
+	}
+	raw, err := os.ReadFile(block.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", block.Path, err)
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// isTextMediaType reports whether a document can be sent as plain text
+func isTextMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		mediaType == "application/xml"
+}
+
+// unavailableBlockText describes a block whose bytes could not be loaded,
+// so the model still knows something was attached
+func unavailableBlockText(block ContentBlock, err error) string {
+	log.Printf("[Content] Skipping %s block: %v", block.Type, err)
+	name := block.Filename
+	if name == "" {
+		name = block.MediaType
+	}
+	return fmt.Sprintf("[%s unavailable: %s]", block.Type, name)
+}
@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"conduit/internal/sessions"
+)
+
+func TestAnthropicProvider_ConvertBlocks(t *testing.T) {
+	provider := &AnthropicProvider{}
+
+	messages := []ChatMessage{{
+		Role:    "user",
+		Content: "what's in these?",
+		Blocks: []ContentBlock{
+			NewImageBlock("image/jpeg", []byte("jpegbytes")),
+			NewDocumentBlock("application/pdf", "report.pdf", []byte("%PDF")),
+			NewDocumentBlock("text/plain", "notes.txt", []byte("hello notes")),
+			{Type: BlockTypeImage, URL: "https://example.com/cat.png"},
+			{Type: BlockTypeImage, MediaType: "image/png", Path: "/does/not/exist.png"},
+		},
+	}}
+
+	converted := provider.convertMessagesToAnthropic(messages)
+	if len(converted) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(converted))
+	}
+	content, ok := converted[0]["content"].([]map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected content array, got %T", converted[0]["content"])
+	}
+	if len(content) != 6 {
+		t.Fatalf("Expected 6 content entries, got %d: %v", len(content), content)
+	}
+
+	if content[0]["type"] != "text" || content[0]["text"] != "what's in these?" {
+		t.Errorf("Expected leading text block, got %v", content[0])
+	}
+
+	image := content[1]["source"].(map[string]interface{})
+	if content[1]["type"] != "image" || image["type"] != "base64" || image["media_type"] != "image/jpeg" {
+		t.Errorf("Unexpected image block: %v", content[1])
+	}
+	if image["data"] != base64.StdEncoding.EncodeToString([]byte("jpegbytes")) {
+		t.Errorf("Unexpected image data: %v", image["data"])
+	}
+
+	pdf := content[2]["source"].(map[string]interface{})
+	if content[2]["type"] != "document" || pdf["type"] != "base64" || content[2]["title"] != "report.pdf" {
+		t.Errorf("Unexpected document block: %v", content[2])
+	}
+
+	text := content[3]["source"].(map[string]interface{})
+	if text["type"] != "text" || text["data"] != "hello notes" {
+		t.Errorf("Expected text document source, got %v", text)
+	}
+
+	url := content[4]["source"].(map[string]interface{})
+	if url["type"] != "url" || url["url"] != "https://example.com/cat.png" {
+		t.Errorf("Expected url image source, got %v", url)
+	}
+
+	if content[5]["type"] != "text" || content[5]["text"] != "[image unavailable: image/png]" {
+		t.Errorf("Expected placeholder for missing file, got %v", content[5])
+	}
+}
+
+func TestOpenAIProvider_ConvertBlocks(t *testing.T) {
+	provider := &OpenAIProvider{}
+
+	path := filepath.Join(t.TempDir(), "photo.png")
+	if err := os.WriteFile(path, []byte("pngbytes"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	messages := []ChatMessage{{
+		Role: "user",
+		Blocks: []ContentBlock{
+			{Type: BlockTypeImage, MediaType: "image/png", Path: path},
+			NewDocumentBlock("application/pdf", "report.pdf", []byte("%PDF")),
+			NewDocumentBlock("text/csv", "data.csv", []byte("a,b")),
+		},
+	}}
+
+	converted := provider.convertMessagesToOpenAI(messages)
+	parts, ok := converted[0]["content"].([]map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected content parts, got %T", converted[0]["content"])
+	}
+	if len(parts) != 3 {
+		t.Fatalf("Expected 3 parts (no empty text), got %d: %v", len(parts), parts)
+	}
+
+	imageURL := parts[0]["image_url"].(map[string]interface{})["url"]
+	want := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("pngbytes"))
+	if parts[0]["type"] != "image_url" || imageURL != want {
+		t.Errorf("Unexpected image part: %v", parts[0])
+	}
+
+	file := parts[1]["file"].(map[string]interface{})
+	if parts[1]["type"] != "file" || file["filename"] != "report.pdf" {
+		t.Errorf("Unexpected file part: %v", parts[1])
+	}
+
+	if parts[2]["type"] != "text" || parts[2]["text"] != "[data.csv]\na,b" {
+		t.Errorf("Expected inlined text document, got %v", parts[2])
+	}
+}
+
+func TestAppendHistory_KeepsPersistedUserMessage(t *testing.T) {
+	history := []sessions.Message{
+		{Role: "assistant", Content: "hi"},
+		{Role: "user", Content: "look", Blocks: []ContentBlock{{Type: BlockTypeImage, MediaType: "image/png", Data: "AA=="}}},
+	}
+
+	messages := appendHistory(nil, history, "look")
+	if len(messages) != 2 {
+		t.Fatalf("Expected current message not to be duplicated, got %d messages", len(messages))
+	}
+	if len(messages[1].Blocks) != 1 {
+		t.Errorf("Expected persisted blocks to carry through, got %+v", messages[1])
+	}
+
+	// A different current message is still appended
+	messages = appendHistory(nil, history, "and now?")
+	if len(messages) != 3 || messages[2].Content != "and now?" {
+		t.Errorf("Expected new user message appended, got %+v", messages)
+	}
+}
@@ -0,0 +1,319 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"conduit/internal/config"
+)
+
+// Failover defaults applied when the corresponding config field is zero
+const (
+	defaultFailoverMaxRetries       = 2
+	defaultFailoverRetryBackoff     = 500 * time.Millisecond
+	defaultFailoverMaxBackoff       = 10 * time.Second
+	defaultFailoverFailureThreshold = 5
+	defaultFailoverCooldown         = 60 * time.Second
+)
+
+// APIError is returned by providers when the upstream API responds with a
+// non-200 status. It lets the router distinguish retryable failures.
+type APIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // Parsed Retry-After header, zero if absent
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error: %d - %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether the request may succeed if retried (429 or 5xx)
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// newAPIError builds an APIError from a failed HTTP response
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+	}
+	if ra := resp.Header.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil && secs > 0 {
+			apiErr.RetryAfter = time.Duration(secs) * time.Second
+		} else if at, err := http.ParseTime(ra); err == nil {
+			apiErr.RetryAfter = time.Until(at)
+		}
+	}
+	return apiErr
+}
+
+// isProviderFault reports whether err counts against the provider's health:
+// rate limits, 5xx responses and transport failures do, while other 4xx
+// responses (bad request, auth) are problems with the request itself
+func isProviderFault(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return err != nil
+}
+
+// noFailoverError marks an error that must be returned to the caller without
+// retrying or trying another provider, e.g. a stream that already emitted text
+type noFailoverError struct {
+	err error
+}
+
+func (e *noFailoverError) Error() string { return e.err.Error() }
+func (e *noFailoverError) Unwrap() error { return e.err }
+
+// CircuitState describes whether a provider is currently accepting requests
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // Provider is healthy
+	CircuitOpen     CircuitState = "open"      // Provider is skipped until cooldown elapses
+	CircuitHalfOpen CircuitState = "half_open" // Cooldown elapsed; next request is a trial
+)
+
+// CircuitBreaker decides whether a provider may be tried based on the
+// consecutive error counts kept by UsageTracker.RecordError. A successful
+// RecordUsage resets the count and closes the circuit again.
+type CircuitBreaker struct {
+	tracker   *UsageTracker
+	threshold int64
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+// NewCircuitBreaker creates a circuit breaker reading from tracker
+func NewCircuitBreaker(tracker *UsageTracker, threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = defaultFailoverFailureThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultFailoverCooldown
+	}
+	return &CircuitBreaker{
+		tracker:   tracker,
+		threshold: int64(threshold),
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// State returns the circuit state for a provider
+func (cb *CircuitBreaker) State(provider string) CircuitState {
+	if cb == nil || cb.tracker == nil {
+		return CircuitClosed
+	}
+
+	record, ok := cb.tracker.GetProviderUsage(provider)
+	if !ok || record.ConsecutiveErrors < cb.threshold {
+		return CircuitClosed
+	}
+
+	if cb.now().Sub(record.LastErrorAt) >= cb.cooldown {
+		return CircuitHalfOpen
+	}
+	return CircuitOpen
+}
+
+// Allow reports whether a request may be sent to the provider
+func (cb *CircuitBreaker) Allow(provider string) bool {
+	return cb.State(provider) != CircuitOpen
+}
+
+// failoverPolicy holds the resolved failover settings for a router
+type failoverPolicy struct {
+	chain      []string
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// newFailoverPolicy resolves a failover config, applying defaults
+func newFailoverPolicy(cfg *config.FailoverConfig) *failoverPolicy {
+	policy := &failoverPolicy{
+		maxRetries: defaultFailoverMaxRetries,
+		backoff:    defaultFailoverRetryBackoff,
+		maxBackoff: defaultFailoverMaxBackoff,
+	}
+	if cfg == nil {
+		return policy
+	}
+
+	policy.chain = append(policy.chain, cfg.Chain...)
+	if cfg.MaxRetries > 0 {
+		policy.maxRetries = cfg.MaxRetries
+	}
+	if cfg.RetryBackoffMs > 0 {
+		policy.backoff = time.Duration(cfg.RetryBackoffMs) * time.Millisecond
+	}
+	if cfg.MaxBackoffMs > 0 {
+		policy.maxBackoff = time.Duration(cfg.MaxBackoffMs) * time.Millisecond
+	}
+	return policy
+}
+
+// delay returns the wait before retry attempt n (1-based)
+func (p *failoverPolicy) delay(attempt int, err error) time.Duration {
+	d := p.backoff << (attempt - 1)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > d {
+		d = apiErr.RetryAfter
+	}
+	if d > p.maxBackoff || d <= 0 {
+		d = p.maxBackoff
+	}
+	return d
+}
+
+// providerCall performs one request against a provider
+type providerCall func(ctx context.Context, provider Provider, req *GenerateRequest) (*GenerateResponse, error)
+
+// candidates returns the ordered, de-duplicated provider names to try
+func (r *Router) candidates(primary string) []string {
+	names := []string{primary}
+	seen := map[string]bool{primary: true}
+	if r.failover != nil {
+		for _, name := range r.failover.chain {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// generateWithFailover sends req to the primary provider, retrying 429/5xx
+// responses with backoff and then walking the failover chain. Providers with
+// an open circuit are skipped. The model override only applies to the
+// primary provider; fallbacks use their own configured model. Returns the
+// response (with Provider set), the provider that produced it and the request
+// actually sent to it. Without a failover config only the primary provider is
+// tried, once.
+func (r *Router) generateWithFailover(ctx context.Context, primary string, req *GenerateRequest, call providerCall) (*GenerateResponse, Provider, *GenerateRequest, error) {
+	policy := r.failover
+	if policy == nil {
+		policy = &failoverPolicy{}
+	}
+
+	var lastErr error
+	for _, name := range r.candidates(primary) {
+		provider, exists := r.providers[name]
+		if !exists {
+			if name == primary {
+				return nil, nil, nil, fmt.Errorf("provider not found: %s", name)
+			}
+			log.Printf("[Router] Failover provider %s not configured, skipping", name)
+			continue
+		}
+
+		if !r.circuitBreaker.Allow(name) {
+			log.Printf("[Router] Circuit open for provider %s, skipping", name)
+			lastErr = fmt.Errorf("provider %s unavailable: circuit open", name)
+			continue
+		}
+
+		attemptReq := *req
+		if name != primary {
+			attemptReq.Model = ""
+		}
+
+		for attempt := 0; ; attempt++ {
+			if attempt > 0 {
+				wait := policy.delay(attempt, lastErr)
+				log.Printf("[Router] Retrying provider %s in %v (attempt %d/%d)", name, wait, attempt, policy.maxRetries)
+				select {
+				case <-ctx.Done():
+					return nil, nil, nil, ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+
+			start := time.Now()
+			response, err := call(ctx, provider, &attemptReq)
+			latencyMs := time.Since(start).Milliseconds()
+			if err == nil {
+				if r.usageTracker != nil {
+					r.usageTracker.RecordUsage(name, attemptReq.Model, response.Usage.PromptTokens, response.Usage.CompletionTokens, latencyMs)
+				}
+				if name != primary {
+					log.Printf("[Router] Provider %s answered after failover from %s", name, primary)
+				}
+				response.Provider = name
+				return response, provider, &attemptReq, nil
+			}
+
+			lastErr = err
+
+			// Cancellation is the caller's decision, not a provider fault
+			if ctx.Err() != nil {
+				return nil, nil, nil, err
+			}
+			var nf *noFailoverError
+			if errors.As(err, &nf) {
+				return nil, nil, nil, nf.err
+			}
+			if !isRetryableError(err) || attempt >= policy.maxRetries || !r.circuitBreaker.Allow(name) {
+				break
+			}
+		}
+
+		// Count the failed request once against the provider, and only if
+		// the provider is at fault rather than the request
+		if r.usageTracker != nil && isProviderFault(lastErr) {
+			r.usageTracker.RecordError(name, attemptReq.Model)
+		}
+		log.Printf("[Router] Provider %s failed: %v", name, lastErr)
+	}
+
+	return nil, nil, nil, lastErr
+}
+
+// ProviderReporter is implemented by conversation responses that record
+// which provider produced them.
+type ProviderReporter interface {
+	GetProvider() string
+}
+
+// ResponseProvider returns the provider that produced resp, or "" if unknown
+func ResponseProvider(resp ConversationResponse) string {
+	if reporter, ok := resp.(ProviderReporter); ok {
+		return reporter.GetProvider()
+	}
+	return ""
+}
+
+// attributedResponse adds provider attribution to a conversation response
+// produced by the execution engine
+type attributedResponse struct {
+	ConversationResponse
+	provider string
+}
+
+func (a *attributedResponse) GetProvider() string {
+	return a.provider
+}
+
+func (a *attributedResponse) GetToolTurns() []ChatMessage {
+	return ResponseToolTurns(a.ConversationResponse)
+}
+
+// attributeProvider records which provider answered on a conversation response
+func attributeProvider(resp ConversationResponse, provider string) ConversationResponse {
+	if simple, ok := resp.(*SimpleConversationResponse); ok {
+		simple.Provider = provider
+		return simple
+	}
+	return &attributedResponse{ConversationResponse: resp, provider: provider}
+}
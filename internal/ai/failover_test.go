@@ -0,0 +1,305 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"conduit/internal/config"
+	"conduit/internal/sessions"
+)
+
+// newFailoverRouter builds a router with the given mock providers registered
+// in order and failover configured across all of them
+func newFailoverRouter(t *testing.T, failover *config.FailoverConfig, providers ...*MockProvider) *Router {
+	t.Helper()
+
+	cfg := config.AIConfig{
+		DefaultProvider: providers[0].Name(),
+		Failover:        failover,
+	}
+	router, err := NewRouter(cfg, nil)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	for _, p := range providers {
+		router.RegisterProvider(p.Name(), p)
+	}
+	return router
+}
+
+func TestAPIError_Retryable(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{429, true},
+		{500, true},
+		{503, true},
+		{400, false},
+		{401, false},
+		{404, false},
+	}
+
+	for _, tt := range tests {
+		err := &APIError{StatusCode: tt.status}
+		if got := isRetryableError(err); got != tt.want {
+			t.Errorf("isRetryableError(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+
+	// Wrapped errors are still classified by status
+	wrapped := fmt.Errorf("AI provider error: %w", &APIError{StatusCode: 502})
+	if !isRetryableError(wrapped) {
+		t.Error("Expected wrapped 502 to be retryable")
+	}
+}
+
+func TestNewAPIError_RetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: 429, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "3")
+
+	apiErr := newAPIError(resp, []byte("slow down"))
+	if apiErr.RetryAfter != 3*time.Second {
+		t.Errorf("Expected 3s retry-after, got %v", apiErr.RetryAfter)
+	}
+	if apiErr.Error() != "API error: 429 - slow down" {
+		t.Errorf("Unexpected error text: %s", apiErr.Error())
+	}
+}
+
+func TestRouter_Failover_NoConfigTriesOnce(t *testing.T) {
+	primary := NewMockProvider("primary")
+	primary.AddErrorResponse(&APIError{StatusCode: 503})
+	backup := NewMockProvider("backup")
+
+	router := newFailoverRouter(t, nil, primary, backup)
+
+	_, err := router.GenerateResponse(context.Background(), &sessions.Session{Key: "s"}, "hi", "")
+	if err == nil {
+		t.Fatal("Expected error without failover configured")
+	}
+	if primary.GetCallCount() != 1 || backup.GetCallCount() != 0 {
+		t.Errorf("Expected single attempt on primary, got primary=%d backup=%d", primary.GetCallCount(), backup.GetCallCount())
+	}
+}
+
+func TestRouter_Failover_RetriesThenSucceeds(t *testing.T) {
+	primary := NewMockProvider("primary")
+	primary.AddErrorResponse(&APIError{StatusCode: 429})
+	primary.AddResponse("second try", nil)
+
+	router := newFailoverRouter(t, &config.FailoverConfig{MaxRetries: 2, RetryBackoffMs: 1}, primary)
+
+	resp, err := router.GenerateResponse(context.Background(), &sessions.Session{Key: "s"}, "hi", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resp.Content != "second try" || resp.Provider != "primary" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+	if primary.GetCallCount() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", primary.GetCallCount())
+	}
+}
+
+func TestRouter_Failover_WalksChain(t *testing.T) {
+	primary := NewMockProvider("primary")
+	primary.SetResponses([]MockResponse{
+		{Error: &APIError{StatusCode: 500}},
+		{Error: &APIError{StatusCode: 500}},
+	})
+	broken := NewMockProvider("broken")
+	broken.AddErrorResponse(&APIError{StatusCode: 401})
+	backup := NewMockProvider("backup")
+	backup.AddResponse("from backup", nil)
+
+	router := newFailoverRouter(t, &config.FailoverConfig{
+		Chain:          []string{"missing", "broken", "backup"},
+		MaxRetries:     1,
+		RetryBackoffMs: 1,
+	}, primary, broken, backup)
+
+	resp, err := router.GenerateResponseWithTools(context.Background(), &sessions.Session{Key: "s"}, "hi", "", "claude-opus-4-6")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if resp.GetContent() != "from backup" {
+		t.Errorf("Expected backup content, got %q", resp.GetContent())
+	}
+	if got := ResponseProvider(resp); got != "backup" {
+		t.Errorf("Expected provider metadata 'backup', got %q", got)
+	}
+
+	// Primary retried once, non-retryable 401 is not retried
+	if primary.GetCallCount() != 2 || broken.GetCallCount() != 1 || backup.GetCallCount() != 1 {
+		t.Errorf("Unexpected call counts: primary=%d broken=%d backup=%d",
+			primary.GetCallCount(), broken.GetCallCount(), backup.GetCallCount())
+	}
+
+	// The model override is specific to the primary provider
+	if model := backup.LastCall().Request.Model; model != "" {
+		t.Errorf("Expected fallback to use its own model, got %q", model)
+	}
+	if model := primary.LastCall().Request.Model; model != "claude-opus-4-6" {
+		t.Errorf("Expected primary to receive model override, got %q", model)
+	}
+}
+
+func TestRouter_Failover_CircuitBreaker(t *testing.T) {
+	primary := NewMockProvider("primary")
+	primary.SetResponses([]MockResponse{
+		{Error: errors.New("connection refused")},
+		{Error: errors.New("connection refused")},
+		{Content: "recovered"},
+	})
+	backup := NewMockProvider("backup")
+	for i := 0; i < 3; i++ {
+		backup.AddResponse("from backup", nil)
+	}
+
+	router := newFailoverRouter(t, &config.FailoverConfig{
+		Chain:            []string{"backup"},
+		FailureThreshold: 2,
+		CooldownSeconds:  30,
+	}, primary, backup)
+
+	now := time.Now()
+	router.circuitBreaker.now = func() time.Time { return now }
+
+	session := &sessions.Session{Key: "s"}
+	for i := 0; i < 2; i++ {
+		if _, err := router.GenerateResponse(context.Background(), session, "hi", ""); err != nil {
+			t.Fatalf("Request %d: expected failover success, got %v", i, err)
+		}
+	}
+
+	if state := router.GetCircuitState("primary"); state != CircuitOpen {
+		t.Fatalf("Expected open circuit after threshold, got %s", state)
+	}
+
+	// Open circuit skips the primary entirely
+	resp, err := router.GenerateResponse(context.Background(), session, "hi", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resp.Provider != "backup" || primary.GetCallCount() != 2 {
+		t.Errorf("Expected primary to be skipped, provider=%s primary calls=%d", resp.Provider, primary.GetCallCount())
+	}
+
+	// After the cooldown a trial request goes through and closes the circuit
+	router.circuitBreaker.now = func() time.Time { return now.Add(31 * time.Second) }
+	if state := router.GetCircuitState("primary"); state != CircuitHalfOpen {
+		t.Fatalf("Expected half-open circuit after cooldown, got %s", state)
+	}
+
+	resp, err = router.GenerateResponse(context.Background(), session, "hi", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resp.Provider != "primary" || resp.Content != "recovered" {
+		t.Errorf("Expected primary to recover, got %+v", resp)
+	}
+	if state := router.GetCircuitState("primary"); state != CircuitClosed {
+		t.Errorf("Expected closed circuit after success, got %s", state)
+	}
+}
+
+func TestRouter_Failover_StreamingBeforeFirstDelta(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	primary, err := NewLocalProvider(config.ProviderConfig{Name: "primary", Model: "llama3", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	backup := NewMockProvider("backup")
+	backup.AddResponse("streamed from backup", nil)
+
+	router, err := NewRouter(config.AIConfig{
+		DefaultProvider: "primary",
+		Failover:        &config.FailoverConfig{Chain: []string{"backup"}, MaxRetries: 1, RetryBackoffMs: 1},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	router.RegisterProvider("primary", primary)
+	router.RegisterProvider("backup", backup)
+
+	var streamed strings.Builder
+	resp, err := router.GenerateResponseStreaming(context.Background(), &sessions.Session{Key: "s"}, "hi", "", func(delta string, done bool) {
+		streamed.WriteString(delta)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if streamed.String() != "streamed from backup" {
+		t.Errorf("Expected deltas from backup, got %q", streamed.String())
+	}
+	if ResponseProvider(resp) != "backup" {
+		t.Errorf("Expected provider 'backup', got %q", ResponseProvider(resp))
+	}
+
+	// The retried request counts as one failure
+	usage, ok := router.GetUsageTracker().GetProviderUsage("primary")
+	if !ok || usage.ErrorCount != 1 {
+		t.Errorf("Expected 1 recorded error for primary, got %+v", usage)
+	}
+}
+
+func TestRouter_Failover_OnlyProviderFaultsCount(t *testing.T) {
+	primary := NewMockProvider("primary")
+	primary.SetResponses([]MockResponse{
+		{Error: &APIError{StatusCode: 400}},
+		{Error: &APIError{StatusCode: 503}}, // Answered to the cancelled request
+		{Error: &APIError{StatusCode: 503}},
+		{Error: &APIError{StatusCode: 503}},
+		{Error: &APIError{StatusCode: 503}},
+	})
+
+	router := newFailoverRouter(t, &config.FailoverConfig{MaxRetries: 2, RetryBackoffMs: 1}, primary)
+	session := &sessions.Session{Key: "s"}
+
+	consecutive := func() int64 {
+		usage, ok := router.GetUsageTracker().GetProviderUsage("primary")
+		if !ok {
+			return 0
+		}
+		return usage.ConsecutiveErrors
+	}
+
+	// A bad request is the caller's fault and is not retried
+	if _, err := router.GenerateResponse(context.Background(), session, "hi", ""); err == nil {
+		t.Fatal("Expected the 400 to be returned")
+	}
+	if got := consecutive(); got != 0 {
+		t.Errorf("Expected a 400 not to count against the provider, got %d consecutive errors", got)
+	}
+
+	// A cancelled request is the caller's decision
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := router.GenerateResponse(ctx, session, "hi", ""); err == nil {
+		t.Fatal("Expected an error for the cancelled request")
+	}
+	if got := consecutive(); got != 0 {
+		t.Errorf("Expected cancellation not to count against the provider, got %d consecutive errors", got)
+	}
+
+	// Retries running out counts once, not once per attempt
+	if _, err := router.GenerateResponse(context.Background(), session, "hi", ""); err == nil {
+		t.Fatal("Expected the 503s to be returned")
+	}
+	if got := consecutive(); got != 1 {
+		t.Errorf("Expected one error after retries ran out, got %d", got)
+	}
+}
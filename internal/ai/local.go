@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"conduit/internal/config"
+)
+
+// DefaultLocalBaseURL is the OpenAI-compatible endpoint served by a default
+// Ollama install.
+const DefaultLocalBaseURL = "http://localhost:11434/v1"
+
+// ModelLister is an optional interface for providers that can enumerate the
+// models available on their backend.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// LocalProvider talks to an on-box model server (Ollama, llama.cpp, vLLM,
+// LM Studio, ...) through its OpenAI-compatible chat completions API.
+// Tool calling and streaming use the same wire format as OpenAIProvider.
+type LocalProvider struct {
+	*OpenAIProvider
+}
+
+// NewLocalProvider creates a provider for an OpenAI-compatible or Ollama
+// endpoint. BaseURL may point at the server root or its /v1 prefix; the API
+// key is optional and only sent when configured.
+func NewLocalProvider(cfg config.ProviderConfig) (*LocalProvider, error) {
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("model is required for local provider")
+	}
+
+	baseURL := normalizeLocalBaseURL(cfg.BaseURL)
+
+	return &LocalProvider{
+		OpenAIProvider: &OpenAIProvider{
+			name:    cfg.Name,
+			apiKey:  cfg.APIKey,
+			model:   cfg.Model,
+			baseURL: baseURL,
+			// Local models can be slow to load and generate on modest hardware
+			client: &http.Client{Timeout: 300 * time.Second},
+		},
+	}, nil
+}
+
+// normalizeLocalBaseURL returns the /v1 API root for a configured base URL
+func normalizeLocalBaseURL(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return DefaultLocalBaseURL
+	}
+	if !strings.HasSuffix(baseURL, "/v1") {
+		baseURL += "/v1"
+	}
+	return baseURL
+}
+
+// BaseURL returns the /v1 API root the provider sends requests to
+func (l *LocalProvider) BaseURL() string {
+	return l.baseURL
+}
+
+// ListModels returns the models served by the local endpoint. It tries the
+// OpenAI-compatible /v1/models listing first and falls back to Ollama's
+// native /api/tags for older Ollama releases.
+func (l *LocalProvider) ListModels(ctx context.Context) ([]string, error) {
+	var openaiList struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	openaiErr := l.getJSON(ctx, l.baseURL+"/models", &openaiList)
+	if openaiErr == nil {
+		models := make([]string, 0, len(openaiList.Data))
+		for _, m := range openaiList.Data {
+			models = append(models, m.ID)
+		}
+		return models, nil
+	}
+
+	var ollamaList struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	root := strings.TrimSuffix(l.baseURL, "/v1")
+	if err := l.getJSON(ctx, root+"/api/tags", &ollamaList); err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", openaiErr)
+	}
+
+	models := make([]string, 0, len(ollamaList.Models))
+	for _, m := range ollamaList.Models {
+		models = append(models, m.Name)
+	}
+	return models, nil
+}
+
+// getJSON issues a GET request and decodes the JSON response into out
+func (l *LocalProvider) getJSON(ctx context.Context, url string, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if l.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+l.apiKey)
+	}
+
+	resp, err := l.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return newAPIError(resp, bodyBytes)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
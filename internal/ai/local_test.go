@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"conduit/internal/config"
+)
+
+func TestNormalizeLocalBaseURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", DefaultLocalBaseURL},
+		{"http://localhost:11434", "http://localhost:11434/v1"},
+		{"http://localhost:11434/", "http://localhost:11434/v1"},
+		{"http://box:8080/v1", "http://box:8080/v1"},
+		{"http://box:8080/v1/", "http://box:8080/v1"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeLocalBaseURL(tt.in); got != tt.want {
+			t.Errorf("normalizeLocalBaseURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewLocalProvider_RequiresModel(t *testing.T) {
+	_, err := NewLocalProvider(config.ProviderConfig{Name: "local", Type: "local"})
+	if err == nil {
+		t.Fatal("Expected error when model is missing")
+	}
+}
+
+func TestRouter_LocalProviderType(t *testing.T) {
+	cfg := config.AIConfig{
+		DefaultProvider: "ollama",
+		Providers: []config.ProviderConfig{
+			{Name: "ollama", Type: "local", Model: "llama3.1:8b"},
+		},
+	}
+
+	router, err := NewRouter(cfg, nil)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	local, ok := router.providers["ollama"].(*LocalProvider)
+	if !ok {
+		t.Fatalf("Expected *LocalProvider, got %T", router.providers["ollama"])
+	}
+	if local.BaseURL() != DefaultLocalBaseURL {
+		t.Errorf("Expected default base URL, got %s", local.BaseURL())
+	}
+}
+
+func TestLocalProvider_GenerateResponseWithTools(t *testing.T) {
+	var gotPath, gotModel, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		gotModel, _ = reqBody["model"].(string)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []interface{}{
+				map[string]interface{}{
+					"message": map[string]interface{}{
+						"content": "",
+						"tool_calls": []interface{}{
+							map[string]interface{}{
+								"id": "call_1",
+								"function": map[string]interface{}{
+									"name":      "Read",
+									"arguments": `{"path": "notes.md"}`,
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	provider, err := NewLocalProvider(config.ProviderConfig{
+		Name:    "ollama",
+		Type:    "local",
+		Model:   "qwen2.5",
+		BaseURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	resp, err := provider.GenerateResponse(context.Background(), &GenerateRequest{
+		Messages: []ChatMessage{{Role: "user", Content: "read my notes"}},
+		Tools:    []Tool{{Name: "Read", Parameters: map[string]interface{}{"type": "object"}}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if gotPath != "/v1/chat/completions" {
+		t.Errorf("Expected /v1/chat/completions, got %s", gotPath)
+	}
+	if gotModel != "qwen2.5" {
+		t.Errorf("Expected model qwen2.5, got %s", gotModel)
+	}
+	if gotAuth != "" {
+		t.Errorf("Expected no Authorization header without API key, got %q", gotAuth)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Args["path"] != "notes.md" {
+		t.Errorf("Unexpected tool calls: %+v", resp.ToolCalls)
+	}
+}
+
+func TestLocalProvider_GenerateResponseStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, openAIStreamBody)
+	}))
+	defer server.Close()
+
+	provider, err := NewLocalProvider(config.ProviderConfig{Name: "local", Model: "llama3", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	var streamed string
+	resp, err := provider.GenerateResponseStream(context.Background(), &GenerateRequest{
+		Messages: []ChatMessage{{Role: "user", Content: "hi"}},
+	}, func(delta string, done bool) {
+		streamed += delta
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if streamed != "Let me check." || resp.Content != "Let me check." {
+		t.Errorf("Unexpected streamed content %q / %q", streamed, resp.Content)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Errorf("Expected 1 tool call, got %d", len(resp.ToolCalls))
+	}
+}
+
+func TestLocalProvider_ListModels(t *testing.T) {
+	t.Run("openai compatible", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/models" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, `{"object":"list","data":[{"id":"llama3.1:8b"},{"id":"qwen2.5:14b"}]}`)
+		}))
+		defer server.Close()
+
+		provider, _ := NewLocalProvider(config.ProviderConfig{Name: "local", Model: "llama3.1:8b", BaseURL: server.URL})
+		models, err := provider.ListModels(context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(models) != 2 || models[0] != "llama3.1:8b" || models[1] != "qwen2.5:14b" {
+			t.Errorf("Unexpected models: %v", models)
+		}
+	})
+
+	t.Run("ollama native fallback", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/tags" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, `{"models":[{"name":"mistral:7b"}]}`)
+		}))
+		defer server.Close()
+
+		provider, _ := NewLocalProvider(config.ProviderConfig{Name: "local", Model: "mistral:7b", BaseURL: server.URL})
+		router, _ := NewRouter(config.AIConfig{DefaultProvider: "local"}, nil)
+		router.RegisterProvider("local", provider)
+
+		models, err := router.ListModels(context.Background(), "")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(models) != 1 || models[0] != "mistral:7b" {
+			t.Errorf("Unexpected models: %v", models)
+		}
+	})
+
+	t.Run("unsupported provider", func(t *testing.T) {
+		router, _ := NewRouter(config.AIConfig{DefaultProvider: "mock"}, nil)
+		router.RegisterProvider("mock", NewMockProvider("mock"))
+
+		if _, err := router.ListModels(context.Background(), ""); err == nil {
+			t.Error("Expected error for provider without model listing")
+		}
+	})
+}
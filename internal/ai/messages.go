@@ -1,6 +1,7 @@
 package ai
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -22,30 +23,26 @@ func (r *Router) buildChatMessages(session *sessions.Session, userMessage string
 		return nil, err
 	}
 
-	for _, msg := range recentMessages {
-		// Skip messages with empty content - Anthropic API requires non-empty content
-		if msg.Content == "" {
-			continue
-		}
-		messages = append(messages, ChatMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
-	}
-
-	// Add current user message
-	messages = append(messages, ChatMessage{
-		Role:    "user",
-		Content: userMessage,
-	})
-
-	return messages, nil
+	return appendHistory(messages, recentMessages, userMessage), nil
 }
 
-// buildChatMessagesWithSystemPrompt constructs messages with agent system prompt
-func (r *Router) buildChatMessagesWithSystemPrompt(session *sessions.Session, userMessage string, systemBlocks []SystemBlock) ([]ChatMessage, error) {
+// buildChatMessagesWithSystemPrompt constructs messages with agent system
+// prompt. History is fitted to model's context window, with any summary of
+// compacted history appended to the system prompt.
+func (r *Router) buildChatMessagesWithSystemPrompt(ctx context.Context, session *sessions.Session, userMessage string, model string, systemBlocks []SystemBlock) ([]ChatMessage, error) {
 	var messages []ChatMessage
 
+	summary, recentMessages, err := r.compactedHistory(ctx, session, model)
+	if err != nil {
+		return nil, err
+	}
+	if summary != "" {
+		systemBlocks = append(systemBlocks[:len(systemBlocks):len(systemBlocks)], SystemBlock{
+			Type: "text",
+			Text: summarySystemBlock(summary),
+		})
+	}
+
 	// Build system message from system blocks
 	if len(systemBlocks) > 0 {
 		var systemContent strings.Builder
@@ -62,30 +59,81 @@ func (r *Router) buildChatMessagesWithSystemPrompt(session *sessions.Session, us
 		})
 	}
 
-	// Add recent message history (limit to last 20 messages for context)
-	recentMessages, err := r.getRecentMessages(session, 20)
-	if err != nil {
-		return nil, err
-	}
+	return appendHistory(messages, recentMessages, userMessage), nil
+}
 
-	for _, msg := range recentMessages {
-		// Skip messages with empty content - Anthropic API requires non-empty content
-		if msg.Content == "" {
+// appendHistory appends session history and the current user message. The
+// gateway persists the user message (with any attachments) before routing,
+// so when history already ends with it the stored copy is kept instead of
+// appending a text-only duplicate.
+func appendHistory(messages []ChatMessage, history []sessions.Message, userMessage string) []ChatMessage {
+	for _, msg := range repairToolPairs(history) {
+		// Skip messages with nothing to send - Anthropic API requires non-empty content
+		if msg.Content == "" && len(msg.Blocks) == 0 && len(msg.ToolCalls) == 0 {
 			continue
 		}
 		messages = append(messages, ChatMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
+			Role:       msg.Role,
+			Content:    msg.Content,
+			Blocks:     msg.Blocks,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
 		})
 	}
 
+	if n := len(history); n > 0 && history[n-1].Role == "user" && history[n-1].Content == userMessage && len(messages) > 0 {
+		last := messages[len(messages)-1]
+		if last.Role == "user" && last.Content == userMessage {
+			return messages
+		}
+	}
+
 	// Add current user message
-	messages = append(messages, ChatMessage{
+	return append(messages, ChatMessage{
 		Role:    "user",
 		Content: userMessage,
 	})
+}
 
-	return messages, nil
+// repairToolPairs keeps only complete tool exchanges. Providers reject a
+// tool result without its call and a call without its results, and the
+// history window can cut an exchange in half, so unmatched results are
+// dropped and calls missing any result are removed from their message.
+func repairToolPairs(history []sessions.Message) []sessions.Message {
+	results := make(map[string]bool)
+	for _, msg := range history {
+		if msg.Role == "tool" {
+			results[msg.ToolCallID] = true
+		}
+	}
+
+	repaired := make([]sessions.Message, 0, len(history))
+	answered := make(map[string]bool)
+	for _, msg := range history {
+		switch {
+		case msg.Role == "tool":
+			if !answered[msg.ToolCallID] {
+				continue
+			}
+		case len(msg.ToolCalls) > 0:
+			complete := true
+			for _, tc := range msg.ToolCalls {
+				if !results[tc.ID] {
+					complete = false
+					break
+				}
+			}
+			if complete {
+				for _, tc := range msg.ToolCalls {
+					answered[tc.ID] = true
+				}
+			} else {
+				msg.ToolCalls = nil
+			}
+		}
+		repaired = append(repaired, msg)
+	}
+	return repaired
 }
 
 // getRecentMessages retrieves recent messages from a session
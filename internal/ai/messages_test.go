@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"testing"
+
+	"conduit/internal/sessions"
+)
+
+func TestAppendHistory_ReplaysToolTurns(t *testing.T) {
+	history := []sessions.Message{
+		{Role: "user", Content: "weather?"},
+		{Role: "assistant", Content: "Checking", ToolCalls: []ToolCall{
+			{ID: "call_1", Name: "WebSearch", Args: map[string]interface{}{"query": "weather"}},
+			{ID: "call_2", Name: "WebFetch", Args: map[string]interface{}{"url": "https://example.com"}},
+		}},
+		{Role: "tool", ToolCallID: "call_1", Content: "sunny"},
+		{Role: "tool", ToolCallID: "call_2", Content: "22C"},
+		{Role: "assistant", Content: "Sunny, 22C"},
+	}
+
+	messages := appendHistory(nil, history, "thanks")
+	if len(messages) != 6 {
+		t.Fatalf("Expected 6 messages, got %d: %+v", len(messages), messages)
+	}
+	if len(messages[1].ToolCalls) != 2 {
+		t.Errorf("Expected tool calls to replay, got %+v", messages[1])
+	}
+	if messages[2].ToolCallID != "call_1" || messages[3].ToolCallID != "call_2" {
+		t.Errorf("Expected tool results linked by call ID, got %+v / %+v", messages[2], messages[3])
+	}
+
+	// Anthropic groups both results into one user message after the tool_use turn
+	converted := (&AnthropicProvider{}).convertMessagesToAnthropic(messages)
+	if len(converted) != 5 {
+		t.Fatalf("Expected 5 Anthropic messages, got %d", len(converted))
+	}
+	results, ok := converted[2]["content"].([]map[string]interface{})
+	if !ok || converted[2]["role"] != "user" || len(results) != 2 {
+		t.Fatalf("Expected merged tool_result message, got %+v", converted[2])
+	}
+	if results[0]["tool_use_id"] != "call_1" || results[1]["tool_use_id"] != "call_2" {
+		t.Errorf("Unexpected tool_result blocks: %+v", results)
+	}
+
+	// OpenAI keeps one tool message per result
+	openaiMessages := (&OpenAIProvider{}).convertMessagesToOpenAI(messages)
+	if len(openaiMessages) != 6 || openaiMessages[3]["tool_call_id"] != "call_2" {
+		t.Errorf("Unexpected OpenAI messages: %+v", openaiMessages)
+	}
+}
+
+func TestAppendHistory_RepairsTruncatedToolTurns(t *testing.T) {
+	// The window starts after the tool call, and the latest call has no result
+	history := []sessions.Message{
+		{Role: "tool", ToolCallID: "call_0", Content: "orphaned"},
+		{Role: "assistant", Content: "Done"},
+		{Role: "user", Content: "again"},
+		{Role: "assistant", Content: "", ToolCalls: []ToolCall{{ID: "call_1", Name: "Bash"}}},
+		{Role: "assistant", Content: "Interrupted", ToolCalls: []ToolCall{{ID: "call_2", Name: "Bash"}}},
+	}
+
+	messages := appendHistory(nil, history, "next")
+	want := []string{"Done", "again", "Interrupted", "next"}
+	if len(messages) != len(want) {
+		t.Fatalf("Expected %d messages, got %d: %+v", len(want), len(messages), messages)
+	}
+	for i, content := range want {
+		if messages[i].Content != content {
+			t.Errorf("Message %d: expected %q, got %q", i, content, messages[i].Content)
+		}
+		if messages[i].Role == "tool" || len(messages[i].ToolCalls) > 0 {
+			t.Errorf("Expected incomplete tool turns to be removed, got %+v", messages[i])
+		}
+	}
+}
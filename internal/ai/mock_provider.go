@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"strings"
 	"sync"
 )
 
@@ -76,6 +77,26 @@ func (m *MockProvider) GenerateResponse(ctx context.Context, req *GenerateReques
 	}, nil
 }
 
+// GenerateResponseStream implements StreamingProvider by returning the next
+// configured response and replaying its content to onDelta word by word
+func (m *MockProvider) GenerateResponseStream(ctx context.Context, req *GenerateRequest, onDelta StreamCallback) (*GenerateResponse, error) {
+	resp, err := m.GenerateResponse(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if onDelta != nil {
+		for _, word := range strings.SplitAfter(resp.Content, " ") {
+			if word != "" {
+				onDelta(word, false)
+			}
+		}
+		onDelta("", true)
+	}
+
+	return resp, nil
+}
+
 // SetResponses configures the responses that will be returned by GenerateResponse
 // Responses are returned in order, cycling back to the beginning when exhausted
 func (m *MockProvider) SetResponses(responses []MockResponse) {
@@ -3,20 +3,26 @@ package ai
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
 	"conduit/internal/config"
 )
 
+// openAIChatCompletionsURL is the OpenAI chat completions endpoint
+const openAIChatCompletionsURL = "https://api.openai.com/v1/chat/completions"
+
 // OpenAIProvider implements the OpenAI API
 type OpenAIProvider struct {
-	name   string
-	apiKey string
-	model  string
-	client *http.Client
+	name    string
+	apiKey  string
+	model   string
+	baseURL string // Empty uses the public OpenAI endpoint
+	client  *http.Client
 }
 
 // NewOpenAIProvider creates a new OpenAI provider
@@ -29,7 +35,7 @@ func NewOpenAIProvider(cfg config.ProviderConfig) (*OpenAIProvider, error) {
 		name:   cfg.Name,
 		apiKey: cfg.APIKey,
 		model:  cfg.Model,
-		client: &http.Client{Timeout: 30 * time.Second},
+		client: &http.Client{Timeout: 120 * time.Second},
 	}, nil
 }
 
@@ -38,13 +44,60 @@ func (o *OpenAIProvider) Name() string {
 }
 
 func (o *OpenAIProvider) GenerateResponse(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	httpReq, err := o.newChatCompletionRequest(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, newAPIError(resp, bodyBytes)
+	}
+
+	var openaiResp map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&openaiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Extract content, tool calls, and usage from OpenAI response
+	content, toolCalls := o.parseOpenAIContent(openaiResp)
+	usage := o.parseOpenAIUsage(openaiResp)
+
+	return &GenerateResponse{
+		Content:   content,
+		ToolCalls: toolCalls,
+		Usage:     usage,
+	}, nil
+}
+
+// newChatCompletionRequest builds the HTTP request for the chat completions
+// endpoint. When stream is true the request asks for SSE deltas and a final
+// usage chunk.
+func (o *OpenAIProvider) newChatCompletionRequest(ctx context.Context, req *GenerateRequest, stream bool) (*http.Request, error) {
+	// Determine which model to use
+	modelToUse := o.model
+	if req.Model != "" {
+		modelToUse = req.Model
+	}
+
 	// OpenAI API request format
 	openaiReq := map[string]interface{}{
-		"model":      o.model,
-		"messages":   req.Messages,
+		"model":      modelToUse,
+		"messages":   o.convertMessagesToOpenAI(req.Messages),
 		"max_tokens": req.MaxTokens,
 	}
 
+	if stream {
+		openaiReq["stream"] = true
+		openaiReq["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
 	// Add tools if provided
 	if len(req.Tools) > 0 {
 		openaiReq["tools"] = o.convertToolsToOpenAI(req.Tools)
@@ -56,38 +109,164 @@ func (o *OpenAIProvider) GenerateResponse(ctx context.Context, req *GenerateRequ
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(reqBody))
+	endpoint := openAIChatCompletionsURL
+	if o.baseURL != "" {
+		endpoint = o.baseURL + "/chat/completions"
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
-
-	resp, err := o.client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+	if o.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: %d", resp.StatusCode)
+	return httpReq, nil
+}
+
+// convertMessagesToOpenAI converts messages to OpenAI chat format.
+// Assistant tool calls carry their arguments as a JSON string, and tool
+// results reference the originating call through tool_call_id.
+func (o *OpenAIProvider) convertMessagesToOpenAI(messages []ChatMessage) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(messages))
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case "assistant":
+			if len(msg.ToolCalls) > 0 {
+				toolCalls := make([]map[string]interface{}, 0, len(msg.ToolCalls))
+				for _, tc := range msg.ToolCalls {
+					args := tc.Args
+					if args == nil {
+						args = make(map[string]interface{})
+					}
+					argsJSON, err := json.Marshal(args)
+					if err != nil {
+						argsJSON = []byte("{}")
+					}
+					toolCalls = append(toolCalls, map[string]interface{}{
+						"id":   tc.ID,
+						"type": "function",
+						"function": map[string]interface{}{
+							"name":      tc.Name,
+							"arguments": string(argsJSON),
+						},
+					})
+				}
+				converted := map[string]interface{}{
+					"role":       "assistant",
+					"tool_calls": toolCalls,
+				}
+				if msg.Content != "" {
+					converted["content"] = msg.Content
+				}
+				result = append(result, converted)
+			} else {
+				result = append(result, map[string]interface{}{
+					"role":    "assistant",
+					"content": msg.Content,
+				})
+			}
+		case "tool":
+			result = append(result, map[string]interface{}{
+				"role":         "tool",
+				"tool_call_id": msg.ToolCallID,
+				"content":      msg.Content,
+			})
+		case "user":
+			if len(msg.Blocks) > 0 {
+				result = append(result, map[string]interface{}{
+					"role":    "user",
+					"content": o.convertBlocksToOpenAI(msg),
+				})
+				continue
+			}
+			result = append(result, map[string]interface{}{
+				"role":    "user",
+				"content": msg.Content,
+			})
+		default:
+			result = append(result, map[string]interface{}{
+				"role":    msg.Role,
+				"content": msg.Content,
+			})
+		}
 	}
 
-	var openaiResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&openaiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	return result
+}
+
+// convertBlocksToOpenAI builds an OpenAI content parts array from a message's
+// text followed by its image and document blocks. Images become data URLs,
+// text documents are inlined and other documents are sent as file parts.
+func (o *OpenAIProvider) convertBlocksToOpenAI(msg ChatMessage) []map[string]interface{} {
+	parts := make([]map[string]interface{}, 0, len(msg.Blocks)+1)
+	if msg.Content != "" {
+		parts = append(parts, map[string]interface{}{
+			"type": "text",
+			"text": msg.Content,
+		})
 	}
 
-	// Extract content, tool calls, and usage from OpenAI response
-	content, toolCalls := o.parseOpenAIContent(openaiResp)
-	usage := o.parseOpenAIUsage(openaiResp)
+	for _, block := range msg.Blocks {
+		if block.Type == BlockTypeText {
+			if block.Text != "" {
+				parts = append(parts, map[string]interface{}{
+					"type": "text",
+					"text": block.Text,
+				})
+			}
+			continue
+		}
 
-	return &GenerateResponse{
-		Content:   content,
-		ToolCalls: toolCalls,
-		Usage:     usage,
-	}, nil
+		url := block.URL
+		var data string
+		if block.Data != "" || block.Path != "" || url == "" {
+			var err error
+			data, err = blockData(block)
+			if err != nil {
+				parts = append(parts, map[string]interface{}{
+					"type": "text",
+					"text": unavailableBlockText(block, err),
+				})
+				continue
+			}
+			url = fmt.Sprintf("data:%s;base64,%s", block.MediaType, data)
+		}
+
+		switch block.Type {
+		case BlockTypeImage:
+			parts = append(parts, map[string]interface{}{
+				"type":      "image_url",
+				"image_url": map[string]interface{}{"url": url},
+			})
+		case BlockTypeDocument:
+			if data != "" && isTextMediaType(block.MediaType) {
+				if raw, err := base64.StdEncoding.DecodeString(data); err == nil {
+					parts = append(parts, map[string]interface{}{
+						"type": "text",
+						"text": fmt.Sprintf("[%s]\n%s", block.Filename, string(raw)),
+					})
+					continue
+				}
+			}
+			parts = append(parts, map[string]interface{}{
+				"type": "file",
+				"file": map[string]interface{}{
+					"filename":  block.Filename,
+					"file_data": url,
+				},
+			})
+		}
+	}
+
+	return parts
 }
 
 // convertToolsToOpenAI converts tool definitions to OpenAI format
@@ -24,14 +24,36 @@ type ConversationResponse interface {
 	HasToolResults() bool
 }
 
+// ToolTurnReporter is implemented by conversation responses that carry the
+// intermediate assistant tool calls and tool results of the exchange, in
+// order, excluding the final assistant reply.
+type ToolTurnReporter interface {
+	GetToolTurns() []ChatMessage
+}
+
+// ResponseToolTurns returns the tool turns of resp, or nil if it has none
+func ResponseToolTurns(resp ConversationResponse) []ChatMessage {
+	if reporter, ok := resp.(ToolTurnReporter); ok {
+		return reporter.GetToolTurns()
+	}
+	return nil
+}
+
 // Router handles AI model interactions
 type Router struct {
 	providers       map[string]Provider
+	models          map[string]string // Configured model by provider name
 	default_        string
 	agentSystem     AgentSystem     // Add agent system to router
 	executionEngine ExecutionEngine // Tool execution engine (interface, not pointer)
 	sessionStore    *sessions.Store // Session store for retrieving message history
 	usageTracker    *UsageTracker
+	visionCfg       *config.VisionConfig     // Image tool model selection (nil = default provider)
+	compactionCfg   *config.CompactionConfig // History budgeting and summaries (nil = defaults)
+
+	// Failover components (nil unless ai.failover is configured)
+	failover       *failoverPolicy
+	circuitBreaker *CircuitBreaker
 
 	// Smart routing components
 	modelSelector      ModelSelector
@@ -76,6 +98,17 @@ type Provider interface {
 	GenerateResponse(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error)
 }
 
+// StreamingProvider is an optional interface for providers that can stream
+// responses. The router discovers it at runtime; providers that do not
+// implement it are served through the non-streaming path.
+type StreamingProvider interface {
+	Provider
+	// GenerateResponseStream generates a response, calling onDelta with each
+	// text delta and once with done=true when the stream completes. Tool calls
+	// detected mid-stream are returned on the final response.
+	GenerateResponseStream(ctx context.Context, req *GenerateRequest, onDelta StreamCallback) (*GenerateResponse, error)
+}
+
 // GenerateRequest represents a request to generate an AI response
 type GenerateRequest struct {
 	Messages  []ChatMessage `json:"messages"`
@@ -89,14 +122,16 @@ type GenerateResponse struct {
 	Content   string     `json:"content"`
 	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 	Usage     Usage      `json:"usage,omitempty"`
+	Provider  string     `json:"provider,omitempty"` // Set by the router to the provider that answered
 }
 
 // ChatMessage represents a message in a conversation
 type ChatMessage struct {
-	Role       string     `json:"role"` // "system", "user", "assistant", "tool"
-	Content    string     `json:"content"`
-	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // For assistant messages with tool calls
-	ToolCallID string     `json:"tool_call_id,omitempty"` // For tool result messages
+	Role       string         `json:"role"` // "system", "user", "assistant", "tool"
+	Content    string         `json:"content"`
+	Blocks     []ContentBlock `json:"blocks,omitempty"`       // Images/documents following Content
+	ToolCalls  []ToolCall     `json:"tool_calls,omitempty"`   // For assistant messages with tool calls
+	ToolCallID string         `json:"tool_call_id,omitempty"` // For tool result messages
 }
 
 // Tool represents a tool/function that the AI can call
@@ -106,12 +141,9 @@ type Tool struct {
 	Parameters  map[string]interface{} `json:"parameters"`
 }
 
-// ToolCall represents a tool function call from the AI
-type ToolCall struct {
-	ID   string                 `json:"id"`
-	Name string                 `json:"name"`
-	Args map[string]interface{} `json:"arguments"`
-}
+// ToolCall represents a tool function call from the AI. It is shared with
+// the session store so tool turns persist and replay without conversion.
+type ToolCall = sessions.ToolCall
 
 // Usage represents token usage statistics
 type Usage struct {
@@ -162,12 +194,17 @@ func ContextWindowForModel(model string) int {
 // NewRouter creates a new AI router
 func NewRouter(cfg config.AIConfig, agentSystem AgentSystem) (*Router, error) {
 	router := &Router{
-		providers:    make(map[string]Provider),
-		default_:     cfg.DefaultProvider,
-		agentSystem:  agentSystem,
-		usageTracker: NewUsageTracker(),
+		providers:     make(map[string]Provider),
+		models:        make(map[string]string),
+		default_:      cfg.DefaultProvider,
+		agentSystem:   agentSystem,
+		usageTracker:  NewUsageTracker(),
+		visionCfg:     cfg.Vision,
+		compactionCfg: cfg.Compaction,
 	}
 
+	router.configureFailover(cfg.Failover)
+
 	return router, router.initializeProviders(cfg)
 }
 
@@ -175,15 +212,36 @@ func NewRouter(cfg config.AIConfig, agentSystem AgentSystem) (*Router, error) {
 func NewRouterWithExecution(cfg config.AIConfig, agentSystem AgentSystem, executionEngine ExecutionEngine) (*Router, error) {
 	router := &Router{
 		providers:       make(map[string]Provider),
+		models:          make(map[string]string),
 		default_:        cfg.DefaultProvider,
 		agentSystem:     agentSystem,
 		executionEngine: executionEngine,
 		usageTracker:    NewUsageTracker(),
+		visionCfg:       cfg.Vision,
+		compactionCfg:   cfg.Compaction,
 	}
 
+	router.configureFailover(cfg.Failover)
+
 	return router, router.initializeProviders(cfg)
 }
 
+// configureFailover enables provider failover and circuit breaking when a
+// failover config is present
+func (r *Router) configureFailover(cfg *config.FailoverConfig) {
+	if cfg == nil {
+		return
+	}
+	r.failover = newFailoverPolicy(cfg)
+	r.circuitBreaker = NewCircuitBreaker(r.usageTracker, cfg.FailureThreshold, time.Duration(cfg.CooldownSeconds)*time.Second)
+}
+
+// GetCircuitState returns the circuit breaker state for a provider.
+// Providers are always reported closed when failover is not configured.
+func (r *Router) GetCircuitState(providerName string) CircuitState {
+	return r.circuitBreaker.State(providerName)
+}
+
 // SetSessionStore sets the session store for retrieving message history
 func (r *Router) SetSessionStore(store *sessions.Store) {
 	r.sessionStore = store
@@ -240,6 +298,8 @@ func (r *Router) initializeProviders(cfg config.AIConfig) error {
 			provider, err = NewAnthropicProvider(providerCfg)
 		case "openai":
 			provider, err = NewOpenAIProvider(providerCfg)
+		case "local":
+			provider, err = NewLocalProvider(providerCfg)
 		default:
 			return fmt.Errorf("unsupported provider type: %s", providerCfg.Type)
 		}
@@ -249,6 +309,7 @@ func (r *Router) initializeProviders(cfg config.AIConfig) error {
 		}
 
 		r.providers[providerCfg.Name] = provider
+		r.models[providerCfg.Name] = providerCfg.Model
 	}
 
 	return nil
@@ -259,6 +320,26 @@ func (r *Router) RegisterProvider(name string, provider Provider) {
 	r.providers[name] = provider
 }
 
+// ListModels returns the models available from a provider that implements
+// ModelLister. An empty provider name uses the default provider.
+func (r *Router) ListModels(ctx context.Context, providerName string) ([]string, error) {
+	if providerName == "" {
+		providerName = r.default_
+	}
+
+	provider, exists := r.providers[providerName]
+	if !exists {
+		return nil, fmt.Errorf("provider not found: %s", providerName)
+	}
+
+	lister, ok := provider.(ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support model listing", providerName)
+	}
+
+	return lister.ListModels(ctx)
+}
+
 // HasProviders returns true if the router has at least one provider configured
 func (r *Router) HasProviders() bool {
 	return len(r.providers) > 0
@@ -271,8 +352,7 @@ func (r *Router) GenerateResponse(ctx context.Context, session *sessions.Session
 		providerName = r.default_
 	}
 
-	provider, exists := r.providers[providerName]
-	if !exists {
+	if _, exists := r.providers[providerName]; !exists {
 		return nil, fmt.Errorf("provider not found: %s", providerName)
 	}
 
@@ -287,7 +367,7 @@ func (r *Router) GenerateResponse(ctx context.Context, session *sessions.Session
 	}
 
 	// Build chat messages from session history with agent system prompt
-	messages, err := r.buildChatMessagesWithSystemPrompt(session, userMessage, systemBlocks)
+	messages, err := r.buildChatMessagesWithSystemPrompt(ctx, session, userMessage, session.Context["model"], systemBlocks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build chat messages: %w", err)
 	}
@@ -304,18 +384,10 @@ func (r *Router) GenerateResponse(ctx context.Context, session *sessions.Session
 		MaxTokens: 4000,
 	}
 
-	start := time.Now()
-	response, err := provider.GenerateResponse(ctx, req)
-	latencyMs := time.Since(start).Milliseconds()
+	response, _, _, err := r.generateWithFailover(ctx, providerName, req, callGenerate)
 	if err != nil {
-		if r.usageTracker != nil {
-			r.usageTracker.RecordError(providerName, req.Model)
-		}
 		return nil, err
 	}
-	if r.usageTracker != nil {
-		r.usageTracker.RecordUsage(providerName, req.Model, response.Usage.PromptTokens, response.Usage.CompletionTokens, latencyMs)
-	}
 
 	// Process response through agent system
 	if r.agentSystem != nil {
@@ -339,6 +411,11 @@ func (r *Router) GenerateResponse(ctx context.Context, session *sessions.Session
 	return response, nil
 }
 
+// callGenerate is the providerCall for non-streaming requests
+func callGenerate(ctx context.Context, provider Provider, req *GenerateRequest) (*GenerateResponse, error) {
+	return provider.GenerateResponse(ctx, req)
+}
+
 // ProgressCallback is called during long operations to provide status updates
 type ProgressCallback func(status string)
 
@@ -355,8 +432,7 @@ func (r *Router) GenerateResponseWithToolsAndProgress(ctx context.Context, sessi
 		providerName = r.default_
 	}
 
-	provider, exists := r.providers[providerName]
-	if !exists {
+	if _, exists := r.providers[providerName]; !exists {
 		return nil, fmt.Errorf("provider not found: %s", providerName)
 	}
 
@@ -371,7 +447,7 @@ func (r *Router) GenerateResponseWithToolsAndProgress(ctx context.Context, sessi
 	}
 
 	// Build chat messages from session history with agent system prompt
-	messages, err := r.buildChatMessagesWithSystemPrompt(session, userMessage, systemBlocks)
+	messages, err := r.buildChatMessagesWithSystemPrompt(ctx, session, userMessage, modelOverride, systemBlocks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build chat messages: %w", err)
 	}
@@ -389,19 +465,11 @@ func (r *Router) GenerateResponseWithToolsAndProgress(ctx context.Context, sessi
 		MaxTokens: 4000,
 	}
 
-	// Get initial AI response
-	start := time.Now()
-	response, err := provider.GenerateResponse(ctx, req)
-	latencyMs := time.Since(start).Milliseconds()
+	// Get initial AI response, failing over to other providers if configured
+	response, provider, req, err := r.generateWithFailover(ctx, providerName, req, callGenerate)
 	if err != nil {
-		if r.usageTracker != nil {
-			r.usageTracker.RecordError(providerName, modelOverride)
-		}
 		return nil, fmt.Errorf("AI provider error: %w", err)
 	}
-	if r.usageTracker != nil {
-		r.usageTracker.RecordUsage(providerName, modelOverride, response.Usage.PromptTokens, response.Usage.CompletionTokens, latencyMs)
-	}
 
 	// Process response through agent system
 	if r.agentSystem != nil {
@@ -437,14 +505,15 @@ func (r *Router) GenerateResponseWithToolsAndProgress(ctx context.Context, sessi
 		}
 		// Post-process for silent response patterns (HEARTBEAT_OK, NO_REPLY)
 		// This applies the same logic as ProcessResponse but after tool execution
-		return r.processSilentPatterns(convResponse), nil
+		return attributeProvider(r.processSilentPatterns(convResponse), response.Provider), nil
 	}
 
 	// No tools called or no execution engine - return simple response
 	return &SimpleConversationResponse{
-		Content: response.Content,
-		Usage:   &response.Usage,
-		Steps:   1,
+		Content:  response.Content,
+		Usage:    &response.Usage,
+		Steps:    1,
+		Provider: response.Provider,
 	}, nil
 }
 
@@ -488,9 +557,8 @@ func (r *Router) GenerateResponseStreaming(ctx context.Context, session *session
 		return nil, fmt.Errorf("default provider not found")
 	}
 
-	// Only Anthropic provider supports streaming currently
-	anthropicProvider, ok := provider.(*AnthropicProvider)
-	if !ok {
+	// Providers opt into streaming by implementing StreamingProvider
+	if _, ok := provider.(StreamingProvider); !ok {
 		// Fall back to non-streaming
 		return r.GenerateResponseWithTools(ctx, session, userMessage, "", modelOverride)
 	}
@@ -506,7 +574,7 @@ func (r *Router) GenerateResponseStreaming(ctx context.Context, session *session
 	}
 
 	// Build chat messages
-	messages, err := r.buildChatMessagesWithSystemPrompt(session, userMessage, systemBlocks)
+	messages, err := r.buildChatMessagesWithSystemPrompt(ctx, session, userMessage, modelOverride, systemBlocks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build messages: %w", err)
 	}
@@ -517,17 +585,42 @@ func (r *Router) GenerateResponseStreaming(ctx context.Context, session *session
 		tools = r.agentSystem.GetToolDefinitions()
 	}
 
-	// Build system prompt string
-	var systemPrompt string
-	for _, block := range systemBlocks {
-		if systemPrompt != "" {
-			systemPrompt += "\n\n"
-		}
-		systemPrompt += block.Text
+	// The request that produces the streamed response; also handed to the
+	// execution engine if tool calls are detected
+	req := &GenerateRequest{
+		Messages:  messages,
+		Model:     modelOverride,
+		Tools:     tools,
+		MaxTokens: 4000,
 	}
 
-	// Call streaming API
-	response, err := anthropicProvider.generateWithStreamOAuth(ctx, messages, tools, systemPrompt, modelOverride, onDelta)
+	// Call streaming API, failing over only while nothing has been streamed
+	var emitted bool
+	trackedDelta := func(delta string, done bool) {
+		if delta != "" {
+			emitted = true
+		}
+		if onDelta != nil {
+			onDelta(delta, done)
+		}
+	}
+	response, provider, req, err := r.generateWithFailover(ctx, r.default_, req, func(ctx context.Context, p Provider, req *GenerateRequest) (*GenerateResponse, error) {
+		sp, ok := p.(StreamingProvider)
+		if !ok {
+			// Non-streaming fallback: deliver the whole reply as a single delta
+			resp, err := p.GenerateResponse(ctx, req)
+			if err == nil {
+				trackedDelta(resp.Content, false)
+				trackedDelta("", true)
+			}
+			return resp, err
+		}
+		resp, err := sp.GenerateResponseStream(ctx, req, trackedDelta)
+		if err != nil && emitted {
+			return nil, &noFailoverError{err: err}
+		}
+		return resp, err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -535,37 +628,30 @@ func (r *Router) GenerateResponseStreaming(ctx context.Context, session *session
 	// Check if tool calls were detected during streaming
 	if len(response.ToolCalls) > 0 && r.executionEngine != nil {
 		// Tool calls found! Transition to tool execution mode
-
-		// Build the request that would produce this response
-		req := &GenerateRequest{
-			Messages:  messages,
-			Model:     modelOverride,
-			Tools:     tools,
-			MaxTokens: 4000,
-		}
-
-		// Use the execution engine to handle tool calls
 		convResponse, err := r.executionEngine.HandleToolCallFlow(ctx, provider, req, response)
 		if err != nil {
 			return nil, err
 		}
 		// Post-process for silent response patterns (HEARTBEAT_OK, NO_REPLY)
-		return r.processSilentPatterns(convResponse), nil
+		return attributeProvider(r.processSilentPatterns(convResponse), response.Provider), nil
 	}
 
 	// No tool calls - return simple streaming response
 	return &SimpleConversationResponse{
-		Content: response.Content,
-		Usage:   &response.Usage,
-		Steps:   1,
+		Content:  response.Content,
+		Usage:    &response.Usage,
+		Steps:    1,
+		Provider: response.Provider,
 	}, nil
 }
 
 // SimpleConversationResponse implements ConversationResponse for non-tool responses
 type SimpleConversationResponse struct {
-	Content string `json:"content"`
-	Usage   *Usage `json:"usage"`
-	Steps   int    `json:"steps"`
+	Content   string        `json:"content"`
+	Usage     *Usage        `json:"usage"`
+	Steps     int           `json:"steps"`
+	Provider  string        `json:"provider,omitempty"`
+	ToolTurns []ChatMessage `json:"tool_turns,omitempty"`
 }
 
 func (s *SimpleConversationResponse) GetContent() string {
@@ -584,6 +670,17 @@ func (s *SimpleConversationResponse) HasToolResults() bool {
 	return false
 }
 
+// GetToolTurns returns the tool calls and tool results that preceded the
+// final reply
+func (s *SimpleConversationResponse) GetToolTurns() []ChatMessage {
+	return s.ToolTurns
+}
+
+// GetProvider returns the provider that produced the response
+func (s *SimpleConversationResponse) GetProvider() string {
+	return s.Provider
+}
+
 // processSilentPatterns checks for HEARTBEAT_OK/NO_REPLY patterns in the response
 // and returns an empty-content response if detected. This applies the same logic
 // as AgentSystem.ProcessResponse but for responses that come from tool execution.
@@ -595,9 +692,10 @@ func (r *Router) processSilentPatterns(response ConversationResponse) Conversati
 	if strings.Contains(upper, "HEARTBEAT_OK") || strings.Contains(upper, "NO_REPLY") {
 		log.Printf("[Router] Silent response pattern detected (suppressing)")
 		return &SimpleConversationResponse{
-			Content: "",
-			Usage:   response.GetUsage(),
-			Steps:   response.GetSteps(),
+			Content:   "",
+			Usage:     response.GetUsage(),
+			Steps:     response.GetSteps(),
+			ToolTurns: ResponseToolTurns(response),
 		}
 	}
 
@@ -2,9 +2,11 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"net/http"
 	"strings"
 	"time"
 
@@ -71,6 +73,10 @@ func isRateLimitError(err error) bool {
 	if _, ok := err.(*RateLimitError); ok {
 		return true
 	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests
+	}
 	msg := strings.ToLower(err.Error())
 	return strings.Contains(msg, "rate limit") ||
 		strings.Contains(msg, "429") ||
@@ -85,6 +91,10 @@ func isRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
 	if isRateLimitError(err) {
 		return true
 	}
@@ -225,11 +235,6 @@ func (r *Router) executeWithFallbacks(ctx context.Context, session *sessions.Ses
 		r.handleRateLimit(primary.Model, err)
 	}
 
-	// Record error for the primary model
-	if r.usageTracker != nil {
-		r.usageTracker.RecordError(providerName, primary.Model)
-	}
-
 	// Build fallback chain: try other tiers in order of preference
 	fallbacks := r.buildFallbackChain(primary)
 
@@ -255,10 +260,6 @@ func (r *Router) executeWithFallbacks(ctx context.Context, session *sessions.Ses
 		if isRateLimitError(err) {
 			r.handleRateLimit(fallback.Model, err)
 		}
-
-		if r.usageTracker != nil {
-			r.usageTracker.RecordError(providerName, fallback.Model)
-		}
 	}
 
 	// All models exhausted
@@ -12,6 +12,14 @@ import (
 	"strings"
 )
 
+// Compile-time checks that the built-in providers support streaming
+var (
+	_ StreamingProvider = (*AnthropicProvider)(nil)
+	_ StreamingProvider = (*OpenAIProvider)(nil)
+	_ StreamingProvider = (*LocalProvider)(nil)
+	_ StreamingProvider = (*MockProvider)(nil)
+)
+
 // StreamCallback is called with text deltas during streaming
 type StreamCallback func(delta string, done bool)
 
@@ -22,6 +30,24 @@ type StreamingResponse struct {
 	Usage     *Usage
 }
 
+// GenerateResponseStream implements StreamingProvider. A leading system
+// message is lifted into the Anthropic system prompt.
+func (a *AnthropicProvider) GenerateResponseStream(ctx context.Context, req *GenerateRequest, onDelta StreamCallback) (*GenerateResponse, error) {
+	// Refresh OAuth token if needed
+	if err := a.refreshOAuthToken(); err != nil {
+		return nil, fmt.Errorf("failed to refresh OAuth token: %w", err)
+	}
+
+	messages := req.Messages
+	var systemPrompt string
+	if len(messages) > 0 && messages[0].Role == "system" {
+		systemPrompt = messages[0].Content
+		messages = messages[1:]
+	}
+
+	return a.generateWithStreamOAuth(ctx, messages, req.Tools, systemPrompt, req.Model, onDelta)
+}
+
 // generateWithStreamOAuth generates a response using Anthropic's streaming API
 // For OAuth tokens, this mimics Claude Code's exact request format
 func (a *AnthropicProvider) generateWithStreamOAuth(
@@ -113,7 +139,7 @@ func (a *AnthropicProvider) generateWithStreamOAuth(
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError(resp, bodyBytes)
 	}
 
 	// Parse SSE stream
@@ -261,3 +287,166 @@ func getFloat64(m map[string]interface{}, key string) float64 {
 	}
 	return 0
 }
+
+// GenerateResponseStream implements StreamingProvider using OpenAI's
+// streaming chat completions API. Text deltas are forwarded to onDelta as
+// they arrive and tool calls are reassembled from their indexed argument
+// fragments.
+func (o *OpenAIProvider) GenerateResponseStream(ctx context.Context, req *GenerateRequest, onDelta StreamCallback) (*GenerateResponse, error) {
+	httpReq, err := o.newChatCompletionRequest(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	modelToUse := o.model
+	if req.Model != "" {
+		modelToUse = req.Model
+	}
+	log.Printf("[OpenAI] Streaming request: model=%s", modelToUse)
+
+	resp, err := o.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, newAPIError(resp, bodyBytes)
+	}
+
+	return o.parseSSEStream(resp.Body, onDelta)
+}
+
+// openAIStreamToolCall accumulates a streamed tool call until the stream ends
+type openAIStreamToolCall struct {
+	id        string
+	name      string
+	arguments strings.Builder
+}
+
+// parseSSEStream parses Server-Sent Events from OpenAI's streaming API
+func (o *OpenAIProvider) parseSSEStream(body io.Reader, onDelta StreamCallback) (*GenerateResponse, error) {
+	scanner := bufio.NewScanner(body)
+	// Tool argument chunks can be large; allow lines up to 1MB
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var contentBuilder strings.Builder
+	var pending []*openAIStreamToolCall
+	var usage Usage
+	done := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "" {
+			continue
+		}
+
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+
+		var chunk map[string]interface{}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			log.Printf("[Streaming] Failed to parse event: %v", err)
+			continue
+		}
+
+		// The final chunk carries usage with an empty choices array
+		if u, ok := chunk["usage"].(map[string]interface{}); ok {
+			usage.PromptTokens = int(getFloat64(u, "prompt_tokens"))
+			usage.CompletionTokens = int(getFloat64(u, "completion_tokens"))
+			usage.TotalTokens = int(getFloat64(u, "total_tokens"))
+		}
+
+		choices, _ := chunk["choices"].([]interface{})
+		if len(choices) == 0 {
+			continue
+		}
+		choice, ok := choices[0].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if delta, ok := choice["delta"].(map[string]interface{}); ok {
+			if text, ok := delta["content"].(string); ok && text != "" {
+				contentBuilder.WriteString(text)
+				if onDelta != nil {
+					onDelta(text, false)
+				}
+			}
+
+			if tcs, ok := delta["tool_calls"].([]interface{}); ok {
+				for _, item := range tcs {
+					tc, ok := item.(map[string]interface{})
+					if !ok {
+						continue
+					}
+					idx := int(getFloat64(tc, "index"))
+					for len(pending) <= idx {
+						pending = append(pending, &openAIStreamToolCall{})
+					}
+					call := pending[idx]
+					if id, ok := tc["id"].(string); ok && id != "" {
+						call.id = id
+					}
+					if fn, ok := tc["function"].(map[string]interface{}); ok {
+						if name, ok := fn["name"].(string); ok && name != "" {
+							call.name = name
+						}
+						if args, ok := fn["arguments"].(string); ok {
+							call.arguments.WriteString(args)
+						}
+					}
+				}
+			}
+		}
+
+		if finishReason, ok := choice["finish_reason"].(string); ok && finishReason != "" {
+			log.Printf("[Streaming] Finish reason: %s", finishReason)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading stream: %w", err)
+	}
+
+	if done && onDelta != nil {
+		onDelta("", true)
+	}
+
+	var toolCalls []ToolCall
+	for _, call := range pending {
+		if call.name == "" {
+			continue
+		}
+		args := make(map[string]interface{})
+		if raw := call.arguments.String(); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &args); err != nil {
+				log.Printf("[Streaming] Failed to parse tool arguments for %s: %v", call.name, err)
+			}
+		}
+		toolCalls = append(toolCalls, ToolCall{
+			ID:   call.id,
+			Name: call.name,
+			Args: args,
+		})
+	}
+
+	if usage.TotalTokens == 0 && (usage.PromptTokens > 0 || usage.CompletionTokens > 0) {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+
+	return &GenerateResponse{
+		Content:   contentBuilder.String(),
+		ToolCalls: toolCalls,
+		Usage:     usage,
+	}, nil
+}
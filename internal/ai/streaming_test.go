@@ -0,0 +1,239 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"conduit/internal/config"
+	"conduit/internal/sessions"
+)
+
+// openAIStreamBody is a canned SSE stream with text, a fragmented tool call and usage
+const openAIStreamBody = `data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"Let me "}}]}
+
+data: {"choices":[{"index":0,"delta":{"content":"check."}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_abc","type":"function","function":{"name":"test_tool","arguments":"{\"param"}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"1\": \"value1\"}"}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":42,"completion_tokens":7,"total_tokens":49}}
+
+data: [DONE]
+
+`
+
+func TestOpenAIProvider_ParseSSEStream(t *testing.T) {
+	provider := &OpenAIProvider{}
+
+	var deltas []string
+	doneCalls := 0
+	resp, err := provider.parseSSEStream(strings.NewReader(openAIStreamBody), func(delta string, done bool) {
+		if done {
+			doneCalls++
+			return
+		}
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if resp.Content != "Let me check." {
+		t.Errorf("Expected content 'Let me check.', got: %q", resp.Content)
+	}
+	if strings.Join(deltas, "|") != "Let me |check." {
+		t.Errorf("Unexpected deltas: %v", deltas)
+	}
+	if doneCalls != 1 {
+		t.Errorf("Expected done callback once, got %d", doneCalls)
+	}
+
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("Expected 1 tool call, got: %d", len(resp.ToolCalls))
+	}
+	tc := resp.ToolCalls[0]
+	if tc.ID != "call_abc" || tc.Name != "test_tool" {
+		t.Errorf("Unexpected tool call: %+v", tc)
+	}
+	if tc.Args["param1"] != "value1" {
+		t.Errorf("Expected param1 'value1', got: %v", tc.Args["param1"])
+	}
+
+	if resp.Usage.PromptTokens != 42 || resp.Usage.CompletionTokens != 7 || resp.Usage.TotalTokens != 49 {
+		t.Errorf("Unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestOpenAIProvider_ConvertMessagesToOpenAI(t *testing.T) {
+	provider := &OpenAIProvider{}
+
+	messages := []ChatMessage{
+		{Role: "system", Content: "be helpful"},
+		{Role: "user", Content: "run it"},
+		{
+			Role: "assistant",
+			ToolCalls: []ToolCall{
+				{ID: "call_1", Name: "test_tool", Args: map[string]interface{}{"param1": "value1"}},
+			},
+		},
+		{Role: "tool", Content: "ok", ToolCallID: "call_1"},
+	}
+
+	converted := provider.convertMessagesToOpenAI(messages)
+	if len(converted) != 4 {
+		t.Fatalf("Expected 4 messages, got %d", len(converted))
+	}
+
+	if converted[0]["role"] != "system" || converted[0]["content"] != "be helpful" {
+		t.Errorf("Unexpected system message: %v", converted[0])
+	}
+
+	assistant := converted[2]
+	if _, hasContent := assistant["content"]; hasContent {
+		t.Errorf("Expected no content on tool-only assistant message, got: %v", assistant["content"])
+	}
+	toolCalls, ok := assistant["tool_calls"].([]map[string]interface{})
+	if !ok || len(toolCalls) != 1 {
+		t.Fatalf("Expected 1 tool call, got: %v", assistant["tool_calls"])
+	}
+	fn := toolCalls[0]["function"].(map[string]interface{})
+	if fn["arguments"] != `{"param1":"value1"}` {
+		t.Errorf("Expected JSON string arguments, got: %v", fn["arguments"])
+	}
+
+	if converted[3]["role"] != "tool" || converted[3]["tool_call_id"] != "call_1" {
+		t.Errorf("Unexpected tool result message: %v", converted[3])
+	}
+}
+
+func TestRouter_GenerateResponseStreaming_OpenAI(t *testing.T) {
+	var streamRequested bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		streamRequested, _ = reqBody["stream"].(bool)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, openAIStreamBody)
+	}))
+	defer server.Close()
+
+	cfg := config.AIConfig{
+		DefaultProvider: "openai",
+		Providers: []config.ProviderConfig{
+			{
+				Name:   "openai",
+				Type:   "openai",
+				APIKey: "test-key",
+				Model:  "gpt-4o",
+			},
+		},
+	}
+
+	mockExecEngine := &MockExecutionEngine{
+		responseContent: "Tool ran",
+		steps:           2,
+	}
+
+	router, err := NewRouterWithExecution(cfg, &MockAgentSystem{}, mockExecEngine)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+
+	router.providers["openai"].(*OpenAIProvider).client = &http.Client{
+		Transport: &mockTransport{server: server},
+	}
+
+	session := &sessions.Session{Key: "test-session"}
+
+	var streamed strings.Builder
+	response, err := router.GenerateResponseStreaming(context.Background(), session, "check it", "", func(delta string, done bool) {
+		streamed.WriteString(delta)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if !streamRequested {
+		t.Error("Expected streaming request to OpenAI")
+	}
+	if streamed.String() != "Let me check." {
+		t.Errorf("Expected streamed text 'Let me check.', got: %q", streamed.String())
+	}
+	if response.GetContent() != "Tool ran" {
+		t.Errorf("Expected execution engine to handle tool call, got content: %q", response.GetContent())
+	}
+}
+
+// nonStreamingProvider wraps a provider while hiding its streaming support
+type nonStreamingProvider struct {
+	Provider
+}
+
+func TestRouter_GenerateResponseStreaming_DiscoversStreamingProvider(t *testing.T) {
+	mock := NewMockProvider("mock")
+	mock.AddResponse("hello from the mock", nil)
+
+	router, err := NewRouter(config.AIConfig{DefaultProvider: "mock"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	router.RegisterProvider("mock", mock)
+
+	var deltas []string
+	var done bool
+	response, err := router.GenerateResponseStreaming(context.Background(), &sessions.Session{Key: "s"}, "hi", "", func(delta string, d bool) {
+		if d {
+			done = true
+			return
+		}
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if strings.Join(deltas, "") != "hello from the mock" || len(deltas) != 4 {
+		t.Errorf("Expected word deltas, got: %q", deltas)
+	}
+	if !done {
+		t.Error("Expected done callback")
+	}
+	if response.GetContent() != "hello from the mock" {
+		t.Errorf("Unexpected content: %q", response.GetContent())
+	}
+}
+
+func TestRouter_GenerateResponseStreaming_FallsBackWithoutStreaming(t *testing.T) {
+	mock := NewMockProvider("plain")
+	mock.AddResponse("not streamed", nil)
+
+	router, err := NewRouter(config.AIConfig{DefaultProvider: "plain"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	router.RegisterProvider("plain", nonStreamingProvider{mock})
+
+	deltaCalls := 0
+	response, err := router.GenerateResponseStreaming(context.Background(), &sessions.Session{Key: "s"}, "hi", "", func(string, bool) {
+		deltaCalls++
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if deltaCalls != 0 {
+		t.Errorf("Expected no stream callbacks for non-streaming provider, got %d", deltaCalls)
+	}
+	if response.GetContent() != "not streamed" {
+		t.Errorf("Unexpected content: %q", response.GetContent())
+	}
+}
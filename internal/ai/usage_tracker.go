@@ -15,6 +15,8 @@ type ProviderUsageRecord struct {
 	TotalLatencyMs    int64     `json:"total_latency_ms"`
 	LastUsed          time.Time `json:"last_used"`
 	ErrorCount        int64     `json:"error_count"`
+	ConsecutiveErrors int64     `json:"consecutive_errors"`
+	LastErrorAt       time.Time `json:"last_error_at,omitempty"`
 }
 
 // ModelUsageRecord tracks usage metrics for a specific model.
@@ -77,6 +79,7 @@ func (ut *UsageTracker) RecordUsage(provider, model string, inputTokens, outputT
 	pr.TotalCost += cost
 	pr.TotalLatencyMs += latencyMs
 	pr.LastUsed = now
+	pr.ConsecutiveErrors = 0
 
 	// Update model record
 	mr, ok := ut.models[model]
@@ -104,6 +107,8 @@ func (ut *UsageTracker) RecordError(provider, model string) {
 		ut.providers[provider] = pr
 	}
 	pr.ErrorCount++
+	pr.ConsecutiveErrors++
+	pr.LastErrorAt = time.Now()
 	pr.TotalRequests++
 
 	if model != "" {
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"conduit/internal/tools/types"
+)
+
+// Vision defaults applied when ai.vision leaves them unset
+const (
+	defaultVisionMaxBytesMB = 5.0
+	defaultVisionMaxTokens  = 1024
+)
+
+var _ types.VisionService = (*Router)(nil)
+
+// AnalyzeImage sends an image and prompt to a vision-capable model. The
+// provider and model come from ai.vision, falling back to the default
+// provider; a model on the request overrides both. Failover applies as for
+// chat requests.
+func (r *Router) AnalyzeImage(ctx context.Context, req types.VisionRequest) (*types.VisionResponse, error) {
+	if int64(len(req.Image)) > r.MaxImageBytes() {
+		return nil, fmt.Errorf("image size (%d bytes) exceeds vision limit (%d bytes)", len(req.Image), r.MaxImageBytes())
+	}
+
+	providerName := r.default_
+	model := req.Model
+	maxTokens := defaultVisionMaxTokens
+	if r.visionCfg != nil {
+		if r.visionCfg.Provider != "" {
+			providerName = r.visionCfg.Provider
+		}
+		if model == "" {
+			model = r.visionCfg.Model
+		}
+		if r.visionCfg.MaxTokens > 0 {
+			maxTokens = r.visionCfg.MaxTokens
+		}
+	}
+
+	genReq := &GenerateRequest{
+		Messages: []ChatMessage{{
+			Role:    "user",
+			Content: req.Prompt,
+			Blocks:  []ContentBlock{NewImageBlock(req.MediaType, req.Image)},
+		}},
+		Model:     model,
+		MaxTokens: maxTokens,
+	}
+
+	response, _, sentReq, err := r.generateWithFailover(ctx, providerName, genReq, callGenerate)
+	if err != nil {
+		return nil, fmt.Errorf("vision request failed: %w", err)
+	}
+
+	return &types.VisionResponse{
+		Content:  response.Content,
+		Provider: response.Provider,
+		Model:    sentReq.Model,
+	}, nil
+}
+
+// MaxImageBytes returns the largest image AnalyzeImage accepts
+func (r *Router) MaxImageBytes() int64 {
+	maxMB := defaultVisionMaxBytesMB
+	if r.visionCfg != nil && r.visionCfg.MaxBytesMB > 0 {
+		maxMB = r.visionCfg.MaxBytesMB
+	}
+	return int64(maxMB * 1024 * 1024)
+}
@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"conduit/internal/config"
+	"conduit/internal/tools/types"
+)
+
+func TestRouter_AnalyzeImage(t *testing.T) {
+	defaultProvider := NewMockProvider("default")
+	visionProvider := NewMockProvider("vision")
+	visionProvider.AddResponse(`{"description": "a diagram"}`, nil)
+
+	router, err := NewRouter(config.AIConfig{
+		DefaultProvider: "default",
+		Vision:          &config.VisionConfig{Provider: "vision", Model: "gpt-4o", MaxTokens: 500},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	router.RegisterProvider("default", defaultProvider)
+	router.RegisterProvider("vision", visionProvider)
+
+	resp, err := router.AnalyzeImage(context.Background(), types.VisionRequest{
+		Image:     []byte("pngbytes"),
+		MediaType: "image/png",
+		Prompt:    "describe",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if resp.Content != `{"description": "a diagram"}` || resp.Provider != "vision" || resp.Model != "gpt-4o" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+	if defaultProvider.GetCallCount() != 0 {
+		t.Error("Expected the configured vision provider to be used")
+	}
+
+	req := visionProvider.LastCall().Request
+	if req.MaxTokens != 500 || len(req.Messages) != 1 {
+		t.Fatalf("Unexpected request: %+v", req)
+	}
+	msg := req.Messages[0]
+	if msg.Content != "describe" || len(msg.Blocks) != 1 || msg.Blocks[0].Type != BlockTypeImage || msg.Blocks[0].MediaType != "image/png" {
+		t.Errorf("Expected prompt with one image block, got %+v", msg)
+	}
+}
+
+func TestRouter_AnalyzeImage_SizeLimit(t *testing.T) {
+	mock := NewMockProvider("mock")
+	router, _ := NewRouter(config.AIConfig{
+		DefaultProvider: "mock",
+		Vision:          &config.VisionConfig{MaxBytesMB: 0.001},
+	}, nil)
+	router.RegisterProvider("mock", mock)
+
+	if router.MaxImageBytes() != 1048 {
+		t.Errorf("Expected 1048 byte limit, got %d", router.MaxImageBytes())
+	}
+
+	_, err := router.AnalyzeImage(context.Background(), types.VisionRequest{Image: make([]byte, 2000), MediaType: "image/png"})
+	if err == nil {
+		t.Fatal("Expected size limit error")
+	}
+	if mock.GetCallCount() != 0 {
+		t.Error("Expected oversized image not to be sent")
+	}
+
+	// Without config the default limit applies and the default provider answers
+	router, _ = NewRouter(config.AIConfig{DefaultProvider: "mock"}, nil)
+	router.RegisterProvider("mock", mock)
+	mock.AddResponse("fine", nil)
+	if router.MaxImageBytes() != 5*1024*1024 {
+		t.Errorf("Expected default 5MB limit, got %d", router.MaxImageBytes())
+	}
+	if _, err := router.AnalyzeImage(context.Background(), types.VisionRequest{Image: []byte("x"), MediaType: "image/png"}); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
@@ -26,6 +26,9 @@ func CreateTokenCmd(config *CLIConfig) *cobra.Command {
 	var (
 		clientName string
 		expiresIn  string
+		scopes     []string
+		channels   []string
+		sessions   []string
 	)
 
 	cmd := &cobra.Command{
@@ -33,14 +36,24 @@ func CreateTokenCmd(config *CLIConfig) *cobra.Command {
 		Short: "Create a new authentication token",
 		Long:  `Create a new authentication token for a client. The token will be displayed once and cannot be retrieved again.`,
 		Example: `  conduit token create --client-name "jules-main" --expires-in "1y"
-  conduit token create --client-name "production-server"`,
+  conduit token create --client-name "production-server"
+  conduit token create --client-name "indexer" --scope vector:write
+  conduit token create --client-name "dashboard" --scope chat,sessions:read --channel "tui_*"`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return createToken(config, clientName, expiresIn)
+			return createToken(config, CreateTokenRequest{
+				ClientName: clientName,
+				Scopes:     scopes,
+				Channels:   channels,
+				Sessions:   sessions,
+			}, expiresIn)
 		},
 	}
 
 	cmd.Flags().StringVar(&clientName, "client-name", "", "Name of the client (required)")
 	cmd.Flags().StringVar(&expiresIn, "expires-in", "", "Expiration duration (e.g., '1y', '30d', '24h') - optional")
+	cmd.Flags().StringSliceVar(&scopes, "scope", DefaultScopes, "Scopes to grant: "+strings.Join(KnownScopes, ", "))
+	cmd.Flags().StringSliceVar(&channels, "channel", nil, "Limit the token to channel IDs matching these patterns (e.g. 'telegram*')")
+	cmd.Flags().StringSliceVar(&sessions, "session", nil, "Limit the token to session keys matching these patterns")
 	cmd.MarkFlagRequired("client-name")
 
 	return cmd
@@ -122,7 +135,7 @@ func TokenRootCmd(config *CLIConfig) *cobra.Command {
 }
 
 // createToken handles token creation
-func createToken(config *CLIConfig, clientName, expiresIn string) error {
+func createToken(config *CLIConfig, req CreateTokenRequest, expiresIn string) error {
 	// Update config from environment if not set
 	if config.DatabasePath == "" {
 		if dbPath := os.Getenv("CONDUIT_DB_PATH"); dbPath != "" {
@@ -135,9 +148,12 @@ func createToken(config *CLIConfig, clientName, expiresIn string) error {
 		config.Verbose = true
 	}
 	// Validate input
-	if strings.TrimSpace(clientName) == "" {
+	if strings.TrimSpace(req.ClientName) == "" {
 		return fmt.Errorf("client-name is required")
 	}
+	if err := ValidateScopes(req.Scopes); err != nil {
+		return err
+	}
 
 	// Parse expiration if provided
 	var expiresAt *time.Time
@@ -166,12 +182,8 @@ func createToken(config *CLIConfig, clientName, expiresIn string) error {
 		return fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	// Create token request (we need to modify the existing CreateToken method)
-	req := CreateTokenRequest{
-		ClientName: clientName,
-		ExpiresAt:  expiresAt,
-		Metadata:   make(map[string]string),
-	}
+	req.ExpiresAt = expiresAt
+	req.Metadata = make(map[string]string)
 
 	// Store the token with our custom format
 	resp, err := storage.CreateTokenWithCustomFormat(req, token)
@@ -189,6 +201,13 @@ func createToken(config *CLIConfig, clientName, expiresIn string) error {
 	} else {
 		fmt.Printf("Expires: Never\n")
 	}
+	fmt.Printf("Scopes: %s\n", strings.Join(resp.TokenInfo.Scopes, ", "))
+	if len(resp.TokenInfo.Channels) > 0 {
+		fmt.Printf("Channels: %s\n", strings.Join(resp.TokenInfo.Channels, ", "))
+	}
+	if len(resp.TokenInfo.Sessions) > 0 {
+		fmt.Printf("Sessions: %s\n", strings.Join(resp.TokenInfo.Sessions, ", "))
+	}
 	fmt.Printf("Token ID: %s\n", resp.TokenInfo.TokenID)
 
 	fmt.Printf("\n⚠️  Save this token now! It cannot be retrieved again.\n")
@@ -235,8 +254,8 @@ func listTokens(config *CLIConfig, includeRevoked bool) error {
 
 	// Display tokens in table format
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "PREFIX\tCLIENT\tCREATED\tEXPIRES\tLAST USED\tSTATUS")
-	fmt.Fprintln(w, "------\t------\t-------\t-------\t---------\t------")
+	fmt.Fprintln(w, "PREFIX\tCLIENT\tSCOPES\tCREATED\tEXPIRES\tLAST USED\tSTATUS")
+	fmt.Fprintln(w, "------\t------\t------\t-------\t-------\t---------\t------")
 
 	for _, token := range tokenList {
 		prefix := tokenspkg.GetTokenPrefix("claw_v1_"+token.TokenID[:8], 12) // Show first 12 chars as prefix
@@ -261,9 +280,15 @@ func listTokens(config *CLIConfig, includeRevoked bool) error {
 			lastUsed = token.LastUsedAt.Format("2006-01-02")
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+		scopes := strings.Join(token.Scopes, ",")
+		if len(token.Channels) > 0 || len(token.Sessions) > 0 {
+			scopes += " (restricted)"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 			prefix,
 			token.ClientName,
+			scopes,
 			token.CreatedAt.Format("2006-01-02"),
 			expires,
 			lastUsed,
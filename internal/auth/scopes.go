@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Token scopes. A token may only use the endpoints its scopes cover.
+const (
+	ScopeAdmin        = "admin"         // Everything, including test and admin endpoints
+	ScopeChat         = "chat"          // WebSocket chat and commands, creating and switching sessions
+	ScopeSessionsRead = "sessions:read" // Listing sessions
+	ScopeChannelsRead = "channels:read" // Channel status
+	ScopeVectorRead   = "vector:read"   // Vector search and status
+	ScopeVectorWrite  = "vector:write"  // Vector indexing and deletion
+)
+
+// KnownScopes lists every scope a token can hold
+var KnownScopes = []string{
+	ScopeAdmin,
+	ScopeChat,
+	ScopeSessionsRead,
+	ScopeChannelsRead,
+	ScopeVectorRead,
+	ScopeVectorWrite,
+}
+
+// DefaultScopes are given to tokens created without explicit scopes, which
+// keeps them as capable as tokens were before scopes existed
+var DefaultScopes = []string{ScopeAdmin}
+
+// ValidateScopes checks that every scope is known
+func ValidateScopes(scopes []string) error {
+	for _, s := range scopes {
+		known := false
+		for _, k := range KnownScopes {
+			if s == k {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown scope %q (valid: %s)", s, strings.Join(KnownScopes, ", "))
+		}
+	}
+	return nil
+}
+
+// HasScope reports whether granted covers required. admin covers every
+// scope, and a ":write" scope covers the matching ":read" scope.
+func HasScope(granted []string, required string) bool {
+	for _, g := range granted {
+		if g == required || g == ScopeAdmin {
+			return true
+		}
+		if base, ok := strings.CutSuffix(required, ":read"); ok && g == base+":write" {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesRestriction reports whether value is allowed by a token's channel
+// or session restriction. Patterns use path.Match syntax, so "telegram*"
+// matches every Telegram channel. An empty list allows everything.
+func MatchesRestriction(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, err := path.Match(p, value); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package auth
+
+import "testing"
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		granted  []string
+		required string
+		want     bool
+	}{
+		{[]string{ScopeChat}, ScopeChat, true},
+		{[]string{ScopeChat}, ScopeVectorRead, false},
+		{[]string{ScopeAdmin}, ScopeVectorWrite, true},
+		{[]string{ScopeVectorWrite}, ScopeVectorRead, true},
+		{[]string{ScopeVectorRead}, ScopeVectorWrite, false},
+		{[]string{ScopeChat, ScopeSessionsRead}, ScopeSessionsRead, true},
+		{nil, ScopeChat, false},
+	}
+
+	for _, tt := range tests {
+		if got := HasScope(tt.granted, tt.required); got != tt.want {
+			t.Errorf("HasScope(%v, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestValidateScopes(t *testing.T) {
+	if err := ValidateScopes([]string{ScopeChat, ScopeVectorWrite}); err != nil {
+		t.Errorf("Expected known scopes to validate, got %v", err)
+	}
+	if err := ValidateScopes([]string{"chat", "root"}); err == nil {
+		t.Error("Expected an error for an unknown scope")
+	}
+}
+
+func TestMatchesRestriction(t *testing.T) {
+	if !MatchesRestriction(nil, "anything") {
+		t.Error("Expected no restriction to allow everything")
+	}
+
+	patterns := []string{"telegram*", "tui_alice"}
+	for value, want := range map[string]bool{
+		"telegram":     true,
+		"telegram_bot": true,
+		"tui_alice":    true,
+		"tui_bob":      false,
+		"whatsapp":     false,
+	} {
+		if got := MatchesRestriction(patterns, value); got != want {
+			t.Errorf("MatchesRestriction(%v, %q) = %v, want %v", patterns, value, got, want)
+		}
+	}
+}
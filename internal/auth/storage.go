@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
@@ -29,6 +30,9 @@ type AuthToken struct {
 	LastUsedAt  *time.Time        `json:"last_used_at,omitempty"`
 	IsActive    bool              `json:"is_active"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
+	Scopes      []string          `json:"scopes"`
+	Channels    []string          `json:"channels,omitempty"` // Channel ID patterns the token is limited to
+	Sessions    []string          `json:"sessions,omitempty"` // Session key patterns the token is limited to
 }
 
 // TokenInfo represents public token information (no sensitive data)
@@ -40,6 +44,9 @@ type TokenInfo struct {
 	LastUsedAt *time.Time        `json:"last_used_at,omitempty"`
 	IsActive   bool              `json:"is_active"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
+	Scopes     []string          `json:"scopes"`
+	Channels   []string          `json:"channels,omitempty"`
+	Sessions   []string          `json:"sessions,omitempty"`
 }
 
 // CreateTokenRequest contains parameters for creating a new token
@@ -47,6 +54,9 @@ type CreateTokenRequest struct {
 	ClientName string            `json:"client_name"`
 	ExpiresAt  *time.Time        `json:"expires_at,omitempty"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
+	Scopes     []string          `json:"scopes,omitempty"`   // Defaults to DefaultScopes
+	Channels   []string          `json:"channels,omitempty"` // Empty allows every channel
+	Sessions   []string          `json:"sessions,omitempty"` // Empty allows every session
 }
 
 // CreateTokenResponse contains the newly created token (including the raw token)
@@ -94,11 +104,17 @@ func (ts *TokenStorage) CreateToken(req CreateTokenRequest) (*CreateTokenRespons
 		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	// Prepare scopes and restrictions
+	scopes, channels, sessions, err := encodeTokenAccess(req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Insert into database
 	_, err = ts.db.Exec(`
 		INSERT INTO auth_tokens 
-		(token_id, client_name, hashed_token, created_at, expires_at, is_active, metadata)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		(token_id, client_name, hashed_token, created_at, expires_at, is_active, metadata, scopes, channels, sessions)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		tokenID,
 		strings.TrimSpace(req.ClientName),
@@ -107,6 +123,9 @@ func (ts *TokenStorage) CreateToken(req CreateTokenRequest) (*CreateTokenRespons
 		req.ExpiresAt,
 		true,
 		string(metadataJSON),
+		scopes,
+		channels,
+		sessions,
 	)
 
 	if err != nil {
@@ -154,11 +173,17 @@ func (ts *TokenStorage) CreateTokenWithCustomFormat(req CreateTokenRequest, rawT
 		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	// Prepare scopes and restrictions
+	scopes, channels, sessions, err := encodeTokenAccess(req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Insert into database
 	_, err = ts.db.Exec(`
 		INSERT INTO auth_tokens 
-		(token_id, client_name, hashed_token, created_at, expires_at, is_active, metadata)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		(token_id, client_name, hashed_token, created_at, expires_at, is_active, metadata, scopes, channels, sessions)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		tokenID,
 		strings.TrimSpace(req.ClientName),
@@ -167,6 +192,9 @@ func (ts *TokenStorage) CreateTokenWithCustomFormat(req CreateTokenRequest, rawT
 		req.ExpiresAt,
 		true,
 		string(metadataJSON),
+		scopes,
+		channels,
+		sessions,
 	)
 
 	if err != nil {
@@ -198,10 +226,10 @@ func (ts *TokenStorage) ValidateToken(rawToken string) (*TokenInfo, error) {
 
 	// Query for the token
 	var token AuthToken
-	var metadataJSON string
+	var metadataJSON, scopesJSON, channelsJSON, sessionsJSON string
 
 	row := ts.db.QueryRow(`
-		SELECT token_id, client_name, hashed_token, created_at, expires_at, last_used_at, is_active, metadata
+		SELECT token_id, client_name, hashed_token, created_at, expires_at, last_used_at, is_active, metadata, scopes, channels, sessions
 		FROM auth_tokens 
 		WHERE hashed_token = ? AND is_active = 1
 	`, hashedToken)
@@ -215,6 +243,9 @@ func (ts *TokenStorage) ValidateToken(rawToken string) (*TokenInfo, error) {
 		&token.LastUsedAt,
 		&token.IsActive,
 		&metadataJSON,
+		&scopesJSON,
+		&channelsJSON,
+		&sessionsJSON,
 	)
 
 	if err != nil {
@@ -239,6 +270,9 @@ func (ts *TokenStorage) ValidateToken(rawToken string) (*TokenInfo, error) {
 	if err := json.Unmarshal([]byte(metadataJSON), &token.Metadata); err != nil {
 		token.Metadata = make(map[string]string)
 	}
+	token.Scopes = decodeStringList(scopesJSON)
+	token.Channels = decodeStringList(channelsJSON)
+	token.Sessions = decodeStringList(sessionsJSON)
 
 	// Return token info (no sensitive data)
 	now := time.Now()
@@ -250,16 +284,19 @@ func (ts *TokenStorage) ValidateToken(rawToken string) (*TokenInfo, error) {
 		LastUsedAt: &now,
 		IsActive:   token.IsActive,
 		Metadata:   token.Metadata,
+		Scopes:     token.Scopes,
+		Channels:   token.Channels,
+		Sessions:   token.Sessions,
 	}, nil
 }
 
 // GetTokenInfo retrieves public information about a token by ID
 func (ts *TokenStorage) GetTokenInfo(tokenID string) (*TokenInfo, error) {
 	var token AuthToken
-	var metadataJSON string
+	var metadataJSON, scopesJSON, channelsJSON, sessionsJSON string
 
 	row := ts.db.QueryRow(`
-		SELECT token_id, client_name, created_at, expires_at, last_used_at, is_active, metadata
+		SELECT token_id, client_name, created_at, expires_at, last_used_at, is_active, metadata, scopes, channels, sessions
 		FROM auth_tokens 
 		WHERE token_id = ?
 	`, tokenID)
@@ -272,6 +309,9 @@ func (ts *TokenStorage) GetTokenInfo(tokenID string) (*TokenInfo, error) {
 		&token.LastUsedAt,
 		&token.IsActive,
 		&metadataJSON,
+		&scopesJSON,
+		&channelsJSON,
+		&sessionsJSON,
 	)
 
 	if err != nil {
@@ -285,6 +325,9 @@ func (ts *TokenStorage) GetTokenInfo(tokenID string) (*TokenInfo, error) {
 	if err := json.Unmarshal([]byte(metadataJSON), &token.Metadata); err != nil {
 		token.Metadata = make(map[string]string)
 	}
+	token.Scopes = decodeStringList(scopesJSON)
+	token.Channels = decodeStringList(channelsJSON)
+	token.Sessions = decodeStringList(sessionsJSON)
 
 	return &TokenInfo{
 		TokenID:    token.TokenID,
@@ -294,6 +337,9 @@ func (ts *TokenStorage) GetTokenInfo(tokenID string) (*TokenInfo, error) {
 		LastUsedAt: token.LastUsedAt,
 		IsActive:   token.IsActive,
 		Metadata:   token.Metadata,
+		Scopes:     token.Scopes,
+		Channels:   token.Channels,
+		Sessions:   token.Sessions,
 	}, nil
 }
 
@@ -305,7 +351,7 @@ func (ts *TokenStorage) ListTokens(clientName string, includeInactive bool) ([]T
 	if clientName != "" {
 		if includeInactive {
 			query = `
-				SELECT token_id, client_name, created_at, expires_at, last_used_at, is_active, metadata
+				SELECT token_id, client_name, created_at, expires_at, last_used_at, is_active, metadata, scopes, channels, sessions
 				FROM auth_tokens 
 				WHERE client_name = ?
 				ORDER BY created_at DESC
@@ -313,7 +359,7 @@ func (ts *TokenStorage) ListTokens(clientName string, includeInactive bool) ([]T
 			args = []interface{}{clientName}
 		} else {
 			query = `
-				SELECT token_id, client_name, created_at, expires_at, last_used_at, is_active, metadata
+				SELECT token_id, client_name, created_at, expires_at, last_used_at, is_active, metadata, scopes, channels, sessions
 				FROM auth_tokens 
 				WHERE client_name = ? AND is_active = 1
 				ORDER BY created_at DESC
@@ -323,13 +369,13 @@ func (ts *TokenStorage) ListTokens(clientName string, includeInactive bool) ([]T
 	} else {
 		if includeInactive {
 			query = `
-				SELECT token_id, client_name, created_at, expires_at, last_used_at, is_active, metadata
+				SELECT token_id, client_name, created_at, expires_at, last_used_at, is_active, metadata, scopes, channels, sessions
 				FROM auth_tokens 
 				ORDER BY created_at DESC
 			`
 		} else {
 			query = `
-				SELECT token_id, client_name, created_at, expires_at, last_used_at, is_active, metadata
+				SELECT token_id, client_name, created_at, expires_at, last_used_at, is_active, metadata, scopes, channels, sessions
 				FROM auth_tokens 
 				WHERE is_active = 1
 				ORDER BY created_at DESC
@@ -346,7 +392,7 @@ func (ts *TokenStorage) ListTokens(clientName string, includeInactive bool) ([]T
 	var tokens []TokenInfo
 	for rows.Next() {
 		var token AuthToken
-		var metadataJSON string
+		var metadataJSON, scopesJSON, channelsJSON, sessionsJSON string
 
 		err := rows.Scan(
 			&token.TokenID,
@@ -356,6 +402,9 @@ func (ts *TokenStorage) ListTokens(clientName string, includeInactive bool) ([]T
 			&token.LastUsedAt,
 			&token.IsActive,
 			&metadataJSON,
+			&scopesJSON,
+			&channelsJSON,
+			&sessionsJSON,
 		)
 
 		if err != nil {
@@ -366,6 +415,9 @@ func (ts *TokenStorage) ListTokens(clientName string, includeInactive bool) ([]T
 		if err := json.Unmarshal([]byte(metadataJSON), &token.Metadata); err != nil {
 			token.Metadata = make(map[string]string)
 		}
+		token.Scopes = decodeStringList(scopesJSON)
+		token.Channels = decodeStringList(channelsJSON)
+		token.Sessions = decodeStringList(sessionsJSON)
 
 		tokens = append(tokens, TokenInfo{
 			TokenID:    token.TokenID,
@@ -375,6 +427,9 @@ func (ts *TokenStorage) ListTokens(clientName string, includeInactive bool) ([]T
 			LastUsedAt: token.LastUsedAt,
 			IsActive:   token.IsActive,
 			Metadata:   token.Metadata,
+			Scopes:     token.Scopes,
+			Channels:   token.Channels,
+			Sessions:   token.Sessions,
 		})
 	}
 
@@ -485,3 +540,42 @@ func (ts *TokenStorage) updateLastUsed(tokenID string) error {
 
 	return err
 }
+
+// encodeTokenAccess validates a request's scopes and restrictions and
+// encodes them for storage
+func encodeTokenAccess(req CreateTokenRequest) (scopes, channels, sessions string, err error) {
+	scopeList := req.Scopes
+	if len(scopeList) == 0 {
+		scopeList = DefaultScopes
+	}
+	if err := ValidateScopes(scopeList); err != nil {
+		return "", "", "", err
+	}
+
+	for _, patterns := range [][]string{req.Channels, req.Sessions} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return "", "", "", fmt.Errorf("invalid restriction pattern %q: %w", p, err)
+			}
+		}
+	}
+
+	encode := func(list []string) string {
+		if list == nil {
+			list = []string{}
+		}
+		data, _ := json.Marshal(list)
+		return string(data)
+	}
+	return encode(scopeList), encode(req.Channels), encode(req.Sessions), nil
+}
+
+// decodeStringList parses a JSON string array column, returning nil when
+// it is empty or invalid
+func decodeStringList(data string) []string {
+	var list []string
+	if err := json.Unmarshal([]byte(data), &list); err != nil || len(list) == 0 {
+		return nil
+	}
+	return list
+}
@@ -562,6 +562,58 @@ func TestDatabaseConstraints(t *testing.T) {
 	}
 }
 
+func TestTokenScopesAndRestrictions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	storage := NewTokenStorage(db)
+
+	// Tokens without scopes get the defaults
+	resp, err := storage.CreateToken(CreateTokenRequest{ClientName: "legacy"})
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+	if len(resp.TokenInfo.Scopes) != 1 || resp.TokenInfo.Scopes[0] != ScopeAdmin {
+		t.Errorf("Expected default scopes %v, got %v", DefaultScopes, resp.TokenInfo.Scopes)
+	}
+
+	resp, err = storage.CreateToken(CreateTokenRequest{
+		ClientName: "bot",
+		Scopes:     []string{ScopeChat, ScopeSessionsRead},
+		Channels:   []string{"telegram*"},
+		Sessions:   []string{"sess_1"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create scoped token: %v", err)
+	}
+
+	info, err := storage.ValidateToken(resp.Token)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if len(info.Scopes) != 2 || info.Scopes[0] != ScopeChat || info.Scopes[1] != ScopeSessionsRead {
+		t.Errorf("Unexpected scopes: %v", info.Scopes)
+	}
+	if len(info.Channels) != 1 || info.Channels[0] != "telegram*" {
+		t.Errorf("Unexpected channels: %v", info.Channels)
+	}
+	if len(info.Sessions) != 1 || info.Sessions[0] != "sess_1" {
+		t.Errorf("Unexpected sessions: %v", info.Sessions)
+	}
+
+	tokens, err := storage.ListTokens("bot", false)
+	if err != nil || len(tokens) != 1 || len(tokens[0].Scopes) != 2 {
+		t.Errorf("Expected listed token to carry scopes, got %+v, %v", tokens, err)
+	}
+
+	if _, err := storage.CreateToken(CreateTokenRequest{ClientName: "bad", Scopes: []string{"root"}}); err == nil {
+		t.Error("Expected an error for an unknown scope")
+	}
+	if _, err := storage.CreateToken(CreateTokenRequest{ClientName: "bad", Channels: []string{"["}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
 // timePtr returns a pointer to a time.Time value
 func timePtr(t time.Time) *time.Time {
 	return &t
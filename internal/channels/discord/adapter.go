@@ -0,0 +1,683 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// maxMessageLength is Discord's limit on message content
+const maxMessageLength = 2000
+
+// Adapter implements the ChannelAdapter interface for Discord
+type Adapter struct {
+	id        string
+	name      string
+	api       *restClient
+	config    DiscordConfig
+	status    channels.StatusCode
+	statusMsg string
+	incoming  chan *protocol.IncomingMessage
+	ctx       context.Context
+	cancel    context.CancelFunc
+	mutex     sync.RWMutex
+	startTime time.Time
+	msgCount  int64
+
+	// Gateway session state, kept across reconnects so they can resume
+	botUser            user
+	sessionID          string
+	resumeURL          string
+	seq                int64
+	commandsRegistered bool
+
+	// Follow-up messages holding the text of streamed messages beyond the
+	// message limit, by the ID of the message they continue
+	continuationMu sync.Mutex
+	continuations  map[string][]continuation
+}
+
+// continuation is a follow-up message carrying part of a longer message
+type continuation struct {
+	id   string
+	text string
+}
+
+// DiscordConfig contains Discord-specific configuration
+type DiscordConfig struct {
+	BotToken      string `json:"bot_token"`
+	ApplicationID string `json:"application_id"`
+
+	// RequireMention makes the bot ignore guild channel messages unless it is
+	// mentioned or replied to. Direct messages are always answered.
+	RequireMention   bool `json:"require_mention"`
+	RegisterCommands bool `json:"register_commands"`
+
+	// AllowedGuilds and AllowedUsers restrict who can talk to the bot. Empty
+	// lists allow everyone.
+	AllowedGuilds []string `json:"allowed_guilds"`
+	AllowedUsers  []string `json:"allowed_users"`
+
+	// MediaDir is where incoming attachments are stored, one subdirectory
+	// per session. Attachments are ignored if empty.
+	MediaDir      string `json:"media_dir"`
+	MaxDownloadMB int    `json:"max_download_mb"`
+
+	// APIURL overrides the REST API base URL (used in tests)
+	APIURL string `json:"api_url"`
+}
+
+// Factory creates Discord channel adapters
+type Factory struct{}
+
+// NewFactory creates a new Discord adapter factory
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// SupportsType returns whether this factory supports the given adapter type
+func (f *Factory) SupportsType(adapterType string) bool {
+	return adapterType == "discord"
+}
+
+// CreateAdapter creates a new Discord adapter instance
+func (f *Factory) CreateAdapter(config channels.ChannelConfig) (channels.ChannelAdapter, error) {
+	discordConfig := DiscordConfig{
+		RequireMention:   true,
+		RegisterCommands: true,
+	}
+
+	// Parse Discord-specific config
+	if token, ok := config.Config["bot_token"].(string); ok && token != "" {
+		discordConfig.BotToken = token
+	} else {
+		return nil, fmt.Errorf("bot_token is required for Discord adapter")
+	}
+
+	if appID, ok := config.Config["application_id"].(string); ok {
+		discordConfig.ApplicationID = appID
+	}
+
+	if requireMention, ok := config.Config["require_mention"].(bool); ok {
+		discordConfig.RequireMention = requireMention
+	}
+
+	if register, ok := config.Config["register_commands"].(bool); ok {
+		discordConfig.RegisterCommands = register
+	}
+
+	discordConfig.AllowedGuilds = channels.StringList(config.Config["allowed_guilds"])
+	discordConfig.AllowedUsers = channels.StringList(config.Config["allowed_users"])
+
+	discordConfig.MediaDir = config.MediaDir
+	if mediaDir, ok := config.Config["media_dir"].(string); ok && mediaDir != "" {
+		discordConfig.MediaDir = mediaDir
+	}
+
+	if maxMB, ok := config.Config["max_download_mb"].(float64); ok {
+		discordConfig.MaxDownloadMB = int(maxMB)
+	}
+
+	if apiURL, ok := config.Config["api_url"].(string); ok {
+		discordConfig.APIURL = strings.TrimSuffix(apiURL, "/")
+	}
+
+	return &Adapter{
+		id:       config.ID,
+		name:     config.Name,
+		api:      newRESTClient(discordConfig.APIURL, discordConfig.BotToken),
+		config:   discordConfig,
+		status:   channels.StatusInitializing,
+		incoming: make(chan *protocol.IncomingMessage, 100),
+	}, nil
+}
+
+// GetSupportedTypes returns the adapter types this factory supports
+func (f *Factory) GetSupportedTypes() []string {
+	return []string{"discord"}
+}
+
+// ID returns the adapter's unique identifier
+func (a *Adapter) ID() string {
+	return a.id
+}
+
+// Name returns the adapter's human-readable name
+func (a *Adapter) Name() string {
+	return a.name
+}
+
+// Type returns the adapter type
+func (a *Adapter) Type() string {
+	return "discord"
+}
+
+// Start verifies the bot token and connects to the Discord gateway
+func (a *Adapter) Start(ctx context.Context) error {
+	a.mutex.Lock()
+	a.ctx, a.cancel = context.WithCancel(ctx)
+	a.status = channels.StatusInitializing
+	a.statusMsg = "Connecting to Discord"
+	a.startTime = time.Now()
+	a.mutex.Unlock()
+
+	me, err := a.api.currentUser(a.ctx)
+	if err != nil {
+		a.setStatus(channels.StatusError, fmt.Sprintf("Failed to authenticate: %v", err))
+		return fmt.Errorf("failed to authenticate Discord bot: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.botUser = *me
+	a.mutex.Unlock()
+
+	go a.runGateway(a.ctx)
+
+	log.Printf("[Discord] Bot started: %s (%s)", a.Name(), me.Username)
+	return nil
+}
+
+// Stop gracefully shuts down the adapter
+func (a *Adapter) Stop() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.cancel != nil {
+		a.cancel()
+	}
+
+	a.status = channels.StatusOffline
+	a.statusMsg = "Adapter stopped"
+
+	// Close incoming message channel
+	close(a.incoming)
+
+	log.Printf("[Discord] Adapter stopped: %s", a.Name())
+	return nil
+}
+
+// setStatus updates the status reported by Status and IsHealthy
+func (a *Adapter) setStatus(status channels.StatusCode, msg string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	channels.SetStatus(a.ctx, &a.status, &a.statusMsg, status, msg)
+}
+
+// SendMessage sends a message to a Discord channel. Long messages are split
+// at Discord's 2000 character limit; files from MediaPath or image_path
+// metadata are uploaded with the first part.
+func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+
+	channelID := msg.UserID
+	if _, err := strconv.ParseUint(channelID, 10, 64); err != nil {
+		return fmt.Errorf("invalid channel ID: %s", channelID)
+	}
+
+	sanitizedText := channels.SanitizeUserFacingText(msg.Text)
+	parts := splitMessage(sanitizedText, maxMessageLength)
+
+	filePath := msg.MediaPath
+	if imagePath, ok := msg.Metadata["image_path"]; ok && imagePath != "" {
+		filePath = imagePath
+	}
+
+	for i, part := range parts {
+		params := &messageCreate{Content: part}
+
+		// Reply to the original message with the first part
+		if i == 0 {
+			if replyTo, ok := msg.Metadata["reply_to_message_id"]; ok && replyTo != "" {
+				params.MessageReference = &messageReference{MessageID: replyTo}
+			}
+		}
+
+		// Attach reply buttons to the last part; presses arrive as interactions
+		if i == len(parts)-1 {
+			params.Components = buttonComponents(msg.Metadata)
+		}
+
+		var err error
+		if i == 0 && filePath != "" {
+			_, err = a.api.createMessageWithFile(a.ctx, channelID, params, filePath)
+		} else if part != "" || len(params.Components) > 0 {
+			_, err = a.api.createMessage(a.ctx, channelID, params)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+
+	log.Printf("[Discord] Message sent to channel %s (%d chars, %d parts)", channelID, len(sanitizedText), len(parts))
+
+	a.mutex.Lock()
+	a.msgCount++
+	a.mutex.Unlock()
+
+	return nil
+}
+
+// buttonComponents converts the inline_keyboard metadata into Discord action
+// rows, or returns nil if there is none
+func buttonComponents(metadata map[string]string) []component {
+	raw, ok := metadata[protocol.MetadataInlineKeyboard]
+	if !ok || raw == "" {
+		return nil
+	}
+
+	var rows [][]protocol.InlineButton
+	if err := json.Unmarshal([]byte(raw), &rows); err != nil {
+		log.Printf("[Discord] Ignoring invalid inline keyboard: %v", err)
+		return nil
+	}
+
+	var components []component
+	for _, row := range rows {
+		actionRow := component{Type: 1}
+		for _, b := range row {
+			actionRow.Components = append(actionRow.Components, component{Type: 2, Style: 1, Label: b.Text, CustomID: b.Data})
+		}
+		components = append(components, actionRow)
+	}
+	return components
+}
+
+// splitMessage splits text into parts of at most limit characters,
+// preferring to break at newlines
+func splitMessage(text string, limit int) []string {
+	var parts []string
+	runes := []rune(text)
+	for len(runes) > limit {
+		cut := limit
+		for i := limit; i > limit/2; i-- {
+			if runes[i-1] == '\n' {
+				cut = i
+				break
+			}
+		}
+		parts = append(parts, strings.TrimRight(string(runes[:cut]), "\n"))
+		runes = runes[cut:]
+	}
+	return append(parts, string(runes))
+}
+
+// SendMessageWithID sends a message and returns the message ID (for later
+// editing). Text beyond the message limit is sent as follow-up messages.
+func (a *Adapter) SendMessageWithID(chatID int64, text string) (int, error) {
+	if a.ctx == nil {
+		return 0, fmt.Errorf("adapter not started")
+	}
+
+	channelID := strconv.FormatInt(chatID, 10)
+	parts := splitMessage(channels.SanitizeUserFacingText(text), maxMessageLength)
+	m, err := a.api.createMessage(a.ctx, channelID, &messageCreate{Content: parts[0]})
+	if err != nil {
+		return 0, err
+	}
+
+	// Snowflake IDs fit in an int on 64-bit platforms
+	id, err := strconv.ParseInt(m.ID, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid message ID %q: %w", m.ID, err)
+	}
+	return int(id), a.syncContinuations(channelID, m.ID, parts[1:])
+}
+
+// EditMessageText edits an existing message. Streamed replies that outgrow
+// the message limit continue in follow-up messages, which later edits
+// update, so the final edit delivers the whole reply.
+func (a *Adapter) EditMessageText(chatID int64, messageID int, text string) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+
+	channelID, id := strconv.FormatInt(chatID, 10), strconv.Itoa(messageID)
+	parts := splitMessage(channels.SanitizeUserFacingText(text), maxMessageLength)
+	if err := a.api.editMessage(a.ctx, channelID, id, parts[0]); err != nil {
+		return err
+	}
+	return a.syncContinuations(channelID, id, parts[1:])
+}
+
+// syncContinuations makes the follow-up messages of a message match parts,
+// editing changed ones, sending missing ones and deleting extra ones
+func (a *Adapter) syncContinuations(channelID, messageID string, parts []string) error {
+	a.continuationMu.Lock()
+	defer a.continuationMu.Unlock()
+
+	follow := a.continuations[messageID]
+	defer func() {
+		if len(follow) == 0 {
+			delete(a.continuations, messageID)
+			return
+		}
+		if a.continuations == nil {
+			a.continuations = make(map[string][]continuation)
+		}
+		a.continuations[messageID] = follow
+	}()
+
+	for i, part := range parts {
+		if i < len(follow) {
+			if follow[i].text != part {
+				if err := a.api.editMessage(a.ctx, channelID, follow[i].id, part); err != nil {
+					return err
+				}
+				follow[i].text = part
+			}
+			continue
+		}
+		m, err := a.api.createMessage(a.ctx, channelID, &messageCreate{Content: part})
+		if err != nil {
+			return fmt.Errorf("failed to send continuation: %w", err)
+		}
+		follow = append(follow, continuation{id: m.ID, text: part})
+	}
+
+	// The text shrank, e.g. the final answer replaced streamed tool output
+	for _, c := range follow[min(len(parts), len(follow)):] {
+		if err := a.api.deleteMessage(a.ctx, channelID, c.id); err != nil {
+			log.Printf("[Discord] Failed to delete continuation %s: %v", c.id, err)
+		}
+	}
+	follow = follow[:min(len(parts), len(follow))]
+	return nil
+}
+
+// DeleteMessage deletes a message (used for silent response cleanup)
+func (a *Adapter) DeleteMessage(chatID int64, messageID int) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+
+	channelID := strconv.FormatInt(chatID, 10)
+	if err := a.syncContinuations(channelID, strconv.Itoa(messageID), nil); err != nil {
+		return err
+	}
+	err := a.api.deleteMessage(a.ctx, channelID, strconv.Itoa(messageID))
+	if apiErr, ok := err.(*apiError); ok && apiErr.StatusCode == 404 {
+		// Ignore "message not found" errors
+		return nil
+	}
+	return err
+}
+
+// SendTypingIndicator shows "Bot is typing..." while the agent is thinking
+func (a *Adapter) SendTypingIndicator(chatID string) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+
+	return a.api.triggerTyping(a.ctx, chatID)
+}
+
+// ReceiveMessages returns the channel for incoming messages
+func (a *Adapter) ReceiveMessages() <-chan *protocol.IncomingMessage {
+	return a.incoming
+}
+
+// Status returns the current adapter status
+func (a *Adapter) Status() channels.ChannelStatus {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	details := map[string]interface{}{
+		"uptime_seconds":  time.Since(a.startTime).Seconds(),
+		"message_count":   a.msgCount,
+		"require_mention": a.config.RequireMention,
+	}
+	if a.botUser.ID != "" {
+		details["bot_id"] = a.botUser.ID
+		details["bot_username"] = a.botUser.Username
+	}
+
+	return channels.ChannelStatus{
+		Status:    a.status,
+		Message:   a.statusMsg,
+		Details:   details,
+		Timestamp: time.Now(),
+	}
+}
+
+// IsHealthy returns whether the adapter is functioning properly
+func (a *Adapter) IsHealthy() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.status == channels.StatusOnline
+}
+
+// handleMessage forwards a MESSAGE_CREATE event to the gateway
+func (a *Adapter) handleMessage(ctx context.Context, m *message) {
+	a.mutex.RLock()
+	botID := a.botUser.ID
+	a.mutex.RUnlock()
+
+	// Ignore our own messages and other bots
+	if m.Author.Bot || m.Author.ID == botID {
+		return
+	}
+
+	if !a.isAllowed(m.GuildID, m.Author.ID) {
+		log.Printf("[Discord] Ignoring message from user %s (not allowed)", m.Author.ID)
+		return
+	}
+
+	text := m.Content
+	chatType := "dm"
+	if m.GuildID != "" {
+		chatType = "guild"
+		if a.config.RequireMention && !mentionsUser(m, botID) {
+			return
+		}
+		text = stripMention(text, botID)
+	}
+
+	if text == "" && len(m.Attachments) == 0 {
+		return
+	}
+
+	sessionKey := "discord_" + m.ChannelID
+	metadata := map[string]string{
+		"message_id":        m.ID,
+		"chat_type":         chatType,
+		"from_user_id":      m.Author.ID,
+		"from_username":     m.Author.Username,
+		"from_display_name": m.Author.GlobalName,
+	}
+	if m.GuildID != "" {
+		metadata["guild_id"] = m.GuildID
+	}
+
+	var attachments []protocol.Attachment
+	if len(m.Attachments) > 0 {
+		metadata["type"] = "attachment"
+		if text == "" {
+			text = attachmentLabel(m.Attachments)
+		}
+		var failed bool
+		attachments, failed = a.downloadAttachments(ctx, sessionKey, m, metadata)
+		if failed {
+			text += "\n[Attachment could not be downloaded]"
+		}
+	}
+
+	incomingMsg := &protocol.IncomingMessage{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeIncomingMessage,
+			ID:        a.generateMessageID(),
+			Timestamp: time.Now(),
+		},
+		ChannelID:   a.id,
+		SessionKey:  sessionKey,
+		UserID:      m.ChannelID,
+		Text:        text,
+		Attachments: attachments,
+		Metadata:    metadata,
+	}
+
+	a.forward(incomingMsg, "message")
+}
+
+// handleInteraction forwards slash commands and button presses as text,
+// the same way Telegram delivers commands and callback queries
+func (a *Adapter) handleInteraction(ctx context.Context, i *interaction) {
+	invoker := i.invoker()
+	if invoker == nil {
+		return
+	}
+
+	if !a.isAllowed(i.GuildID, invoker.ID) {
+		a.api.respondToInteraction(ctx, i, callbackChannelMessage, &messageCreate{
+			Content: "You are not allowed to use this bot.",
+			Flags:   messageFlagEphemeral,
+		})
+		return
+	}
+
+	metadata := map[string]string{
+		"interaction_id":    i.ID,
+		"from_user_id":      invoker.ID,
+		"from_username":     invoker.Username,
+		"from_display_name": invoker.GlobalName,
+	}
+
+	var text string
+	var err error
+	switch i.Type {
+	case interactionApplicationCommand:
+		text = commandText(i)
+		metadata["type"] = "slash_command"
+		// Echo the command; the reply arrives as a normal message
+		err = a.api.respondToInteraction(ctx, i, callbackChannelMessage, &messageCreate{Content: "> " + text})
+	case interactionMessageComponent:
+		text = i.Data.CustomID
+		metadata["type"] = "callback_query"
+		metadata["callback_id"] = i.ID
+		err = a.api.respondToInteraction(ctx, i, callbackDeferredUpdate, nil)
+	default:
+		return
+	}
+	if err != nil {
+		log.Printf("[Discord] Failed to acknowledge interaction: %v", err)
+	}
+
+	incomingMsg := &protocol.IncomingMessage{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeIncomingMessage,
+			ID:        a.generateMessageID(),
+			Timestamp: time.Now(),
+		},
+		ChannelID:  a.id,
+		SessionKey: "discord_" + i.ChannelID,
+		UserID:     i.ChannelID,
+		Text:       text,
+		Metadata:   metadata,
+	}
+
+	a.forward(incomingMsg, metadata["type"])
+}
+
+// commandText renders a slash command invocation as "/name value..."
+func commandText(i *interaction) string {
+	parts := []string{"/" + i.Data.Name}
+	for _, opt := range i.Data.Options {
+		parts = append(parts, fmt.Sprint(opt.Value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// forward sends a message to the incoming channel without blocking
+func (a *Adapter) forward(msg *protocol.IncomingMessage, kind string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	switch err := channels.Forward(a.ctx, a.incoming, msg); err {
+	case nil:
+		a.msgCount++
+		// Privacy-safe logging - no message content or user names
+		log.Printf("[Discord] Received %s from channel %s (%d chars, %d attachments)",
+			kind, msg.UserID, len(msg.Text), len(msg.Attachments))
+	case channels.ErrQueueFull:
+		log.Printf("[Discord] Warning: incoming message channel is full, dropping %s", kind)
+	}
+}
+
+// isAllowed applies the guild and user allowlists
+func (a *Adapter) isAllowed(guildID, userID string) bool {
+	if len(a.config.AllowedUsers) > 0 && !slices.Contains(a.config.AllowedUsers, userID) {
+		return false
+	}
+	if guildID != "" && len(a.config.AllowedGuilds) > 0 && !slices.Contains(a.config.AllowedGuilds, guildID) {
+		return false
+	}
+	return true
+}
+
+// mentionsUser reports whether a message mentions userID or replies to one
+// of its messages
+func mentionsUser(m *message, userID string) bool {
+	for _, u := range m.Mentions {
+		if u.ID == userID {
+			return true
+		}
+	}
+	return m.ReferencedMessage != nil && m.ReferencedMessage.Author.ID == userID
+}
+
+// stripMention removes <@id> and <@!id> mentions of userID from text
+func stripMention(text, userID string) string {
+	if userID == "" {
+		return strings.TrimSpace(text)
+	}
+	re := regexp.MustCompile(`<@!?` + regexp.QuoteMeta(userID) + `>`)
+	return strings.TrimSpace(re.ReplaceAllString(text, ""))
+}
+
+// generateMessageID creates a unique message ID
+func (a *Adapter) generateMessageID() string {
+	return fmt.Sprintf("discord_%s_%s", a.id, uuid.New().String()[:8])
+}
+
+// registerCommands registers slash commands with Discord, matching the
+// commands Telegram registers with BotFather
+func (a *Adapter) registerCommands(ctx context.Context) {
+	a.mutex.RLock()
+	appID := a.config.ApplicationID
+	a.mutex.RUnlock()
+	if appID == "" {
+		log.Printf("[Discord] Warning: No application ID, slash commands not registered")
+		return
+	}
+
+	commands := []applicationCommand{
+		{Name: "reset", Description: "Clear conversation history and start fresh"},
+		{Name: "status", Description: "Show session status and model info"},
+		{Name: "help", Description: "Show available commands"},
+		{Name: "model", Description: "Switch or view current model", Options: []applicationCommandOption{
+			{Type: 3, Name: "name", Description: "Model or alias to switch to"},
+		}},
+		{Name: "context", Description: "Show context window usage"},
+		{Name: "stop", Description: "Stop the current operation"},
+	}
+
+	if err := a.api.overwriteCommands(ctx, appID, commands); err != nil {
+		log.Printf("[Discord] Warning: Failed to register commands: %v", err)
+	} else {
+		log.Printf("[Discord] Registered %d slash commands", len(commands))
+	}
+}
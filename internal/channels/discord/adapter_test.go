@@ -0,0 +1,512 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+const (
+	testBotID     = "1000"
+	testChannelID = "2000"
+	testGuildID   = "3000"
+)
+
+// apiCall is a REST request received by the fake server
+type apiCall struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// fakeDiscord serves the REST API and a websocket gateway
+type fakeDiscord struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu         sync.Mutex
+	calls      []apiCall
+	messages   int       // Messages created, numbered from 5000
+	gatewayOps []payload // Identify and resume frames, in order
+	conn       *websocket.Conn
+	seq        int64
+	connected  chan struct{}
+}
+
+func newFakeDiscord(t *testing.T) *fakeDiscord {
+	f := &fakeDiscord{t: t, connected: make(chan struct{}, 10)}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeDiscord) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/ws/" {
+		f.serveGateway(w, r)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	f.calls = append(f.calls, apiCall{Method: r.Method, Path: r.URL.Path, Body: string(body)})
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.URL.Path == "/api/users/@me":
+		w.Write([]byte(`{"id": "` + testBotID + `", "username": "conduit-bot", "bot": true}`))
+	case r.URL.Path == "/api/gateway/bot":
+		w.Write([]byte(`{"url": "ws` + strings.TrimPrefix(f.server.URL, "http") + `/ws"}`))
+	case r.URL.Path == "/cdn/photo.png":
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\nfake image"))
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/messages"):
+		f.mu.Lock()
+		id := 5000 + f.messages
+		f.messages++
+		f.mu.Unlock()
+		w.Write([]byte(`{"id": "` + strconv.Itoa(id) + `", "channel_id": "` + testChannelID + `"}`))
+	case r.Method == http.MethodDelete:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": 10008, "message": "Unknown Message"}`))
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// serveGateway performs the hello/identify handshake and then sends READY
+// (or RESUMED) before handing the connection to the test
+func (f *fakeDiscord) serveGateway(w http.ResponseWriter, r *http.Request) {
+	ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	ws.WriteJSON(payload{Op: opHello, D: json.RawMessage(`{"heartbeat_interval": 45000}`)})
+	var p payload
+	if err := ws.ReadJSON(&p); err != nil {
+		ws.Close()
+		return
+	}
+
+	f.mu.Lock()
+	f.gatewayOps = append(f.gatewayOps, p)
+	f.conn = ws
+	f.mu.Unlock()
+
+	if p.Op == opResume {
+		f.dispatch("RESUMED", map[string]interface{}{})
+	} else {
+		f.dispatch("READY", map[string]interface{}{
+			"session_id":         "session-1",
+			"resume_gateway_url": "ws" + strings.TrimPrefix(f.server.URL, "http") + "/ws",
+			"user":               map[string]interface{}{"id": testBotID, "username": "conduit-bot", "bot": true},
+			"application":        map[string]interface{}{"id": "4000"},
+		})
+	}
+	f.connected <- struct{}{}
+
+	// Drain heartbeats until the client goes away
+	for {
+		if _, _, err := ws.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// dispatch sends an event on the current gateway connection
+func (f *fakeDiscord) dispatch(event string, data interface{}) {
+	raw, err := json.Marshal(data)
+	require.NoError(f.t, err)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	seq := f.seq
+	require.NoError(f.t, f.conn.WriteJSON(payload{Op: opDispatch, T: event, S: &seq, D: raw}))
+}
+
+func (f *fakeDiscord) waitConnected() {
+	select {
+	case <-f.connected:
+	case <-time.After(5 * time.Second):
+		f.t.Fatal("timed out waiting for gateway connection")
+	}
+}
+
+// callsTo returns the recorded requests whose path ends with suffix
+func (f *fakeDiscord) callsTo(method, suffix string) []apiCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []apiCall
+	for _, c := range f.calls {
+		if c.Method == method && strings.HasSuffix(c.Path, suffix) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+func newTestAdapter(t *testing.T, f *fakeDiscord, extra map[string]interface{}) *Adapter {
+	cfg := map[string]interface{}{
+		"bot_token": "test-token",
+		"api_url":   f.server.URL + "/api",
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	adapter, err := NewFactory().CreateAdapter(channels.ChannelConfig{
+		ID: "discord", Type: "discord", Name: "Discord", Enabled: true, Config: cfg,
+	})
+	require.NoError(t, err)
+	return adapter.(*Adapter)
+}
+
+func startTestAdapter(t *testing.T, f *fakeDiscord, extra map[string]interface{}) *Adapter {
+	a := newTestAdapter(t, f, extra)
+	require.NoError(t, a.Start(context.Background()))
+	t.Cleanup(func() { a.Stop() })
+	f.waitConnected()
+	require.Eventually(t, a.IsHealthy, 5*time.Second, 10*time.Millisecond)
+	return a
+}
+
+func receive(t *testing.T, a *Adapter) *protocol.IncomingMessage {
+	select {
+	case msg := <-a.ReceiveMessages():
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for incoming message")
+		return nil
+	}
+}
+
+func TestFactory_CreateAdapter(t *testing.T) {
+	f := NewFactory()
+	assert.True(t, f.SupportsType("discord"))
+	assert.False(t, f.SupportsType("telegram"))
+
+	_, err := f.CreateAdapter(channels.ChannelConfig{ID: "discord", Config: map[string]interface{}{}})
+	assert.Error(t, err)
+
+	adapter, err := f.CreateAdapter(channels.ChannelConfig{
+		ID:       "discord",
+		MediaDir: "/tmp/media",
+		Config: map[string]interface{}{
+			"bot_token":      "token",
+			"allowed_guilds": []interface{}{"123", float64(456)},
+		},
+	})
+	require.NoError(t, err)
+
+	a := adapter.(*Adapter)
+	assert.True(t, a.config.RequireMention, "mention-only should be the default in guilds")
+	assert.True(t, a.config.RegisterCommands)
+	assert.Equal(t, []string{"123", "456"}, a.config.AllowedGuilds)
+	assert.Equal(t, "/tmp/media", a.config.MediaDir)
+	assert.Equal(t, DefaultAPIURL, a.api.baseURL)
+}
+
+func TestAdapter_ConnectsAndRegistersCommands(t *testing.T) {
+	f := newFakeDiscord(t)
+	a := startTestAdapter(t, f, nil)
+
+	f.mu.Lock()
+	identify := f.gatewayOps[0]
+	f.mu.Unlock()
+	assert.Equal(t, opIdentify, identify.Op)
+	assert.Contains(t, string(identify.D), `"token":"test-token"`)
+
+	require.Eventually(t, func() bool {
+		return len(f.callsTo(http.MethodPut, "/applications/4000/commands")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var commands []applicationCommand
+	require.NoError(t, json.Unmarshal([]byte(f.callsTo(http.MethodPut, "/commands")[0].Body), &commands))
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.Name
+	}
+	assert.Equal(t, []string{"reset", "status", "help", "model", "context", "stop"}, names)
+
+	status := a.Status()
+	assert.Equal(t, channels.StatusOnline, status.Status)
+	assert.Equal(t, "conduit-bot", status.Details["bot_username"])
+}
+
+func TestAdapter_ReceivesMessages(t *testing.T) {
+	f := newFakeDiscord(t)
+	a := startTestAdapter(t, f, nil)
+
+	author := map[string]interface{}{"id": "42", "username": "alice"}
+
+	// Ignored: the bot's own message, another bot, and a guild message
+	// that doesn't mention the bot
+	f.dispatch("MESSAGE_CREATE", map[string]interface{}{
+		"id": "1", "channel_id": testChannelID, "content": "echo", "author": map[string]interface{}{"id": testBotID},
+	})
+	f.dispatch("MESSAGE_CREATE", map[string]interface{}{
+		"id": "2", "channel_id": testChannelID, "content": "beep", "author": map[string]interface{}{"id": "7", "bot": true},
+	})
+	f.dispatch("MESSAGE_CREATE", map[string]interface{}{
+		"id": "3", "channel_id": testChannelID, "guild_id": testGuildID, "content": "chatter", "author": author,
+	})
+
+	// Guild message mentioning the bot
+	f.dispatch("MESSAGE_CREATE", map[string]interface{}{
+		"id": "4", "channel_id": testChannelID, "guild_id": testGuildID, "author": author,
+		"content":  "<@!" + testBotID + "> what's up?",
+		"mentions": []interface{}{map[string]interface{}{"id": testBotID}},
+	})
+	msg := receive(t, a)
+	assert.Equal(t, "what's up?", msg.Text)
+	assert.Equal(t, "discord_"+testChannelID, msg.SessionKey)
+	assert.Equal(t, testChannelID, msg.UserID)
+	assert.Equal(t, "guild", msg.Metadata["chat_type"])
+	assert.Equal(t, "42", msg.Metadata["from_user_id"])
+	assert.Equal(t, "4", msg.Metadata["message_id"])
+
+	// Direct messages don't need a mention
+	f.dispatch("MESSAGE_CREATE", map[string]interface{}{
+		"id": "5", "channel_id": "2001", "content": "hello", "author": author,
+	})
+	msg = receive(t, a)
+	assert.Equal(t, "hello", msg.Text)
+	assert.Equal(t, "dm", msg.Metadata["chat_type"])
+	assert.Equal(t, "discord_2001", msg.SessionKey)
+}
+
+func TestAdapter_AllowedUsers(t *testing.T) {
+	f := newFakeDiscord(t)
+	a := startTestAdapter(t, f, map[string]interface{}{"allowed_users": []interface{}{"42"}})
+
+	f.dispatch("MESSAGE_CREATE", map[string]interface{}{
+		"id": "1", "channel_id": testChannelID, "content": "let me in", "author": map[string]interface{}{"id": "99"},
+	})
+	f.dispatch("MESSAGE_CREATE", map[string]interface{}{
+		"id": "2", "channel_id": testChannelID, "content": "hi", "author": map[string]interface{}{"id": "42"},
+	})
+
+	msg := receive(t, a)
+	assert.Equal(t, "hi", msg.Text)
+	assert.False(t, a.isAllowed("", "99"))
+}
+
+func TestAdapter_Interactions(t *testing.T) {
+	f := newFakeDiscord(t)
+	a := startTestAdapter(t, f, nil)
+
+	f.dispatch("INTERACTION_CREATE", map[string]interface{}{
+		"id": "700", "type": interactionApplicationCommand, "token": "tok", "channel_id": testChannelID, "guild_id": testGuildID,
+		"member": map[string]interface{}{"user": map[string]interface{}{"id": "42", "username": "alice"}},
+		"data":   map[string]interface{}{"name": "model", "options": []interface{}{map[string]interface{}{"name": "name", "value": "opus"}}},
+	})
+	msg := receive(t, a)
+	assert.Equal(t, "/model opus", msg.Text)
+	assert.Equal(t, "slash_command", msg.Metadata["type"])
+	assert.Equal(t, testChannelID, msg.UserID)
+
+	f.dispatch("INTERACTION_CREATE", map[string]interface{}{
+		"id": "701", "type": interactionMessageComponent, "token": "tok", "channel_id": testChannelID,
+		"user": map[string]interface{}{"id": "42"},
+		"data": map[string]interface{}{"custom_id": "/approve abc"},
+	})
+	msg = receive(t, a)
+	assert.Equal(t, "/approve abc", msg.Text)
+	assert.Equal(t, "callback_query", msg.Metadata["type"])
+
+	require.Eventually(t, func() bool {
+		return len(f.callsTo(http.MethodPost, "/callback")) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, f.callsTo(http.MethodPost, "/interactions/700/tok/callback")[0].Body, `"type":4`)
+	assert.Contains(t, f.callsTo(http.MethodPost, "/interactions/701/tok/callback")[0].Body, `"type":6`)
+}
+
+func TestAdapter_ReceivesAttachments(t *testing.T) {
+	f := newFakeDiscord(t)
+	mediaDir := t.TempDir()
+	a := startTestAdapter(t, f, map[string]interface{}{"media_dir": mediaDir})
+
+	f.dispatch("MESSAGE_CREATE", map[string]interface{}{
+		"id": "9", "channel_id": testChannelID, "content": "", "author": map[string]interface{}{"id": "42"},
+		"attachments": []interface{}{map[string]interface{}{
+			"id": "1", "filename": "photo.png", "content_type": "image/png", "size": 18, "url": f.server.URL + "/cdn/photo.png",
+		}},
+	})
+
+	msg := receive(t, a)
+	assert.Equal(t, "[Attachment: photo.png]", msg.Text)
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, protocol.AttachmentImage, msg.Attachments[0].Type)
+	assert.Equal(t, "image/png", msg.Attachments[0].MediaType)
+	assert.Equal(t, "9_photo.png", msg.Attachments[0].Filename)
+
+	data, err := os.ReadFile(msg.Attachments[0].Path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "fake image")
+}
+
+func TestAdapter_SendMessage(t *testing.T) {
+	f := newFakeDiscord(t)
+	a := startTestAdapter(t, f, nil)
+
+	buttons, _ := json.Marshal([][]protocol.InlineButton{{{Text: "Approve", Data: "/approve abc"}}})
+	err := a.SendMessage(&protocol.OutgoingMessage{
+		UserID: testChannelID,
+		Text:   strings.Repeat("a", 1500) + "\n" + strings.Repeat("b", 1500),
+		Metadata: map[string]string{
+			"reply_to_message_id":           "4",
+			protocol.MetadataInlineKeyboard: string(buttons),
+		},
+	})
+	require.NoError(t, err)
+
+	sent := f.callsTo(http.MethodPost, "/channels/"+testChannelID+"/messages")
+	require.Len(t, sent, 2, "long messages should be split")
+	var first, second messageCreate
+	require.NoError(t, json.Unmarshal([]byte(sent[0].Body), &first))
+	require.NoError(t, json.Unmarshal([]byte(sent[1].Body), &second))
+	assert.Equal(t, strings.Repeat("a", 1500), first.Content)
+	assert.Equal(t, "4", first.MessageReference.MessageID)
+	assert.Empty(t, first.Components)
+	require.Len(t, second.Components, 1)
+	assert.Equal(t, "/approve abc", second.Components[0].Components[0].CustomID)
+
+	assert.Error(t, a.SendMessage(&protocol.OutgoingMessage{UserID: "not-a-channel", Text: "hi"}))
+}
+
+func TestAdapter_SendMessageWithFile(t *testing.T) {
+	f := newFakeDiscord(t)
+	a := startTestAdapter(t, f, nil)
+
+	path := t.TempDir() + "/chart.png"
+	require.NoError(t, os.WriteFile(path, []byte("png bytes"), 0600))
+
+	require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{UserID: testChannelID, Text: "Here you go", MediaPath: path}))
+
+	sent := f.callsTo(http.MethodPost, "/messages")
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0].Body, `name="files[0]"; filename="chart.png"`)
+	assert.Contains(t, sent[0].Body, `"content":"Here you go"`)
+	assert.Contains(t, sent[0].Body, "png bytes")
+}
+
+func TestAdapter_StreamingAndTyping(t *testing.T) {
+	f := newFakeDiscord(t)
+	a := startTestAdapter(t, f, nil)
+
+	var streaming channels.StreamingAdapter = a
+	var typing channels.TypingIndicator = a
+
+	id, err := streaming.SendMessageWithID(2000, "thinking...")
+	require.NoError(t, err)
+	assert.Equal(t, 5000, id)
+
+	content := func(call apiCall) string {
+		var m messageCreate
+		require.NoError(t, json.Unmarshal([]byte(call.Body), &m))
+		return m.Content
+	}
+
+	// A streamed reply that outgrows the limit continues in a follow-up message
+	require.NoError(t, streaming.EditMessageText(2000, id, strings.Repeat("x", 2500)))
+	edits := f.callsTo(http.MethodPatch, "/channels/2000/messages/5000")
+	require.Len(t, edits, 1)
+	assert.Len(t, []rune(content(edits[0])), maxMessageLength)
+	sent := f.callsTo(http.MethodPost, "/channels/2000/messages")
+	require.Len(t, sent, 2)
+	assert.Equal(t, strings.Repeat("x", 500), content(sent[1]))
+
+	// Later edits update the follow-up instead of sending another
+	require.NoError(t, streaming.EditMessageText(2000, id, strings.Repeat("x", 2600)))
+	assert.Len(t, f.callsTo(http.MethodPost, "/channels/2000/messages"), 2)
+	followUps := f.callsTo(http.MethodPatch, "/channels/2000/messages/5001")
+	require.Len(t, followUps, 1)
+	assert.Equal(t, strings.Repeat("x", 600), content(followUps[0]))
+
+	// A shorter final reply removes it
+	require.NoError(t, streaming.EditMessageText(2000, id, "done"))
+	assert.Len(t, f.callsTo(http.MethodDelete, "/channels/2000/messages/5001"), 1)
+
+	assert.NoError(t, streaming.DeleteMessage(2000, id), "deleting a missing message is not an error")
+
+	require.NoError(t, typing.SendTypingIndicator(testChannelID))
+	assert.Len(t, f.callsTo(http.MethodPost, "/channels/"+testChannelID+"/typing"), 1)
+}
+
+func TestAdapter_ResumesAfterDisconnect(t *testing.T) {
+	f := newFakeDiscord(t)
+	a := startTestAdapter(t, f, nil)
+
+	f.mu.Lock()
+	f.conn.Close()
+	f.mu.Unlock()
+
+	f.waitConnected()
+	require.Eventually(t, a.IsHealthy, 5*time.Second, 10*time.Millisecond)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.Len(t, f.gatewayOps, 2)
+	assert.Equal(t, opResume, f.gatewayOps[1].Op)
+	assert.Contains(t, string(f.gatewayOps[1].D), `"session_id":"session-1"`)
+}
+
+func TestAdapter_MessageContentIntent(t *testing.T) {
+	identifyIntents := func(extra map[string]interface{}) int {
+		f := newFakeDiscord(t)
+		startTestAdapter(t, f, extra)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var identify struct {
+			Intents int `json:"intents"`
+		}
+		require.NoError(t, json.Unmarshal(f.gatewayOps[0].D, &identify))
+		return identify.Intents
+	}
+
+	assert.Zero(t, identifyIntents(nil)&messageContentIntent, "mention-only mode must not need the privileged intent")
+	assert.NotZero(t, identifyIntents(map[string]interface{}{"require_mention": false})&messageContentIntent)
+}
+
+func TestAdapter_DisallowedIntentIsExplained(t *testing.T) {
+	f := newFakeDiscord(t)
+	a := startTestAdapter(t, f, map[string]interface{}{"require_mention": false})
+
+	f.mu.Lock()
+	f.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(closeDisallowedIntent, "Disallowed intent(s)."))
+	f.mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		return a.Status().Status == channels.StatusError
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, a.Status().Message, "enable the Message Content intent")
+}
+
+func TestSplitMessage(t *testing.T) {
+	assert.Equal(t, []string{"short"}, splitMessage("short", 10))
+	assert.Equal(t, []string{"line one", "line two"}, splitMessage("line one\nline two", 12))
+	assert.Equal(t, []string{"abcde", "fghij", "k"}, splitMessage("abcdefghijk", 5))
+}
+
+func TestStripMention(t *testing.T) {
+	assert.Equal(t, "hi there", stripMention("<@123> hi there", "123"))
+	assert.Equal(t, "hi", stripMention("hi <@!123>", "123"))
+	assert.Equal(t, "<@456> hi", stripMention("<@456> hi", "123"))
+}
@@ -0,0 +1,349 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DefaultAPIURL is the Discord REST API base URL
+const DefaultAPIURL = "https://discord.com/api/v10"
+
+// maxRateLimitRetries bounds how often a request is retried after a 429
+const maxRateLimitRetries = 3
+
+// user is a Discord user object
+type user struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name"`
+	Bot        bool   `json:"bot"`
+}
+
+// member is a guild member object; User is only set on interactions
+type member struct {
+	User *user `json:"user"`
+}
+
+// attachment is a file attached to a Discord message
+type attachment struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`
+}
+
+// message is a Discord message object
+type message struct {
+	ID                string       `json:"id"`
+	ChannelID         string       `json:"channel_id"`
+	GuildID           string       `json:"guild_id"`
+	Author            user         `json:"author"`
+	Content           string       `json:"content"`
+	Mentions          []user       `json:"mentions"`
+	Attachments       []attachment `json:"attachments"`
+	ReferencedMessage *message     `json:"referenced_message"`
+}
+
+// Interaction types
+const (
+	interactionApplicationCommand = 2
+	interactionMessageComponent   = 3
+)
+
+// Interaction callback types
+const (
+	callbackChannelMessage = 4
+	callbackDeferredUpdate = 6
+)
+
+// messageFlagEphemeral makes an interaction response visible only to the invoker
+const messageFlagEphemeral = 64
+
+// interaction is a slash command invocation or button press
+type interaction struct {
+	ID        string  `json:"id"`
+	Type      int     `json:"type"`
+	Token     string  `json:"token"`
+	ChannelID string  `json:"channel_id"`
+	GuildID   string  `json:"guild_id"`
+	Member    *member `json:"member"`
+	User      *user   `json:"user"`
+	Data      struct {
+		Name     string `json:"name"`
+		CustomID string `json:"custom_id"`
+		Options  []struct {
+			Name  string      `json:"name"`
+			Value interface{} `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+}
+
+// invoker returns the user who triggered the interaction
+func (i *interaction) invoker() *user {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+// messageReference points a new message at the one it replies to
+type messageReference struct {
+	MessageID       string `json:"message_id"`
+	FailIfNotExists bool   `json:"fail_if_not_exists"`
+}
+
+// component is an action row (type 1) or a button (type 2)
+type component struct {
+	Type       int         `json:"type"`
+	Style      int         `json:"style,omitempty"`
+	Label      string      `json:"label,omitempty"`
+	CustomID   string      `json:"custom_id,omitempty"`
+	Components []component `json:"components,omitempty"`
+}
+
+// messageCreate is the body of a create or edit message request
+type messageCreate struct {
+	Content          string            `json:"content"`
+	MessageReference *messageReference `json:"message_reference,omitempty"`
+	Components       []component       `json:"components,omitempty"`
+	Flags            int               `json:"flags,omitempty"`
+}
+
+// applicationCommand is a slash command definition
+type applicationCommand struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Options     []applicationCommandOption `json:"options,omitempty"`
+}
+
+// applicationCommandOption is a slash command argument
+type applicationCommandOption struct {
+	Type        int    `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+// apiError is an error response from the Discord API
+type apiError struct {
+	StatusCode int     `json:"-"`
+	Code       int     `json:"code"`
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("discord API error %d (code %d): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// restClient is a minimal client for the Discord REST API
+type restClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func newRESTClient(baseURL, token string) *restClient {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &restClient{
+		baseURL:    baseURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: 2 * time.Minute}, // Long enough for attachment downloads
+	}
+}
+
+// doJSON sends body as JSON and decodes the response into out if non-nil
+func (c *restClient) doJSON(ctx context.Context, method, path string, body, out interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+	return c.do(ctx, method, path, "application/json", data, out)
+}
+
+// do sends a request, waiting and retrying when Discord rate limits it
+func (c *restClient) do(ctx context.Context, method, path, contentType string, body []byte, out interface{}) error {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bot "+c.token)
+		req.Header.Set("User-Agent", "DiscordBot (https://github.com/jefflaplante/conduit, 1.0)")
+		if body != nil {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("%s %s failed: %w", method, path, err)
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if out != nil && len(respBody) > 0 {
+				if err := json.Unmarshal(respBody, out); err != nil {
+					return fmt.Errorf("failed to decode response: %w", err)
+				}
+			}
+			return nil
+		}
+
+		apiErr := &apiError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(respBody, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRateLimitRetries {
+			return apiErr
+		}
+
+		wait := time.Duration(apiErr.RetryAfter * float64(time.Second))
+		if wait <= 0 {
+			if secs, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil {
+				wait = time.Duration(secs * float64(time.Second))
+			} else {
+				wait = time.Second
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// currentUser returns the bot's own user
+func (c *restClient) currentUser(ctx context.Context) (*user, error) {
+	var u user
+	if err := c.doJSON(ctx, http.MethodGet, "/users/@me", nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// gatewayURL returns the websocket URL to connect to
+func (c *restClient) gatewayURL(ctx context.Context) (string, error) {
+	var resp struct {
+		URL string `json:"url"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/gateway/bot", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.URL, nil
+}
+
+// createMessage posts a message to a channel
+func (c *restClient) createMessage(ctx context.Context, channelID string, params *messageCreate) (*message, error) {
+	var m message
+	if err := c.doJSON(ctx, http.MethodPost, "/channels/"+channelID+"/messages", params, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// createMessageWithFile posts a message with a file upload
+func (c *restClient) createMessageWithFile(ctx context.Context, channelID string, params *messageCreate, path string) (*message, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="payload_json"`)
+	header.Set("Content-Type", "application/json")
+	part, err := w.CreatePart(header)
+	if err == nil {
+		_, err = part.Write(payload)
+	}
+	if err == nil {
+		part, err = w.CreateFormFile("files[0]", filepath.Base(path))
+	}
+	if err == nil {
+		_, err = part.Write(data)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	var m message
+	if err := c.do(ctx, http.MethodPost, "/channels/"+channelID+"/messages", w.FormDataContentType(), body.Bytes(), &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// editMessage replaces the content of a message
+func (c *restClient) editMessage(ctx context.Context, channelID, messageID, content string) error {
+	return c.doJSON(ctx, http.MethodPatch, "/channels/"+channelID+"/messages/"+messageID, &messageCreate{Content: content}, nil)
+}
+
+// deleteMessage deletes a message
+func (c *restClient) deleteMessage(ctx context.Context, channelID, messageID string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/channels/"+channelID+"/messages/"+messageID, nil, nil)
+}
+
+// triggerTyping shows the typing indicator in a channel for a few seconds
+func (c *restClient) triggerTyping(ctx context.Context, channelID string) error {
+	return c.doJSON(ctx, http.MethodPost, "/channels/"+channelID+"/typing", nil, nil)
+}
+
+// respondToInteraction answers a slash command or button press
+func (c *restClient) respondToInteraction(ctx context.Context, i *interaction, callbackType int, data *messageCreate) error {
+	body := map[string]interface{}{"type": callbackType}
+	if data != nil {
+		body["data"] = data
+	}
+	return c.doJSON(ctx, http.MethodPost, "/interactions/"+i.ID+"/"+i.Token+"/callback", body, nil)
+}
+
+// overwriteCommands replaces the application's global slash commands
+func (c *restClient) overwriteCommands(ctx context.Context, applicationID string, commands []applicationCommand) error {
+	return c.doJSON(ctx, http.MethodPut, "/applications/"+applicationID+"/commands", commands, nil)
+}
+
+// download fetches an attachment from Discord's CDN
+func (c *restClient) download(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,353 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"conduit/internal/channels"
+)
+
+// Gateway opcodes
+const (
+	opDispatch       = 0
+	opHeartbeat      = 1
+	opIdentify       = 2
+	opResume         = 6
+	opReconnect      = 7
+	opInvalidSession = 9
+	opHello          = 10
+	opHeartbeatACK   = 11
+)
+
+// Gateway intents: GUILDS, GUILD_MESSAGES and DIRECT_MESSAGES, plus
+// MESSAGE_CONTENT when the bot reads every guild message. MESSAGE_CONTENT is
+// privileged; without it guild messages only carry content when they mention
+// the bot, which is all mention-only mode needs.
+const (
+	defaultIntents        = 1<<0 | 1<<9 | 1<<12
+	messageContentIntent  = 1 << 15
+	closeDisallowedIntent = 4014
+)
+
+// intents returns the gateway intents the adapter's configuration needs
+func (a *Adapter) intents() int {
+	if a.config.RequireMention {
+		return defaultIntents
+	}
+	return defaultIntents | messageContentIntent
+}
+
+// Reconnect backoff bounds
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 2 * time.Minute
+)
+
+// payload is a gateway websocket frame
+type payload struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d,omitempty"`
+	S  *int64          `json:"s,omitempty"`
+	T  string          `json:"t,omitempty"`
+}
+
+// readyEvent is the READY dispatch sent after identifying
+type readyEvent struct {
+	SessionID        string `json:"session_id"`
+	ResumeGatewayURL string `json:"resume_gateway_url"`
+	User             user   `json:"user"`
+	Application      struct {
+		ID string `json:"id"`
+	} `json:"application"`
+}
+
+// errFatalClose marks a gateway close code that reconnecting cannot fix
+var errFatalClose = errors.New("discord gateway closed the connection permanently")
+
+// gatewayConn is one websocket connection to the Discord gateway
+type gatewayConn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+}
+
+func (c *gatewayConn) send(op int, d interface{}) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteJSON(payload{Op: op, D: data})
+}
+
+// runGateway keeps a gateway connection open until ctx is cancelled,
+// resuming or re-identifying with exponential backoff after disconnects
+func (a *Adapter) runGateway(ctx context.Context) {
+	delay := minReconnectDelay
+	for {
+		connectedAt := time.Now()
+		err := a.connectGateway(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if errors.Is(err, errFatalClose) {
+			log.Printf("[Discord] Gateway connection failed permanently: %v", err)
+			a.setStatus(channels.StatusError, err.Error())
+			return
+		}
+
+		// A connection that stayed up for a while starts the backoff over
+		if time.Since(connectedAt) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+		log.Printf("[Discord] Gateway disconnected: %v (reconnecting in %v)", err, delay)
+		a.setStatus(channels.StatusReconnecting, fmt.Sprintf("Gateway disconnected: %v", err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// connectGateway runs a single gateway connection until it drops
+func (a *Adapter) connectGateway(ctx context.Context) error {
+	a.mutex.RLock()
+	url, resuming := a.resumeURL, a.sessionID != ""
+	a.mutex.RUnlock()
+
+	if !resuming || url == "" {
+		var err error
+		if url, err = a.api.gatewayURL(ctx); err != nil {
+			return fmt.Errorf("failed to get gateway URL: %w", err)
+		}
+		resuming = false
+	}
+
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, gatewayQuery(url), nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to gateway: %w", err)
+	}
+	conn := &gatewayConn{ws: ws}
+	defer ws.Close()
+
+	// Unblock reads when the adapter is stopped
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+
+	var hello payload
+	if err := ws.ReadJSON(&hello); err != nil {
+		return fmt.Errorf("failed to read hello: %w", err)
+	}
+	if hello.Op != opHello {
+		return fmt.Errorf("expected hello, got opcode %d", hello.Op)
+	}
+	var helloData struct {
+		HeartbeatInterval int64 `json:"heartbeat_interval"`
+	}
+	if err := json.Unmarshal(hello.D, &helloData); err != nil || helloData.HeartbeatInterval <= 0 {
+		return fmt.Errorf("invalid hello payload")
+	}
+
+	if resuming {
+		a.mutex.RLock()
+		resume := map[string]interface{}{"token": a.config.BotToken, "session_id": a.sessionID, "seq": a.seq}
+		a.mutex.RUnlock()
+		err = conn.send(opResume, resume)
+	} else {
+		err = conn.send(opIdentify, map[string]interface{}{
+			"token":   a.config.BotToken,
+			"intents": a.intents(),
+			"properties": map[string]string{
+				"os":      runtime.GOOS,
+				"browser": "conduit",
+				"device":  "conduit",
+			},
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to identify: %w", err)
+	}
+
+	acked := make(chan struct{}, 1)
+	go a.heartbeat(conn, time.Duration(helloData.HeartbeatInterval)*time.Millisecond, acked, done)
+
+	for {
+		var p payload
+		if err := ws.ReadJSON(&p); err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) && closeErr.Code == closeDisallowedIntent {
+				return fmt.Errorf("%w: %d %s: enable the Message Content intent for the bot in the Discord Developer Portal, or set require_mention to true",
+					errFatalClose, closeErr.Code, closeErr.Text)
+			}
+			if errors.As(err, &closeErr) && isFatalCloseCode(closeErr.Code) {
+				return fmt.Errorf("%w: %d %s", errFatalClose, closeErr.Code, closeErr.Text)
+			}
+			return err
+		}
+
+		switch p.Op {
+		case opDispatch:
+			if p.S != nil {
+				a.mutex.Lock()
+				a.seq = *p.S
+				a.mutex.Unlock()
+			}
+			a.handleDispatch(ctx, p.T, p.D)
+
+		case opHeartbeat:
+			a.mutex.RLock()
+			seq := a.seq
+			a.mutex.RUnlock()
+			if err := conn.send(opHeartbeat, seq); err != nil {
+				return err
+			}
+
+		case opHeartbeatACK:
+			select {
+			case acked <- struct{}{}:
+			default:
+			}
+
+		case opReconnect:
+			return fmt.Errorf("gateway requested reconnect")
+
+		case opInvalidSession:
+			var resumable bool
+			json.Unmarshal(p.D, &resumable)
+			if !resumable {
+				a.mutex.Lock()
+				a.sessionID, a.resumeURL, a.seq = "", "", 0
+				a.mutex.Unlock()
+			}
+			return fmt.Errorf("gateway invalidated the session")
+		}
+	}
+}
+
+// heartbeat sends heartbeats at the interval Discord asked for. If the
+// previous heartbeat was never acknowledged the connection is a zombie and is
+// closed so runGateway can resume it.
+func (a *Adapter) heartbeat(conn *gatewayConn, interval time.Duration, acked <-chan struct{}, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	waiting := false
+	for {
+		select {
+		case <-done:
+			return
+		case <-acked:
+			waiting = false
+		case <-ticker.C:
+			if waiting {
+				log.Printf("[Discord] Heartbeat not acknowledged, reconnecting")
+				conn.ws.Close()
+				return
+			}
+			a.mutex.RLock()
+			seq := a.seq
+			a.mutex.RUnlock()
+			if err := conn.send(opHeartbeat, seq); err != nil {
+				return
+			}
+			waiting = true
+		}
+	}
+}
+
+// handleDispatch routes a gateway event
+func (a *Adapter) handleDispatch(ctx context.Context, event string, data json.RawMessage) {
+	switch event {
+	case "READY":
+		var ready readyEvent
+		if err := json.Unmarshal(data, &ready); err != nil {
+			log.Printf("[Discord] Invalid READY event: %v", err)
+			return
+		}
+		a.mutex.Lock()
+		a.sessionID = ready.SessionID
+		a.resumeURL = ready.ResumeGatewayURL
+		a.botUser = ready.User
+		if a.config.ApplicationID == "" {
+			a.config.ApplicationID = ready.Application.ID
+		}
+		register := a.config.RegisterCommands && !a.commandsRegistered
+		a.commandsRegistered = a.commandsRegistered || register
+		a.mutex.Unlock()
+
+		a.setStatus(channels.StatusOnline, "Connected to Discord gateway")
+		log.Printf("[Discord] Connected as %s", ready.User.Username)
+		if register {
+			go a.registerCommands(ctx)
+		}
+
+	case "RESUMED":
+		a.setStatus(channels.StatusOnline, "Connected to Discord gateway")
+		log.Printf("[Discord] Gateway session resumed")
+
+	case "MESSAGE_CREATE":
+		var m message
+		if err := json.Unmarshal(data, &m); err != nil {
+			log.Printf("[Discord] Invalid MESSAGE_CREATE event: %v", err)
+			return
+		}
+		// Downloads would stall the read loop and miss heartbeat ACKs
+		if len(m.Attachments) > 0 {
+			go a.handleMessage(ctx, &m)
+		} else {
+			a.handleMessage(ctx, &m)
+		}
+
+	case "INTERACTION_CREATE":
+		var i interaction
+		if err := json.Unmarshal(data, &i); err != nil {
+			log.Printf("[Discord] Invalid INTERACTION_CREATE event: %v", err)
+			return
+		}
+		// Interactions must be answered within three seconds
+		go a.handleInteraction(ctx, &i)
+	}
+}
+
+// gatewayQuery adds the API version and encoding to a gateway URL
+func gatewayQuery(url string) string {
+	url = strings.TrimSuffix(url, "/")
+	if i := strings.Index(url, "?"); i >= 0 {
+		url = url[:i]
+	}
+	return url + "/?v=10&encoding=json"
+}
+
+// isFatalCloseCode reports close codes Discord documents as not reconnectable:
+// authentication failed, invalid shard, sharding required, invalid API
+// version, invalid intents and disallowed intents
+func isFatalCloseCode(code int) bool {
+	switch code {
+	case 4004, 4010, 4011, 4012, 4013, 4014:
+		return true
+	}
+	return false
+}
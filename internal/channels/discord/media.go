@@ -0,0 +1,93 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// attachmentLabel is the placeholder text for a message with only attachments
+func attachmentLabel(attachments []attachment) string {
+	names := make([]string, len(attachments))
+	for i, att := range attachments {
+		names[i] = att.Filename
+	}
+	return fmt.Sprintf("[Attachment: %s]", strings.Join(names, ", "))
+}
+
+// downloadAttachments stores a message's attachments in the session's media
+// directory. Failures are recorded in metadata so the message can still be
+// forwarded; failed reports whether any download failed.
+func (a *Adapter) downloadAttachments(ctx context.Context, sessionKey string, m *message, metadata map[string]string) (attachments []protocol.Attachment, failed bool) {
+	var errs []string
+	for _, att := range m.Attachments {
+		attachment, err := a.downloadAttachment(ctx, sessionKey, m.ID, att)
+		if err != nil {
+			log.Printf("[Discord] Failed to download attachment from channel %s: %v", m.ChannelID, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", att.Filename, err))
+			continue
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	if len(errs) > 0 {
+		metadata["attachment_error"] = strings.Join(errs, "; ")
+	}
+	return attachments, len(errs) > 0
+}
+
+// downloadAttachment fetches one attachment from Discord's CDN and stores it
+func (a *Adapter) downloadAttachment(ctx context.Context, sessionKey, messageID string, att attachment) (*protocol.Attachment, error) {
+	if a.config.MediaDir == "" {
+		return nil, fmt.Errorf("media directory not configured")
+	}
+
+	maxBytes := int64(channels.DefaultMaxAttachmentBytes)
+	if a.config.MaxDownloadMB > 0 {
+		maxBytes = int64(a.config.MaxDownloadMB) * 1024 * 1024
+	}
+	if att.Size > maxBytes {
+		return nil, fmt.Errorf("file is %d bytes, limit is %d", att.Size, maxBytes)
+	}
+
+	resp, err := a.api.download(ctx, att.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	path, size, sniffed, err := channels.SaveAttachment(a.config.MediaDir, sessionKey, messageID+"_"+att.Filename, resp.Body, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Discord appends "; charset=..." to text types
+	mediaType := strings.TrimSpace(strings.SplitN(att.ContentType, ";", 2)[0])
+	if mediaType == "" {
+		mediaType = sniffed
+	}
+
+	return &protocol.Attachment{
+		Type:      attachmentType(mediaType),
+		Path:      path,
+		MediaType: mediaType,
+		Filename:  filepath.Base(path),
+		Size:      size,
+	}, nil
+}
+
+// attachmentType classifies an attachment by media type
+func attachmentType(mediaType string) string {
+	switch {
+	case mediaType == "image/jpeg", mediaType == "image/png", mediaType == "image/gif", mediaType == "image/webp":
+		return protocol.AttachmentImage
+	case strings.HasPrefix(mediaType, "audio/"):
+		return protocol.AttachmentAudio
+	}
+	return protocol.AttachmentDocument
+}
@@ -0,0 +1,616 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// Defaults for optional settings
+const (
+	defaultPollInterval = time.Minute
+	defaultMailbox      = "INBOX"
+	defaultSubject      = "Message from Conduit"
+
+	// threadTTL is how long an idle thread is remembered for replies
+	threadTTL = 30 * 24 * time.Hour
+)
+
+// Adapter implements the ChannelAdapter interface for email. Incoming mail
+// is polled from IMAP or a local Maildir; replies go out over SMTP. Each
+// email thread (by Message-ID/References) is its own session.
+type Adapter struct {
+	id        string
+	name      string
+	config    EmailConfig
+	mailbox   mailbox
+	status    channels.StatusCode
+	statusMsg string
+	incoming  chan *protocol.IncomingMessage
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{} // Closed when the poll loop exits
+	mutex     sync.RWMutex
+	startTime time.Time
+	msgCount  int64
+
+	lastPoll time.Time
+	sent     int64
+	rejected int64
+
+	// Thread state by session key, used to thread replies
+	threads map[string]*thread
+}
+
+// thread is what a reply needs to stay in its email thread
+type thread struct {
+	subject    string
+	references []string // Message IDs in the thread, oldest first
+	lastActive time.Time
+}
+
+// EmailConfig contains email adapter configuration
+type EmailConfig struct {
+	// Address is the mailbox's own address, used as the From address
+	Address  string `json:"address"`
+	FromName string `json:"from_name"`
+
+	// Incoming mail: either an IMAP server or a local Maildir
+	IMAPHost     string        `json:"imap_host"` // host:port
+	IMAPUsername string        `json:"imap_username"`
+	IMAPPassword string        `json:"imap_password"`
+	IMAPTLS      bool          `json:"imap_tls"`
+	Mailbox      string        `json:"mailbox"`
+	Maildir      string        `json:"maildir"`
+	PollInterval time.Duration `json:"-"` // poll_interval_seconds
+
+	// Outgoing mail. SMTPTLS is "starttls" (default), "tls" or "none".
+	SMTPHost     string `json:"smtp_host"` // host:port
+	SMTPUsername string `json:"smtp_username"`
+	SMTPPassword string `json:"smtp_password"`
+	SMTPTLS      string `json:"smtp_tls"`
+
+	// AllowedSenders lists addresses, "@domain" entries or "*". Mail from
+	// anyone else is marked read and ignored.
+	AllowedSenders []string `json:"allowed_senders"`
+
+	// Subject is used for messages that don't reply to a thread
+	Subject string `json:"subject"`
+
+	// MediaDir is where attachments are stored, one subdirectory per
+	// session. Attachments are ignored if empty.
+	MediaDir      string `json:"media_dir"`
+	MaxDownloadMB int    `json:"max_download_mb"`
+}
+
+// Factory creates email channel adapters
+type Factory struct{}
+
+// NewFactory creates a new email adapter factory
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// SupportsType returns whether this factory supports the given adapter type
+func (f *Factory) SupportsType(adapterType string) bool {
+	return adapterType == "email"
+}
+
+// GetSupportedTypes returns the adapter types this factory supports
+func (f *Factory) GetSupportedTypes() []string {
+	return []string{"email"}
+}
+
+// CreateAdapter creates a new email adapter instance
+func (f *Factory) CreateAdapter(config channels.ChannelConfig) (channels.ChannelAdapter, error) {
+	emailConfig := EmailConfig{
+		IMAPTLS:      true,
+		Mailbox:      defaultMailbox,
+		PollInterval: defaultPollInterval,
+		SMTPTLS:      "starttls",
+		Subject:      defaultSubject,
+	}
+
+	if address, ok := config.Config["address"].(string); ok && address != "" {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address for email adapter: %w", err)
+		}
+		emailConfig.Address = strings.ToLower(parsed.Address)
+	} else {
+		return nil, fmt.Errorf("address is required for email adapter")
+	}
+
+	if fromName, ok := config.Config["from_name"].(string); ok {
+		emailConfig.FromName = fromName
+	}
+
+	for key, dest := range map[string]*string{
+		"imap_host":     &emailConfig.IMAPHost,
+		"imap_username": &emailConfig.IMAPUsername,
+		"imap_password": &emailConfig.IMAPPassword,
+		"mailbox":       &emailConfig.Mailbox,
+		"maildir":       &emailConfig.Maildir,
+		"smtp_host":     &emailConfig.SMTPHost,
+		"smtp_username": &emailConfig.SMTPUsername,
+		"smtp_password": &emailConfig.SMTPPassword,
+		"smtp_tls":      &emailConfig.SMTPTLS,
+		"subject":       &emailConfig.Subject,
+	} {
+		if v, ok := config.Config[key].(string); ok && v != "" {
+			*dest = v
+		}
+	}
+
+	if imapTLS, ok := config.Config["imap_tls"].(bool); ok {
+		emailConfig.IMAPTLS = imapTLS
+	}
+	if interval, ok := config.Config["poll_interval_seconds"].(float64); ok && interval > 0 {
+		emailConfig.PollInterval = time.Duration(interval * float64(time.Second))
+	}
+
+	var source mailbox
+	switch {
+	case emailConfig.IMAPHost != "" && emailConfig.Maildir != "":
+		return nil, fmt.Errorf("email adapter takes either imap_host or maildir, not both")
+	case emailConfig.IMAPHost != "":
+		if emailConfig.IMAPUsername == "" {
+			emailConfig.IMAPUsername = emailConfig.Address
+		}
+		source = &imapMailbox{
+			addr:     emailConfig.IMAPHost,
+			username: emailConfig.IMAPUsername,
+			password: emailConfig.IMAPPassword,
+			useTLS:   emailConfig.IMAPTLS,
+			mailbox:  emailConfig.Mailbox,
+		}
+	case emailConfig.Maildir != "":
+		source = &maildirMailbox{dir: emailConfig.Maildir}
+	default:
+		return nil, fmt.Errorf("imap_host or maildir is required for email adapter")
+	}
+
+	if emailConfig.SMTPHost == "" {
+		return nil, fmt.Errorf("smtp_host is required for email adapter")
+	}
+	switch emailConfig.SMTPTLS {
+	case "starttls", "tls", "none":
+	default:
+		return nil, fmt.Errorf("smtp_tls must be starttls, tls or none")
+	}
+
+	// An open inbox would let anyone on the internet talk to the agent, so
+	// senders must be listed explicitly ("*" to opt out)
+	emailConfig.AllowedSenders = channels.StringList(config.Config["allowed_senders"])
+	if len(emailConfig.AllowedSenders) == 0 {
+		return nil, fmt.Errorf("allowed_senders is required for email adapter")
+	}
+
+	emailConfig.MediaDir = config.MediaDir
+	if mediaDir, ok := config.Config["media_dir"].(string); ok && mediaDir != "" {
+		emailConfig.MediaDir = mediaDir
+	}
+	if maxMB, ok := config.Config["max_download_mb"].(float64); ok {
+		emailConfig.MaxDownloadMB = int(maxMB)
+	}
+
+	return &Adapter{
+		id:       config.ID,
+		name:     config.Name,
+		config:   emailConfig,
+		mailbox:  source,
+		status:   channels.StatusInitializing,
+		incoming: make(chan *protocol.IncomingMessage, 100),
+		threads:  make(map[string]*thread),
+	}, nil
+}
+
+// ID returns the adapter's unique identifier
+func (a *Adapter) ID() string {
+	return a.id
+}
+
+// Name returns the adapter's human-readable name
+func (a *Adapter) Name() string {
+	return a.name
+}
+
+// Type returns the adapter type
+func (a *Adapter) Type() string {
+	return "email"
+}
+
+// Start begins polling for new mail
+func (a *Adapter) Start(ctx context.Context) error {
+	a.mutex.Lock()
+	a.ctx, a.cancel = context.WithCancel(ctx)
+	a.done = make(chan struct{})
+	a.startTime = time.Now()
+	a.status = channels.StatusInitializing
+	a.statusMsg = "Waiting for first poll"
+	a.mutex.Unlock()
+
+	go a.pollLoop(a.ctx)
+
+	log.Printf("[Email] Adapter started: %s (%s)", a.Name(), a.config.Address)
+	return nil
+}
+
+// Stop stops polling
+func (a *Adapter) Stop() error {
+	a.mutex.Lock()
+	if a.cancel != nil {
+		a.cancel()
+	}
+	done := a.done
+	a.mutex.Unlock()
+
+	// Let an in-flight poll finish before closing incoming
+	if done != nil {
+		<-done
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.status = channels.StatusOffline
+	a.statusMsg = "Adapter stopped"
+
+	// Close incoming message channel
+	close(a.incoming)
+
+	log.Printf("[Email] Adapter stopped: %s", a.Name())
+	return nil
+}
+
+// pollLoop polls the mailbox until ctx is cancelled. Failed polls are
+// reported in the status and retried on the next tick.
+func (a *Adapter) pollLoop(ctx context.Context) {
+	defer close(a.done)
+
+	ticker := time.NewTicker(a.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := a.mailbox.poll(ctx, a.handleMessage)
+		if ctx.Err() != nil {
+			return
+		}
+
+		a.mutex.Lock()
+		if err != nil {
+			log.Printf("[Email] %s: poll failed: %v", a.id, err)
+			a.status = channels.StatusError
+			a.statusMsg = fmt.Sprintf("Poll failed: %v", err)
+		} else {
+			a.status = channels.StatusOnline
+			a.statusMsg = "Polling for mail"
+			a.lastPoll = time.Now()
+		}
+		a.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleMessage forwards one raw message. Returning nil marks it read, so
+// mail that will never be forwarded (rejected, unparseable) returns nil too.
+func (a *Adapter) handleMessage(raw []byte) error {
+	msg, err := parseMessage(raw)
+	if err != nil {
+		log.Printf("[Email] %s: ignoring unparseable message: %v", a.id, err)
+		return nil
+	}
+
+	sender := msg.from.Address
+	switch {
+	case sender == a.config.Address:
+		// Our own mail, e.g. copies of replies
+		return nil
+	case msg.autoGenerated:
+		log.Printf("[Email] %s: ignoring automatic message from %s", a.id, sender)
+		return nil
+	case !a.config.senderAllowed(sender):
+		log.Printf("[Email] %s: ignoring message from %s (not allowed)", a.id, sender)
+		a.mutex.Lock()
+		a.rejected++
+		a.mutex.Unlock()
+		return nil
+	}
+
+	if msg.messageID == "" {
+		msg.messageID = a.newMessageID()
+	}
+	root := msg.threadRoot()
+	sessionKey := sessionKey(sender, root)
+
+	text := msg.text
+	// The subject of a new thread is usually part of the request
+	if root == msg.messageID && msg.subject != "" {
+		if text == "" {
+			text = msg.subject
+		} else {
+			text = fmt.Sprintf("Subject: %s\n\n%s", msg.subject, text)
+		}
+	}
+
+	metadata := map[string]string{
+		"message_id": msg.messageID,
+		"subject":    msg.subject,
+		"thread_id":  root,
+	}
+	if msg.from.Name != "" {
+		metadata["from_name"] = msg.from.Name
+	}
+
+	attachments := a.saveAttachments(sessionKey, msg.attachments, metadata)
+	if len(attachments) > 0 {
+		metadata["type"] = "attachment"
+	}
+	if text == "" && len(msg.attachments) > 0 {
+		names := make([]string, len(msg.attachments))
+		for i, att := range msg.attachments {
+			names[i] = att.filename
+		}
+		text = fmt.Sprintf("[Attachment: %s]", strings.Join(names, ", "))
+	}
+	if text == "" {
+		return nil
+	}
+
+	incoming := &protocol.IncomingMessage{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeIncomingMessage,
+			ID:        a.generateMessageID(),
+			Timestamp: time.Now(),
+		},
+		ChannelID:   a.id,
+		SessionKey:  sessionKey,
+		UserID:      sender,
+		Text:        text,
+		Attachments: attachments,
+		Metadata:    metadata,
+	}
+
+	if err := a.forward(incoming); err != nil {
+		return err
+	}
+	a.rememberThread(sessionKey, msg)
+	return nil
+}
+
+// forward queues a message without blocking. A full queue leaves the mail
+// unread so the next poll picks it up again.
+func (a *Adapter) forward(msg *protocol.IncomingMessage) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	err := channels.Forward(a.ctx, a.incoming, msg)
+	switch err {
+	case nil:
+		a.msgCount++
+		// Privacy-safe logging - no message content
+		log.Printf("[Email] %s: received message for session %s (%d chars)", a.id, msg.SessionKey, len(msg.Text))
+	case channels.ErrQueueFull:
+		log.Printf("[Email] %s: incoming message channel is full, leaving message unread", a.id)
+	}
+	return err
+}
+
+// rememberThread records a message so replies join its thread
+func (a *Adapter) rememberThread(sessionKey string, msg *parsedMessage) {
+	refs := msg.references
+	if len(refs) == 0 && msg.inReplyTo != "" {
+		refs = []string{msg.inReplyTo}
+	}
+	refs = append(append([]string(nil), refs...), msg.messageID)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := time.Now()
+	for key, t := range a.threads {
+		if now.Sub(t.lastActive) > threadTTL {
+			delete(a.threads, key)
+		}
+	}
+
+	t, ok := a.threads[sessionKey]
+	if !ok {
+		t = &thread{subject: msg.subject}
+		a.threads[sessionKey] = t
+	}
+	if len(refs) > len(t.references) {
+		t.references = refs
+	} else {
+		t.references = append(t.references, msg.messageID)
+	}
+	t.lastActive = now
+}
+
+// saveAttachments stores attachments in the session's media directory.
+// Failures are recorded in metadata so the message can still be forwarded.
+func (a *Adapter) saveAttachments(sessionKey string, atts []mailAttachment, metadata map[string]string) []protocol.Attachment {
+	if len(atts) == 0 {
+		return nil
+	}
+
+	maxBytes := int64(channels.DefaultMaxAttachmentBytes)
+	if a.config.MaxDownloadMB > 0 {
+		maxBytes = int64(a.config.MaxDownloadMB) * 1024 * 1024
+	}
+
+	var attachments []protocol.Attachment
+	var errs []string
+	for _, att := range atts {
+		if int64(len(att.data)) > maxBytes {
+			errs = append(errs, fmt.Sprintf("%s: file is %d bytes, limit is %d", att.filename, len(att.data), maxBytes))
+			continue
+		}
+		path, size, sniffed, err := channels.SaveAttachment(a.config.MediaDir, sessionKey, att.filename, bytes.NewReader(att.data), maxBytes)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", att.filename, err))
+			continue
+		}
+
+		mediaType := att.contentType
+		if mediaType == "" || mediaType == "application/octet-stream" {
+			mediaType = sniffed
+		}
+		attachments = append(attachments, protocol.Attachment{
+			Type:      attachmentType(mediaType),
+			Path:      path,
+			MediaType: mediaType,
+			Filename:  filepath.Base(path),
+			Size:      size,
+		})
+	}
+
+	if len(errs) > 0 {
+		log.Printf("[Email] %s: failed to save attachments: %s", a.id, strings.Join(errs, "; "))
+		metadata["attachment_error"] = strings.Join(errs, "; ")
+	}
+	return attachments
+}
+
+// attachmentType classifies an attachment by media type
+func attachmentType(mediaType string) string {
+	switch {
+	case mediaType == "image/jpeg", mediaType == "image/png", mediaType == "image/gif", mediaType == "image/webp":
+		return protocol.AttachmentImage
+	case strings.HasPrefix(mediaType, "audio/"):
+		return protocol.AttachmentAudio
+	}
+	return protocol.AttachmentDocument
+}
+
+// SendMessage sends an email to msg.UserID. Replies to a known session stay
+// in its thread; otherwise a new thread starts with metadata["subject"] or
+// the configured subject. MediaPath or image_path metadata are attached.
+func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
+	to, err := mail.ParseAddress(msg.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.UserID, err)
+	}
+
+	out := &outgoingMail{
+		from:       mail.Address{Name: a.config.FromName, Address: a.config.Address},
+		to:         to.Address,
+		subject:    a.config.Subject,
+		messageID:  a.newMessageID(),
+		text:       channels.SanitizeUserFacingText(msg.Text),
+		attachment: msg.MediaPath,
+	}
+	if imagePath, ok := msg.Metadata["image_path"]; ok && imagePath != "" {
+		out.attachment = imagePath
+	}
+	if subject := msg.Metadata["subject"]; subject != "" {
+		out.subject = subject
+	}
+
+	a.mutex.RLock()
+	if t, ok := a.threads[msg.SessionKey]; ok && msg.SessionKey != "" {
+		out.subject = replySubject(t.subject)
+		out.references = append([]string(nil), t.references...)
+		out.inReplyTo = t.references[len(t.references)-1]
+	}
+	a.mutex.RUnlock()
+
+	data, err := out.bytes()
+	if err != nil {
+		return err
+	}
+	if err := a.sendMail(out.to, data); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.sent++
+	if t, ok := a.threads[msg.SessionKey]; ok && msg.SessionKey != "" {
+		t.references = append(t.references, out.messageID)
+	}
+	a.mutex.Unlock()
+	return nil
+}
+
+// ReceiveMessages returns the channel for incoming messages
+func (a *Adapter) ReceiveMessages() <-chan *protocol.IncomingMessage {
+	return a.incoming
+}
+
+// Status returns the current adapter status
+func (a *Adapter) Status() channels.ChannelStatus {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	details := map[string]interface{}{
+		"uptime_seconds": time.Since(a.startTime).Seconds(),
+		"message_count":  a.msgCount,
+		"sent":           a.sent,
+		"rejected":       a.rejected,
+		"address":        a.config.Address,
+	}
+	if !a.lastPoll.IsZero() {
+		details["last_poll"] = a.lastPoll
+	}
+
+	return channels.ChannelStatus{
+		Status:    a.status,
+		Message:   a.statusMsg,
+		Details:   details,
+		Timestamp: time.Now(),
+	}
+}
+
+// IsHealthy returns whether the last poll succeeded
+func (a *Adapter) IsHealthy() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.status == channels.StatusOnline
+}
+
+// senderAllowed checks an address against allowed_senders
+func (c *EmailConfig) senderAllowed(address string) bool {
+	address = strings.ToLower(address)
+	for _, entry := range c.AllowedSenders {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "*":
+			return true
+		case strings.HasPrefix(entry, "@"):
+			if strings.HasSuffix(address, entry) {
+				return true
+			}
+		case entry == address:
+			return true
+		}
+	}
+	return false
+}
+
+// newMessageID creates an RFC 5322 Message-ID in our own domain
+func (a *Adapter) newMessageID() string {
+	domain := a.config.Address[strings.LastIndexByte(a.config.Address, '@')+1:]
+	return fmt.Sprintf("<%s@%s>", uuid.New().String(), domain)
+}
+
+// generateMessageID creates a unique message ID
+func (a *Adapter) generateMessageID() string {
+	return fmt.Sprintf("email_%s_%s", a.id, uuid.New().String()[:8])
+}
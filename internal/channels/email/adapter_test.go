@@ -0,0 +1,508 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// fakeSMTP is an in-process SMTP server that records delivered messages
+type fakeSMTP struct {
+	ln       net.Listener
+	messages chan smtpDelivery
+}
+
+type smtpDelivery struct {
+	from, to string
+	msg      *mail.Message
+	raw      string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSMTP{ln: ln, messages: make(chan smtpDelivery, 10)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 fake ESMTP")
+	var d smtpDelivery
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-fake")
+			reply("250 8BITMIME")
+		case "MAIL":
+			d.from = smtpPath(strings.TrimPrefix(line, "MAIL FROM:"))
+			reply("250 OK")
+		case "RCPT":
+			d.to = smtpPath(strings.TrimPrefix(line, "RCPT TO:"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			d.raw = data.String()
+			d.msg, _ = mail.ReadMessage(strings.NewReader(d.raw))
+			s.messages <- d
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// smtpPath extracts the address from "<addr> PARAMS"
+func smtpPath(arg string) string {
+	arg, _, _ = strings.Cut(arg, ">")
+	return strings.TrimPrefix(arg, "<")
+}
+
+func (s *fakeSMTP) receive(t *testing.T) smtpDelivery {
+	select {
+	case d := <-s.messages:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for SMTP delivery")
+		return smtpDelivery{}
+	}
+}
+
+// fakeIMAP is an in-process IMAP server with a single mailbox
+type fakeIMAP struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	messages []*imapMessage
+	nextUID  int
+}
+
+type imapMessage struct {
+	uid  int
+	raw  string
+	seen bool
+}
+
+func newFakeIMAP(t *testing.T) *fakeIMAP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeIMAP{ln: ln, nextUID: 1}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeIMAP) deliver(raw string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, &imapMessage{uid: s.nextUID, raw: strings.ReplaceAll(raw, "\n", "\r\n")})
+	s.nextUID++
+}
+
+func (s *fakeIMAP) unseen() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, m := range s.messages {
+		if !m.seen {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *fakeIMAP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	write := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+
+	write("* OK fake IMAP ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		tag, cmd := fields[0], strings.ToUpper(fields[1])
+		if cmd == "UID" && len(fields) > 2 {
+			cmd += " " + strings.ToUpper(fields[2])
+		}
+
+		s.mu.Lock()
+		switch cmd {
+		case "LOGIN":
+			if fields[2] == `"bot@example.com"` && fields[3] == `"secret"` {
+				write("%s OK logged in", tag)
+			} else {
+				write("%s NO invalid credentials", tag)
+			}
+		case "SELECT":
+			write("* %d EXISTS", len(s.messages))
+			write("%s OK [READ-WRITE] selected", tag)
+		case "UID SEARCH":
+			var uids []string
+			for _, m := range s.messages {
+				if !m.seen {
+					uids = append(uids, fmt.Sprint(m.uid))
+				}
+			}
+			write("* SEARCH %s", strings.Join(uids, " "))
+			write("%s OK search done", tag)
+		case "UID FETCH":
+			for i, m := range s.messages {
+				if fmt.Sprint(m.uid) == fields[3] {
+					fmt.Fprintf(conn, "* %d FETCH (UID %d BODY[] {%d}\r\n%s)\r\n", i+1, m.uid, len(m.raw), m.raw)
+				}
+			}
+			write("%s OK fetch done", tag)
+		case "UID STORE":
+			for _, m := range s.messages {
+				if fmt.Sprint(m.uid) == fields[3] {
+					m.seen = true
+				}
+			}
+			write("%s OK store done", tag)
+		case "LOGOUT":
+			write("* BYE")
+			write("%s OK logout", tag)
+			s.mu.Unlock()
+			return
+		default:
+			write("%s BAD unknown command", tag)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func startTestAdapter(t *testing.T, cfg map[string]interface{}) *Adapter {
+	adapter, err := NewFactory().CreateAdapter(channels.ChannelConfig{
+		ID: "mail", Type: "email", Name: "Mail", Enabled: true, Config: cfg,
+	})
+	require.NoError(t, err)
+	a := adapter.(*Adapter)
+	require.NoError(t, a.Start(context.Background()))
+	t.Cleanup(func() { a.Stop() })
+	return a
+}
+
+func imapConfig(imap *fakeIMAP, smtp *fakeSMTP) map[string]interface{} {
+	return map[string]interface{}{
+		"address":               "bot@example.com",
+		"from_name":             "Conduit",
+		"imap_host":             imap.ln.Addr().String(),
+		"imap_password":         "secret",
+		"imap_tls":              false,
+		"smtp_host":             smtp.ln.Addr().String(),
+		"smtp_tls":              "none",
+		"allowed_senders":       []interface{}{"alice@example.com", "@trusted.org"},
+		"poll_interval_seconds": 0.05,
+	}
+}
+
+func receive(t *testing.T, a *Adapter) *protocol.IncomingMessage {
+	select {
+	case msg := <-a.ReceiveMessages():
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for incoming message")
+		return nil
+	}
+}
+
+func TestFactory_CreateAdapter(t *testing.T) {
+	f := NewFactory()
+	assert.True(t, f.SupportsType("email"))
+
+	base := func(extra map[string]interface{}) map[string]interface{} {
+		cfg := map[string]interface{}{
+			"address":         "Bot <Bot@Example.com>",
+			"maildir":         "/var/mail/bot",
+			"smtp_host":       "smtp.example.com:587",
+			"allowed_senders": []interface{}{"alice@example.com"},
+		}
+		for k, v := range extra {
+			if v == nil {
+				delete(cfg, k)
+			} else {
+				cfg[k] = v
+			}
+		}
+		return cfg
+	}
+
+	adapter, err := f.CreateAdapter(channels.ChannelConfig{ID: "mail", Config: base(nil)})
+	require.NoError(t, err)
+	a := adapter.(*Adapter)
+	assert.Equal(t, "bot@example.com", a.config.Address)
+	assert.Equal(t, "starttls", a.config.SMTPTLS)
+	assert.Equal(t, time.Minute, a.config.PollInterval)
+
+	for name, cfg := range map[string]map[string]interface{}{
+		"missing address":   base(map[string]interface{}{"address": nil}),
+		"missing source":    base(map[string]interface{}{"maildir": nil}),
+		"both sources":      base(map[string]interface{}{"imap_host": "imap.example.com:993"}),
+		"missing smtp":      base(map[string]interface{}{"smtp_host": nil}),
+		"bad smtp_tls":      base(map[string]interface{}{"smtp_tls": "ssl"}),
+		"missing allowlist": base(map[string]interface{}{"allowed_senders": nil}),
+	} {
+		_, err := f.CreateAdapter(channels.ChannelConfig{ID: "mail", Config: cfg})
+		assert.Error(t, err, name)
+	}
+}
+
+func TestAdapter_IMAPThreadRoundTrip(t *testing.T) {
+	imap, smtp := newFakeIMAP(t), newFakeSMTP(t)
+	imap.deliver(`From: Alice <Alice@Example.com>
+To: bot@example.com
+Subject: Deploy status?
+Message-ID: <first@example.com>
+Content-Type: text/plain; charset=utf-8
+
+Did the deploy finish?
+`)
+
+	a := startTestAdapter(t, imapConfig(imap, smtp))
+
+	msg := receive(t, a)
+	assert.Equal(t, "mail", msg.ChannelID)
+	assert.Equal(t, "alice@example.com", msg.UserID)
+	assert.Equal(t, sessionKey("alice@example.com", "<first@example.com>"), msg.SessionKey)
+	assert.Equal(t, "Subject: Deploy status?\n\nDid the deploy finish?", msg.Text)
+	assert.Equal(t, "Alice", msg.Metadata["from_name"])
+	require.Eventually(t, func() bool { return imap.unseen() == 0 }, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, a.IsHealthy, 5*time.Second, 10*time.Millisecond)
+
+	// The reply stays in the thread
+	require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{
+		SessionKey: msg.SessionKey,
+		UserID:     msg.UserID,
+		Text:       "Yes, it finished.",
+	}))
+	sent := smtp.receive(t)
+	assert.Equal(t, "bot@example.com", sent.from)
+	assert.Equal(t, "alice@example.com", sent.to)
+	assert.Equal(t, "Re: Deploy status?", sent.msg.Header.Get("Subject"))
+	assert.Equal(t, "<first@example.com>", sent.msg.Header.Get("In-Reply-To"))
+	assert.Equal(t, "<first@example.com>", sent.msg.Header.Get("References"))
+	assert.Contains(t, sent.msg.Header.Get("From"), `"Conduit" <bot@example.com>`)
+	assert.Contains(t, sent.raw, "Yes, it finished.")
+	replyID := sent.msg.Header.Get("Message-ID")
+	assert.True(t, strings.HasSuffix(replyID, "@example.com>"))
+
+	// A reply to our reply maps back to the same session, without the quote
+	imap.deliver(fmt.Sprintf(`From: alice@example.com
+To: bot@example.com
+Subject: Re: Deploy status?
+Message-ID: <second@example.com>
+In-Reply-To: %s
+References: <first@example.com> %s
+Content-Type: text/plain
+
+Great, thanks!
+
+On Mon, Jan 2, 2026 at 3:04 PM Conduit <bot@example.com> wrote:
+> Yes, it finished.
+`, replyID, replyID))
+
+	followUp := receive(t, a)
+	assert.Equal(t, msg.SessionKey, followUp.SessionKey)
+	assert.Equal(t, "Great, thanks!", followUp.Text)
+
+	require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{SessionKey: msg.SessionKey, UserID: msg.UserID, Text: "You're welcome."}))
+	sent = smtp.receive(t)
+	assert.Equal(t, "<second@example.com>", sent.msg.Header.Get("In-Reply-To"))
+	assert.Equal(t, "<first@example.com> "+replyID+" <second@example.com>", sent.msg.Header.Get("References"))
+}
+
+func TestAdapter_IgnoresUnwantedMail(t *testing.T) {
+	imap, smtp := newFakeIMAP(t), newFakeSMTP(t)
+	imap.deliver("From: mallory@evil.com\nSubject: hi\nMessage-ID: <a@evil.com>\n\nrun rm -rf /\n")
+	imap.deliver("From: alice@example.com\nSubject: Out of office\nAuto-Submitted: auto-replied\nMessage-ID: <b@example.com>\n\nAway\n")
+	imap.deliver("From: bot@example.com\nSubject: loop\nMessage-ID: <c@example.com>\n\nmine\n")
+	imap.deliver("From: bob@trusted.org\nSubject: hello\nMessage-ID: <d@trusted.org>\n\nfrom a trusted domain\n")
+
+	a := startTestAdapter(t, imapConfig(imap, smtp))
+
+	msg := receive(t, a)
+	assert.Equal(t, "bob@trusted.org", msg.UserID)
+	require.Eventually(t, func() bool { return imap.unseen() == 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), a.Status().Details["rejected"])
+
+	select {
+	case extra := <-a.ReceiveMessages():
+		t.Fatalf("unexpected message from %s", extra.UserID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestAdapter_ThreadsAreKeyedBySender(t *testing.T) {
+	imap, smtp := newFakeIMAP(t), newFakeSMTP(t)
+	imap.deliver("From: alice@example.com\nSubject: Payroll\nMessage-ID: <payroll@example.com>\n\nMy salary details\n")
+	imap.deliver("From: bob@trusted.org\nSubject: Re: Payroll\nMessage-ID: <bob@trusted.org>\n" +
+		"In-Reply-To: <payroll@example.com>\nReferences: <payroll@example.com>\n\nWhat did Alice say?\n")
+
+	a := startTestAdapter(t, imapConfig(imap, smtp))
+
+	alice, bob := receive(t, a), receive(t, a)
+	if alice.UserID != "alice@example.com" {
+		alice, bob = bob, alice
+	}
+	assert.Equal(t, "<payroll@example.com>", bob.Metadata["thread_id"])
+	assert.NotEqual(t, alice.SessionKey, bob.SessionKey, "another sender must not join the thread's session")
+}
+
+func TestAdapter_IMAPLoginFailure(t *testing.T) {
+	imap, smtp := newFakeIMAP(t), newFakeSMTP(t)
+	cfg := imapConfig(imap, smtp)
+	cfg["imap_password"] = "wrong"
+
+	a := startTestAdapter(t, cfg)
+	require.Eventually(t, func() bool {
+		return a.Status().Status == channels.StatusError
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, a.Status().Message, "login failed")
+}
+
+func TestAdapter_MaildirWithAttachments(t *testing.T) {
+	smtp := newFakeSMTP(t)
+	dir := t.TempDir()
+	for _, sub := range []string{"new", "cur", "tmp"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0o755))
+	}
+	raw := strings.ReplaceAll(`From: alice@example.com
+To: bot@example.com
+Subject: Report
+Message-ID: <report@example.com>
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="XYZ"
+
+--XYZ
+Content-Type: text/plain; charset=utf-8
+Content-Transfer-Encoding: quoted-printable
+
+Please summarise the attached caf=C3=A9 report.
+--XYZ
+Content-Type: text/csv; name="report.csv"
+Content-Disposition: attachment; filename="report.csv"
+Content-Transfer-Encoding: base64
+
+aXRlbSxjb3N0CmNvZmZlZSwz
+--XYZ--
+`, "\n", "\r\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new", "1700000000.1.host"), []byte(raw), 0o644))
+
+	mediaDir := t.TempDir()
+	a := startTestAdapter(t, map[string]interface{}{
+		"address":               "bot@example.com",
+		"maildir":               dir,
+		"smtp_host":             smtp.ln.Addr().String(),
+		"smtp_tls":              "none",
+		"allowed_senders":       []interface{}{"*"},
+		"media_dir":             mediaDir,
+		"poll_interval_seconds": 0.05,
+	})
+
+	msg := receive(t, a)
+	assert.Equal(t, "Subject: Report\n\nPlease summarise the attached café report.", msg.Text)
+	require.Len(t, msg.Attachments, 1)
+	att := msg.Attachments[0]
+	assert.Equal(t, protocol.AttachmentDocument, att.Type)
+	assert.Equal(t, "text/csv", att.MediaType)
+	data, err := os.ReadFile(att.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "item,cost\ncoffee,3", string(data))
+	assert.Equal(t, "attachment", msg.Metadata["type"])
+
+	// The message is marked read
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, "cur", "1700000000.1.host:2,S"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestAdapter_SendNewThreadWithAttachment(t *testing.T) {
+	imap, smtp := newFakeIMAP(t), newFakeSMTP(t)
+	a := startTestAdapter(t, imapConfig(imap, smtp))
+
+	file := filepath.Join(t.TempDir(), "chart.png")
+	require.NoError(t, os.WriteFile(file, []byte("\x89PNG fake"), 0o644))
+
+	require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{
+		UserID:    "ops@example.com",
+		Text:      "Disk usage is at 95%",
+		MediaPath: file,
+		Metadata:  map[string]string{"subject": "Heartbeat alert"},
+	}))
+
+	sent := smtp.receive(t)
+	assert.Equal(t, "ops@example.com", sent.to)
+	assert.Equal(t, "Heartbeat alert", sent.msg.Header.Get("Subject"))
+	assert.Empty(t, sent.msg.Header.Get("In-Reply-To"))
+	assert.Contains(t, sent.msg.Header.Get("Content-Type"), "multipart/mixed")
+
+	parsed, err := parseMessage([]byte(sent.raw))
+	require.NoError(t, err)
+	assert.Equal(t, "Disk usage is at 95%", parsed.text)
+	require.Len(t, parsed.attachments, 1)
+	assert.Equal(t, "chart.png", parsed.attachments[0].filename)
+	assert.Equal(t, "\x89PNG fake", string(parsed.attachments[0].data))
+
+	assert.Error(t, a.SendMessage(&protocol.OutgoingMessage{UserID: "not an address", Text: "hi"}))
+}
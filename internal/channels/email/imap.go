@@ -0,0 +1,223 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits for a single IMAP session
+const (
+	imapSessionTimeout = 2 * time.Minute
+	maxLiteralBytes    = 64 * 1024 * 1024
+	maxFetchPerPoll    = 25
+)
+
+// mailbox is a source of incoming mail
+type mailbox interface {
+	// poll calls handle for each unread message. A message is marked read
+	// only when handle returns nil, so it is retried on the next poll
+	// otherwise.
+	poll(ctx context.Context, handle func(raw []byte) error) error
+}
+
+// imapMailbox polls a mailbox over IMAP4rev1 (RFC 3501). It implements the
+// handful of commands the adapter needs rather than a general client.
+type imapMailbox struct {
+	addr     string
+	username string
+	password string
+	useTLS   bool
+	mailbox  string
+}
+
+func (m *imapMailbox) poll(ctx context.Context, handle func(raw []byte) error) error {
+	c, err := dialIMAP(ctx, m.addr, m.useTLS)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	if _, err := c.command("LOGIN %s %s", imapQuote(m.username), imapQuote(m.password)); err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	if _, err := c.command("SELECT %s", imapQuote(m.mailbox)); err != nil {
+		return fmt.Errorf("failed to select %s: %w", m.mailbox, err)
+	}
+
+	responses, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+	var uids []string
+	for _, resp := range responses {
+		if fields := strings.Fields(resp.line); len(fields) > 2 && fields[1] == "SEARCH" {
+			uids = append(uids, fields[2:]...)
+		}
+	}
+	if len(uids) > maxFetchPerPoll {
+		uids = uids[:maxFetchPerPoll]
+	}
+
+	for _, uid := range uids {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, err := strconv.ParseUint(uid, 10, 32); err != nil {
+			continue
+		}
+
+		// PEEK leaves the message unread until it has been handled
+		responses, err := c.command("UID FETCH %s (BODY.PEEK[])", uid)
+		if err != nil {
+			return fmt.Errorf("fetch failed: %w", err)
+		}
+		var raw []byte
+		for _, resp := range responses {
+			if len(resp.literals) > 0 {
+				raw = resp.literals[0]
+				break
+			}
+		}
+		if raw == nil {
+			continue
+		}
+
+		if err := handle(raw); err != nil {
+			continue
+		}
+		if _, err := c.command("UID STORE %s +FLAGS.SILENT (\\Seen)", uid); err != nil {
+			return fmt.Errorf("failed to mark message read: %w", err)
+		}
+	}
+
+	c.command("LOGOUT")
+	return nil
+}
+
+// imapConn is a connection to an IMAP server
+type imapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// imapResponse is one untagged response line with any literals it carried
+type imapResponse struct {
+	line     string
+	literals [][]byte
+}
+
+func dialIMAP(ctx context.Context, addr string, useTLS bool) (*imapConn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	var err error
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to IMAP server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(imapSessionTimeout))
+
+	c := &imapConn{conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := c.readResponse()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read IMAP greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting.line, "* OK") && !strings.HasPrefix(greeting.line, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected IMAP greeting: %s", greeting.line)
+	}
+	return c, nil
+}
+
+func (c *imapConn) close() error {
+	return c.conn.Close()
+}
+
+// command sends a tagged command and returns the untagged responses once
+// the server completes it
+func (c *imapConn) command(format string, args ...interface{}) ([]imapResponse, error) {
+	c.tag++
+	tag := fmt.Sprintf("A%03d", c.tag)
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, fmt.Sprintf(format, args...)); err != nil {
+		return nil, err
+	}
+
+	var responses []imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if status, ok := strings.CutPrefix(resp.line, tag+" "); ok {
+			if strings.HasPrefix(status, "OK") {
+				return responses, nil
+			}
+			return nil, fmt.Errorf("%s", status)
+		}
+		responses = append(responses, resp)
+	}
+}
+
+// readResponse reads one response, following {n} literals onto the next line
+func (c *imapConn) readResponse() (imapResponse, error) {
+	var resp imapResponse
+	var line strings.Builder
+	for {
+		part, err := c.r.ReadString('\n')
+		if err != nil {
+			return resp, err
+		}
+		part = strings.TrimRight(part, "\r\n")
+		line.WriteString(part)
+
+		n, ok := literalSize(part)
+		if !ok {
+			resp.line = line.String()
+			return resp, nil
+		}
+		if n > maxLiteralBytes {
+			return resp, fmt.Errorf("literal of %d bytes exceeds limit", n)
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, data)
+	}
+}
+
+// literalSize parses a trailing "{n}" literal marker
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	i := strings.LastIndexByte(line, '{')
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(line[i+1 : len(line)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// imapQuote renders s as an IMAP quoted string
+func imapQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,57 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maildirMailbox reads new mail from a local Maildir, e.g. one filled by
+// fetchmail or an MTA. Handled messages move from new/ to cur/ with the
+// Seen flag, as a mail client would.
+type maildirMailbox struct {
+	dir string
+}
+
+func (m *maildirMailbox) poll(ctx context.Context, handle func(raw []byte) error) error {
+	newDir := filepath.Join(m.dir, "new")
+	entries, err := os.ReadDir(newDir)
+	if err != nil {
+		return fmt.Errorf("failed to read maildir: %w", err)
+	}
+
+	// Maildir names start with the delivery time, so this is arrival order
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(newDir, entry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("[Email] Failed to read %s: %v", path, err)
+			continue
+		}
+		if err := handle(raw); err != nil {
+			continue
+		}
+
+		name := entry.Name()
+		if !strings.Contains(name, ":2,") {
+			name += ":2,S"
+		}
+		if err := os.Rename(path, filepath.Join(m.dir, "cur", name)); err != nil {
+			return fmt.Errorf("failed to mark message read: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+package email
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxReferences bounds the References header on replies
+const maxReferences = 10
+
+// parsedMessage is the part of an incoming email the adapter uses
+type parsedMessage struct {
+	messageID     string
+	inReplyTo     string
+	references    []string
+	from          *mail.Address
+	subject       string
+	text          string
+	html          string
+	autoGenerated bool
+	attachments   []mailAttachment
+}
+
+// mailAttachment is a decoded attachment held in memory
+type mailAttachment struct {
+	filename    string
+	contentType string
+	data        []byte
+}
+
+// headerGetter is implemented by mail.Header and textproto.MIMEHeader
+type headerGetter interface {
+	Get(key string) string
+}
+
+var wordDecoder = new(mime.WordDecoder)
+
+// decodeHeader decodes RFC 2047 encoded words, keeping the raw value on error
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// parseMessage parses a raw RFC 5322 message
+func parseMessage(raw []byte) (*parsedMessage, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid From header: %w", err)
+	}
+	from.Address = strings.ToLower(from.Address)
+
+	p := &parsedMessage{
+		messageID:  strings.TrimSpace(msg.Header.Get("Message-ID")),
+		inReplyTo:  firstMessageID(msg.Header.Get("In-Reply-To")),
+		references: messageIDs(msg.Header.Get("References")),
+		from:       from,
+		subject:    decodeHeader(msg.Header.Get("Subject")),
+	}
+
+	// Don't answer auto-replies, bounces or list traffic (RFC 3834)
+	if auto := strings.ToLower(msg.Header.Get("Auto-Submitted")); auto != "" && auto != "no" {
+		p.autoGenerated = true
+	}
+	switch strings.ToLower(msg.Header.Get("Precedence")) {
+	case "bulk", "list", "junk":
+		p.autoGenerated = true
+	}
+
+	if err := p.walk(msg.Header, msg.Body, true); err != nil {
+		return nil, err
+	}
+	if p.text == "" && p.html != "" {
+		p.text = htmlToText(p.html)
+	}
+	p.text = stripQuotedReply(p.text)
+	return p, nil
+}
+
+// walk collects the text body and attachments of a (possibly multipart) part.
+// multipart.Reader decodes quoted-printable parts itself, so only the
+// top-level body needs that done here.
+func (p *parsedMessage) walk(header headerGetter, body io.Reader, topLevel bool) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid multipart body: %w", err)
+			}
+			if err := p.walk(part.Header, part, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: body})
+	case "quoted-printable":
+		if topLevel {
+			body = quotedprintable.NewReader(body)
+		}
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to decode body: %w", err)
+	}
+
+	disposition, dispParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	filename = decodeHeader(filename)
+
+	switch {
+	case disposition == "attachment" || (filename != "" && !strings.HasPrefix(mediaType, "text/")):
+		if filename == "" {
+			filename = "attachment"
+		}
+		p.attachments = append(p.attachments, mailAttachment{filename: filename, contentType: mediaType, data: data})
+	case mediaType == "text/plain" && p.text == "":
+		p.text = string(data)
+	case mediaType == "text/html" && p.html == "":
+		p.html = string(data)
+	}
+	return nil
+}
+
+// newlineStripper drops CR and LF so base64 bodies split across lines decode
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (s *newlineStripper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	j := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' {
+			p[j] = b
+			j++
+		}
+	}
+	return j, err
+}
+
+var msgIDRe = regexp.MustCompile(`<[^<>\s]+>`)
+
+// messageIDs extracts the message IDs from a References-style header
+func messageIDs(value string) []string {
+	return msgIDRe.FindAllString(value, -1)
+}
+
+func firstMessageID(value string) string {
+	if ids := messageIDs(value); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+// threadRoot returns the ID of the first message in the thread
+func (p *parsedMessage) threadRoot() string {
+	switch {
+	case len(p.references) > 0:
+		return p.references[0]
+	case p.inReplyTo != "":
+		return p.inReplyTo
+	}
+	return p.messageID
+}
+
+// sessionKey maps a sender's thread to a session. The sender is part of the
+// key so that pointing In-Reply-To or References at someone else's thread
+// starts a separate session instead of joining theirs.
+func sessionKey(from, root string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(from) + "\x00" + root))
+	return "email_" + hex.EncodeToString(sum[:8])
+}
+
+var (
+	// "On Mon, Jan 2, 2006 at 3:04 PM Someone <a@b.c> wrote:"
+	replyHeaderRe = regexp.MustCompile(`(?m)^On .+ wrote:\s*$`)
+	htmlBreakRe   = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>`)
+	htmlTagRe     = regexp.MustCompile(`<[^>]*>`)
+	blankLinesRe  = regexp.MustCompile(`\n{3,}`)
+)
+
+// stripQuotedReply removes the quoted previous message that mail clients
+// append to replies
+func stripQuotedReply(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if loc := replyHeaderRe.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+	if i := strings.Index(text, "-----Original Message-----"); i >= 0 {
+		text = text[:i]
+	}
+
+	lines := strings.Split(text, "\n")
+	end := len(lines)
+	for end > 0 {
+		line := strings.TrimSpace(lines[end-1])
+		if line != "" && !strings.HasPrefix(line, ">") {
+			break
+		}
+		end--
+	}
+	return strings.TrimSpace(strings.Join(lines[:end], "\n"))
+}
+
+// htmlToText is a rough conversion for HTML-only messages
+func htmlToText(s string) string {
+	s = htmlBreakRe.ReplaceAllString(s, "\n")
+	s = htmlTagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(blankLinesRe.ReplaceAllString(s, "\n\n"))
+}
+
+// replySubject prefixes a subject with "Re:" once
+func replySubject(subject string) string {
+	if strings.HasPrefix(strings.ToLower(subject), "re:") {
+		return subject
+	}
+	return "Re: " + subject
+}
+
+// outgoingMail is a message to send over SMTP
+type outgoingMail struct {
+	from       mail.Address
+	to         string
+	subject    string
+	messageID  string
+	inReplyTo  string
+	references []string
+	text       string
+	attachment string // Local file path, optional
+}
+
+// bytes renders the message as RFC 5322 with a quoted-printable text body
+// and, if set, a base64 attachment
+func (m *outgoingMail) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	header("From", m.from.String())
+	header("To", m.to)
+	header("Subject", mime.QEncoding.Encode("utf-8", m.subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", m.messageID)
+	if m.inReplyTo != "" {
+		header("In-Reply-To", m.inReplyTo)
+	}
+	if len(m.references) > 0 {
+		refs := m.references
+		// Keep the root and the most recent messages
+		if len(refs) > maxReferences {
+			refs = append([]string{refs[0]}, refs[len(refs)-maxReferences+1:]...)
+		}
+		header("References", strings.Join(refs, " "))
+	}
+	header("MIME-Version", "1.0")
+
+	if m.attachment == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, m.text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	data, err := os.ReadFile(m.attachment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", mw.Boundary()))
+	buf.WriteString("\r\n")
+
+	textPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeQuotedPrintable(textPart, m.text); err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(m.attachment)
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	filePart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": name})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		fmt.Fprintf(filePart, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(filePart, "%s\r\n", encoded)
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n"))); err != nil {
+		return err
+	}
+	return qp.Close()
+}
@@ -0,0 +1,70 @@
+package email
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripQuotedReply(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "Hello there\n", "Hello there"},
+		{"gmail style", "Sounds good\r\n\r\nOn Tue, Mar 3, 2026 at 9:00 AM Bot <bot@example.com> wrote:\r\n> earlier\r\n", "Sounds good"},
+		{"outlook style", "Thanks\n\n-----Original Message-----\nFrom: bot\n", "Thanks"},
+		{"trailing quote", "Yes\n\n> Should I?\n>\n", "Yes"},
+		{"inline quote kept", "> point one\nagreed\n", "> point one\nagreed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stripQuotedReply(tt.in))
+		})
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	t.Run("html only", func(t *testing.T) {
+		p, err := parseMessage([]byte("From: a@example.com\r\nContent-Type: text/html\r\n\r\n<p>Hello &amp; welcome</p><p>Line<br>two</p>"))
+		require.NoError(t, err)
+		assert.Equal(t, "Hello & welcome\nLine\ntwo", p.text)
+	})
+
+	t.Run("encoded subject and quoted-printable body", func(t *testing.T) {
+		p, err := parseMessage([]byte("From: a@example.com\r\nSubject: =?utf-8?q?Caf=C3=A9?=\r\n" +
+			"Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\nna=C3=AFve\r\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "Café", p.subject)
+		assert.Equal(t, "naïve", p.text)
+	})
+
+	t.Run("alternative prefers plain text", func(t *testing.T) {
+		p, err := parseMessage([]byte("From: a@example.com\r\nContent-Type: multipart/alternative; boundary=b\r\n\r\n" +
+			"--b\r\nContent-Type: text/html\r\n\r\n<b>rich</b>\r\n--b\r\nContent-Type: text/plain\r\n\r\nplain\r\n--b--\r\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "plain", p.text)
+	})
+
+	t.Run("threading and auto replies", func(t *testing.T) {
+		p, err := parseMessage([]byte("From: a@example.com\r\nMessage-ID: <3@x>\r\nIn-Reply-To: <2@x>\r\n" +
+			"References: <1@x>\r\n <2@x>\r\nPrecedence: bulk\r\n\r\nhi"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"<1@x>", "<2@x>"}, p.references)
+		assert.Equal(t, "<1@x>", p.threadRoot())
+		assert.True(t, p.autoGenerated)
+	})
+
+	_, err := parseMessage([]byte("Subject: no sender\r\n\r\nhi"))
+	assert.Error(t, err)
+}
+
+func TestSenderAllowed(t *testing.T) {
+	cfg := EmailConfig{AllowedSenders: []string{"Alice@Example.com", "@corp.example"}}
+	assert.True(t, cfg.senderAllowed("alice@example.com"))
+	assert.True(t, cfg.senderAllowed("bob@corp.example"))
+	assert.False(t, cfg.senderAllowed("bob@evilcorp.example"))
+	assert.False(t, cfg.senderAllowed("eve@example.com"))
+
+	assert.True(t, (&EmailConfig{AllowedSenders: []string{"*"}}).senderAllowed("anyone@anywhere"))
+}
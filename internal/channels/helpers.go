@@ -0,0 +1,71 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"conduit/pkg/protocol"
+)
+
+// Errors returned by Forward
+var (
+	ErrAdapterStopped = errors.New("adapter not running")
+	ErrQueueFull      = errors.New("incoming message queue is full")
+)
+
+// StringList converts a JSON array from an adapter's config into a string
+// slice. Numbers are accepted too, since IDs are often written unquoted.
+func StringList(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	var list []string
+	for _, item := range items {
+		switch item := item.(type) {
+		case string:
+			list = append(list, item)
+		case float64:
+			list = append(list, strconv.FormatFloat(item, 'f', 0, 64))
+		}
+	}
+	return list
+}
+
+// TruncateText shortens text to at most limit runes, ending it with an
+// ellipsis when it was cut
+func TruncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// Forward queues a message on an adapter's incoming channel without
+// blocking. ctx is the adapter's run context; adapters close incoming when
+// they stop, so the caller must hold the lock Stop closes it under.
+func Forward(ctx context.Context, incoming chan<- *protocol.IncomingMessage, msg *protocol.IncomingMessage) error {
+	if ctx == nil || ctx.Err() != nil {
+		return ErrAdapterStopped
+	}
+
+	select {
+	case incoming <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// SetStatus updates an adapter's status and status message, unless ctx, the
+// adapter's run context, has ended: a late event must not revive a stopped
+// adapter. The caller holds the lock guarding the fields.
+func SetStatus(ctx context.Context, status *StatusCode, statusMsg *string, code StatusCode, msg string) {
+	if ctx != nil && ctx.Err() != nil {
+		return
+	}
+	*status = code
+	*statusMsg = msg
+}
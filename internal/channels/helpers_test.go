@@ -0,0 +1,49 @@
+package channels
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"conduit/pkg/protocol"
+)
+
+func TestStringList(t *testing.T) {
+	assert.Equal(t, []string{"a", "1234567"}, StringList([]interface{}{"a", float64(1234567), true}))
+	assert.Nil(t, StringList("a"))
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "héllo", TruncateText("héllo", 5))
+	assert.Equal(t, "hé…", TruncateText("héllo", 3))
+	assert.Len(t, []rune(TruncateText(strings.Repeat("x", 50), 10)), 10)
+}
+
+func TestForward(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	incoming := make(chan *protocol.IncomingMessage, 1)
+	msg := &protocol.IncomingMessage{Text: "hi"}
+
+	assert.NoError(t, Forward(ctx, incoming, msg))
+	assert.Equal(t, ErrQueueFull, Forward(ctx, incoming, msg))
+	<-incoming
+
+	cancel()
+	assert.Equal(t, ErrAdapterStopped, Forward(ctx, incoming, msg))
+	assert.Equal(t, ErrAdapterStopped, Forward(nil, incoming, msg), "not started")
+}
+
+func TestSetStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	status, msg := StatusInitializing, ""
+
+	SetStatus(ctx, &status, &msg, StatusOnline, "Connected")
+	assert.Equal(t, StatusOnline, status)
+	assert.Equal(t, "Connected", msg)
+
+	cancel()
+	SetStatus(ctx, &status, &msg, StatusReconnecting, "late event")
+	assert.Equal(t, StatusOnline, status, "a stopped adapter keeps its status")
+}
@@ -2,6 +2,7 @@ package channels
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"conduit/pkg/protocol"
@@ -58,11 +59,12 @@ const (
 
 // ChannelConfig contains configuration for channel adapters
 type ChannelConfig struct {
-	ID      string                 `json:"id"`
-	Type    string                 `json:"type"`
-	Name    string                 `json:"name"`
-	Enabled bool                   `json:"enabled"`
-	Config  map[string]interface{} `json:"config"`
+	ID       string                 `json:"id"`
+	Type     string                 `json:"type"`
+	Name     string                 `json:"name"`
+	Enabled  bool                   `json:"enabled"`
+	Config   map[string]interface{} `json:"config"`
+	MediaDir string                 `json:"media_dir,omitempty"` // Root for downloaded attachments
 }
 
 // TypingIndicator is an optional interface for adapters that support typing indicators
@@ -80,6 +82,25 @@ type StreamingAdapter interface {
 	DeleteMessage(chatID int64, messageID int) error
 }
 
+// TextStreamingAdapter is the StreamingAdapter equivalent for platforms whose
+// chat and message IDs are not integers, such as Slack channel IDs and
+// message timestamps. chatID is the UserID of the incoming message.
+type TextStreamingAdapter interface {
+	// SendStreamingMessage sends a message and returns its ID for later editing
+	SendStreamingMessage(chatID, text string) (string, error)
+	// EditStreamingMessage edits an existing message
+	EditStreamingMessage(chatID, messageID, text string) error
+	// DeleteStreamingMessage deletes a message (used for silent response cleanup)
+	DeleteStreamingMessage(chatID, messageID string) error
+}
+
+// WebhookAdapter is an optional interface for adapters that receive messages
+// over HTTP. The gateway routes POST /webhooks/<adapter id> to ServeWebhook
+// without token auth; the adapter authenticates requests itself.
+type WebhookAdapter interface {
+	ServeWebhook(w http.ResponseWriter, r *http.Request)
+}
+
 // ChannelFactory creates new channel adapters
 type ChannelFactory interface {
 	// SupportsType returns whether this factory can create adapters of the given type
@@ -24,6 +24,7 @@ func StripReplyTags(text string) string {
 // Manager manages all channel adapters (both native Go and TypeScript processes)
 type Manager struct {
 	adapters     map[string]ChannelAdapter
+	configs      map[string]ChannelConfig // Configurations of created adapters, for restarts
 	factories    map[string]ChannelFactory
 	incoming     chan *protocol.IncomingMessage
 	outgoing     chan *protocol.OutgoingMessage
@@ -31,19 +32,45 @@ type Manager struct {
 	cancel       context.CancelFunc
 	mutex        sync.RWMutex
 	messageStats map[string]int64
+
+	outbox     *Outbox         // Durable queue for outgoing messages; nil keeps them in memory
+	outboxWake chan struct{}   // Signals the outbox loop that messages may be due
+	delivering map[string]bool // Channels with an outbox delivery in progress
 }
 
+// Outbox delivery tuning
+const (
+	outboxPollInterval = time.Second
+	outboxBatchSize    = 100
+	outboxPurgeEvery   = time.Hour
+)
+
 // NewManager creates a new channel manager
 func NewManager() *Manager {
 	return &Manager{
 		adapters:     make(map[string]ChannelAdapter),
+		configs:      make(map[string]ChannelConfig),
 		factories:    make(map[string]ChannelFactory),
 		incoming:     make(chan *protocol.IncomingMessage, 1000),
 		outgoing:     make(chan *protocol.OutgoingMessage, 1000),
 		messageStats: make(map[string]int64),
+		outboxWake:   make(chan struct{}, 1),
+		delivering:   make(map[string]bool),
 	}
 }
 
+// SetOutbox makes the manager persist outgoing messages and retry failed
+// sends. It must be called before Start.
+func (m *Manager) SetOutbox(outbox *Outbox) {
+	m.outbox = outbox
+}
+
+// Outbox returns the durable outgoing queue, or nil if messages are kept in
+// memory
+func (m *Manager) Outbox() *Outbox {
+	return m.outbox
+}
+
 // RegisterFactory registers a channel adapter factory
 func (m *Manager) RegisterFactory(factory ChannelFactory) {
 	m.mutex.Lock()
@@ -74,6 +101,9 @@ func (m *Manager) Start(ctx context.Context, configs []ChannelConfig) error {
 
 	// Start message routing
 	go m.routeMessages()
+	if m.outbox != nil {
+		go m.runOutbox()
+	}
 
 	log.Printf("[ChannelManager] Started with %d adapters", len(m.adapters))
 	return nil
@@ -113,6 +143,9 @@ func (m *Manager) CreateAdapter(config ChannelConfig) error {
 		return fmt.Errorf("no factory found for adapter type: %s", config.Type)
 	}
 
+	// Kept even if the adapter fails to start, so it can be restarted later
+	m.configs[config.ID] = config
+
 	adapter, err := factory.CreateAdapter(config)
 	if err != nil {
 		return fmt.Errorf("failed to create adapter: %w", err)
@@ -124,7 +157,9 @@ func (m *Manager) CreateAdapter(config ChannelConfig) error {
 	}
 
 	m.adapters[config.ID] = adapter
-	m.messageStats[config.ID] = 0
+	if _, exists := m.messageStats[config.ID]; !exists {
+		m.messageStats[config.ID] = 0
+	}
 
 	// Start message forwarding from this adapter
 	go m.forwardMessages(adapter)
@@ -139,23 +174,36 @@ func (m *Manager) RemoveAdapter(id string) error {
 	defer m.mutex.Unlock()
 
 	adapter, exists := m.adapters[id]
-	if !exists {
+	if _, configured := m.configs[id]; !exists && !configured {
 		return fmt.Errorf("adapter not found: %s", id)
 	}
 
-	if err := adapter.Stop(); err != nil {
-		log.Printf("[ChannelManager] Error stopping adapter %s: %v", id, err)
+	if exists {
+		if err := adapter.Stop(); err != nil {
+			log.Printf("[ChannelManager] Error stopping adapter %s: %v", id, err)
+		}
 	}
 
 	delete(m.adapters, id)
+	delete(m.configs, id)
 	delete(m.messageStats, id)
 
 	log.Printf("[ChannelManager] Removed adapter: %s", id)
 	return nil
 }
 
-// SendMessage sends a message through the specified channel
+// SendMessage sends a message through the specified channel. With an
+// outbox the message is persisted first and retried until delivered.
 func (m *Manager) SendMessage(msg *protocol.OutgoingMessage) error {
+	if m.outbox != nil {
+		_, err := m.outbox.Enqueue(msg)
+		if err == nil {
+			m.wakeOutbox()
+			return nil
+		}
+		log.Printf("[ChannelManager] Failed to persist message for %s, sending without retry: %v", msg.ChannelID, err)
+	}
+
 	select {
 	case m.outgoing <- msg:
 		return nil
@@ -330,77 +378,211 @@ func (m *Manager) routeMessages() {
 				return
 			}
 
-			// Strip reply tags from text; set metadata for adapters that support replies
-			processReplyTags(msg)
+			if err := m.deliver(msg); err != nil {
+				log.Printf("[ChannelManager] Error sending message via %s: %v", msg.ChannelID, err)
+			}
 
-			m.mutex.RLock()
-			adapter, exists := m.adapters[msg.ChannelID]
-			m.mutex.RUnlock()
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver sends a message through its channel's adapter
+func (m *Manager) deliver(msg *protocol.OutgoingMessage) error {
+	// Strip reply tags from text; set metadata for adapters that support replies
+	processReplyTags(msg)
 
-			if !exists {
-				// Check if this is a TUI channel that needs dynamic creation
-				if strings.HasPrefix(msg.ChannelID, "tui_") {
-					log.Printf("[ChannelManager] Creating dynamic TUI adapter for channel %s", msg.ChannelID)
-
-					// Create dynamic TUI adapter configuration
-					tuiConfig := ChannelConfig{
-						ID:      msg.ChannelID,
-						Type:    "tui",
-						Name:    "TUI Dynamic",
-						Enabled: true,
-						Config:  map[string]interface{}{},
-					}
-
-					// Try to create the adapter
-					if err := m.CreateAdapter(tuiConfig); err != nil {
-						log.Printf("[ChannelManager] Failed to create dynamic TUI adapter for %s: %v", msg.ChannelID, err)
-						continue
-					}
-
-					// Get the newly created adapter
-					m.mutex.RLock()
-					adapter, exists = m.adapters[msg.ChannelID]
-					m.mutex.RUnlock()
-				}
-
-				if !exists {
-					log.Printf("[ChannelManager] Warning: no adapter found for channel %s", msg.ChannelID)
-					continue
-				}
+	m.mutex.RLock()
+	adapter, exists := m.adapters[msg.ChannelID]
+	m.mutex.RUnlock()
+
+	if !exists {
+		// Check if this is a TUI channel that needs dynamic creation
+		if strings.HasPrefix(msg.ChannelID, "tui_") {
+			log.Printf("[ChannelManager] Creating dynamic TUI adapter for channel %s", msg.ChannelID)
+
+			// Create dynamic TUI adapter configuration
+			tuiConfig := ChannelConfig{
+				ID:      msg.ChannelID,
+				Type:    "tui",
+				Name:    "TUI Dynamic",
+				Enabled: true,
+				Config:  map[string]interface{}{},
 			}
 
-			if err := adapter.SendMessage(msg); err != nil {
-				log.Printf("[ChannelManager] Error sending message via %s: %v", msg.ChannelID, err)
+			// Try to create the adapter
+			if err := m.CreateAdapter(tuiConfig); err != nil {
+				return fmt.Errorf("failed to create dynamic TUI adapter: %w", err)
 			}
 
+			// Get the newly created adapter
+			m.mutex.RLock()
+			adapter, exists = m.adapters[msg.ChannelID]
+			m.mutex.RUnlock()
+		}
+
+		if !exists {
+			return fmt.Errorf("no adapter found for channel %s", msg.ChannelID)
+		}
+	}
+
+	return adapter.SendMessage(msg)
+}
+
+// wakeOutbox tells the outbox loop to look for due messages now
+func (m *Manager) wakeOutbox() {
+	select {
+	case m.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// runOutbox delivers due outbox messages until the manager stops. Each
+// channel is delivered by its own goroutine so a slow or failing adapter
+// does not hold up the others.
+func (m *Manager) runOutbox() {
+	m.purgeOutbox()
+	purge := time.NewTicker(outboxPurgeEvery)
+	defer purge.Stop()
+	poll := time.NewTicker(outboxPollInterval)
+	defer poll.Stop()
+
+	for {
+		m.dispatchOutbox()
+
+		select {
 		case <-m.ctx.Done():
 			return
+		case <-m.outboxWake:
+		case <-poll.C:
+		case <-purge.C:
+			m.purgeOutbox()
 		}
 	}
 }
 
-// RestartAdapter stops and restarts a specific adapter
-func (m *Manager) RestartAdapter(id string) error {
+// dispatchOutbox starts delivery of due messages for every channel that is
+// not already being delivered
+func (m *Manager) dispatchOutbox() {
 	m.mutex.RLock()
-	adapter, exists := m.adapters[id]
+	var busy []string
+	for channelID := range m.delivering {
+		busy = append(busy, channelID)
+	}
 	m.mutex.RUnlock()
 
-	if !exists {
+	entries, err := m.outbox.Due(time.Now(), busy, outboxBatchSize)
+	if err != nil {
+		log.Printf("[ChannelManager] Failed to read outbox: %v", err)
+		return
+	}
+
+	var order []string
+	byChannel := make(map[string][]*OutboxEntry)
+	for _, entry := range entries {
+		if _, seen := byChannel[entry.ChannelID]; !seen {
+			order = append(order, entry.ChannelID)
+		}
+		byChannel[entry.ChannelID] = append(byChannel[entry.ChannelID], entry)
+	}
+
+	m.mutex.Lock()
+	for _, channelID := range order {
+		m.delivering[channelID] = true
+	}
+	m.mutex.Unlock()
+
+	for _, channelID := range order {
+		go m.deliverOutbox(channelID, byChannel[channelID])
+	}
+}
+
+// deliverOutbox sends one channel's due messages in order
+func (m *Manager) deliverOutbox(channelID string, entries []*OutboxEntry) {
+	defer func() {
+		m.mutex.Lock()
+		delete(m.delivering, channelID)
+		m.mutex.Unlock()
+		m.wakeOutbox()
+	}()
+
+	held := make(map[string]bool) // Users whose earlier message failed in this batch
+	for _, entry := range entries {
+		if m.ctx.Err() != nil {
+			return
+		}
+		if held[entry.UserID] {
+			continue
+		}
+
+		err := m.deliver(entry.Message)
+		if err == nil {
+			if err := m.outbox.MarkDelivered(entry.ID); err != nil {
+				log.Printf("[ChannelManager] %v", err)
+			}
+			continue
+		}
+
+		if wait, limited := RetryAfter(err); limited {
+			log.Printf("[ChannelManager] %s is rate limited, holding its messages for %v", channelID, wait)
+			if err := m.outbox.Postpone(channelID, time.Now().Add(wait), err); err != nil {
+				log.Printf("[ChannelManager] %v", err)
+			}
+			return
+		}
+
+		status, markErr := m.outbox.MarkFailed(entry, err)
+		switch {
+		case markErr != nil:
+			log.Printf("[ChannelManager] Error sending message via %s: %v (%v)", channelID, err, markErr)
+		case status == OutboxDead:
+			log.Printf("[ChannelManager] Giving up on outbox message %d via %s after %d attempts: %v",
+				entry.ID, channelID, entry.Attempts, err)
+		default:
+			log.Printf("[ChannelManager] Error sending outbox message %d via %s (attempt %d, retrying at %s): %v",
+				entry.ID, channelID, entry.Attempts, entry.NextAttemptAt.Local().Format(time.TimeOnly), err)
+			held[entry.UserID] = true
+		}
+	}
+}
+
+// purgeOutbox deletes delivered messages past their retention
+func (m *Manager) purgeOutbox() {
+	n, err := m.outbox.PurgeDelivered()
+	if err != nil {
+		log.Printf("[ChannelManager] %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("[ChannelManager] Purged %d delivered outbox messages", n)
+	}
+}
+
+// RestartAdapter replaces an adapter with a new instance created from its
+// configuration. Adapters close their message channel when stopped, so a
+// stopped instance cannot be started again. Adapters that failed to start
+// can be restarted too.
+func (m *Manager) RestartAdapter(id string) error {
+	m.mutex.Lock()
+	config, configured := m.configs[id]
+	adapter, exists := m.adapters[id]
+	delete(m.adapters, id)
+	m.mutex.Unlock()
+
+	if !configured {
 		return fmt.Errorf("adapter not found: %s", id)
 	}
 
 	log.Printf("[ChannelManager] Restarting adapter: %s", id)
 
-	// Stop the adapter
-	if err := adapter.Stop(); err != nil {
-		log.Printf("[ChannelManager] Error stopping adapter %s: %v", id, err)
+	if exists {
+		if err := adapter.Stop(); err != nil {
+			log.Printf("[ChannelManager] Error stopping adapter %s: %v", id, err)
+		}
 	}
 
-	// Wait a moment for cleanup
-	time.Sleep(1 * time.Second)
-
-	// Restart the adapter
-	if err := adapter.Start(m.ctx); err != nil {
+	if err := m.CreateAdapter(config); err != nil {
 		return fmt.Errorf("failed to restart adapter %s: %w", id, err)
 	}
 
@@ -0,0 +1,512 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// maxMessageLength keeps events well under the 64 KiB event size limit
+const maxMessageLength = 30000
+
+// typingTimeout is how long a typing notification lasts unless refreshed
+const typingTimeout = 30 * time.Second
+
+// Invite policies
+const (
+	AutoJoinAllowed = "allowed" // Accept invites from allowed users
+	AutoJoinAll     = "all"     // Accept every invite
+	AutoJoinNone    = "none"    // Reject every invite
+)
+
+// Adapter implements the ChannelAdapter interface for Matrix using the
+// Client-Server API: /sync for events and room sends for replies. Each room
+// is its own session.
+type Adapter struct {
+	id        string
+	name      string
+	api       *client
+	config    MatrixConfig
+	status    channels.StatusCode
+	statusMsg string
+	incoming  chan *protocol.IncomingMessage
+	ctx       context.Context
+	cancel    context.CancelFunc
+	mutex     sync.RWMutex
+	startTime time.Time
+	msgCount  int64
+
+	userID string
+	since  string // Sync token of the last batch handled
+
+	// Joined member counts by room, to tell direct chats from group rooms
+	memberCounts map[string]int
+	// Encrypted rooms, which the adapter can't read (logged once per room)
+	encrypted map[string]bool
+}
+
+// MatrixConfig contains Matrix-specific configuration
+type MatrixConfig struct {
+	HomeserverURL string `json:"homeserver_url"`
+	AccessToken   string `json:"access_token"`
+
+	// AllowedUsers lists user IDs, or ":server" entries for every user on a
+	// homeserver. It defaults to the bot's own homeserver.
+	AllowedUsers []string `json:"allowed_users"`
+	// AllowedRooms restricts which rooms the bot answers in. Empty allows
+	// all rooms it has joined.
+	AllowedRooms []string `json:"allowed_rooms"`
+	// AutoJoin decides which invites are accepted: "allowed" (default),
+	// "all" or "none"
+	AutoJoin string `json:"auto_join"`
+	// RequireMention makes the bot answer only when mentioned in rooms with
+	// more than two members
+	RequireMention bool `json:"require_mention"`
+
+	// MediaDir is where shared files are stored, one subdirectory per
+	// session. Files are ignored if empty.
+	MediaDir      string `json:"media_dir"`
+	MaxDownloadMB int    `json:"max_download_mb"`
+
+	SyncTimeout time.Duration `json:"-"` // sync_timeout_seconds
+}
+
+// Factory creates Matrix channel adapters
+type Factory struct{}
+
+// NewFactory creates a new Matrix adapter factory
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// SupportsType returns whether this factory supports the given adapter type
+func (f *Factory) SupportsType(adapterType string) bool {
+	return adapterType == "matrix"
+}
+
+// GetSupportedTypes returns the adapter types this factory supports
+func (f *Factory) GetSupportedTypes() []string {
+	return []string{"matrix"}
+}
+
+// CreateAdapter creates a new Matrix adapter instance
+func (f *Factory) CreateAdapter(config channels.ChannelConfig) (channels.ChannelAdapter, error) {
+	matrixConfig := MatrixConfig{
+		AutoJoin:       AutoJoinAllowed,
+		RequireMention: true,
+		SyncTimeout:    30 * time.Second,
+	}
+
+	// Parse Matrix-specific config
+	if homeserver, ok := config.Config["homeserver_url"].(string); ok && homeserver != "" {
+		if !strings.HasPrefix(homeserver, "https://") && !strings.HasPrefix(homeserver, "http://") {
+			return nil, fmt.Errorf("homeserver_url must be an http(s) URL")
+		}
+		matrixConfig.HomeserverURL = homeserver
+	} else {
+		return nil, fmt.Errorf("homeserver_url is required for Matrix adapter")
+	}
+
+	if token, ok := config.Config["access_token"].(string); ok && token != "" {
+		matrixConfig.AccessToken = token
+	} else {
+		return nil, fmt.Errorf("access_token is required for Matrix adapter")
+	}
+
+	matrixConfig.AllowedUsers = channels.StringList(config.Config["allowed_users"])
+	matrixConfig.AllowedRooms = channels.StringList(config.Config["allowed_rooms"])
+
+	if autoJoin, ok := config.Config["auto_join"].(string); ok && autoJoin != "" {
+		switch autoJoin {
+		case AutoJoinAllowed, AutoJoinAll, AutoJoinNone:
+			matrixConfig.AutoJoin = autoJoin
+		default:
+			return nil, fmt.Errorf("auto_join must be allowed, all or none")
+		}
+	}
+
+	if requireMention, ok := config.Config["require_mention"].(bool); ok {
+		matrixConfig.RequireMention = requireMention
+	}
+
+	matrixConfig.MediaDir = config.MediaDir
+	if mediaDir, ok := config.Config["media_dir"].(string); ok && mediaDir != "" {
+		matrixConfig.MediaDir = mediaDir
+	}
+
+	if maxMB, ok := config.Config["max_download_mb"].(float64); ok {
+		matrixConfig.MaxDownloadMB = int(maxMB)
+	}
+
+	if timeout, ok := config.Config["sync_timeout_seconds"].(float64); ok && timeout > 0 {
+		matrixConfig.SyncTimeout = time.Duration(timeout * float64(time.Second))
+	}
+
+	return &Adapter{
+		id:           config.ID,
+		name:         config.Name,
+		api:          newClient(matrixConfig.HomeserverURL, matrixConfig.AccessToken),
+		config:       matrixConfig,
+		status:       channels.StatusInitializing,
+		incoming:     make(chan *protocol.IncomingMessage, 100),
+		memberCounts: make(map[string]int),
+		encrypted:    make(map[string]bool),
+	}, nil
+}
+
+// ID returns the adapter's unique identifier
+func (a *Adapter) ID() string {
+	return a.id
+}
+
+// Name returns the adapter's human-readable name
+func (a *Adapter) Name() string {
+	return a.name
+}
+
+// Type returns the adapter type
+func (a *Adapter) Type() string {
+	return "matrix"
+}
+
+// Start verifies the access token and begins syncing
+func (a *Adapter) Start(ctx context.Context) error {
+	a.mutex.Lock()
+	a.ctx, a.cancel = context.WithCancel(ctx)
+	a.status = channels.StatusInitializing
+	a.statusMsg = "Connecting to homeserver"
+	a.startTime = time.Now()
+	a.mutex.Unlock()
+
+	userID, err := a.api.whoami(a.ctx)
+	if err != nil {
+		a.setStatus(channels.StatusError, fmt.Sprintf("Failed to authenticate: %v", err))
+		return fmt.Errorf("failed to authenticate Matrix user: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.userID = userID
+	if len(a.config.AllowedUsers) == 0 {
+		a.config.AllowedUsers = []string{":" + serverName(userID)}
+	}
+	a.mutex.Unlock()
+
+	go a.runSync(a.ctx)
+
+	log.Printf("[Matrix] Bot started: %s (%s)", a.Name(), userID)
+	return nil
+}
+
+// Stop gracefully shuts down the adapter
+func (a *Adapter) Stop() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.cancel != nil {
+		a.cancel()
+	}
+
+	a.status = channels.StatusOffline
+	a.statusMsg = "Adapter stopped"
+
+	// Close incoming message channel
+	close(a.incoming)
+
+	log.Printf("[Matrix] Adapter stopped: %s", a.Name())
+	return nil
+}
+
+// setStatus updates the status reported by Status and IsHealthy
+func (a *Adapter) setStatus(status channels.StatusCode, msg string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	channels.SetStatus(a.ctx, &a.status, &a.statusMsg, status, msg)
+}
+
+// sessionKey maps a room to a session
+func sessionKey(roomID string) string {
+	return "matrix_" + roomID
+}
+
+// serverName returns the homeserver part of a user or room ID
+func serverName(id string) string {
+	_, server, _ := strings.Cut(id, ":")
+	return server
+}
+
+// SendMessage posts a message to a room; UserID is the room ID. Files from
+// MediaPath or image_path metadata are uploaded and sent after the text.
+func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+
+	roomID := msg.UserID
+	if !strings.HasPrefix(roomID, "!") {
+		return fmt.Errorf("invalid room ID: %s", roomID)
+	}
+
+	sanitizedText := channels.SanitizeUserFacingText(msg.Text)
+	if sanitizedText != "" {
+		if _, err := a.api.sendEvent(a.ctx, roomID, "m.room.message", newTxnID(), textContent(sanitizedText)); err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+
+	filePath := msg.MediaPath
+	if imagePath, ok := msg.Metadata["image_path"]; ok && imagePath != "" {
+		filePath = imagePath
+	}
+	if filePath != "" {
+		if err := a.sendFile(roomID, filePath); err != nil {
+			return fmt.Errorf("failed to send file: %w", err)
+		}
+	}
+
+	// Replying ends the typing notification started for this message
+	a.api.typing(a.ctx, roomID, a.userID, false, 0)
+
+	log.Printf("[Matrix] Message sent to %s (%d chars, sanitized from %d)", roomID, len(sanitizedText), len(msg.Text))
+
+	a.mutex.Lock()
+	a.msgCount++
+	a.mutex.Unlock()
+
+	return nil
+}
+
+// sendFile uploads a local file and posts it to a room
+func (a *Adapter) sendFile(roomID, filePath string) error {
+	name := filepath.Base(filePath)
+	mediaType := mime.TypeByExtension(filepath.Ext(name))
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+
+	uri, size, err := a.api.upload(a.ctx, filePath, mediaType)
+	if err != nil {
+		return err
+	}
+
+	msgtype := "m.file"
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		msgtype = "m.image"
+	case strings.HasPrefix(mediaType, "audio/"):
+		msgtype = "m.audio"
+	case strings.HasPrefix(mediaType, "video/"):
+		msgtype = "m.video"
+	}
+
+	_, err = a.api.sendEvent(a.ctx, roomID, "m.room.message", newTxnID(), map[string]interface{}{
+		"msgtype": msgtype,
+		"body":    name,
+		"url":     uri,
+		"info":    map[string]interface{}{"mimetype": mediaType, "size": size},
+	})
+	return err
+}
+
+// textContent builds an m.text message with an HTML rendering of the
+// Markdown the agent writes
+func textContent(text string) map[string]interface{} {
+	text = channels.TruncateText(text, maxMessageLength)
+	content := map[string]interface{}{
+		"msgtype": "m.text",
+		"body":    text,
+	}
+	if formatted := markdownToHTML(text); formatted != html.EscapeString(text) {
+		content["format"] = "org.matrix.custom.html"
+		content["formatted_body"] = formatted
+	}
+	return content
+}
+
+var (
+	codeBlockRe  = regexp.MustCompile("(?s)```[a-zA-Z0-9_+-]*\n?(.*?)```")
+	inlineCodeRe = regexp.MustCompile("`([^`\n]+)`")
+	boldRe       = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	linkRe       = regexp.MustCompile(`\[([^\]\n]+)\]\((https?://[^)\s]+)\)`)
+)
+
+// markdownToHTML converts the common Markdown subset (code, bold, links)
+// to HTML. Code is rendered before other rules so its contents stay literal.
+func markdownToHTML(text string) string {
+	var blocks []string
+	placeholder := func(s string) string {
+		blocks = append(blocks, s)
+		return fmt.Sprintf("\x00%d\x00", len(blocks)-1)
+	}
+
+	text = codeBlockRe.ReplaceAllStringFunc(text, func(m string) string {
+		code := codeBlockRe.FindStringSubmatch(m)[1]
+		return placeholder("<pre><code>" + html.EscapeString(strings.TrimSuffix(code, "\n")) + "</code></pre>")
+	})
+	text = inlineCodeRe.ReplaceAllStringFunc(text, func(m string) string {
+		return placeholder("<code>" + html.EscapeString(inlineCodeRe.FindStringSubmatch(m)[1]) + "</code>")
+	})
+
+	text = html.EscapeString(text)
+	text = boldRe.ReplaceAllString(text, "<strong>$1</strong>")
+	text = linkRe.ReplaceAllString(text, `<a href="$2">$1</a>`)
+	text = strings.ReplaceAll(text, "\n", "<br>")
+
+	for i, block := range blocks {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), block, 1)
+	}
+	return text
+}
+
+// SendStreamingMessage posts a message and returns its event ID for later
+// edits
+func (a *Adapter) SendStreamingMessage(chatID, text string) (string, error) {
+	if a.ctx == nil {
+		return "", fmt.Errorf("adapter not started")
+	}
+	return a.api.sendEvent(a.ctx, chatID, "m.room.message", newTxnID(),
+		textContent(channels.SanitizeUserFacingText(text)))
+}
+
+// EditStreamingMessage replaces the text of a message with an m.replace
+// edit. The fallback body is what clients without edit support show.
+func (a *Adapter) EditStreamingMessage(chatID, messageID, text string) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+
+	newContent := textContent(channels.SanitizeUserFacingText(text))
+	content := map[string]interface{}{
+		"msgtype":       "m.text",
+		"body":          "* " + newContent["body"].(string),
+		"m.new_content": newContent,
+		"m.relates_to": map[string]interface{}{
+			"rel_type": "m.replace",
+			"event_id": messageID,
+		},
+	}
+	_, err := a.api.sendEvent(a.ctx, chatID, "m.room.message", newTxnID(), content)
+	return err
+}
+
+// DeleteStreamingMessage redacts a message (used for silent response cleanup)
+func (a *Adapter) DeleteStreamingMessage(chatID, messageID string) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+
+	err := a.api.redact(a.ctx, chatID, messageID, newTxnID())
+	if apiErr, ok := err.(*apiError); ok && apiErr.Code == "M_NOT_FOUND" {
+		return nil
+	}
+	return err
+}
+
+// SendTypingIndicator shows the bot as typing in a room
+func (a *Adapter) SendTypingIndicator(chatID string) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+	return a.api.typing(a.ctx, chatID, a.userID, true, typingTimeout)
+}
+
+// ReceiveMessages returns the channel for incoming messages
+func (a *Adapter) ReceiveMessages() <-chan *protocol.IncomingMessage {
+	return a.incoming
+}
+
+// Status returns the current adapter status
+func (a *Adapter) Status() channels.ChannelStatus {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	details := map[string]interface{}{
+		"uptime_seconds": time.Since(a.startTime).Seconds(),
+		"message_count":  a.msgCount,
+		"homeserver":     a.config.HomeserverURL,
+		"auto_join":      a.config.AutoJoin,
+		"rooms":          len(a.memberCounts),
+	}
+	if a.userID != "" {
+		details["user_id"] = a.userID
+	}
+
+	return channels.ChannelStatus{
+		Status:    a.status,
+		Message:   a.statusMsg,
+		Details:   details,
+		Timestamp: time.Now(),
+	}
+}
+
+// IsHealthy returns whether the adapter is functioning properly
+func (a *Adapter) IsHealthy() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.status == channels.StatusOnline
+}
+
+// userAllowed checks a user ID against allowed_users
+func (a *Adapter) userAllowed(userID string) bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	for _, entry := range a.config.AllowedUsers {
+		if entry == userID || (strings.HasPrefix(entry, ":") && entry == ":"+serverName(userID)) {
+			return true
+		}
+	}
+	return false
+}
+
+// roomAllowed checks a room ID against allowed_rooms
+func (a *Adapter) roomAllowed(roomID string) bool {
+	if len(a.config.AllowedRooms) == 0 {
+		return true
+	}
+	for _, room := range a.config.AllowedRooms {
+		if room == roomID {
+			return true
+		}
+	}
+	return false
+}
+
+// forward sends a message to the incoming channel without blocking
+func (a *Adapter) forward(msg *protocol.IncomingMessage) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	switch err := channels.Forward(a.ctx, a.incoming, msg); err {
+	case nil:
+		a.msgCount++
+		// Privacy-safe logging - no message content or user names
+		log.Printf("[Matrix] Received message in %s (%d chars, %d attachments)",
+			msg.UserID, len(msg.Text), len(msg.Attachments))
+	case channels.ErrQueueFull:
+		log.Printf("[Matrix] Warning: incoming message channel is full, dropping message")
+	}
+}
+
+// newTxnID creates a transaction ID; the homeserver deduplicates retried
+// sends with the same ID
+func newTxnID() string {
+	return "conduit" + uuid.New().String()
+}
+
+// generateMessageID creates a unique message ID
+func (a *Adapter) generateMessageID() string {
+	return fmt.Sprintf("matrix_%s_%s", a.id, uuid.New().String()[:8])
+}
@@ -0,0 +1,410 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+const (
+	testBotID  = "@conduit:example.org"
+	testUserID = "@alice:example.org"
+	testDMRoom = "!dm:example.org"
+	testGroup  = "!group:example.org"
+)
+
+// apiCall is a Client-Server API request received by the fake homeserver
+type apiCall struct {
+	Method string
+	Path   string
+	Body   map[string]interface{}
+}
+
+// fakeHomeserver serves the endpoints the adapter uses. Sync batches queued
+// with push are returned one per /sync request.
+type fakeHomeserver struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu      sync.Mutex
+	calls   []apiCall
+	initial map[string]interface{}
+	batches chan map[string]interface{}
+	batch   int
+	events  int
+}
+
+func newFakeHomeserver(t *testing.T) *fakeHomeserver {
+	f := &fakeHomeserver{t: t, batches: make(chan map[string]interface{}, 10)}
+	f.initial = map[string]interface{}{}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeHomeserver) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	params := map[string]interface{}{}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		json.Unmarshal(body, &params)
+	}
+	if r.Header.Get("Authorization") != "Bearer secret-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errcode": "M_UNKNOWN_TOKEN", "error": "Invalid token"}`))
+		return
+	}
+
+	p := r.URL.Path
+	if p != "/_matrix/client/v3/sync" {
+		f.mu.Lock()
+		f.calls = append(f.calls, apiCall{Method: r.Method, Path: p, Body: params})
+		f.mu.Unlock()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case p == "/_matrix/client/v3/account/whoami":
+		fmt.Fprintf(w, `{"user_id": %q}`, testBotID)
+	case p == "/_matrix/client/v3/sync":
+		f.serveSync(w, r)
+	case strings.Contains(p, "/send/"):
+		f.mu.Lock()
+		f.events++
+		id := fmt.Sprintf("$event%d", f.events)
+		f.mu.Unlock()
+		fmt.Fprintf(w, `{"event_id": %q}`, id)
+	case strings.Contains(p, "/redact/$gone"):
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errcode": "M_NOT_FOUND", "error": "Event not found"}`))
+	case strings.HasSuffix(p, "/joined_members"):
+		if strings.Contains(p, testDMRoom) {
+			fmt.Fprintf(w, `{"joined": {%q: {}, %q: {}}}`, testBotID, testUserID)
+		} else {
+			fmt.Fprintf(w, `{"joined": {%q: {}, %q: {}, "@bob:example.org": {}}}`, testBotID, testUserID)
+		}
+	case p == "/_matrix/media/v3/upload":
+		assert.Equal(f.t, "chart.png", r.URL.Query().Get("filename"))
+		assert.Equal(f.t, "image/png", r.Header.Get("Content-Type"))
+		w.Write([]byte(`{"content_uri": "mxc://example.org/uploaded"}`))
+	case p == "/_matrix/client/v1/media/download/example.org/photo":
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\nfake image"))
+	default:
+		w.Write([]byte(`{}`))
+	}
+}
+
+func (f *fakeHomeserver) serveSync(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("since") == "" {
+		resp := map[string]interface{}{"next_batch": "s0", "rooms": f.initial}
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	select {
+	case rooms := <-f.batches:
+		f.mu.Lock()
+		f.batch++
+		next := fmt.Sprintf("s%d", f.batch)
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"next_batch": next, "rooms": rooms})
+	case <-time.After(50 * time.Millisecond):
+		fmt.Fprintf(w, `{"next_batch": %q}`, r.URL.Query().Get("since"))
+	}
+}
+
+// push queues a sync batch with timeline events for one room
+func (f *fakeHomeserver) push(roomID string, events ...map[string]interface{}) {
+	f.batches <- map[string]interface{}{
+		"join": map[string]interface{}{
+			roomID: map[string]interface{}{"timeline": map[string]interface{}{"events": events}},
+		},
+	}
+}
+
+// callsTo returns recorded calls whose path contains s
+func (f *fakeHomeserver) callsTo(s string) []apiCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []apiCall
+	for _, c := range f.calls {
+		if strings.Contains(c.Path, s) {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+func textEvent(id, sender, body string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "m.room.message",
+		"event_id": id,
+		"sender":   sender,
+		"content":  map[string]interface{}{"msgtype": "m.text", "body": body},
+	}
+}
+
+func startTestAdapter(t *testing.T, f *fakeHomeserver, extra map[string]interface{}) *Adapter {
+	cfg := map[string]interface{}{
+		"homeserver_url":       f.server.URL,
+		"access_token":         "secret-token",
+		"media_dir":            t.TempDir(),
+		"sync_timeout_seconds": float64(1),
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	adapter, err := NewFactory().CreateAdapter(channels.ChannelConfig{
+		ID: "matrix", Type: "matrix", Name: "Matrix", Enabled: true, Config: cfg,
+	})
+	require.NoError(t, err)
+	a := adapter.(*Adapter)
+	require.NoError(t, a.Start(context.Background()))
+	t.Cleanup(func() { a.Stop() })
+	require.Eventually(t, a.IsHealthy, 5*time.Second, 10*time.Millisecond)
+	return a
+}
+
+func receive(t *testing.T, a *Adapter) *protocol.IncomingMessage {
+	select {
+	case msg := <-a.ReceiveMessages():
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for incoming message")
+		return nil
+	}
+}
+
+func assertNoMessage(t *testing.T, a *Adapter) {
+	select {
+	case msg := <-a.ReceiveMessages():
+		t.Fatalf("unexpected message: %q", msg.Text)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestFactory_CreateAdapter(t *testing.T) {
+	f := NewFactory()
+	assert.True(t, f.SupportsType("matrix"))
+
+	_, err := f.CreateAdapter(channels.ChannelConfig{ID: "m", Config: map[string]interface{}{"access_token": "t"}})
+	assert.Error(t, err, "homeserver_url is required")
+	_, err = f.CreateAdapter(channels.ChannelConfig{ID: "m", Config: map[string]interface{}{"homeserver_url": "https://matrix.example.org"}})
+	assert.Error(t, err, "access_token is required")
+	_, err = f.CreateAdapter(channels.ChannelConfig{ID: "m", Config: map[string]interface{}{
+		"homeserver_url": "https://matrix.example.org", "access_token": "t", "auto_join": "sometimes",
+	}})
+	assert.Error(t, err, "auto_join is validated")
+
+	adapter, err := f.CreateAdapter(channels.ChannelConfig{ID: "m", Config: map[string]interface{}{
+		"homeserver_url": "https://matrix.example.org", "access_token": "t",
+	}})
+	require.NoError(t, err)
+	a := adapter.(*Adapter)
+	assert.Equal(t, AutoJoinAllowed, a.config.AutoJoin)
+	assert.True(t, a.config.RequireMention)
+
+	_, isText := adapter.(channels.TextStreamingAdapter)
+	assert.True(t, isText)
+	_, isTyping := adapter.(channels.TypingIndicator)
+	assert.True(t, isTyping)
+}
+
+func TestAdapter_StartRejectsBadToken(t *testing.T) {
+	f := newFakeHomeserver(t)
+	adapter, err := NewFactory().CreateAdapter(channels.ChannelConfig{ID: "m", Config: map[string]interface{}{
+		"homeserver_url": f.server.URL, "access_token": "wrong",
+	}})
+	require.NoError(t, err)
+	assert.ErrorContains(t, adapter.Start(context.Background()), "M_UNKNOWN_TOKEN")
+	assert.Equal(t, channels.StatusError, adapter.Status().Status)
+}
+
+func TestAdapter_Invites(t *testing.T) {
+	f := newFakeHomeserver(t)
+	invite := func(inviter string) map[string]interface{} {
+		return map[string]interface{}{"invite_state": map[string]interface{}{"events": []interface{}{
+			map[string]interface{}{
+				"type": "m.room.member", "sender": inviter, "state_key": testBotID,
+				"content": map[string]interface{}{"membership": "invite"},
+			},
+		}}}
+	}
+	f.initial["invite"] = map[string]interface{}{
+		"!friendly:example.org": invite(testUserID),
+		"!stranger:evil.net":    invite("@mallory:evil.net"),
+	}
+	// History from before the adapter started is not answered
+	f.initial["join"] = map[string]interface{}{
+		testDMRoom: map[string]interface{}{"timeline": map[string]interface{}{"events": []interface{}{
+			textEvent("$old", testUserID, "old message"),
+		}}},
+	}
+
+	a := startTestAdapter(t, f, nil)
+
+	require.Eventually(t, func() bool {
+		return len(f.callsTo("/join/")) == 1 && len(f.callsTo("/leave")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "/_matrix/client/v3/join/!friendly:example.org", f.callsTo("/join/")[0].Path)
+	assert.Equal(t, "/_matrix/client/v3/rooms/!stranger:evil.net/leave", f.callsTo("/leave")[0].Path)
+	assertNoMessage(t, a)
+}
+
+func TestAdapter_DirectMessages(t *testing.T) {
+	f := newFakeHomeserver(t)
+	a := startTestAdapter(t, f, nil)
+
+	f.push(testDMRoom,
+		textEvent("$own", testBotID, "my own message"),
+		map[string]interface{}{
+			"type": "m.room.message", "event_id": "$notice", "sender": testUserID,
+			"content": map[string]interface{}{"msgtype": "m.notice", "body": "bot notice"},
+		},
+		map[string]interface{}{
+			"type": "m.room.message", "event_id": "$reply", "sender": testUserID,
+			"content": map[string]interface{}{
+				"msgtype":      "m.text",
+				"body":         "> <@conduit:example.org> earlier answer\n\nWhat about tomorrow?",
+				"m.relates_to": map[string]interface{}{"m.in_reply_to": map[string]interface{}{"event_id": "$prev"}},
+			},
+		},
+	)
+
+	msg := receive(t, a)
+	assert.Equal(t, "matrix", msg.ChannelID)
+	assert.Equal(t, testDMRoom, msg.UserID)
+	assert.Equal(t, "matrix_"+testDMRoom, msg.SessionKey)
+	assert.Equal(t, "What about tomorrow?", msg.Text)
+	assert.Equal(t, testUserID, msg.Metadata["from_user_id"])
+	assert.Equal(t, "$reply", msg.Metadata["event_id"])
+	assertNoMessage(t, a)
+
+	// Users from other homeservers are not allowed by default
+	f.push(testDMRoom, textEvent("$evil", "@mallory:evil.net", "hi"))
+	assertNoMessage(t, a)
+}
+
+func TestAdapter_GroupRoomsRequireMention(t *testing.T) {
+	f := newFakeHomeserver(t)
+	a := startTestAdapter(t, f, nil)
+
+	f.push(testGroup, textEvent("$chatter", testUserID, "lunch anyone?"))
+	assertNoMessage(t, a)
+
+	f.push(testGroup, textEvent("$mention", testUserID, "conduit: what's the build status?"))
+	msg := receive(t, a)
+	assert.Equal(t, "what's the build status?", msg.Text)
+	assert.Equal(t, "matrix_"+testGroup, msg.SessionKey)
+
+	// Intentional mentions count too
+	f.push(testGroup, map[string]interface{}{
+		"type": "m.room.message", "event_id": "$pill", "sender": testUserID,
+		"content": map[string]interface{}{
+			"msgtype": "m.text", "body": "Bot, deploy please",
+			"m.mentions": map[string]interface{}{"user_ids": []interface{}{testBotID}},
+		},
+	})
+	assert.Equal(t, "Bot, deploy please", receive(t, a).Text)
+}
+
+func TestAdapter_IncomingMedia(t *testing.T) {
+	f := newFakeHomeserver(t)
+	a := startTestAdapter(t, f, nil)
+
+	f.push(testDMRoom, map[string]interface{}{
+		"type": "m.room.message", "event_id": "$img", "sender": testUserID,
+		"content": map[string]interface{}{
+			"msgtype": "m.image", "body": "photo.png", "url": "mxc://example.org/photo",
+			"info": map[string]interface{}{"mimetype": "image/png", "size": float64(18)},
+		},
+	})
+
+	msg := receive(t, a)
+	assert.Equal(t, "[Attachment: photo.png]", msg.Text)
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, protocol.AttachmentImage, msg.Attachments[0].Type)
+	data, err := os.ReadFile(msg.Attachments[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "\x89PNG\r\n\x1a\nfake image", string(data))
+}
+
+func TestAdapter_SendMessage(t *testing.T) {
+	f := newFakeHomeserver(t)
+	a := startTestAdapter(t, f, nil)
+
+	file := filepath.Join(t.TempDir(), "chart.png")
+	require.NoError(t, os.WriteFile(file, []byte("\x89PNG fake"), 0o644))
+
+	require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{
+		UserID:    testDMRoom,
+		Text:      "Here is **the chart**",
+		MediaPath: file,
+	}))
+
+	sends := f.callsTo("/send/m.room.message/")
+	require.Len(t, sends, 2)
+	assert.True(t, strings.HasPrefix(sends[0].Path, "/_matrix/client/v3/rooms/!dm:example.org/send/m.room.message/conduit"))
+	assert.Equal(t, "Here is **the chart**", sends[0].Body["body"])
+	assert.Equal(t, "Here is <strong>the chart</strong>", sends[0].Body["formatted_body"])
+	assert.Equal(t, "m.image", sends[1].Body["msgtype"])
+	assert.Equal(t, "mxc://example.org/uploaded", sends[1].Body["url"])
+	assert.Equal(t, "chart.png", sends[1].Body["body"])
+
+	typing := f.callsTo("/typing/")
+	require.Len(t, typing, 1)
+	assert.Equal(t, false, typing[0].Body["typing"])
+
+	assert.Error(t, a.SendMessage(&protocol.OutgoingMessage{UserID: "not-a-room", Text: "hi"}))
+}
+
+func TestAdapter_StreamingAndTyping(t *testing.T) {
+	f := newFakeHomeserver(t)
+	a := startTestAdapter(t, f, nil)
+
+	require.NoError(t, a.SendTypingIndicator(testDMRoom))
+	typing := f.callsTo("/typing/" + testBotID)
+	require.Len(t, typing, 1)
+	assert.Equal(t, true, typing[0].Body["typing"])
+	assert.Equal(t, float64(30000), typing[0].Body["timeout"])
+
+	id, err := a.SendStreamingMessage(testDMRoom, "Thinking")
+	require.NoError(t, err)
+	assert.Equal(t, "$event1", id)
+
+	require.NoError(t, a.EditStreamingMessage(testDMRoom, id, "Thinking done"))
+	edit := f.callsTo("/send/")[1].Body
+	assert.Equal(t, "* Thinking done", edit["body"])
+	assert.Equal(t, map[string]interface{}{"rel_type": "m.replace", "event_id": "$event1"}, edit["m.relates_to"])
+	assert.Equal(t, "Thinking done", edit["m.new_content"].(map[string]interface{})["body"])
+
+	require.NoError(t, a.DeleteStreamingMessage(testDMRoom, id))
+	require.Len(t, f.callsTo("/redact/$event1/"), 1)
+	assert.NoError(t, a.DeleteStreamingMessage(testDMRoom, "$gone"), "already deleted messages are ignored")
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	assert.Equal(t, "a &lt;b&gt; <strong>bold</strong><br><a href=\"https://x.org/a\">link</a>",
+		markdownToHTML("a <b> **bold**\n[link](https://x.org/a)"))
+	assert.Equal(t, "run <code>**x** &amp; y</code>", markdownToHTML("run `**x** & y`"))
+	assert.Equal(t, "<pre><code>if a &lt; b {\n}</code></pre>", markdownToHTML("```go\nif a < b {\n}\n```"))
+
+	// Plain text isn't sent with a formatted body
+	_, formatted := textContent("just text")["formatted_body"]
+	assert.False(t, formatted)
+}
@@ -0,0 +1,288 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRateLimitRetries bounds retries of a single rate limited request
+const maxRateLimitRetries = 3
+
+// apiError is an error response from the homeserver
+type apiError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"errcode"`
+	Message string `json:"error"`
+}
+
+func (e *apiError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("matrix request failed with status %d", e.Status)
+	}
+	return fmt.Sprintf("matrix request failed: %s: %s", e.Code, e.Message)
+}
+
+// event is a room event from /sync
+type event struct {
+	Type     string                 `json:"type"`
+	EventID  string                 `json:"event_id"`
+	Sender   string                 `json:"sender"`
+	StateKey *string                `json:"state_key,omitempty"`
+	Content  map[string]interface{} `json:"content"`
+}
+
+// syncResponse is the part of a /sync response the adapter uses
+type syncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Summary struct {
+				JoinedMemberCount *int `json:"m.joined_member_count"`
+			} `json:"summary"`
+			Timeline struct {
+				Events []event `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+		Invite map[string]struct {
+			InviteState struct {
+				Events []event `json:"events"`
+			} `json:"invite_state"`
+		} `json:"invite"`
+	} `json:"rooms"`
+}
+
+// client is a minimal client for the Matrix Client-Server API
+type client struct {
+	homeserver  string
+	accessToken string
+	httpClient  *http.Client
+}
+
+func newClient(homeserver, accessToken string) *client {
+	return &client{
+		homeserver:  strings.TrimSuffix(homeserver, "/"),
+		accessToken: accessToken,
+		httpClient:  &http.Client{Timeout: 2 * time.Minute}, // Longer than the sync timeout
+	}
+}
+
+// path joins escaped segments onto an API prefix
+func path(prefix string, segments ...string) string {
+	for _, s := range segments {
+		prefix += "/" + url.PathEscape(s)
+	}
+	return prefix
+}
+
+// do sends a request and decodes a JSON response into out. Rate limited
+// requests are retried after the delay the server asks for.
+func (c *client) do(ctx context.Context, method, endpoint string, query url.Values, contentType string, body []byte, out interface{}) error {
+	target := c.homeserver + endpoint
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, target, reader)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("matrix request failed: %w", err)
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			if out == nil {
+				return nil
+			}
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+			return nil
+		}
+
+		apiErr := &apiError{Status: resp.StatusCode}
+		json.Unmarshal(respBody, apiErr)
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			wait := time.Second
+			var limited struct {
+				RetryAfterMs int `json:"retry_after_ms"`
+			}
+			if json.Unmarshal(respBody, &limited) == nil && limited.RetryAfterMs > 0 {
+				wait = time.Duration(limited.RetryAfterMs) * time.Millisecond
+			} else if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				wait = time.Duration(secs) * time.Second
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+		return apiErr
+	}
+}
+
+// doJSON sends a JSON body
+func (c *client) doJSON(ctx context.Context, method, endpoint string, body, out interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+	return c.do(ctx, method, endpoint, nil, "application/json", data, out)
+}
+
+// whoami returns the user ID the access token belongs to
+func (c *client) whoami(ctx context.Context) (string, error) {
+	var resp struct {
+		UserID string `json:"user_id"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/_matrix/client/v3/account/whoami", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.UserID, nil
+}
+
+// sync long-polls for new events since the given batch token
+func (c *client) sync(ctx context.Context, since string, timeout time.Duration) (*syncResponse, error) {
+	query := url.Values{"timeout": {strconv.FormatInt(timeout.Milliseconds(), 10)}}
+	if since != "" {
+		query.Set("since", since)
+	} else {
+		// The initial sync only establishes where to start; skip history
+		query.Set("filter", `{"room":{"timeline":{"limit":1}}}`)
+	}
+
+	var resp syncResponse
+	if err := c.do(ctx, http.MethodGet, "/_matrix/client/v3/sync", query, "", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// sendEvent sends a room event and returns its event ID
+func (c *client) sendEvent(ctx context.Context, roomID, eventType, txnID string, content interface{}) (string, error) {
+	var resp struct {
+		EventID string `json:"event_id"`
+	}
+	endpoint := path("/_matrix/client/v3/rooms", roomID, "send", eventType, txnID)
+	if err := c.doJSON(ctx, http.MethodPut, endpoint, content, &resp); err != nil {
+		return "", err
+	}
+	return resp.EventID, nil
+}
+
+// redact removes an event's content
+func (c *client) redact(ctx context.Context, roomID, eventID, txnID string) error {
+	endpoint := path("/_matrix/client/v3/rooms", roomID, "redact", eventID, txnID)
+	return c.doJSON(ctx, http.MethodPut, endpoint, map[string]string{}, nil)
+}
+
+// typing sets or clears the typing notification in a room
+func (c *client) typing(ctx context.Context, roomID, userID string, typing bool, timeout time.Duration) error {
+	body := map[string]interface{}{"typing": typing}
+	if typing {
+		body["timeout"] = timeout.Milliseconds()
+	}
+	return c.doJSON(ctx, http.MethodPut, path("/_matrix/client/v3/rooms", roomID, "typing", userID), body, nil)
+}
+
+// join accepts an invite
+func (c *client) join(ctx context.Context, roomID string) error {
+	return c.doJSON(ctx, http.MethodPost, path("/_matrix/client/v3/join", roomID), map[string]string{}, nil)
+}
+
+// leave rejects an invite or leaves a room
+func (c *client) leave(ctx context.Context, roomID string) error {
+	return c.doJSON(ctx, http.MethodPost, path("/_matrix/client/v3/rooms", roomID, "leave"), map[string]string{}, nil)
+}
+
+// joinedMemberCount returns how many users are in a room
+func (c *client) joinedMemberCount(ctx context.Context, roomID string) (int, error) {
+	var resp struct {
+		Joined map[string]json.RawMessage `json:"joined"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, path("/_matrix/client/v3/rooms", roomID, "joined_members"), nil, &resp); err != nil {
+		return 0, err
+	}
+	return len(resp.Joined), nil
+}
+
+// upload stores a local file in the content repository and returns its
+// mxc:// URI
+func (c *client) upload(ctx context.Context, filePath, contentType string) (string, int, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	var resp struct {
+		ContentURI string `json:"content_uri"`
+	}
+	query := url.Values{"filename": {filepath.Base(filePath)}}
+	if err := c.do(ctx, http.MethodPost, "/_matrix/media/v3/upload", query, contentType, data, &resp); err != nil {
+		return "", 0, err
+	}
+	return resp.ContentURI, len(data), nil
+}
+
+// download fetches an mxc:// URI. The authenticated media endpoint is tried
+// first, falling back to the legacy one for older homeservers.
+func (c *client) download(ctx context.Context, mxc string) (*http.Response, error) {
+	serverName, mediaID, ok := strings.Cut(strings.TrimPrefix(mxc, "mxc://"), "/")
+	if !ok || !strings.HasPrefix(mxc, "mxc://") {
+		return nil, fmt.Errorf("invalid media URI: %s", mxc)
+	}
+
+	var lastStatus int
+	for _, prefix := range []string{"/_matrix/client/v1/media/download", "/_matrix/media/v3/download"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.homeserver+path(prefix, serverName, mediaID), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create download request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("download failed: %w", err)
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		resp.Body.Close()
+		lastStatus = resp.StatusCode
+		if resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusBadRequest {
+			break
+		}
+	}
+	return nil, fmt.Errorf("download failed with status %d", lastStatus)
+}
@@ -0,0 +1,80 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// downloadMedia stores the file of an m.image, m.file, m.audio or m.video
+// message in the session's media directory
+func (a *Adapter) downloadMedia(ctx context.Context, sessionKey string, content map[string]interface{}) (*protocol.Attachment, error) {
+	if a.config.MediaDir == "" {
+		return nil, fmt.Errorf("media directory not configured")
+	}
+
+	uri, _ := content["url"].(string)
+	if uri == "" {
+		// Encrypted attachments carry a "file" object instead
+		return nil, fmt.Errorf("message has no unencrypted media URL")
+	}
+
+	maxBytes := int64(channels.DefaultMaxAttachmentBytes)
+	if a.config.MaxDownloadMB > 0 {
+		maxBytes = int64(a.config.MaxDownloadMB) * 1024 * 1024
+	}
+
+	var mediaType string
+	if info, ok := content["info"].(map[string]interface{}); ok {
+		mediaType, _ = info["mimetype"].(string)
+		if size, ok := info["size"].(float64); ok && int64(size) > maxBytes {
+			return nil, fmt.Errorf("file is %d bytes, limit is %d", int64(size), maxBytes)
+		}
+	}
+
+	name, _ := content["filename"].(string)
+	if name == "" {
+		name, _ = content["body"].(string)
+	}
+	if name == "" {
+		name = "file"
+	}
+
+	resp, err := a.api.download(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	path, size, sniffed, err := channels.SaveAttachment(a.config.MediaDir, sessionKey, name, resp.Body, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if mediaType == "" {
+		mediaType = sniffed
+	}
+
+	return &protocol.Attachment{
+		Type:      attachmentType(mediaType),
+		Path:      path,
+		MediaType: mediaType,
+		Filename:  filepath.Base(path),
+		Size:      size,
+	}, nil
+}
+
+// attachmentType classifies an attachment by media type
+func attachmentType(mediaType string) string {
+	switch {
+	case mediaType == "image/jpeg", mediaType == "image/png", mediaType == "image/gif", mediaType == "image/webp":
+		return protocol.AttachmentImage
+	case strings.HasPrefix(mediaType, "audio/"):
+		return protocol.AttachmentAudio
+	}
+	return protocol.AttachmentDocument
+}
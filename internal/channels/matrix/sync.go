@@ -0,0 +1,288 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// Reconnect backoff bounds for failed syncs
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// runSync long-polls /sync until ctx is cancelled, backing off exponentially
+// while the homeserver is unreachable
+func (a *Adapter) runSync(ctx context.Context) {
+	delay := minReconnectDelay
+	for {
+		a.mutex.RLock()
+		since := a.since
+		a.mutex.RUnlock()
+
+		resp, err := a.api.sync(ctx, since, a.config.SyncTimeout)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			status := channels.StatusReconnecting
+			if apiErr, ok := err.(*apiError); ok && apiErr.Code == "M_UNKNOWN_TOKEN" {
+				status = channels.StatusError
+			}
+			log.Printf("[Matrix] Sync failed: %v (retrying in %v)", err, delay)
+			a.setStatus(status, fmt.Sprintf("Sync failed: %v", err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay *= 2
+			if delay > maxReconnectDelay {
+				delay = maxReconnectDelay
+			}
+			continue
+		}
+
+		delay = minReconnectDelay
+		a.setStatus(channels.StatusOnline, "Syncing")
+		// Messages from before the adapter started are not answered
+		a.handleSync(ctx, resp, since == "")
+
+		a.mutex.Lock()
+		a.since = resp.NextBatch
+		a.mutex.Unlock()
+	}
+}
+
+// handleSync processes invites and new room events from one sync batch
+func (a *Adapter) handleSync(ctx context.Context, resp *syncResponse, initial bool) {
+	for roomID, room := range resp.Rooms.Invite {
+		a.handleInvite(ctx, roomID, room.InviteState.Events)
+	}
+
+	for roomID, room := range resp.Rooms.Join {
+		a.mutex.Lock()
+		if count := room.Summary.JoinedMemberCount; count != nil {
+			a.memberCounts[roomID] = *count
+		} else if _, known := a.memberCounts[roomID]; !known {
+			a.memberCounts[roomID] = 0 // Looked up when first needed
+		}
+		a.mutex.Unlock()
+
+		if initial {
+			continue
+		}
+		for i := range room.Timeline.Events {
+			a.handleEvent(ctx, roomID, &room.Timeline.Events[i])
+		}
+	}
+}
+
+// handleInvite accepts or rejects an invite according to auto_join
+func (a *Adapter) handleInvite(ctx context.Context, roomID string, events []event) {
+	var inviter string
+	for _, ev := range events {
+		if ev.Type == "m.room.member" && ev.StateKey != nil && *ev.StateKey == a.userID &&
+			ev.Content["membership"] == "invite" {
+			inviter = ev.Sender
+		}
+	}
+	if inviter == "" {
+		return
+	}
+
+	accept := false
+	switch a.config.AutoJoin {
+	case AutoJoinAll:
+		accept = true
+	case AutoJoinAllowed:
+		accept = a.userAllowed(inviter) && a.roomAllowed(roomID)
+	}
+
+	if !accept {
+		log.Printf("[Matrix] Rejecting invite to %s from %s", roomID, inviter)
+		if err := a.api.leave(ctx, roomID); err != nil {
+			log.Printf("[Matrix] Failed to reject invite to %s: %v", roomID, err)
+		}
+		return
+	}
+
+	log.Printf("[Matrix] Accepting invite to %s from %s", roomID, inviter)
+	if err := a.api.join(ctx, roomID); err != nil {
+		log.Printf("[Matrix] Failed to join %s: %v", roomID, err)
+	}
+}
+
+// handleEvent forwards messages from allowed users in direct chats, and
+// mentions in group rooms
+func (a *Adapter) handleEvent(ctx context.Context, roomID string, ev *event) {
+	if ev.Sender == a.userID {
+		return
+	}
+
+	switch ev.Type {
+	case "m.room.message":
+	case "m.room.encrypted":
+		a.mutex.Lock()
+		warned := a.encrypted[roomID]
+		a.encrypted[roomID] = true
+		a.mutex.Unlock()
+		if !warned {
+			log.Printf("[Matrix] Room %s is end-to-end encrypted, which is not supported; ignoring its messages", roomID)
+		}
+		return
+	default:
+		return
+	}
+
+	// Edits of earlier messages are not new requests
+	if relatesTo, ok := ev.Content["m.relates_to"].(map[string]interface{}); ok && relatesTo["rel_type"] == "m.replace" {
+		return
+	}
+
+	msgtype, _ := ev.Content["msgtype"].(string)
+	body, _ := ev.Content["body"].(string)
+	switch msgtype {
+	case "m.text", "m.emote", "m.image", "m.file", "m.audio", "m.video":
+	default:
+		// m.notice is for bots; answering them risks loops
+		return
+	}
+
+	if !a.roomAllowed(roomID) || !a.userAllowed(ev.Sender) {
+		log.Printf("[Matrix] Ignoring message from %s in %s (not allowed)", ev.Sender, roomID)
+		return
+	}
+
+	if a.config.RequireMention && !a.isDirect(ctx, roomID) {
+		if !a.mentioned(ev.Content, body) {
+			return
+		}
+	}
+
+	metadata := map[string]string{
+		"from_user_id": ev.Sender,
+		"event_id":     ev.EventID,
+		"room_id":      roomID,
+	}
+
+	key := sessionKey(roomID)
+	var text string
+	var attachments []protocol.Attachment
+	switch msgtype {
+	case "m.text", "m.emote":
+		text = a.stripMention(stripReplyFallback(ev.Content, body))
+		if msgtype == "m.emote" {
+			text = "* " + text
+		}
+	default:
+		text = fmt.Sprintf("[Attachment: %s]", body)
+		if attachment, err := a.downloadMedia(ctx, key, ev.Content); err != nil {
+			log.Printf("[Matrix] Failed to download media in %s: %v", roomID, err)
+			metadata["attachment_error"] = err.Error()
+		} else {
+			attachments = append(attachments, *attachment)
+			metadata["type"] = "attachment"
+		}
+	}
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	a.forward(&protocol.IncomingMessage{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeIncomingMessage,
+			ID:        a.generateMessageID(),
+			Timestamp: time.Now(),
+		},
+		ChannelID:   a.id,
+		SessionKey:  key,
+		UserID:      roomID,
+		Text:        text,
+		Attachments: attachments,
+		Metadata:    metadata,
+	})
+}
+
+// isDirect reports whether a room has at most two members
+func (a *Adapter) isDirect(ctx context.Context, roomID string) bool {
+	a.mutex.RLock()
+	count := a.memberCounts[roomID]
+	a.mutex.RUnlock()
+
+	if count == 0 {
+		n, err := a.api.joinedMemberCount(ctx, roomID)
+		if err != nil {
+			log.Printf("[Matrix] Failed to get members of %s: %v", roomID, err)
+			return false
+		}
+		a.mutex.Lock()
+		a.memberCounts[roomID] = n
+		a.mutex.Unlock()
+		count = n
+	}
+	return count <= 2
+}
+
+// localpart returns the name part of the bot's user ID
+func (a *Adapter) localpart() string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(a.userID, "@"), ":")
+	return name
+}
+
+// mentioned reports whether a message mentions the bot, through intentional
+// mentions or its user ID or name in the text
+func (a *Adapter) mentioned(content map[string]interface{}, body string) bool {
+	if mentions, ok := content["m.mentions"].(map[string]interface{}); ok {
+		if ids, ok := mentions["user_ids"].([]interface{}); ok {
+			for _, id := range ids {
+				if id == a.userID {
+					return true
+				}
+			}
+		}
+	}
+	lower := strings.ToLower(body)
+	return strings.Contains(lower, strings.ToLower(a.userID)) ||
+		strings.Contains(lower, strings.ToLower(a.localpart()))
+}
+
+// stripMention removes a leading "bot: " or user ID mention
+func (a *Adapter) stripMention(text string) string {
+	trimmed := strings.TrimSpace(text)
+	for _, prefix := range []string{a.userID, a.localpart(), "@" + a.localpart()} {
+		if len(trimmed) >= len(prefix) && strings.EqualFold(trimmed[:len(prefix)], prefix) {
+			rest := strings.TrimLeft(trimmed[len(prefix):], ":, ")
+			if rest != "" {
+				return rest
+			}
+		}
+	}
+	return trimmed
+}
+
+// stripReplyFallback removes the quoted original that older clients put at
+// the start of replies
+func stripReplyFallback(content map[string]interface{}, body string) string {
+	relatesTo, _ := content["m.relates_to"].(map[string]interface{})
+	if _, isReply := relatesTo["m.in_reply_to"]; !isReply || !strings.HasPrefix(body, "> ") {
+		return body
+	}
+
+	lines := strings.Split(body, "\n")
+	i := 0
+	for i < len(lines) && strings.HasPrefix(lines[i], ">") {
+		i++
+	}
+	if i < len(lines) && lines[i] == "" {
+		i++
+	}
+	return strings.Join(lines[i:], "\n")
+}
@@ -0,0 +1,80 @@
+package channels
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultMaxAttachmentBytes caps attachment downloads when an adapter has no
+// explicit limit (matches the Telegram Bot API download limit)
+const DefaultMaxAttachmentBytes = 20 * 1024 * 1024
+
+// unsafePathChars matches characters not allowed in media directory and file names
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SessionMediaDir returns the per-session attachment directory under root
+func SessionMediaDir(root, sessionKey string) string {
+	return filepath.Join(root, sanitizePathComponent(sessionKey))
+}
+
+// SaveAttachment copies r into the session's media directory under filename,
+// refusing files larger than maxBytes. It returns the stored path, the number
+// of bytes written and the media type sniffed from the file's first bytes.
+func SaveAttachment(root, sessionKey, filename string, r io.Reader, maxBytes int64) (string, int64, string, error) {
+	if root == "" {
+		return "", 0, "", fmt.Errorf("no media directory configured")
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxAttachmentBytes
+	}
+
+	dir := SessionMediaDir(root, sessionKey)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", 0, "", fmt.Errorf("failed to create media directory: %w", err)
+	}
+
+	name := sanitizePathComponent(filename)
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("failed to create %s: %w", name, err)
+	}
+
+	// Read one byte past the limit so oversized files are detected, not truncated
+	var head [512]byte
+	limited := io.LimitReader(r, maxBytes+1)
+	n, _ := io.ReadFull(limited, head[:])
+	written, err := f.Write(head[:n])
+	if err == nil {
+		var rest int64
+		rest, err = io.Copy(f, limited)
+		written += int(rest)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && int64(written) > maxBytes {
+		err = fmt.Errorf("attachment exceeds %d byte limit", maxBytes)
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", 0, "", fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	return path, int64(written), http.DetectContentType(head[:n]), nil
+}
+
+// sanitizePathComponent makes s safe to use as a single path element
+func sanitizePathComponent(s string) string {
+	s = unsafePathChars.ReplaceAllString(filepath.Base(s), "_")
+	s = strings.Trim(s, "._")
+	if s == "" {
+		return "file"
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+package channels
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAttachment(t *testing.T) {
+	root := t.TempDir()
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("x", 32)
+
+	path, size, mediaType, err := SaveAttachment(root, "telegram_42", "photo.png", strings.NewReader(png), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(root, "telegram_42", "photo.png"), path)
+	assert.Equal(t, int64(len(png)), size)
+	assert.Equal(t, "image/png", mediaType)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, png, string(data))
+}
+
+func TestSaveAttachment_TooLarge(t *testing.T) {
+	root := t.TempDir()
+
+	_, _, _, err := SaveAttachment(root, "s", "big.bin", strings.NewReader(strings.Repeat("a", 11)), 10)
+	require.Error(t, err)
+
+	_, statErr := os.Stat(filepath.Join(root, "s", "big.bin"))
+	assert.True(t, os.IsNotExist(statErr), "partial file should be removed")
+}
+
+func TestSaveAttachment_SanitizesNames(t *testing.T) {
+	root := t.TempDir()
+
+	path, _, _, err := SaveAttachment(root, "../escape", "../../etc/pass wd", strings.NewReader("hi"), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(root, "escape", "pass_wd"), path)
+}
@@ -0,0 +1,366 @@
+package channels
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"conduit/pkg/protocol"
+)
+
+// Outbox entry states
+const (
+	OutboxPending   = "pending"   // Waiting for its next delivery attempt
+	OutboxDelivered = "delivered" // Accepted by the adapter
+	OutboxDead      = "dead"      // Gave up after too many failed attempts
+)
+
+// Outbox defaults, used when OutboxOptions leaves a field zero
+const (
+	DefaultOutboxMaxAttempts = 8
+	DefaultOutboxBackoff     = 5 * time.Second
+	DefaultOutboxMaxBackoff  = 10 * time.Minute
+	DefaultOutboxRetention   = 24 * time.Hour
+)
+
+// RateLimitError reports that a platform asked the adapter to slow down.
+// The outbox holds the adapter's messages for RetryAfter instead of
+// counting the failure as an attempt.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited for %v: %v", e.RetryAfter, e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns the delay requested by a rate limited send
+func RetryAfter(err error) (time.Duration, bool) {
+	var limited *RateLimitError
+	if errors.As(err, &limited) {
+		return limited.RetryAfter, true
+	}
+	return 0, false
+}
+
+// OutboxOptions controls retries of outgoing messages
+type OutboxOptions struct {
+	MaxAttempts int           // Failed attempts before a message is dead-lettered
+	Backoff     time.Duration // Delay before the first retry, doubled after each failure
+	MaxBackoff  time.Duration // Upper bound on the retry delay
+	Retention   time.Duration // How long delivered messages are kept
+}
+
+// OutboxEntry is a persisted outgoing message
+type OutboxEntry struct {
+	ID            int64                     `json:"id"`
+	ChannelID     string                    `json:"channel_id"`
+	UserID        string                    `json:"user_id"`
+	SessionKey    string                    `json:"session_key,omitempty"`
+	Message       *protocol.OutgoingMessage `json:"message"`
+	Status        string                    `json:"status"`
+	Attempts      int                       `json:"attempts"`
+	NextAttemptAt time.Time                 `json:"next_attempt_at"`
+	LastError     string                    `json:"last_error,omitempty"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	UpdatedAt     time.Time                 `json:"updated_at"`
+}
+
+// OutboxFilter selects entries for List
+type OutboxFilter struct {
+	Status    string
+	ChannelID string
+	Limit     int
+}
+
+// Outbox stores outgoing messages in SQLite so they survive failed sends
+// and restarts. Delivery is at least once: a message whose send succeeded
+// just before a crash is sent again on the next start.
+type Outbox struct {
+	db   *sql.DB
+	opts OutboxOptions
+}
+
+// NewOutbox creates an outbox over a database migrated with the
+// channel_outbox table
+func NewOutbox(db *sql.DB, opts OutboxOptions) *Outbox {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultOutboxMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultOutboxBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultOutboxMaxBackoff
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = opts.Backoff
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultOutboxRetention
+	}
+	return &Outbox{db: db, opts: opts}
+}
+
+// Options returns the effective retry options
+func (o *Outbox) Options() OutboxOptions {
+	return o.opts
+}
+
+const outboxColumns = `id, channel_id, user_id, session_key, message, status, attempts,
+	next_attempt_at, last_error, created_at, updated_at`
+
+// Enqueue persists a message for immediate delivery
+func (o *Outbox) Enqueue(msg *protocol.OutgoingMessage) (int64, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	now := time.Now().UTC()
+	result, err := o.db.Exec(`
+		INSERT INTO channel_outbox (channel_id, user_id, session_key, message, status, next_attempt_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, msg.ChannelID, msg.UserID, msg.SessionKey, string(data), OutboxPending, now, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue message: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// Due returns pending entries whose next attempt is at or before now,
+// oldest first, skipping the given channels
+func (o *Outbox) Due(now time.Time, skipChannels []string, limit int) ([]*OutboxEntry, error) {
+	query := `SELECT ` + outboxColumns + ` FROM channel_outbox WHERE status = ? AND next_attempt_at <= ?`
+	args := []interface{}{OutboxPending, now.UTC()}
+	if len(skipChannels) > 0 {
+		query += ` AND channel_id NOT IN (?` + strings.Repeat(", ?", len(skipChannels)-1) + `)`
+		for _, id := range skipChannels {
+			args = append(args, id)
+		}
+	}
+	query += ` ORDER BY id LIMIT ?`
+	args = append(args, limit)
+
+	return o.query(query, args...)
+}
+
+// MarkDelivered records a successful send
+func (o *Outbox) MarkDelivered(id int64) error {
+	_, err := o.db.Exec(`
+		UPDATE channel_outbox SET status = ?, attempts = attempts + 1, last_error = '', updated_at = ?
+		WHERE id = ?
+	`, OutboxDelivered, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark message %d delivered: %w", id, err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed send. The entry is retried after an
+// exponential backoff, or dead-lettered once it has used all its attempts.
+// Later messages to the same user wait for the retry so they are not
+// delivered out of order. It returns the entry's new status.
+func (o *Outbox) MarkFailed(entry *OutboxEntry, sendErr error) (string, error) {
+	attempts := entry.Attempts + 1
+	status := OutboxPending
+	if attempts >= o.opts.MaxAttempts {
+		status = OutboxDead
+	}
+
+	now := time.Now().UTC()
+	next := now.Add(o.backoff(attempts))
+	tx, err := o.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("failed to record failure of message %d: %w", entry.ID, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE channel_outbox SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`, status, attempts, next, sendErr.Error(), now, entry.ID); err != nil {
+		return "", fmt.Errorf("failed to record failure of message %d: %w", entry.ID, err)
+	}
+	if status == OutboxPending {
+		if _, err := tx.Exec(`
+			UPDATE channel_outbox SET next_attempt_at = ?, updated_at = ?
+			WHERE status = ? AND channel_id = ? AND user_id = ? AND id > ? AND next_attempt_at < ?
+		`, next, now, OutboxPending, entry.ChannelID, entry.UserID, entry.ID, next); err != nil {
+			return "", fmt.Errorf("failed to hold messages after %d: %w", entry.ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to record failure of message %d: %w", entry.ID, err)
+	}
+
+	entry.Attempts = attempts
+	entry.Status = status
+	entry.NextAttemptAt = next
+	entry.LastError = sendErr.Error()
+	return status, nil
+}
+
+// Postpone holds all pending messages of a channel until the given time
+// without using any of their attempts, for platforms that rate limit sends
+func (o *Outbox) Postpone(channelID string, until time.Time, reason error) error {
+	_, err := o.db.Exec(`
+		UPDATE channel_outbox SET next_attempt_at = ?, last_error = ?, updated_at = ?
+		WHERE status = ? AND channel_id = ? AND next_attempt_at < ?
+	`, until.UTC(), reason.Error(), time.Now().UTC(), OutboxPending, channelID, until.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to postpone messages for %s: %w", channelID, err)
+	}
+	return nil
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.opts.Backoff
+	for i := 1; i < attempts && delay < o.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > o.opts.MaxBackoff {
+		delay = o.opts.MaxBackoff
+	}
+	return delay
+}
+
+// Get returns a single entry
+func (o *Outbox) Get(id int64) (*OutboxEntry, error) {
+	entries, err := o.query(`SELECT `+outboxColumns+` FROM channel_outbox WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("outbox message not found: %d", id)
+	}
+	return entries[0], nil
+}
+
+// List returns entries matching the filter, newest first
+func (o *Outbox) List(filter OutboxFilter) ([]*OutboxEntry, error) {
+	query := `SELECT ` + outboxColumns + ` FROM channel_outbox WHERE 1 = 1`
+	var args []interface{}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	if filter.ChannelID != "" {
+		query += ` AND channel_id = ?`
+		args = append(args, filter.ChannelID)
+	}
+	query += ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+	return o.query(query, args...)
+}
+
+// Counts returns the number of entries in each state
+func (o *Outbox) Counts() (map[string]int, error) {
+	rows, err := o.db.Query(`SELECT status, COUNT(*) FROM channel_outbox GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count outbox: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{OutboxPending: 0, OutboxDelivered: 0, OutboxDead: 0}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox count: %w", err)
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+// Replay queues a dead or delivered entry for delivery again with a fresh
+// set of attempts
+func (o *Outbox) Replay(id int64) error {
+	now := time.Now().UTC()
+	result, err := o.db.Exec(`
+		UPDATE channel_outbox SET status = ?, attempts = 0, next_attempt_at = ?, last_error = '', updated_at = ?
+		WHERE id = ?
+	`, OutboxPending, now, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to replay message %d: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("outbox message not found: %d", id)
+	}
+	return nil
+}
+
+// ReplayDead queues all dead-lettered entries again, optionally only those
+// of one channel, and returns how many were queued
+func (o *Outbox) ReplayDead(channelID string) (int64, error) {
+	now := time.Now().UTC()
+	query := `UPDATE channel_outbox SET status = ?, attempts = 0, next_attempt_at = ?, last_error = '', updated_at = ? WHERE status = ?`
+	args := []interface{}{OutboxPending, now, now, OutboxDead}
+	if channelID != "" {
+		query += ` AND channel_id = ?`
+		args = append(args, channelID)
+	}
+
+	result, err := o.db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to replay dead messages: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Purge deletes entries in the given state last updated before the cutoff
+// and returns how many were deleted
+func (o *Outbox) Purge(status string, before time.Time) (int64, error) {
+	result, err := o.db.Exec(`DELETE FROM channel_outbox WHERE status = ? AND updated_at < ?`, status, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// PurgeDelivered deletes delivered entries older than the retention period
+func (o *Outbox) PurgeDelivered() (int64, error) {
+	return o.Purge(OutboxDelivered, time.Now().Add(-o.opts.Retention))
+}
+
+// query runs a select over outboxColumns and decodes the rows
+func (o *Outbox) query(query string, args ...interface{}) ([]*OutboxEntry, error) {
+	rows, err := o.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*OutboxEntry
+	for rows.Next() {
+		var e OutboxEntry
+		var sessionKey, lastError sql.NullString
+		var data string
+		if err := rows.Scan(&e.ID, &e.ChannelID, &e.UserID, &sessionKey, &data, &e.Status, &e.Attempts,
+			&e.NextAttemptAt, &lastError, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox row: %w", err)
+		}
+		e.SessionKey = sessionKey.String
+		e.LastError = lastError.String
+
+		e.Message = &protocol.OutgoingMessage{}
+		if err := json.Unmarshal([]byte(data), e.Message); err != nil {
+			return nil, fmt.Errorf("failed to decode outbox message %d: %w", e.ID, err)
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,275 @@
+package channels
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"conduit/internal/database"
+	"conduit/pkg/protocol"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestOutbox(t *testing.T, opts OutboxOptions) *Outbox {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "outbox.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.ConfigureDatabase(db))
+	return NewOutbox(db, opts)
+}
+
+func outgoing(channelID, userID, text string) *protocol.OutgoingMessage {
+	return &protocol.OutgoingMessage{
+		BaseMessage: protocol.BaseMessage{Type: protocol.TypeOutgoingMessage, ID: text},
+		ChannelID:   channelID,
+		UserID:      userID,
+		Text:        text,
+		Metadata:    map[string]string{"subject": "test"},
+	}
+}
+
+func TestOutbox_EnqueueAndDeliver(t *testing.T) {
+	outbox := newTestOutbox(t, OutboxOptions{})
+
+	id, err := outbox.Enqueue(outgoing("telegram", "42", "hello"))
+	require.NoError(t, err)
+
+	due, err := outbox.Due(time.Now(), nil, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, id, due[0].ID)
+	assert.Equal(t, "hello", due[0].Message.Text)
+	assert.Equal(t, "test", due[0].Message.Metadata["subject"])
+	assert.Equal(t, OutboxPending, due[0].Status)
+
+	skipped, err := outbox.Due(time.Now(), []string{"telegram"}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+
+	require.NoError(t, outbox.MarkDelivered(id))
+	entry, err := outbox.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, OutboxDelivered, entry.Status)
+	assert.Equal(t, 1, entry.Attempts)
+
+	counts, err := outbox.Counts()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{OutboxPending: 0, OutboxDelivered: 1, OutboxDead: 0}, counts)
+}
+
+func TestOutbox_BackoffAndDeadLetter(t *testing.T) {
+	outbox := newTestOutbox(t, OutboxOptions{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: 90 * time.Second})
+
+	first, err := outbox.Enqueue(outgoing("telegram", "42", "first"))
+	require.NoError(t, err)
+	second, err := outbox.Enqueue(outgoing("telegram", "42", "second"))
+	require.NoError(t, err)
+	other, err := outbox.Enqueue(outgoing("telegram", "7", "other user"))
+	require.NoError(t, err)
+
+	entry, err := outbox.Get(first)
+	require.NoError(t, err)
+	status, err := outbox.MarkFailed(entry, errors.New("bad gateway"))
+	require.NoError(t, err)
+	assert.Equal(t, OutboxPending, status)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), entry.NextAttemptAt, 5*time.Second)
+
+	// The later message to the same user waits; other users are unaffected
+	due, err := outbox.Due(time.Now(), nil, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, other, due[0].ID)
+
+	held, err := outbox.Get(second)
+	require.NoError(t, err)
+	assert.False(t, held.NextAttemptAt.Before(entry.NextAttemptAt))
+
+	status, err = outbox.MarkFailed(entry, errors.New("bad gateway"))
+	require.NoError(t, err)
+	assert.Equal(t, OutboxPending, status)
+	assert.WithinDuration(t, time.Now().Add(90*time.Second), entry.NextAttemptAt, 5*time.Second)
+
+	status, err = outbox.MarkFailed(entry, errors.New("bad gateway"))
+	require.NoError(t, err)
+	assert.Equal(t, OutboxDead, status)
+
+	dead, err := outbox.List(OutboxFilter{Status: OutboxDead})
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, first, dead[0].ID)
+	assert.Equal(t, 3, dead[0].Attempts)
+	assert.Equal(t, "bad gateway", dead[0].LastError)
+}
+
+func TestOutbox_Postpone(t *testing.T) {
+	outbox := newTestOutbox(t, OutboxOptions{})
+
+	_, err := outbox.Enqueue(outgoing("telegram", "42", "one"))
+	require.NoError(t, err)
+	_, err = outbox.Enqueue(outgoing("telegram", "7", "two"))
+	require.NoError(t, err)
+	slack, err := outbox.Enqueue(outgoing("slack", "C1", "three"))
+	require.NoError(t, err)
+
+	require.NoError(t, outbox.Postpone("telegram", time.Now().Add(time.Hour), errors.New("flood control")))
+
+	due, err := outbox.Due(time.Now(), nil, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, slack, due[0].ID)
+
+	later, err := outbox.Due(time.Now().Add(2*time.Hour), nil, 10)
+	require.NoError(t, err)
+	require.Len(t, later, 3)
+	assert.Equal(t, 0, later[0].Attempts)
+	assert.Equal(t, "flood control", later[0].LastError)
+}
+
+func TestOutbox_ReplayAndPurge(t *testing.T) {
+	outbox := newTestOutbox(t, OutboxOptions{MaxAttempts: 1})
+
+	var ids []int64
+	for _, channelID := range []string{"telegram", "telegram", "slack"} {
+		id, err := outbox.Enqueue(outgoing(channelID, "42", "hello"))
+		require.NoError(t, err)
+		entry, err := outbox.Get(id)
+		require.NoError(t, err)
+		_, err = outbox.MarkFailed(entry, errors.New("down"))
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	require.NoError(t, outbox.Replay(ids[0]))
+	entry, err := outbox.Get(ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, OutboxPending, entry.Status)
+	assert.Equal(t, 0, entry.Attempts)
+	assert.Empty(t, entry.LastError)
+
+	assert.Error(t, outbox.Replay(9999))
+
+	n, err := outbox.ReplayDead("slack")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	n, err = outbox.Purge(OutboxDead, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = outbox.Get(ids[1])
+	assert.Error(t, err)
+
+	require.NoError(t, outbox.MarkDelivered(ids[2]))
+	n, err = outbox.PurgeDelivered()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n, "delivered messages are kept for the retention period")
+}
+
+// flakyAdapter fails its first sends, then records messages
+type flakyAdapter struct {
+	mu       sync.Mutex
+	failures []error
+	sent     []string
+}
+
+func (a *flakyAdapter) ID() string                                        { return "flaky" }
+func (a *flakyAdapter) Name() string                                      { return "Flaky" }
+func (a *flakyAdapter) Type() string                                      { return "flaky" }
+func (a *flakyAdapter) Start(ctx context.Context) error                   { return nil }
+func (a *flakyAdapter) Stop() error                                       { return nil }
+func (a *flakyAdapter) ReceiveMessages() <-chan *protocol.IncomingMessage { return nil }
+func (a *flakyAdapter) Status() ChannelStatus                             { return ChannelStatus{Status: StatusOnline} }
+func (a *flakyAdapter) IsHealthy() bool                                   { return true }
+
+func (a *flakyAdapter) SendMessage(msg *protocol.OutgoingMessage) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.failures) > 0 {
+		err := a.failures[0]
+		a.failures = a.failures[1:]
+		return err
+	}
+	a.sent = append(a.sent, msg.Text)
+	return nil
+}
+
+func (a *flakyAdapter) Sent() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.sent...)
+}
+
+func startOutboxManager(t *testing.T, outbox *Outbox, adapter ChannelAdapter) *Manager {
+	t.Helper()
+	m := NewManager()
+	m.SetOutbox(outbox)
+	require.NoError(t, m.Start(context.Background(), nil))
+	m.mutex.Lock()
+	m.adapters[adapter.ID()] = adapter
+	m.mutex.Unlock()
+	t.Cleanup(func() { m.Stop() })
+	return m
+}
+
+func TestManager_OutboxRetriesFailedSends(t *testing.T) {
+	outbox := newTestOutbox(t, OutboxOptions{Backoff: 10 * time.Millisecond})
+	adapter := &flakyAdapter{failures: []error{errors.New("502 Bad Gateway"), errors.New("502 Bad Gateway")}}
+	m := startOutboxManager(t, outbox, adapter)
+
+	require.NoError(t, m.SendMessage(outgoing("flaky", "42", "first [[reply_to:9]]")))
+	require.NoError(t, m.SendMessage(outgoing("flaky", "42", "second")))
+
+	require.Eventually(t, func() bool { return len(adapter.Sent()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"first", "second"}, adapter.Sent())
+
+	delivered, err := outbox.List(OutboxFilter{Status: OutboxDelivered})
+	require.NoError(t, err)
+	require.Len(t, delivered, 2)
+	assert.Equal(t, 3, delivered[1].Attempts)
+}
+
+func TestManager_OutboxDeadLetters(t *testing.T) {
+	outbox := newTestOutbox(t, OutboxOptions{MaxAttempts: 2, Backoff: 10 * time.Millisecond})
+	adapter := &flakyAdapter{failures: []error{errors.New("chat not found"), errors.New("chat not found")}}
+	m := startOutboxManager(t, outbox, adapter)
+
+	require.NoError(t, m.SendMessage(outgoing("flaky", "42", "lost")))
+	require.NoError(t, m.SendMessage(outgoing("missing", "42", "nowhere")))
+
+	require.Eventually(t, func() bool {
+		counts, err := outbox.Counts()
+		return err == nil && counts[OutboxDead] == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, adapter.Sent())
+
+	dead, err := outbox.List(OutboxFilter{Status: OutboxDead, ChannelID: "missing"})
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Contains(t, dead[0].LastError, "no adapter found")
+}
+
+func TestManager_OutboxHonoursRateLimits(t *testing.T) {
+	outbox := newTestOutbox(t, OutboxOptions{MaxAttempts: 1})
+	limited := &RateLimitError{RetryAfter: 200 * time.Millisecond, Err: errors.New("too many requests")}
+	adapter := &flakyAdapter{failures: []error{limited}}
+	m := startOutboxManager(t, outbox, adapter)
+
+	start := time.Now()
+	require.NoError(t, m.SendMessage(outgoing("flaky", "42", "patient")))
+
+	require.Eventually(t, func() bool { return len(adapter.Sent()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	// A rate limit is not a failed attempt, so MaxAttempts 1 did not dead-letter it
+	counts, err := outbox.Counts()
+	require.NoError(t, err)
+	assert.Equal(t, 1, counts[OutboxDelivered])
+}
@@ -0,0 +1,336 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// Defaults for optional settings
+const (
+	defaultStartupTimeout  = 10 * time.Second
+	defaultHealthInterval  = 30 * time.Second
+	defaultRestartBackoff  = time.Second
+	defaultMaxBackoff      = time.Minute
+	defaultShutdownTimeout = 5 * time.Second
+)
+
+// Adapter implements the ChannelAdapter interface by running an external
+// executable that speaks the process adapter protocol (see pkg/protocol) on
+// stdin/stdout. The process is restarted with backoff when it exits.
+type Adapter struct {
+	id        string
+	name      string
+	config    ProcessConfig
+	status    channels.StatusCode
+	statusMsg string
+	incoming  chan *protocol.IncomingMessage
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{} // Closed when the supervisor exits
+	mutex     sync.RWMutex
+	startTime time.Time
+	msgCount  int64
+
+	// Current process, guarded by mutex; stdin writes by writeMu
+	writeMu  sync.Mutex
+	stdin    io.WriteCloser
+	pid      int
+	lastSeen time.Time
+
+	restarts int
+	lastExit string
+	reported map[string]interface{} // Details from the last status line
+}
+
+// ProcessConfig contains process adapter configuration
+type ProcessConfig struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	Dir     string            `json:"dir"`
+
+	// AdapterConfig is passed to the process in the hello command
+	AdapterConfig map[string]interface{} `json:"config"`
+
+	StartupTimeout  time.Duration `json:"-"` // startup_timeout_seconds
+	HealthInterval  time.Duration `json:"-"` // health_interval_seconds
+	RestartBackoff  time.Duration `json:"-"` // restart_backoff_ms
+	MaxBackoff      time.Duration `json:"-"` // max_backoff_seconds
+	ShutdownTimeout time.Duration `json:"-"` // shutdown_timeout_seconds
+}
+
+// Factory creates process channel adapters
+type Factory struct{}
+
+// NewFactory creates a new process adapter factory
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// SupportsType returns whether this factory supports the given adapter type
+func (f *Factory) SupportsType(adapterType string) bool {
+	return adapterType == "process"
+}
+
+// GetSupportedTypes returns the adapter types this factory supports
+func (f *Factory) GetSupportedTypes() []string {
+	return []string{"process"}
+}
+
+// CreateAdapter creates a new process adapter instance
+func (f *Factory) CreateAdapter(config channels.ChannelConfig) (channels.ChannelAdapter, error) {
+	processConfig := ProcessConfig{
+		StartupTimeout:  defaultStartupTimeout,
+		HealthInterval:  defaultHealthInterval,
+		RestartBackoff:  defaultRestartBackoff,
+		MaxBackoff:      defaultMaxBackoff,
+		ShutdownTimeout: defaultShutdownTimeout,
+	}
+
+	if command, ok := config.Config["command"].(string); ok && command != "" {
+		processConfig.Command = command
+	} else {
+		return nil, fmt.Errorf("command is required for process adapter")
+	}
+
+	if args, ok := config.Config["args"].([]interface{}); ok {
+		for _, arg := range args {
+			s, ok := arg.(string)
+			if !ok {
+				return nil, fmt.Errorf("args must be strings")
+			}
+			processConfig.Args = append(processConfig.Args, s)
+		}
+	}
+
+	if env, ok := config.Config["env"].(map[string]interface{}); ok {
+		processConfig.Env = make(map[string]string)
+		for k, v := range env {
+			processConfig.Env[k] = fmt.Sprint(v)
+		}
+	}
+
+	if dir, ok := config.Config["dir"].(string); ok {
+		processConfig.Dir = dir
+	}
+
+	if adapterConfig, ok := config.Config["config"].(map[string]interface{}); ok {
+		processConfig.AdapterConfig = adapterConfig
+	}
+
+	durations := []struct {
+		key  string
+		unit time.Duration
+		dest *time.Duration
+	}{
+		{"startup_timeout_seconds", time.Second, &processConfig.StartupTimeout},
+		{"health_interval_seconds", time.Second, &processConfig.HealthInterval},
+		{"restart_backoff_ms", time.Millisecond, &processConfig.RestartBackoff},
+		{"max_backoff_seconds", time.Second, &processConfig.MaxBackoff},
+		{"shutdown_timeout_seconds", time.Second, &processConfig.ShutdownTimeout},
+	}
+	for _, d := range durations {
+		if v, ok := config.Config[d.key].(float64); ok && v > 0 {
+			*d.dest = time.Duration(v * float64(d.unit))
+		}
+	}
+
+	return &Adapter{
+		id:       config.ID,
+		name:     config.Name,
+		config:   processConfig,
+		status:   channels.StatusInitializing,
+		incoming: make(chan *protocol.IncomingMessage, 100),
+	}, nil
+}
+
+// ID returns the adapter's unique identifier
+func (a *Adapter) ID() string {
+	return a.id
+}
+
+// Name returns the adapter's human-readable name
+func (a *Adapter) Name() string {
+	return a.name
+}
+
+// Type returns the adapter type
+func (a *Adapter) Type() string {
+	return "process"
+}
+
+// Start launches the process and keeps it running until Stop
+func (a *Adapter) Start(ctx context.Context) error {
+	// Fail fast on a missing executable rather than restarting forever
+	if _, err := exec.LookPath(a.config.Command); err != nil {
+		return fmt.Errorf("process adapter command not found: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.ctx, a.cancel = context.WithCancel(ctx)
+	a.done = make(chan struct{})
+	a.startTime = time.Now()
+	a.status = channels.StatusInitializing
+	a.statusMsg = "Starting process"
+	a.mutex.Unlock()
+
+	go a.supervise(a.ctx)
+
+	log.Printf("[Process] Adapter started: %s (%s)", a.Name(), a.config.Command)
+	return nil
+}
+
+// Stop asks the process to shut down, killing it after the shutdown timeout
+func (a *Adapter) Stop() error {
+	a.mutex.Lock()
+	if a.cancel != nil {
+		a.cancel()
+	}
+	done := a.done
+	a.mutex.Unlock()
+
+	// The supervisor stops the process and its reader before we close incoming
+	if done != nil {
+		<-done
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.status = channels.StatusOffline
+	a.statusMsg = "Adapter stopped"
+
+	// Close incoming message channel
+	close(a.incoming)
+
+	log.Printf("[Process] Adapter stopped: %s", a.Name())
+	return nil
+}
+
+// SendMessage writes an outgoing message to the process
+func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
+	out := *msg
+	out.Type = protocol.TypeOutgoingMessage
+	if out.Timestamp.IsZero() {
+		out.Timestamp = time.Now()
+	}
+	if err := a.writeLine(out); err != nil {
+		return fmt.Errorf("failed to send message to process: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.msgCount++
+	a.mutex.Unlock()
+	return nil
+}
+
+// sendCommand writes a protocol command to the process
+func (a *Adapter) sendCommand(command string, args map[string]interface{}) error {
+	return a.writeLine(protocol.ChannelCommand{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeChannelCommand,
+			ID:        a.generateMessageID(),
+			Timestamp: time.Now(),
+		},
+		ChannelID: a.id,
+		Command:   command,
+		Args:      args,
+	})
+}
+
+// writeLine encodes v as a single line on the process's stdin
+func (a *Adapter) writeLine(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.RLock()
+	stdin := a.stdin
+	a.mutex.RUnlock()
+	if stdin == nil {
+		return fmt.Errorf("process not running")
+	}
+
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+	_, err = stdin.Write(append(data, '\n'))
+	return err
+}
+
+// ReceiveMessages returns the channel for incoming messages
+func (a *Adapter) ReceiveMessages() <-chan *protocol.IncomingMessage {
+	return a.incoming
+}
+
+// Status returns the current adapter status, including the details last
+// reported by the process
+func (a *Adapter) Status() channels.ChannelStatus {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	details := map[string]interface{}{
+		"uptime_seconds":   time.Since(a.startTime).Seconds(),
+		"message_count":    a.msgCount,
+		"restarts":         a.restarts,
+		"command":          a.config.Command,
+		"protocol_version": protocol.ProcessProtocolVersion,
+	}
+	if a.pid != 0 {
+		details["pid"] = a.pid
+		details["last_seen"] = a.lastSeen
+	}
+	if a.lastExit != "" {
+		details["last_exit"] = a.lastExit
+	}
+	if len(a.reported) > 0 {
+		details["adapter"] = a.reported
+	}
+
+	return channels.ChannelStatus{
+		Status:    a.status,
+		Message:   a.statusMsg,
+		Details:   details,
+		Timestamp: time.Now(),
+	}
+}
+
+// IsHealthy returns whether the process is running and reports itself online
+func (a *Adapter) IsHealthy() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.status == channels.StatusOnline
+}
+
+// generateMessageID creates a unique message ID
+func (a *Adapter) generateMessageID() string {
+	return fmt.Sprintf("process_%s_%s", a.id, uuid.New().String()[:8])
+}
+
+// environ returns the configured environment additions in a stable order
+func (c *ProcessConfig) environ() []string {
+	keys := make([]string, 0, len(c.Env))
+	for k := range c.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(keys))
+	for _, k := range keys {
+		env = append(env, k+"="+c.Env[k])
+	}
+	return env
+}
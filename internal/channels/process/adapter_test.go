@@ -0,0 +1,207 @@
+package process
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"conduit/internal/channels"
+	"conduit/internal/channels/process/echo"
+	"conduit/pkg/protocol"
+)
+
+// The test binary doubles as the reference echo adapter when re-executed
+// with CONDUIT_ECHO_ADAPTER set
+func TestMain(m *testing.M) {
+	if os.Getenv("CONDUIT_ECHO_ADAPTER") == "1" {
+		if err := echo.Run(os.Stdin, os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func echoConfig(extra map[string]interface{}) map[string]interface{} {
+	cfg := map[string]interface{}{
+		"command":            os.Args[0],
+		"env":                map[string]interface{}{"CONDUIT_ECHO_ADAPTER": "1"},
+		"restart_backoff_ms": float64(10),
+		"config":             map[string]interface{}{"greeting": "hi"},
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	return cfg
+}
+
+func startTestAdapter(t *testing.T, cfg map[string]interface{}) *Adapter {
+	adapter, err := NewFactory().CreateAdapter(channels.ChannelConfig{
+		ID: "echo", Type: "process", Name: "Echo", Enabled: true, Config: cfg,
+	})
+	require.NoError(t, err)
+	a := adapter.(*Adapter)
+	require.NoError(t, a.Start(context.Background()))
+	t.Cleanup(func() { a.Stop() })
+	return a
+}
+
+func receive(t *testing.T, a *Adapter) *protocol.IncomingMessage {
+	select {
+	case msg := <-a.ReceiveMessages():
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for incoming message")
+		return nil
+	}
+}
+
+func TestFactory_CreateAdapter(t *testing.T) {
+	f := NewFactory()
+	assert.True(t, f.SupportsType("process"))
+
+	_, err := f.CreateAdapter(channels.ChannelConfig{ID: "p", Config: map[string]interface{}{}})
+	assert.Error(t, err, "command is required")
+
+	adapter, err := f.CreateAdapter(channels.ChannelConfig{ID: "p", Config: map[string]interface{}{
+		"command": "node", "args": []interface{}{"adapter.js"}, "health_interval_seconds": float64(5),
+	}})
+	require.NoError(t, err)
+	a := adapter.(*Adapter)
+	assert.Equal(t, []string{"adapter.js"}, a.config.Args)
+	assert.Equal(t, 5*time.Second, a.config.HealthInterval)
+
+	missing, err := f.CreateAdapter(channels.ChannelConfig{ID: "p", Config: map[string]interface{}{"command": "/nonexistent/adapter"}})
+	require.NoError(t, err)
+	assert.Error(t, missing.Start(context.Background()), "missing executables fail at start")
+}
+
+func TestAdapter_HandshakeAndEcho(t *testing.T) {
+	a := startTestAdapter(t, echoConfig(nil))
+	require.Eventually(t, a.IsHealthy, 5*time.Second, 10*time.Millisecond)
+
+	status := a.Status()
+	assert.Equal(t, "Echo adapter ready", status.Message)
+	assert.NotZero(t, status.Details["pid"])
+	assert.Equal(t, float64(protocol.ProcessProtocolVersion), status.Details["adapter"].(map[string]interface{})["protocol_version"])
+
+	require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{
+		BaseMessage: protocol.BaseMessage{ID: "out-1"},
+		SessionKey:  "echo_u1",
+		UserID:      "u1",
+		Text:        "hello",
+	}))
+
+	msg := receive(t, a)
+	assert.Equal(t, "echo", msg.ChannelID)
+	assert.Equal(t, "u1", msg.UserID)
+	assert.Equal(t, "echo_u1", msg.SessionKey)
+	assert.Equal(t, "hello", msg.Text)
+	assert.Equal(t, "out-1", msg.Metadata["echo_of"])
+}
+
+func TestAdapter_NamespacesSessionKeys(t *testing.T) {
+	a := startTestAdapter(t, echoConfig(nil))
+	require.Eventually(t, a.IsHealthy, 5*time.Second, 10*time.Millisecond)
+
+	for _, tt := range []struct{ sent, want string }{
+		{"thread-1", "echo_thread-1"},
+		{"webhook_other_u1", "echo_webhook_other_u1"},
+		{"echo_thread-2", "echo_thread-2"},
+		{"../u1 x", ""},
+		{"", "echo_u1"},
+	} {
+		require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{SessionKey: tt.sent, UserID: "u1", Text: tt.sent}))
+		if tt.want == "" {
+			continue // Dropped; the next message arrives first
+		}
+		msg := receive(t, a)
+		assert.Equal(t, tt.sent, msg.Text)
+		assert.Equal(t, tt.want, msg.SessionKey)
+	}
+}
+
+func TestAdapter_WithholdsGatewayEnvironment(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-secret")
+	a := startTestAdapter(t, map[string]interface{}{
+		"command": "sh",
+		"args": []interface{}{"-c",
+			`read line; echo "{\"type\":\"channel_status\",\"status\":\"online\",\"details\":{\"protocol_version\":1,\"message\":\"key=$ANTHROPIC_API_KEY token=$BRIDGE_TOKEN\"}}"; sleep 30`},
+		"env": map[string]interface{}{"BRIDGE_TOKEN": "abc"},
+	})
+	require.Eventually(t, a.IsHealthy, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "key= token=abc", a.Status().Message)
+}
+
+func TestAdapter_RestartsAfterCrash(t *testing.T) {
+	a := startTestAdapter(t, echoConfig(nil))
+	require.Eventually(t, a.IsHealthy, 5*time.Second, 10*time.Millisecond)
+
+	pid := a.Status().Details["pid"].(int)
+	require.NoError(t, syscall.Kill(pid, syscall.SIGKILL))
+
+	require.Eventually(t, func() bool {
+		st := a.Status()
+		newPID, _ := st.Details["pid"].(int)
+		return a.IsHealthy() && newPID != 0 && newPID != pid
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, a.Status().Details["restarts"])
+	assert.Contains(t, a.Status().Details["last_exit"], "killed")
+
+	// The new process works
+	require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{UserID: "u1", Text: "still there?"}))
+	assert.Equal(t, "still there?", receive(t, a).Text)
+}
+
+func TestAdapter_HandshakeFailures(t *testing.T) {
+	t.Run("incompatible version is not restarted", func(t *testing.T) {
+		a := startTestAdapter(t, map[string]interface{}{
+			"command": "sh",
+			"args": []interface{}{"-c",
+				`read line; echo '{"type":"channel_status","status":"online","details":{"protocol_version":99}}'; sleep 30`},
+		})
+		require.Eventually(t, func() bool {
+			return a.Status().Status == channels.StatusError
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Contains(t, a.Status().Message, "incompatible protocol version")
+		assert.Equal(t, 0, a.Status().Details["restarts"])
+	})
+
+	t.Run("silent process is restarted", func(t *testing.T) {
+		a := startTestAdapter(t, map[string]interface{}{
+			"command":                 "sh",
+			"args":                    []interface{}{"-c", "sleep 30"},
+			"startup_timeout_seconds": 0.1,
+			"restart_backoff_ms":      float64(10),
+		})
+		require.Eventually(t, func() bool {
+			restarts, _ := a.Status().Details["restarts"].(int)
+			return restarts >= 1
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Contains(t, a.Status().Details["last_exit"], "no handshake")
+		assert.False(t, a.IsHealthy())
+	})
+}
+
+func TestAdapter_StopShutsDownProcess(t *testing.T) {
+	adapter, err := NewFactory().CreateAdapter(channels.ChannelConfig{ID: "echo", Config: echoConfig(nil)})
+	require.NoError(t, err)
+	a := adapter.(*Adapter)
+	require.NoError(t, a.Start(context.Background()))
+	require.Eventually(t, a.IsHealthy, 5*time.Second, 10*time.Millisecond)
+	pid := a.Status().Details["pid"].(int)
+
+	require.NoError(t, a.Stop())
+	assert.Equal(t, channels.StatusOffline, a.Status().Status)
+	// The echo adapter exits on shutdown and has been reaped
+	assert.Error(t, syscall.Kill(pid, 0))
+
+	// Stop closes the incoming channel
+	_, open := <-a.ReceiveMessages()
+	assert.False(t, open)
+}
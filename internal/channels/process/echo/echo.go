@@ -0,0 +1,78 @@
+// Package echo is a reference process adapter. It speaks the process adapter
+// protocol on stdio and sends every outgoing message straight back as an
+// incoming one, which makes it useful for testing the bridge. Don't enable it
+// in a live gateway: the agent would end up answering its own replies.
+package echo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	"conduit/pkg/protocol"
+)
+
+// Run serves the protocol until in is closed or a shutdown command arrives
+func Run(in io.Reader, out io.Writer) error {
+	enc := json.NewEncoder(out)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var channelID string
+	var echoed int
+	started := time.Now()
+
+	status := func(state string, details map[string]interface{}) error {
+		details["protocol_version"] = protocol.ProcessProtocolVersion
+		details["echoed"] = echoed
+		details["uptime_seconds"] = int(time.Since(started).Seconds())
+		return enc.Encode(protocol.ChannelStatus{
+			BaseMessage: protocol.BaseMessage{Type: protocol.TypeChannelStatus, ID: uuid.NewString(), Timestamp: time.Now()},
+			ChannelID:   channelID,
+			Status:      state,
+			Details:     details,
+		})
+	}
+
+	for scanner.Scan() {
+		msg, err := protocol.ParseMessage(scanner.Bytes())
+		if err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+
+		switch m := msg.(type) {
+		case *protocol.ChannelCommand:
+			switch m.Command {
+			case protocol.ProcessCommandHello:
+				if v, _ := m.Args["protocol_version"].(float64); int(v) != protocol.ProcessProtocolVersion {
+					return fmt.Errorf("unsupported protocol version %v", m.Args["protocol_version"])
+				}
+				channelID = m.ChannelID
+				err = status("online", map[string]interface{}{"message": "Echo adapter ready"})
+			case protocol.ProcessCommandPing:
+				err = status("online", map[string]interface{}{})
+			case protocol.ProcessCommandShutdown:
+				return nil
+			}
+
+		case *protocol.OutgoingMessage:
+			echoed++
+			err = enc.Encode(protocol.IncomingMessage{
+				BaseMessage: protocol.BaseMessage{Type: protocol.TypeIncomingMessage, ID: uuid.NewString(), Timestamp: time.Now()},
+				ChannelID:   channelID,
+				SessionKey:  m.SessionKey,
+				UserID:      m.UserID,
+				Text:        m.Text,
+				Metadata:    map[string]string{"echo_of": m.ID},
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
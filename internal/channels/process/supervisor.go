@@ -0,0 +1,351 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// maxLineBytes bounds a single protocol line from the process
+const maxLineBytes = 4 * 1024 * 1024
+
+// errIncompatible means the process speaks another protocol version.
+// Restarting won't help, so the supervisor gives up.
+var errIncompatible = errors.New("incompatible protocol version")
+
+// supervise runs the process until ctx is cancelled, restarting it with
+// exponential backoff whenever it exits
+func (a *Adapter) supervise(ctx context.Context) {
+	defer close(a.done)
+
+	backoff := a.config.RestartBackoff
+	for {
+		started := time.Now()
+		err := a.runProcess(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errIncompatible) {
+			log.Printf("[Process] %s: %v, not restarting", a.id, err)
+			a.setStatus(channels.StatusError, err.Error())
+			return
+		}
+
+		// A process that ran for a while starts the backoff over
+		if time.Since(started) > a.config.MaxBackoff {
+			backoff = a.config.RestartBackoff
+		}
+
+		a.mutex.Lock()
+		a.restarts++
+		a.lastExit = err.Error()
+		a.mutex.Unlock()
+		log.Printf("[Process] %s: %v (restarting in %v)", a.id, err, backoff)
+		a.setStatus(channels.StatusReconnecting, fmt.Sprintf("%v, restarting in %v", err, backoff))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > a.config.MaxBackoff {
+			backoff = a.config.MaxBackoff
+		}
+	}
+}
+
+// runProcess runs one instance of the process until it exits, is killed for
+// failing the handshake or health checks, or ctx is cancelled
+func (a *Adapter) runProcess(ctx context.Context) error {
+	cmd := exec.Command(a.config.Command, a.config.Args...)
+	cmd.Dir = a.config.Dir
+	cmd.Env = append(inheritedEnv(), a.config.environ()...)
+	cmd.Stderr = &stderrLogger{id: a.id}
+	// Run in its own process group so helpers it spawns are killed with it
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start process: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.stdin = stdin
+	a.pid = cmd.Process.Pid
+	a.lastSeen = time.Now()
+	a.reported = nil
+	a.mutex.Unlock()
+	a.setStatus(channels.StatusInitializing, "Waiting for handshake")
+	defer func() {
+		a.mutex.Lock()
+		a.stdin = nil
+		a.pid = 0
+		a.mutex.Unlock()
+	}()
+
+	handshake := make(chan error, 1)
+	readDone := make(chan error, 1)
+	go func() { readDone <- a.readLoop(stdout, handshake) }()
+
+	if err := a.sendCommand(protocol.ProcessCommandHello, map[string]interface{}{
+		"protocol_version": protocol.ProcessProtocolVersion,
+		"config":           a.config.AdapterConfig,
+	}); err != nil {
+		log.Printf("[Process] %s: failed to send hello: %v", a.id, err)
+	}
+
+	startup := time.NewTimer(a.config.StartupTimeout)
+	defer startup.Stop()
+	health := time.NewTicker(a.config.HealthInterval)
+	defer health.Stop()
+
+	// Why the process was killed, reported instead of its exit status
+	var killReason error
+	kill := func(reason error) {
+		killReason = reason
+		killProcessGroup(cmd)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.shutdown(cmd, stdin, readDone)
+			return ctx.Err()
+
+		case readErr := <-readDone:
+			// Stdout is closed; make sure the process is gone before waiting
+			killProcessGroup(cmd)
+			waitErr := cmd.Wait()
+			switch {
+			case killReason != nil:
+				return killReason
+			case readErr != nil:
+				return fmt.Errorf("failed to read process output: %w", readErr)
+			case waitErr != nil:
+				return fmt.Errorf("process exited: %w", waitErr)
+			}
+			return fmt.Errorf("process exited")
+
+		case err := <-handshake:
+			if err != nil {
+				kill(err)
+				continue
+			}
+			startup.Stop()
+			log.Printf("[Process] %s: handshake complete (pid %d)", a.id, cmd.Process.Pid)
+
+		case <-startup.C:
+			kill(fmt.Errorf("no handshake within %v", a.config.StartupTimeout))
+
+		case <-health.C:
+			a.mutex.RLock()
+			silent := time.Since(a.lastSeen)
+			a.mutex.RUnlock()
+			if silent > 3*a.config.HealthInterval {
+				kill(fmt.Errorf("process unresponsive for %v", silent.Round(time.Second)))
+				continue
+			}
+			if err := a.sendCommand(protocol.ProcessCommandPing, nil); err != nil {
+				log.Printf("[Process] %s: failed to send ping: %v", a.id, err)
+			}
+		}
+	}
+}
+
+// inheritedEnv is the part of the gateway's environment the process gets.
+// The rest holds API keys and is withheld; the adapter's env setting adds
+// anything else the process needs.
+func inheritedEnv() []string {
+	var env []string
+	for _, key := range []string{"PATH", "HOME", "USER", "LANG", "LC_ALL", "TZ", "TMPDIR"} {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+	return env
+}
+
+// shutdown asks the process to exit and kills it after the shutdown timeout
+func (a *Adapter) shutdown(cmd *exec.Cmd, stdin io.Closer, readDone <-chan error) {
+	a.sendCommand(protocol.ProcessCommandShutdown, nil)
+	stdin.Close()
+
+	select {
+	case <-readDone:
+	case <-time.After(a.config.ShutdownTimeout):
+		log.Printf("[Process] %s: process did not exit within %v, killing it", a.id, a.config.ShutdownTimeout)
+		killProcessGroup(cmd)
+		<-readDone
+	}
+	cmd.Wait()
+}
+
+// killProcessGroup kills the process and everything it started; otherwise a
+// child holding stdout open would keep the reader from seeing EOF
+func killProcessGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// readLoop handles protocol lines from the process until stdout closes. The
+// first status line completes the handshake.
+func (a *Adapter) readLoop(stdout io.Reader, handshake chan<- error) error {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+
+	handshaken, rejected := false, false
+	for scanner.Scan() {
+		if rejected {
+			continue
+		}
+
+		a.mutex.Lock()
+		a.lastSeen = time.Now()
+		a.mutex.Unlock()
+
+		msg, err := protocol.ParseMessage(scanner.Bytes())
+		if err != nil {
+			log.Printf("[Process] %s: ignoring invalid line: %v", a.id, err)
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *protocol.ChannelStatus:
+			if !handshaken {
+				version, _ := m.Details["protocol_version"].(float64)
+				if int(version) != protocol.ProcessProtocolVersion {
+					rejected = true
+					handshake <- fmt.Errorf("%w: process speaks %v, gateway speaks %d",
+						errIncompatible, m.Details["protocol_version"], protocol.ProcessProtocolVersion)
+					continue
+				}
+				handshaken = true
+				handshake <- nil
+			}
+			a.applyStatus(m)
+
+		case *protocol.IncomingMessage:
+			if !handshaken {
+				log.Printf("[Process] %s: ignoring message sent before handshake", a.id)
+				continue
+			}
+			a.forward(m)
+
+		default:
+			log.Printf("[Process] %s: ignoring unexpected message %T", a.id, m)
+		}
+	}
+	return scanner.Err()
+}
+
+// applyStatus relays a status line from the process into ChannelStatus
+func (a *Adapter) applyStatus(st *protocol.ChannelStatus) {
+	status := channels.StatusCode(st.Status)
+	switch status {
+	case channels.StatusOnline, channels.StatusOffline, channels.StatusError,
+		channels.StatusReconnecting, channels.StatusInitializing:
+	default:
+		status = channels.StatusError
+	}
+
+	msg, _ := st.Details["message"].(string)
+	if msg == "" {
+		msg = fmt.Sprintf("Process reported %s", st.Status)
+	}
+	a.setStatus(status, msg)
+
+	a.mutex.Lock()
+	a.reported = st.Details
+	a.mutex.Unlock()
+}
+
+// forward fills in gateway-side fields and queues a message without blocking
+func (a *Adapter) forward(msg *protocol.IncomingMessage) {
+	msg.Type = protocol.TypeIncomingMessage
+	msg.ChannelID = a.id
+	if msg.ID == "" {
+		msg.ID = a.generateMessageID()
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+
+	// The process only reaches sessions of this adapter, never another
+	// channel's. Keys it got from outgoing messages already carry the prefix.
+	defaultKey := fmt.Sprintf("%s_%s", a.id, msg.UserID)
+	if msg.SessionKey == "" || msg.SessionKey == defaultKey {
+		msg.SessionKey = defaultKey
+	} else if key := strings.TrimPrefix(msg.SessionKey, a.id+"_"); channels.ValidSessionKey(key) {
+		msg.SessionKey = fmt.Sprintf("%s_%s", a.id, key)
+	} else {
+		log.Printf("[Process] %s: dropping message with invalid session key", a.id)
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	switch err := channels.Forward(a.ctx, a.incoming, msg); err {
+	case nil:
+		a.msgCount++
+		// Privacy-safe logging - no message content
+		log.Printf("[Process] %s: received message for session %s (%d chars)", a.id, msg.SessionKey, len(msg.Text))
+	case channels.ErrQueueFull:
+		log.Printf("[Process] %s: incoming message channel is full, dropping message", a.id)
+	}
+}
+
+// setStatus updates the status reported by Status and IsHealthy
+func (a *Adapter) setStatus(status channels.StatusCode, msg string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	channels.SetStatus(a.ctx, &a.status, &a.statusMsg, status, msg)
+}
+
+// stderrLogger logs the process's stderr line by line
+type stderrLogger struct {
+	id  string
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (l *stderrLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf = append(l.buf, p...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+		log.Printf("[Process] %s stderr: %s", l.id, l.buf[:i])
+		l.buf = l.buf[i+1:]
+	}
+	// Don't buffer unbounded output without newlines
+	if len(l.buf) > 64*1024 {
+		log.Printf("[Process] %s stderr: %s", l.id, l.buf)
+		l.buf = l.buf[:0]
+	}
+	return len(p), nil
+}
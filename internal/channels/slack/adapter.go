@@ -0,0 +1,683 @@
+package slack
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// maxMessageLength is the most text chat.postMessage and chat.update accept
+const maxMessageLength = 40000
+
+// threadTTL is how long the bot keeps following a thread it was mentioned in
+const threadTTL = 7 * 24 * time.Hour
+
+// Adapter implements the ChannelAdapter interface for Slack using Socket
+// Mode for events and the Web API for replies
+type Adapter struct {
+	id         string
+	name       string
+	api        *webClient
+	config     SlackConfig
+	status     channels.StatusCode
+	statusMsg  string
+	incoming   chan *protocol.IncomingMessage
+	ctx        context.Context
+	cancel     context.CancelFunc
+	mutex      sync.RWMutex
+	startTime  time.Time
+	msgCount   int64
+	pairingMgr *PairingManager
+
+	botUserID string
+	team      string
+
+	// Threads the bot was mentioned in or replied to, keyed by chat ID.
+	// Follow-up replies in these threads don't need another mention.
+	threads map[string]time.Time
+}
+
+// SlackConfig contains Slack-specific configuration
+type SlackConfig struct {
+	BotToken string `json:"bot_token"` // xoxb- token for the Web API
+	AppToken string `json:"app_token"` // xapp- token with connections:write for Socket Mode
+
+	// AllowedUsers can always talk to the bot. Other users must pair first
+	// when pairing is enabled, or are ignored when it is disabled.
+	AllowedUsers []string `json:"allowed_users"`
+	// AllowedChannels restricts which channels the bot answers in. Direct
+	// messages are unaffected. Empty allows all channels.
+	AllowedChannels []string `json:"allowed_channels"`
+	Pairing         bool     `json:"pairing"`
+
+	// SlashCommand is the command configured for the app, e.g. "/conduit".
+	// "/conduit status" is forwarded as "/status".
+	SlashCommand string `json:"slash_command"`
+
+	// MediaDir is where shared files are stored, one subdirectory per
+	// session. Files are ignored if empty.
+	MediaDir      string `json:"media_dir"`
+	MaxDownloadMB int    `json:"max_download_mb"`
+
+	// APIURL overrides the Web API base URL (used in tests)
+	APIURL string `json:"api_url"`
+}
+
+// Factory creates Slack channel adapters
+type Factory struct {
+	db *sql.DB
+}
+
+// NewFactory creates a new Slack adapter factory
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// NewFactoryWithDB creates a new Slack adapter factory with database support
+// for pairing
+func NewFactoryWithDB(db *sql.DB) *Factory {
+	return &Factory{db: db}
+}
+
+// SupportsType returns whether this factory supports the given adapter type
+func (f *Factory) SupportsType(adapterType string) bool {
+	return adapterType == "slack"
+}
+
+// CreateAdapter creates a new Slack adapter instance
+func (f *Factory) CreateAdapter(config channels.ChannelConfig) (channels.ChannelAdapter, error) {
+	slackConfig := SlackConfig{
+		Pairing:      true,
+		SlashCommand: "/conduit",
+	}
+
+	// Parse Slack-specific config
+	if token, ok := config.Config["bot_token"].(string); ok && token != "" {
+		slackConfig.BotToken = token
+	} else {
+		return nil, fmt.Errorf("bot_token is required for Slack adapter")
+	}
+
+	if token, ok := config.Config["app_token"].(string); ok && token != "" {
+		slackConfig.AppToken = token
+	} else {
+		return nil, fmt.Errorf("app_token is required for Slack adapter (Socket Mode)")
+	}
+
+	slackConfig.AllowedUsers = channels.StringList(config.Config["allowed_users"])
+	slackConfig.AllowedChannels = channels.StringList(config.Config["allowed_channels"])
+
+	if pairing, ok := config.Config["pairing"].(bool); ok {
+		slackConfig.Pairing = pairing
+	}
+
+	if command, ok := config.Config["slash_command"].(string); ok && command != "" {
+		slackConfig.SlashCommand = "/" + strings.TrimPrefix(command, "/")
+	}
+
+	slackConfig.MediaDir = config.MediaDir
+	if mediaDir, ok := config.Config["media_dir"].(string); ok && mediaDir != "" {
+		slackConfig.MediaDir = mediaDir
+	}
+
+	if maxMB, ok := config.Config["max_download_mb"].(float64); ok {
+		slackConfig.MaxDownloadMB = int(maxMB)
+	}
+
+	if apiURL, ok := config.Config["api_url"].(string); ok {
+		slackConfig.APIURL = strings.TrimSuffix(apiURL, "/")
+	}
+
+	adapter := &Adapter{
+		id:       config.ID,
+		name:     config.Name,
+		api:      newWebClient(slackConfig.APIURL, slackConfig.BotToken, slackConfig.AppToken),
+		config:   slackConfig,
+		status:   channels.StatusInitializing,
+		incoming: make(chan *protocol.IncomingMessage, 100),
+		threads:  make(map[string]time.Time),
+	}
+
+	if slackConfig.Pairing {
+		if f.db != nil {
+			adapter.pairingMgr = NewPairingManager(f.db)
+		} else {
+			log.Printf("[Slack] Warning: No database connection, pairing will be disabled for adapter %s", config.ID)
+		}
+	}
+
+	return adapter, nil
+}
+
+// GetSupportedTypes returns the adapter types this factory supports
+func (f *Factory) GetSupportedTypes() []string {
+	return []string{"slack"}
+}
+
+// ID returns the adapter's unique identifier
+func (a *Adapter) ID() string {
+	return a.id
+}
+
+// Name returns the adapter's human-readable name
+func (a *Adapter) Name() string {
+	return a.name
+}
+
+// Type returns the adapter type
+func (a *Adapter) Type() string {
+	return "slack"
+}
+
+// Start verifies the bot token and opens the Socket Mode connection
+func (a *Adapter) Start(ctx context.Context) error {
+	a.mutex.Lock()
+	a.ctx, a.cancel = context.WithCancel(ctx)
+	a.status = channels.StatusInitializing
+	a.statusMsg = "Connecting to Slack"
+	a.startTime = time.Now()
+	a.mutex.Unlock()
+
+	userID, team, err := a.api.authTest(a.ctx)
+	if err != nil {
+		a.setStatus(channels.StatusError, fmt.Sprintf("Failed to authenticate: %v", err))
+		return fmt.Errorf("failed to authenticate Slack bot: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.botUserID = userID
+	a.team = team
+	a.mutex.Unlock()
+
+	go a.runSocket(a.ctx)
+
+	log.Printf("[Slack] Bot started: %s (workspace %s)", a.Name(), team)
+	return nil
+}
+
+// Stop gracefully shuts down the adapter
+func (a *Adapter) Stop() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.cancel != nil {
+		a.cancel()
+	}
+
+	a.status = channels.StatusOffline
+	a.statusMsg = "Adapter stopped"
+
+	// Close incoming message channel
+	close(a.incoming)
+
+	log.Printf("[Slack] Adapter stopped: %s", a.Name())
+	return nil
+}
+
+// setStatus updates the status reported by Status and IsHealthy
+func (a *Adapter) setStatus(status channels.StatusCode, msg string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	channels.SetStatus(a.ctx, &a.status, &a.statusMsg, status, msg)
+}
+
+// chatID identifies where replies go: a channel, or "channel:thread_ts" for
+// a thread. It is used as the UserID of incoming messages.
+func chatID(channel, threadTS string) string {
+	if threadTS == "" {
+		return channel
+	}
+	return channel + ":" + threadTS
+}
+
+// parseChatID splits a chat ID into channel and thread timestamp
+func parseChatID(id string) (channel, threadTS string) {
+	channel, threadTS, _ = strings.Cut(id, ":")
+	return channel, threadTS
+}
+
+// sessionKey maps a channel or thread to a session
+func sessionKey(channel, threadTS string) string {
+	if threadTS == "" {
+		return "slack_" + channel
+	}
+	return "slack_" + channel + "_" + threadTS
+}
+
+// SendMessage posts a message to a Slack channel or thread. Files from
+// MediaPath or image_path metadata are uploaded with the text as a comment.
+func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+
+	channel, threadTS := parseChatID(msg.UserID)
+	if channel == "" {
+		return fmt.Errorf("invalid chat ID: %s", msg.UserID)
+	}
+
+	sanitizedText := channels.SanitizeUserFacingText(msg.Text)
+	text := channels.TruncateText(convertToMrkdwn(sanitizedText), maxMessageLength)
+
+	filePath := msg.MediaPath
+	if imagePath, ok := msg.Metadata["image_path"]; ok && imagePath != "" {
+		filePath = imagePath
+	}
+	if filePath != "" {
+		if err := a.api.uploadFile(a.ctx, channel, threadTS, filePath, text); err != nil {
+			return fmt.Errorf("failed to upload file: %w", err)
+		}
+	} else {
+		params := map[string]interface{}{"channel": channel, "text": text}
+		if threadTS != "" {
+			params["thread_ts"] = threadTS
+		}
+		// Attach reply buttons; presses arrive as block actions
+		if blocks := buttonBlocks(text, msg.Metadata); blocks != nil {
+			params["blocks"] = blocks
+		}
+		if _, err := a.api.postMessage(a.ctx, params); err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+
+	if threadTS != "" {
+		a.followThread(msg.UserID)
+	}
+
+	log.Printf("[Slack] Message sent to %s (%d chars, sanitized from %d)", channel, len(sanitizedText), len(msg.Text))
+
+	a.mutex.Lock()
+	a.msgCount++
+	a.mutex.Unlock()
+
+	return nil
+}
+
+// buttonBlocks converts the inline_keyboard metadata into Block Kit blocks
+// (the text followed by one actions block per row), or returns nil
+func buttonBlocks(text string, metadata map[string]string) []map[string]interface{} {
+	raw, ok := metadata[protocol.MetadataInlineKeyboard]
+	if !ok || raw == "" {
+		return nil
+	}
+
+	var rows [][]protocol.InlineButton
+	if err := json.Unmarshal([]byte(raw), &rows); err != nil {
+		log.Printf("[Slack] Ignoring invalid inline keyboard: %v", err)
+		return nil
+	}
+
+	blocks := []map[string]interface{}{{
+		"type": "section",
+		"text": map[string]string{"type": "mrkdwn", "text": text},
+	}}
+	for i, row := range rows {
+		var elements []map[string]interface{}
+		for j, b := range row {
+			elements = append(elements, map[string]interface{}{
+				"type":      "button",
+				"text":      map[string]string{"type": "plain_text", "text": b.Text},
+				"action_id": fmt.Sprintf("conduit_button_%d_%d", i, j),
+				"value":     b.Data,
+			})
+		}
+		blocks = append(blocks, map[string]interface{}{"type": "actions", "elements": elements})
+	}
+	return blocks
+}
+
+// Regex patterns for Slack mrkdwn conversion
+var (
+	// Headers: # Header -> *Header*
+	headerRe = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+	// Standard **bold** -> *bold*
+	doubleBoldRe = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	// Links [text](url) -> <url|text>
+	linkRe = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// convertToMrkdwn converts standard markdown to Slack's mrkdwn. &, < and >
+// are escaped first as Slack requires.
+func convertToMrkdwn(text string) string {
+	text = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+	text = headerRe.ReplaceAllString(text, "*$1*")
+	text = doubleBoldRe.ReplaceAllString(text, "*$1*")
+	text = linkRe.ReplaceAllString(text, "<$2|$1>")
+	return text
+}
+
+// SendStreamingMessage posts a message and returns its timestamp for later
+// edits with chat.update
+func (a *Adapter) SendStreamingMessage(chatID, text string) (string, error) {
+	if a.ctx == nil {
+		return "", fmt.Errorf("adapter not started")
+	}
+
+	channel, threadTS := parseChatID(chatID)
+	params := map[string]interface{}{
+		"channel": channel,
+		"text":    channels.TruncateText(convertToMrkdwn(channels.SanitizeUserFacingText(text)), maxMessageLength),
+	}
+	if threadTS != "" {
+		params["thread_ts"] = threadTS
+		a.followThread(chatID)
+	}
+	return a.api.postMessage(a.ctx, params)
+}
+
+// EditStreamingMessage replaces the text of a message
+func (a *Adapter) EditStreamingMessage(chatID, messageID, text string) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+
+	channel, _ := parseChatID(chatID)
+	return a.api.updateMessage(a.ctx, channel, messageID,
+		channels.TruncateText(convertToMrkdwn(channels.SanitizeUserFacingText(text)), maxMessageLength))
+}
+
+// DeleteStreamingMessage deletes a message (used for silent response cleanup)
+func (a *Adapter) DeleteStreamingMessage(chatID, messageID string) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+
+	channel, _ := parseChatID(chatID)
+	err := a.api.deleteMessage(a.ctx, channel, messageID)
+	if apiErr, ok := err.(*apiError); ok && apiErr.Code == "message_not_found" {
+		return nil
+	}
+	return err
+}
+
+// ReceiveMessages returns the channel for incoming messages
+func (a *Adapter) ReceiveMessages() <-chan *protocol.IncomingMessage {
+	return a.incoming
+}
+
+// Status returns the current adapter status
+func (a *Adapter) Status() channels.ChannelStatus {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	details := map[string]interface{}{
+		"uptime_seconds":  time.Since(a.startTime).Seconds(),
+		"message_count":   a.msgCount,
+		"active_threads":  len(a.threads),
+		"pairing_enabled": a.pairingMgr != nil,
+	}
+	if a.botUserID != "" {
+		details["bot_user_id"] = a.botUserID
+		details["team"] = a.team
+	}
+
+	return channels.ChannelStatus{
+		Status:    a.status,
+		Message:   a.statusMsg,
+		Details:   details,
+		Timestamp: time.Now(),
+	}
+}
+
+// IsHealthy returns whether the adapter is functioning properly
+func (a *Adapter) IsHealthy() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.status == channels.StatusOnline
+}
+
+// handleEvent forwards direct messages, mentions and follow-ups in threads
+// the bot is part of
+func (a *Adapter) handleEvent(ctx context.Context, ev *event) {
+	a.mutex.RLock()
+	botUserID := a.botUserID
+	a.mutex.RUnlock()
+
+	// Ignore bots (including ourselves), edits, joins and other subtypes
+	if ev.BotID != "" || ev.User == "" || ev.User == botUserID {
+		return
+	}
+	if ev.Subtype != "" && ev.Subtype != "file_share" {
+		return
+	}
+
+	mention := "<@" + botUserID + ">"
+	dm := ev.ChannelType == "im"
+	threadTS := ev.ThreadTS
+
+	switch {
+	case ev.Type == "app_mention":
+		// Reply in a thread under the mention, which becomes the session
+		if threadTS == "" {
+			threadTS = ev.TS
+		}
+	case ev.Type == "message" && dm:
+	case ev.Type == "message" && ev.ThreadTS != "" && !strings.Contains(ev.Text, mention):
+		// Mentions are delivered separately as app_mention events
+		if !a.isFollowedThread(chatID(ev.Channel, ev.ThreadTS)) {
+			return
+		}
+	default:
+		return
+	}
+
+	if !a.authorize(ctx, ev.User, ev.Channel, dm) {
+		return
+	}
+
+	replyTo := chatID(ev.Channel, threadTS)
+	if threadTS != "" {
+		a.followThread(replyTo)
+	}
+
+	text := strings.TrimSpace(strings.ReplaceAll(ev.Text, mention, ""))
+	key := sessionKey(ev.Channel, threadTS)
+	chatType := "channel"
+	if dm {
+		chatType = "dm"
+	}
+	metadata := map[string]string{
+		"message_id":   ev.TS,
+		"chat_type":    chatType,
+		"from_user_id": ev.User,
+	}
+	if threadTS != "" {
+		metadata["thread_ts"] = threadTS
+	}
+
+	var attachments []protocol.Attachment
+	if len(ev.Files) > 0 {
+		metadata["type"] = "attachment"
+		if text == "" {
+			text = fileLabel(ev.Files)
+		}
+		var failed bool
+		attachments, failed = a.downloadFiles(ctx, key, ev, metadata)
+		if failed {
+			text += "\n[Attachment could not be downloaded]"
+		}
+	}
+	if text == "" {
+		return
+	}
+
+	a.forward(&protocol.IncomingMessage{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeIncomingMessage,
+			ID:        a.generateMessageID(),
+			Timestamp: time.Now(),
+		},
+		ChannelID:   a.id,
+		SessionKey:  key,
+		UserID:      replyTo,
+		Text:        text,
+		Attachments: attachments,
+		Metadata:    metadata,
+	}, "message")
+}
+
+// handleSlashCommand forwards "/conduit <command> [args]" as "/<command> [args]",
+// the form the gateway's command handler expects
+func (a *Adapter) handleSlashCommand(ctx context.Context, cmd *slashCommand) {
+	if cmd.Command != a.config.SlashCommand {
+		return
+	}
+	if !a.authorize(ctx, cmd.UserID, cmd.ChannelID, strings.HasPrefix(cmd.ChannelID, "D")) {
+		return
+	}
+
+	text := "/" + strings.TrimPrefix(strings.TrimSpace(cmd.Text), "/")
+	if text == "/" {
+		text = "/help"
+	}
+
+	a.forward(&protocol.IncomingMessage{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeIncomingMessage,
+			ID:        a.generateMessageID(),
+			Timestamp: time.Now(),
+		},
+		ChannelID:  a.id,
+		SessionKey: sessionKey(cmd.ChannelID, ""),
+		UserID:     cmd.ChannelID,
+		Text:       text,
+		Metadata: map[string]string{
+			"type":          "slash_command",
+			"from_user_id":  cmd.UserID,
+			"from_username": cmd.UserName,
+		},
+	}, "slash command")
+}
+
+// handleBlockActions forwards button presses like Telegram callback queries
+func (a *Adapter) handleBlockActions(ctx context.Context, actions *blockActions) {
+	if len(actions.Actions) == 0 {
+		return
+	}
+	channel := actions.Channel.ID
+	if !a.authorize(ctx, actions.User.ID, channel, strings.HasPrefix(channel, "D")) {
+		return
+	}
+
+	threadTS := actions.Container.ThreadTS
+	a.forward(&protocol.IncomingMessage{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeIncomingMessage,
+			ID:        a.generateMessageID(),
+			Timestamp: time.Now(),
+		},
+		ChannelID:  a.id,
+		SessionKey: sessionKey(channel, threadTS),
+		UserID:     chatID(channel, threadTS),
+		Text:       actions.Actions[0].Value,
+		Metadata: map[string]string{
+			"type":          "callback_query",
+			"callback_id":   actions.Actions[0].ActionID,
+			"from_user_id":  actions.User.ID,
+			"from_username": actions.User.Username,
+		},
+	}, "button press")
+}
+
+// authorize applies the channel allowlist, user allowlist and pairing.
+// Unpaired users are sent their pairing code, privately in channels.
+func (a *Adapter) authorize(ctx context.Context, userID, channel string, dm bool) bool {
+	if !dm && len(a.config.AllowedChannels) > 0 && !slices.Contains(a.config.AllowedChannels, channel) {
+		return false
+	}
+	if slices.Contains(a.config.AllowedUsers, userID) {
+		return true
+	}
+	if a.pairingMgr == nil {
+		if len(a.config.AllowedUsers) > 0 {
+			log.Printf("[Slack] Ignoring message from user %s (not allowed)", userID)
+			return false
+		}
+		return true
+	}
+
+	notify := func(text string) error {
+		if dm {
+			_, err := a.api.postMessage(ctx, map[string]interface{}{"channel": channel, "text": text})
+			return err
+		}
+		return a.api.postEphemeral(ctx, channel, userID, text)
+	}
+	isPaired, err := a.pairingMgr.HandlePairingForUser(userID, notify)
+	if err != nil {
+		log.Printf("[Slack] Error handling pairing for user %s: %v", userID, err)
+		return false
+	}
+	if !isPaired {
+		log.Printf("[Slack] User %s is not paired, message blocked", userID)
+	}
+	return isPaired
+}
+
+// followThread marks a thread so follow-ups don't need a mention
+func (a *Adapter) followThread(chatID string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := time.Now()
+	for id, seen := range a.threads {
+		if now.Sub(seen) > threadTTL {
+			delete(a.threads, id)
+		}
+	}
+	a.threads[chatID] = now
+}
+
+// isFollowedThread reports whether the bot is taking part in a thread
+func (a *Adapter) isFollowedThread(chatID string) bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	seen, ok := a.threads[chatID]
+	return ok && time.Since(seen) <= threadTTL
+}
+
+// forward sends a message to the incoming channel without blocking
+func (a *Adapter) forward(msg *protocol.IncomingMessage, kind string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	switch err := channels.Forward(a.ctx, a.incoming, msg); err {
+	case nil:
+		a.msgCount++
+		// Privacy-safe logging - no message content or user names
+		log.Printf("[Slack] Received %s in %s (%d chars, %d attachments)",
+			kind, msg.UserID, len(msg.Text), len(msg.Attachments))
+	case channels.ErrQueueFull:
+		log.Printf("[Slack] Warning: incoming message channel is full, dropping %s", kind)
+	}
+}
+
+// generateMessageID creates a unique message ID
+func (a *Adapter) generateMessageID() string {
+	return fmt.Sprintf("slack_%s_%s", a.id, uuid.New().String()[:8])
+}
+
+// GetPairingManager returns the pairing manager for this adapter (if enabled)
+func (a *Adapter) GetPairingManager() *PairingManager {
+	return a.pairingMgr
+}
+
+// IsPairingEnabled returns whether pairing is enabled for this adapter
+func (a *Adapter) IsPairingEnabled() bool {
+	return a.pairingMgr != nil
+}
@@ -0,0 +1,444 @@
+package slack
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"conduit/internal/channels"
+	"conduit/internal/database"
+	"conduit/pkg/protocol"
+)
+
+const (
+	testBotUserID = "UBOT"
+	testUserID    = "U123"
+	testChannelID = "C100"
+	testDMID      = "D200"
+)
+
+// apiCall is a Web API request received by the fake server
+type apiCall struct {
+	Method string
+	Body   map[string]interface{}
+}
+
+// fakeSlack serves the Web API and a Socket Mode websocket
+type fakeSlack struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu        sync.Mutex
+	calls     []apiCall
+	acks      []string
+	conn      *websocket.Conn
+	seq       int
+	connected chan struct{}
+}
+
+func newFakeSlack(t *testing.T) *fakeSlack {
+	f := &fakeSlack{t: t, connected: make(chan struct{}, 10)}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeSlack) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/ws" {
+		f.serveSocket(w, r)
+		return
+	}
+
+	method := strings.TrimPrefix(r.URL.Path, "/api/")
+	body, _ := io.ReadAll(r.Body)
+	params := map[string]interface{}{}
+	json.Unmarshal(body, &params)
+	f.mu.Lock()
+	f.calls = append(f.calls, apiCall{Method: method, Body: params})
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch method {
+	case "auth.test":
+		w.Write([]byte(`{"ok": true, "user_id": "` + testBotUserID + `", "team": "Test"}`))
+	case "apps.connections.open":
+		w.Write([]byte(`{"ok": true, "url": "ws` + strings.TrimPrefix(f.server.URL, "http") + `/ws"}`))
+	case "chat.postMessage":
+		w.Write([]byte(`{"ok": true, "ts": "1700000000.000200"}`))
+	case "chat.delete":
+		w.Write([]byte(`{"ok": false, "error": "message_not_found"}`))
+	case "files.getUploadURLExternal":
+		w.Write([]byte(`{"ok": true, "upload_url": "` + f.server.URL + `/upload", "file_id": "F1"}`))
+	case "upload":
+		w.WriteHeader(http.StatusOK)
+	case "files/photo.png":
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\nfake image"))
+	default:
+		w.Write([]byte(`{"ok": true}`))
+	}
+}
+
+// serveSocket sends hello and then records acknowledgements
+func (f *fakeSlack) serveSocket(w http.ResponseWriter, r *http.Request) {
+	ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	f.conn = ws
+	f.mu.Unlock()
+	ws.WriteJSON(map[string]string{"type": "hello"})
+	f.connected <- struct{}{}
+
+	for {
+		var ack map[string]string
+		if err := ws.ReadJSON(&ack); err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.acks = append(f.acks, ack["envelope_id"])
+		f.mu.Unlock()
+	}
+}
+
+// send delivers an envelope on the current socket connection
+func (f *fakeSlack) send(envType string, payload interface{}) {
+	raw, err := json.Marshal(payload)
+	require.NoError(f.t, err)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	require.NoError(f.t, f.conn.WriteJSON(envelope{
+		EnvelopeID: "env-" + string(rune('a'+f.seq)),
+		Type:       envType,
+		Payload:    raw,
+	}))
+}
+
+// sendEvent delivers an Events API event
+func (f *fakeSlack) sendEvent(ev map[string]interface{}) {
+	f.send("events_api", map[string]interface{}{"type": "event_callback", "event": ev})
+}
+
+func (f *fakeSlack) waitConnected() {
+	select {
+	case <-f.connected:
+	case <-time.After(5 * time.Second):
+		f.t.Fatal("timed out waiting for socket connection")
+	}
+}
+
+// callsTo returns the recorded calls to a Web API method
+func (f *fakeSlack) callsTo(method string) []apiCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []apiCall
+	for _, c := range f.calls {
+		if c.Method == method {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+func newTestAdapter(t *testing.T, f *fakeSlack, factory *Factory, extra map[string]interface{}) *Adapter {
+	cfg := map[string]interface{}{
+		"bot_token": "xoxb-test",
+		"app_token": "xapp-test",
+		"api_url":   f.server.URL + "/api",
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	adapter, err := factory.CreateAdapter(channels.ChannelConfig{
+		ID: "slack", Type: "slack", Name: "Slack", Enabled: true, Config: cfg,
+	})
+	require.NoError(t, err)
+	return adapter.(*Adapter)
+}
+
+func startTestAdapter(t *testing.T, f *fakeSlack, factory *Factory, extra map[string]interface{}) *Adapter {
+	a := newTestAdapter(t, f, factory, extra)
+	require.NoError(t, a.Start(context.Background()))
+	t.Cleanup(func() { a.Stop() })
+	f.waitConnected()
+	require.Eventually(t, a.IsHealthy, 5*time.Second, 10*time.Millisecond)
+	return a
+}
+
+func receive(t *testing.T, a *Adapter) *protocol.IncomingMessage {
+	select {
+	case msg := <-a.ReceiveMessages():
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for incoming message")
+		return nil
+	}
+}
+
+func expectNoMessage(t *testing.T, a *Adapter) {
+	select {
+	case msg := <-a.ReceiveMessages():
+		t.Fatalf("unexpected message: %q", msg.Text)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func setupPairingDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, database.RunMigrations(db))
+	return db
+}
+
+func TestFactory_CreateAdapter(t *testing.T) {
+	f := NewFactory()
+	assert.True(t, f.SupportsType("slack"))
+	assert.False(t, f.SupportsType("discord"))
+
+	_, err := f.CreateAdapter(channels.ChannelConfig{ID: "slack", Config: map[string]interface{}{"bot_token": "xoxb"}})
+	assert.Error(t, err, "app_token is required")
+
+	adapter, err := f.CreateAdapter(channels.ChannelConfig{ID: "slack", Config: map[string]interface{}{
+		"bot_token":     "xoxb",
+		"app_token":     "xapp",
+		"allowed_users": []interface{}{"U1", "U2"},
+		"slash_command": "ask",
+	}})
+	require.NoError(t, err)
+	a := adapter.(*Adapter)
+	assert.Equal(t, []string{"U1", "U2"}, a.config.AllowedUsers)
+	assert.Equal(t, "/ask", a.config.SlashCommand)
+	assert.False(t, a.IsPairingEnabled(), "pairing needs a database")
+}
+
+func TestAdapter_DirectMessage(t *testing.T) {
+	f := newFakeSlack(t)
+	a := startTestAdapter(t, f, NewFactory(), nil)
+
+	f.sendEvent(map[string]interface{}{
+		"type": "message", "channel": testDMID, "channel_type": "im",
+		"user": testUserID, "text": "hello", "ts": "1.1",
+	})
+
+	msg := receive(t, a)
+	assert.Equal(t, "hello", msg.Text)
+	assert.Equal(t, "slack_"+testDMID, msg.SessionKey)
+	assert.Equal(t, testDMID, msg.UserID)
+	assert.Equal(t, "dm", msg.Metadata["chat_type"])
+
+	require.Eventually(t, func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return len(f.acks) == 1
+	}, time.Second, 10*time.Millisecond, "envelope should be acknowledged")
+}
+
+func TestAdapter_MentionStartsThreadSession(t *testing.T) {
+	f := newFakeSlack(t)
+	a := startTestAdapter(t, f, NewFactory(), nil)
+
+	// Channel messages without a mention are ignored
+	f.sendEvent(map[string]interface{}{
+		"type": "message", "channel": testChannelID, "channel_type": "channel",
+		"user": testUserID, "text": "just chatting", "ts": "1.0",
+	})
+	expectNoMessage(t, a)
+
+	f.sendEvent(map[string]interface{}{
+		"type": "app_mention", "channel": testChannelID,
+		"user": testUserID, "text": "<@" + testBotUserID + "> summarize this", "ts": "2.0",
+	})
+	msg := receive(t, a)
+	assert.Equal(t, "summarize this", msg.Text)
+	assert.Equal(t, "slack_"+testChannelID+"_2.0", msg.SessionKey)
+	assert.Equal(t, testChannelID+":2.0", msg.UserID)
+
+	// Follow-ups in the thread no longer need a mention
+	f.sendEvent(map[string]interface{}{
+		"type": "message", "channel": testChannelID, "channel_type": "channel",
+		"user": testUserID, "text": "and shorter", "ts": "2.5", "thread_ts": "2.0",
+	})
+	msg = receive(t, a)
+	assert.Equal(t, "and shorter", msg.Text)
+	assert.Equal(t, "slack_"+testChannelID+"_2.0", msg.SessionKey)
+
+	// The bot's own messages are ignored
+	f.sendEvent(map[string]interface{}{
+		"type": "message", "channel": testChannelID, "channel_type": "channel",
+		"user": testBotUserID, "bot_id": "B1", "text": "reply", "ts": "2.6", "thread_ts": "2.0",
+	})
+	expectNoMessage(t, a)
+}
+
+func TestAdapter_AllowedUsersAndChannels(t *testing.T) {
+	f := newFakeSlack(t)
+	a := startTestAdapter(t, f, NewFactory(), map[string]interface{}{
+		"allowed_users":    []interface{}{testUserID},
+		"allowed_channels": []interface{}{testChannelID},
+	})
+
+	f.sendEvent(map[string]interface{}{
+		"type": "message", "channel": testDMID, "channel_type": "im",
+		"user": "U999", "text": "let me in", "ts": "1.0",
+	})
+	f.sendEvent(map[string]interface{}{
+		"type": "app_mention", "channel": "C999",
+		"user": testUserID, "text": "<@" + testBotUserID + "> hi", "ts": "2.0",
+	})
+	expectNoMessage(t, a)
+
+	f.sendEvent(map[string]interface{}{
+		"type": "app_mention", "channel": testChannelID,
+		"user": testUserID, "text": "<@" + testBotUserID + "> hi", "ts": "3.0",
+	})
+	assert.Equal(t, "hi", receive(t, a).Text)
+}
+
+func TestAdapter_PairingFlow(t *testing.T) {
+	f := newFakeSlack(t)
+	db := setupPairingDB(t)
+	a := startTestAdapter(t, f, NewFactoryWithDB(db), nil)
+	require.True(t, a.IsPairingEnabled())
+
+	f.sendEvent(map[string]interface{}{
+		"type": "app_mention", "channel": testChannelID,
+		"user": testUserID, "text": "<@" + testBotUserID + "> hi", "ts": "1.0",
+	})
+	expectNoMessage(t, a)
+
+	// The code is shown only to the user
+	require.Eventually(t, func() bool { return len(f.callsTo("chat.postEphemeral")) == 1 }, time.Second, 10*time.Millisecond)
+	code, err := a.GetPairingManager().GetActivePairingCode(testUserID)
+	require.NoError(t, err)
+	assert.Contains(t, f.callsTo("chat.postEphemeral")[0].Body["text"], code.Code)
+
+	require.NoError(t, a.GetPairingManager().ApprovePairing(code.Code))
+
+	f.sendEvent(map[string]interface{}{
+		"type": "app_mention", "channel": testChannelID,
+		"user": testUserID, "text": "<@" + testBotUserID + "> hi again", "ts": "2.0",
+	})
+	assert.Equal(t, "hi again", receive(t, a).Text)
+}
+
+func TestAdapter_SlashCommandAndButtons(t *testing.T) {
+	f := newFakeSlack(t)
+	a := startTestAdapter(t, f, NewFactory(), nil)
+
+	f.send("slash_commands", map[string]interface{}{
+		"command": "/conduit", "text": "reset", "user_id": testUserID, "channel_id": testChannelID,
+	})
+	msg := receive(t, a)
+	assert.Equal(t, "/reset", msg.Text)
+	assert.Equal(t, "slack_"+testChannelID, msg.SessionKey)
+
+	f.send("interactive", map[string]interface{}{
+		"type":      "block_actions",
+		"user":      map[string]string{"id": testUserID},
+		"channel":   map[string]string{"id": testChannelID},
+		"container": map[string]string{"message_ts": "5.1", "thread_ts": "5.0"},
+		"actions":   []map[string]string{{"action_id": "conduit_button_0_0", "value": "/approve abc"}},
+	})
+	msg = receive(t, a)
+	assert.Equal(t, "/approve abc", msg.Text)
+	assert.Equal(t, "callback_query", msg.Metadata["type"])
+	assert.Equal(t, testChannelID+":5.0", msg.UserID)
+}
+
+func TestAdapter_FileShare(t *testing.T) {
+	f := newFakeSlack(t)
+	a := startTestAdapter(t, f, NewFactory(), map[string]interface{}{"media_dir": t.TempDir()})
+
+	f.sendEvent(map[string]interface{}{
+		"type": "message", "subtype": "file_share", "channel": testDMID, "channel_type": "im",
+		"user": testUserID, "ts": "1.0",
+		"files": []map[string]interface{}{{
+			"id": "F1", "name": "photo.png", "mimetype": "image/png", "size": 20,
+			"url_private_download": f.server.URL + "/api/files/photo.png",
+		}},
+	})
+
+	msg := receive(t, a)
+	assert.Equal(t, "[Attachment: photo.png]", msg.Text)
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, protocol.AttachmentImage, msg.Attachments[0].Type)
+	assert.FileExists(t, msg.Attachments[0].Path)
+}
+
+func TestAdapter_SendMessage(t *testing.T) {
+	f := newFakeSlack(t)
+	a := startTestAdapter(t, f, NewFactory(), nil)
+
+	keyboard, _ := json.Marshal([][]protocol.InlineButton{{{Text: "Approve", Data: "/approve 1"}}})
+	require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{
+		UserID:   testChannelID + ":2.0",
+		Text:     "**Done**, see [docs](https://example.com)",
+		Metadata: map[string]string{protocol.MetadataInlineKeyboard: string(keyboard)},
+	}))
+
+	calls := f.callsTo("chat.postMessage")
+	require.Len(t, calls, 1)
+	body := calls[0].Body
+	assert.Equal(t, testChannelID, body["channel"])
+	assert.Equal(t, "2.0", body["thread_ts"])
+	assert.Equal(t, "*Done*, see <https://example.com|docs>", body["text"])
+	blocks := body["blocks"].([]interface{})
+	require.Len(t, blocks, 2)
+	button := blocks[1].(map[string]interface{})["elements"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "/approve 1", button["value"])
+
+	// Replying in a thread means follow-ups there are forwarded
+	assert.True(t, a.isFollowedThread(testChannelID+":2.0"))
+}
+
+func TestAdapter_StreamingMessages(t *testing.T) {
+	f := newFakeSlack(t)
+	a := startTestAdapter(t, f, NewFactory(), nil)
+
+	var _ channels.TextStreamingAdapter = a
+
+	ts, err := a.SendStreamingMessage(testDMID, "...")
+	require.NoError(t, err)
+	assert.Equal(t, "1700000000.000200", ts)
+
+	require.NoError(t, a.EditStreamingMessage(testDMID, ts, "partial answer"))
+	updates := f.callsTo("chat.update")
+	require.Len(t, updates, 1)
+	assert.Equal(t, ts, updates[0].Body["ts"])
+	assert.Equal(t, "partial answer", updates[0].Body["text"])
+
+	// Deleting a message that is already gone is not an error
+	assert.NoError(t, a.DeleteStreamingMessage(testDMID, ts))
+}
+
+func TestConvertToMrkdwn(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"## Summary", "*Summary*"},
+		{"a **bold** word", "a *bold* word"},
+		{"[link](https://x.io/a?b=1&c=2)", "<https://x.io/a?b=1&amp;c=2|link>"},
+		{"1 < 2 & 3 > 2", "1 &lt; 2 &amp; 3 &gt; 2"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, convertToMrkdwn(tt.in))
+	}
+}
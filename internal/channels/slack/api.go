@@ -0,0 +1,300 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DefaultAPIURL is the Slack Web API base URL
+const DefaultAPIURL = "https://slack.com/api"
+
+// maxRateLimitRetries bounds how often a call is retried after a 429
+const maxRateLimitRetries = 3
+
+// file is a file shared in a Slack message
+type file struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Mimetype           string `json:"mimetype"`
+	Size               int64  `json:"size"`
+	URLPrivateDownload string `json:"url_private_download"`
+}
+
+// event is the inner event of an Events API payload. Only message and
+// app_mention events are handled.
+type event struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type"`
+	User        string `json:"user"`
+	BotID       string `json:"bot_id"`
+	Text        string `json:"text"`
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts"`
+	Files       []file `json:"files"`
+}
+
+// slashCommand is a slash command invocation
+type slashCommand struct {
+	Command   string `json:"command"`
+	Text      string `json:"text"`
+	UserID    string `json:"user_id"`
+	UserName  string `json:"user_name"`
+	ChannelID string `json:"channel_id"`
+}
+
+// blockActions is an interactive payload sent when a button is pressed
+type blockActions struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	Container struct {
+		MessageTS string `json:"message_ts"`
+		ThreadTS  string `json:"thread_ts"`
+	} `json:"container"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// apiError is an error returned by the Slack Web API
+type apiError struct {
+	Method string
+	Code   string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("slack %s failed: %s", e.Method, e.Code)
+}
+
+// webClient is a minimal client for the Slack Web API
+type webClient struct {
+	baseURL    string
+	botToken   string
+	appToken   string
+	httpClient *http.Client
+}
+
+func newWebClient(baseURL, botToken, appToken string) *webClient {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &webClient{
+		baseURL:    baseURL,
+		botToken:   botToken,
+		appToken:   appToken,
+		httpClient: &http.Client{Timeout: 2 * time.Minute}, // Long enough for file transfers
+	}
+}
+
+// call invokes a Web API method with a JSON body and decodes the response
+// into out. Rate limited calls are retried after the Retry-After delay.
+func (c *webClient) call(ctx context.Context, method, token string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("slack %s failed: %w", method, err)
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			wait := time.Second
+			if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				wait = time.Duration(secs) * time.Second
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return &apiError{Method: method, Code: fmt.Sprintf("HTTP %d", resp.StatusCode)}
+		}
+
+		var result struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", method, err)
+		}
+		if !result.OK {
+			return &apiError{Method: method, Code: result.Error}
+		}
+		if out != nil {
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return fmt.Errorf("failed to decode %s response: %w", method, err)
+			}
+		}
+		return nil
+	}
+}
+
+// authTest returns the bot's user ID and team
+func (c *webClient) authTest(ctx context.Context) (userID, team string, err error) {
+	var resp struct {
+		UserID string `json:"user_id"`
+		Team   string `json:"team"`
+	}
+	if err := c.call(ctx, "auth.test", c.botToken, map[string]string{}, &resp); err != nil {
+		return "", "", err
+	}
+	return resp.UserID, resp.Team, nil
+}
+
+// openConnection returns a Socket Mode websocket URL
+func (c *webClient) openConnection(ctx context.Context) (string, error) {
+	var resp struct {
+		URL string `json:"url"`
+	}
+	if err := c.call(ctx, "apps.connections.open", c.appToken, map[string]string{}, &resp); err != nil {
+		return "", err
+	}
+	return resp.URL, nil
+}
+
+// postMessage posts a message and returns its timestamp
+func (c *webClient) postMessage(ctx context.Context, params map[string]interface{}) (string, error) {
+	var resp struct {
+		TS string `json:"ts"`
+	}
+	if err := c.call(ctx, "chat.postMessage", c.botToken, params, &resp); err != nil {
+		return "", err
+	}
+	return resp.TS, nil
+}
+
+// postEphemeral shows a message to a single user in a channel
+func (c *webClient) postEphemeral(ctx context.Context, channel, user, text string) error {
+	return c.call(ctx, "chat.postEphemeral", c.botToken, map[string]string{
+		"channel": channel, "user": user, "text": text,
+	}, nil)
+}
+
+// updateMessage replaces the text of a message
+func (c *webClient) updateMessage(ctx context.Context, channel, ts, text string) error {
+	return c.call(ctx, "chat.update", c.botToken, map[string]string{
+		"channel": channel, "ts": ts, "text": text,
+	}, nil)
+}
+
+// deleteMessage deletes a message
+func (c *webClient) deleteMessage(ctx context.Context, channel, ts string) error {
+	return c.call(ctx, "chat.delete", c.botToken, map[string]string{
+		"channel": channel, "ts": ts,
+	}, nil)
+}
+
+// uploadFile shares a local file in a channel or thread using the external
+// upload flow: reserve an upload URL, send the bytes, then complete it
+func (c *webClient) uploadFile(ctx context.Context, channel, threadTS, path, comment string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	name := filepath.Base(path)
+
+	// files.getUploadURLExternal only accepts form parameters
+	form := url.Values{"filename": {name}, "length": {strconv.Itoa(len(data))}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/files.getUploadURLExternal", bytes.NewReader([]byte(form.Encode())))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.botToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack files.getUploadURLExternal failed: %w", err)
+	}
+	var upload struct {
+		OK        bool   `json:"ok"`
+		Error     string `json:"error"`
+		UploadURL string `json:"upload_url"`
+		FileID    string `json:"file_id"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&upload)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode files.getUploadURLExternal response: %w", err)
+	}
+	if !upload.OK {
+		return &apiError{Method: "files.getUploadURLExternal", Code: upload.Error}
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, upload.UploadURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	resp, err = c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("file upload failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("file upload failed with status %d", resp.StatusCode)
+	}
+
+	params := map[string]interface{}{
+		"files":      []map[string]string{{"id": upload.FileID, "title": name}},
+		"channel_id": channel,
+	}
+	if threadTS != "" {
+		params["thread_ts"] = threadTS
+	}
+	if comment != "" {
+		params["initial_comment"] = comment
+	}
+	return c.call(ctx, "files.completeUploadExternal", c.botToken, params, nil)
+}
+
+// download fetches a private file; Slack requires the bot token for these
+func (c *webClient) download(ctx context.Context, fileURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.botToken)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,95 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// fileLabel is the placeholder text for a message with only files
+func fileLabel(files []file) string {
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Name
+	}
+	return fmt.Sprintf("[Attachment: %s]", strings.Join(names, ", "))
+}
+
+// downloadFiles stores a message's files in the session's media directory.
+// Failures are recorded in metadata so the message can still be forwarded;
+// failed reports whether any download failed.
+func (a *Adapter) downloadFiles(ctx context.Context, sessionKey string, ev *event, metadata map[string]string) (attachments []protocol.Attachment, failed bool) {
+	var errs []string
+	for _, f := range ev.Files {
+		attachment, err := a.downloadFile(ctx, sessionKey, f)
+		if err != nil {
+			log.Printf("[Slack] Failed to download file from channel %s: %v", ev.Channel, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", f.Name, err))
+			continue
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	if len(errs) > 0 {
+		metadata["attachment_error"] = strings.Join(errs, "; ")
+	}
+	return attachments, len(errs) > 0
+}
+
+// downloadFile fetches one private file and stores it
+func (a *Adapter) downloadFile(ctx context.Context, sessionKey string, f file) (*protocol.Attachment, error) {
+	if a.config.MediaDir == "" {
+		return nil, fmt.Errorf("media directory not configured")
+	}
+	if f.URLPrivateDownload == "" {
+		return nil, fmt.Errorf("file has no download URL")
+	}
+
+	maxBytes := int64(channels.DefaultMaxAttachmentBytes)
+	if a.config.MaxDownloadMB > 0 {
+		maxBytes = int64(a.config.MaxDownloadMB) * 1024 * 1024
+	}
+	if f.Size > maxBytes {
+		return nil, fmt.Errorf("file is %d bytes, limit is %d", f.Size, maxBytes)
+	}
+
+	resp, err := a.api.download(ctx, f.URLPrivateDownload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	path, size, sniffed, err := channels.SaveAttachment(a.config.MediaDir, sessionKey, f.ID+"_"+f.Name, resp.Body, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType := f.Mimetype
+	if mediaType == "" {
+		mediaType = sniffed
+	}
+
+	return &protocol.Attachment{
+		Type:      attachmentType(mediaType),
+		Path:      path,
+		MediaType: mediaType,
+		Filename:  filepath.Base(path),
+		Size:      size,
+	}, nil
+}
+
+// attachmentType classifies an attachment by media type
+func attachmentType(mediaType string) string {
+	switch {
+	case mediaType == "image/jpeg", mediaType == "image/png", mediaType == "image/gif", mediaType == "image/webp":
+		return protocol.AttachmentImage
+	case strings.HasPrefix(mediaType, "audio/"):
+		return protocol.AttachmentAudio
+	}
+	return protocol.AttachmentDocument
+}
@@ -0,0 +1,242 @@
+package slack
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Pairing constants
+const (
+	// Pairing code expiration time
+	PairingCodeExpiration = 1 * time.Hour
+
+	// Pairing messages (Slack mrkdwn)
+	PairingCodeMessage = `:closed_lock_with_key: *Conduit Pairing Required*
+
+To use this bot, please provide this pairing code to your Conduit admin:
+
+` + "`%s`" + `
+
+:alarm_clock: This code expires in 1 hour.`
+
+	PairingErrorMessage = `:x: *Pairing Error*
+
+There was an error during the pairing process. Please try again later or contact your administrator.`
+)
+
+// PairingRecord represents a pairing code record in the database
+type PairingRecord struct {
+	Code      string    `json:"code"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	IsActive  bool      `json:"is_active"`
+}
+
+// PairingManager handles Slack pairing codes, following the Telegram flow:
+// an unpaired user is sent a code, and an admin approves it with
+// `conduit pairing slack approve`
+type PairingManager struct {
+	db *sql.DB
+}
+
+// NewPairingManager creates a new pairing manager
+func NewPairingManager(db *sql.DB) *PairingManager {
+	return &PairingManager{db: db}
+}
+
+// GeneratePairingCode generates a new pairing code for the given user,
+// replacing any active one
+func (p *PairingManager) GeneratePairingCode(userID string) (string, error) {
+	// Unlike Telegram, old codes are deleted rather than deactivated, since an
+	// inactive code means an approved pairing
+	if _, err := p.db.Exec(`DELETE FROM slack_pairings WHERE user_id = ? AND is_active = 1`, userID); err != nil {
+		log.Printf("[Pairing] Warning: Failed to remove existing codes for Slack user %s: %v", userID, err)
+	}
+
+	code := uuid.New().String()
+	now := time.Now()
+	_, err := p.db.Exec(`
+		INSERT INTO slack_pairings (code, user_id, created_at, expires_at, is_active, metadata)
+		VALUES (?, ?, ?, ?, 1, '{}')
+	`, code, userID, now, now.Add(PairingCodeExpiration))
+	if err != nil {
+		return "", fmt.Errorf("failed to create pairing code: %w", err)
+	}
+
+	log.Printf("[Pairing] Generated new pairing code for Slack user %s", userID)
+	return code, nil
+}
+
+// ValidatePairingCode returns the active, unexpired record for code
+func (p *PairingManager) ValidatePairingCode(code string) (*PairingRecord, error) {
+	record, err := p.scanRecord(p.db.QueryRow(`
+		SELECT code, user_id, created_at, expires_at, is_active
+		FROM slack_pairings
+		WHERE code = ? AND is_active = 1
+	`, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pairing code not found or inactive")
+		}
+		return nil, fmt.Errorf("failed to validate pairing code: %w", err)
+	}
+
+	if time.Now().After(record.ExpiresAt) {
+		return nil, fmt.Errorf("pairing code has expired")
+	}
+	return record, nil
+}
+
+// ApprovePairing approves a pairing by marking the code as used
+func (p *PairingManager) ApprovePairing(code string) error {
+	if _, err := p.ValidatePairingCode(code); err != nil {
+		return fmt.Errorf("cannot approve pairing: %w", err)
+	}
+
+	result, err := p.db.Exec(`UPDATE slack_pairings SET is_active = 0 WHERE code = ? AND is_active = 1`, code)
+	if err != nil {
+		return fmt.Errorf("failed to approve pairing: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("pairing code not found or already used")
+	}
+
+	log.Printf("[Pairing] Approved Slack pairing for code %s", code[:8]+"...")
+	return nil
+}
+
+// IsUserPaired checks if a user has an approved (used) pairing code
+func (p *PairingManager) IsUserPaired(userID string) (bool, error) {
+	var count int
+	err := p.db.QueryRow(`SELECT COUNT(*) FROM slack_pairings WHERE user_id = ? AND is_active = 0`, userID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check user pairing status: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetActivePairingCode gets the current unexpired pairing code for a user
+func (p *PairingManager) GetActivePairingCode(userID string) (*PairingRecord, error) {
+	record, err := p.scanRecord(p.db.QueryRow(`
+		SELECT code, user_id, created_at, expires_at, is_active
+		FROM slack_pairings
+		WHERE user_id = ? AND is_active = 1 AND expires_at > ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID, time.Now()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("no active pairing code found")
+		}
+		return nil, fmt.Errorf("failed to get active pairing code: %w", err)
+	}
+	return record, nil
+}
+
+// ListPairings returns pairing codes, newest first. Approved pairings are
+// included; expired codes only if includeExpired is set.
+func (p *PairingManager) ListPairings(includeExpired bool) ([]PairingRecord, error) {
+	query := `SELECT code, user_id, created_at, expires_at, is_active FROM slack_pairings`
+	var args []interface{}
+	if !includeExpired {
+		query += ` WHERE is_active = 0 OR expires_at > ?`
+		args = append(args, time.Now())
+	}
+	rows, err := p.db.Query(query+` ORDER BY created_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pairings: %w", err)
+	}
+	defer rows.Close()
+
+	var records []PairingRecord
+	for rows.Next() {
+		record, err := p.scanRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pairing row: %w", err)
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+// FindPairingCode resolves a full code or unique prefix of an active code
+func (p *PairingManager) FindPairingCode(prefix string) (string, error) {
+	rows, err := p.db.Query(`SELECT code FROM slack_pairings WHERE code LIKE ? AND is_active = 1`, prefix+"%")
+	if err != nil {
+		return "", fmt.Errorf("failed to search for pairing code: %w", err)
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return "", err
+		}
+		codes = append(codes, code)
+	}
+	switch len(codes) {
+	case 0:
+		return "", fmt.Errorf("no active pairing code found matching: %s", prefix)
+	case 1:
+		return codes[0], nil
+	}
+	return "", fmt.Errorf("ambiguous prefix %s matches %d codes, please provide more characters", prefix, len(codes))
+}
+
+// CleanupExpiredCodes removes expired, unapproved pairing codes
+func (p *PairingManager) CleanupExpiredCodes() error {
+	result, err := p.db.Exec(`DELETE FROM slack_pairings WHERE expires_at < ? AND is_active = 1`, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to cleanup expired codes: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("[Pairing] Cleaned up %d expired Slack pairing codes", n)
+	}
+	return nil
+}
+
+// HandlePairingForUser checks whether a user is paired. If not, it sends
+// them their active pairing code (generating one if needed) through notify.
+// Returns whether the user is paired and their message should be processed.
+func (p *PairingManager) HandlePairingForUser(userID string, notify func(text string) error) (bool, error) {
+	isPaired, err := p.IsUserPaired(userID)
+	if err != nil {
+		notify(PairingErrorMessage)
+		return false, err
+	}
+	if isPaired {
+		return true, nil
+	}
+
+	code := ""
+	if active, err := p.GetActivePairingCode(userID); err == nil {
+		code = active.Code
+	} else if code, err = p.GeneratePairingCode(userID); err != nil {
+		notify(PairingErrorMessage)
+		return false, err
+	}
+
+	if err := notify(fmt.Sprintf(PairingCodeMessage, code)); err != nil {
+		return false, fmt.Errorf("failed to send pairing code: %w", err)
+	}
+	return false, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (p *PairingManager) scanRecord(row rowScanner) (*PairingRecord, error) {
+	var record PairingRecord
+	if err := row.Scan(&record.Code, &record.UserID, &record.CreatedAt, &record.ExpiresAt, &record.IsActive); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
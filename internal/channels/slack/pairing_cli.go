@@ -0,0 +1,129 @@
+package slack
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"conduit/internal/database"
+
+	"github.com/spf13/cobra"
+	_ "modernc.org/sqlite"
+)
+
+// PairingCLIConfig holds configuration for pairing CLI commands
+type PairingCLIConfig struct {
+	DatabasePath string
+	Verbose      bool
+}
+
+// SlackPairingRootCmd creates the root pairing command for Slack with subcommands
+func SlackPairingRootCmd(config *PairingCLIConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "slack",
+		Short: "Manage Slack pairing codes",
+		Long:  `List and approve Slack pairing codes for Conduit Gateway Slack integration.`,
+	}
+
+	var includeExpired bool
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List Slack pairing codes",
+		Long:  `List pending and approved Slack pairing codes. Use --include-expired to show expired codes as well.`,
+		Example: `  conduit pairing slack list
+  conduit pairing slack list --include-expired`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withPairingManager(config, func(p *PairingManager) error {
+				return listPairings(p, includeExpired)
+			})
+		},
+	}
+	listCmd.Flags().BoolVar(&includeExpired, "include-expired", false, "Include expired pairing codes in the list")
+
+	approveCmd := &cobra.Command{
+		Use:   "approve <CODE>",
+		Short: "Approve a Slack pairing code",
+		Long:  `Approve a Slack pairing code. The user can message the bot from then on. A unique prefix of the code is enough.`,
+		Example: `  conduit pairing slack approve 550e8400-e29b-41d4-a716-446655440000
+  conduit pairing slack approve 550e8400`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withPairingManager(config, func(p *PairingManager) error {
+				code, err := p.FindPairingCode(args[0])
+				if err != nil {
+					return err
+				}
+				record, err := p.ValidatePairingCode(code)
+				if err != nil {
+					return err
+				}
+				if err := p.ApprovePairing(code); err != nil {
+					return err
+				}
+				fmt.Printf("✅ Approved pairing for Slack user %s\n", record.UserID)
+				return nil
+			})
+		},
+	}
+
+	cmd.AddCommand(listCmd)
+	cmd.AddCommand(approveCmd)
+	return cmd
+}
+
+// withPairingManager opens the gateway database and runs fn against it
+func withPairingManager(config *PairingCLIConfig, fn func(p *PairingManager) error) error {
+	// Update config from environment if not set
+	if config.DatabasePath == "" {
+		if dbPath := os.Getenv("CONDUIT_DB_PATH"); dbPath != "" {
+			config.DatabasePath = dbPath
+		} else {
+			config.DatabasePath = "gateway.db" // default
+		}
+	}
+
+	db, err := sql.Open("sqlite", config.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if err := database.ConfigureDatabase(db); err != nil {
+		return fmt.Errorf("failed to configure database: %w", err)
+	}
+
+	return fn(NewPairingManager(db))
+}
+
+// listPairings prints pairing codes as a table
+func listPairings(p *PairingManager, includeExpired bool) error {
+	records, err := p.ListPairings(includeExpired)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Println("No Slack pairing codes found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CODE\tUSER ID\tCREATED\tEXPIRES\tSTATUS")
+	for _, r := range records {
+		status := "Pending"
+		if !r.IsActive {
+			status = "Approved"
+		} else if time.Now().After(r.ExpiresAt) {
+			status = "Expired"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			r.Code[:8]+"...",
+			r.UserID,
+			r.CreatedAt.Format("2006-01-02 15:04"),
+			r.ExpiresAt.Format("2006-01-02 15:04"),
+			status,
+		)
+	}
+	return w.Flush()
+}
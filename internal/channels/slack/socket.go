@@ -0,0 +1,148 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"conduit/internal/channels"
+)
+
+// Reconnect backoff bounds
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 2 * time.Minute
+)
+
+// envelope is a Socket Mode frame. Every frame with an envelope ID must be
+// acknowledged within three seconds or Slack redelivers it.
+type envelope struct {
+	EnvelopeID   string          `json:"envelope_id"`
+	Type         string          `json:"type"`
+	Payload      json.RawMessage `json:"payload"`
+	RetryAttempt int             `json:"retry_attempt"`
+	Reason       string          `json:"reason"`
+}
+
+// runSocket keeps a Socket Mode connection open until ctx is cancelled,
+// reconnecting with exponential backoff
+func (a *Adapter) runSocket(ctx context.Context) {
+	delay := minReconnectDelay
+	for {
+		connectedAt := time.Now()
+		err := a.connectSocket(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// A connection that stayed up for a while starts the backoff over
+		if time.Since(connectedAt) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+		log.Printf("[Slack] Socket Mode disconnected: %v (reconnecting in %v)", err, delay)
+		a.setStatus(channels.StatusReconnecting, fmt.Sprintf("Socket Mode disconnected: %v", err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// connectSocket runs a single Socket Mode connection until it drops
+func (a *Adapter) connectSocket(ctx context.Context) error {
+	url, err := a.api.openConnection(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open connection: %w", err)
+	}
+
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer ws.Close()
+
+	// Unblock reads when the adapter is stopped
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+
+	// Handlers call the Web API, so they run on a worker that keeps the read
+	// loop free for acks while preserving message order
+	work := make(chan func(), 100)
+	defer close(work)
+	go func() {
+		for fn := range work {
+			fn()
+		}
+	}()
+
+	for {
+		var env envelope
+		if err := ws.ReadJSON(&env); err != nil {
+			return err
+		}
+		if env.EnvelopeID != "" {
+			if err := ws.WriteJSON(map[string]string{"envelope_id": env.EnvelopeID}); err != nil {
+				return fmt.Errorf("failed to acknowledge envelope: %w", err)
+			}
+		}
+
+		switch env.Type {
+		case "hello":
+			a.setStatus(channels.StatusOnline, "Connected to Slack")
+			log.Printf("[Slack] Socket Mode connected")
+
+		case "disconnect":
+			// Sent before Slack rotates the connection
+			return fmt.Errorf("slack requested disconnect (%s)", env.Reason)
+
+		case "events_api":
+			var callback struct {
+				Event event `json:"event"`
+			}
+			if err := json.Unmarshal(env.Payload, &callback); err != nil {
+				log.Printf("[Slack] Invalid events_api payload: %v", err)
+				continue
+			}
+			// Redeliveries happen when an ack was lost; the event was already handled
+			if env.RetryAttempt > 0 {
+				continue
+			}
+			work <- func() { a.handleEvent(ctx, &callback.Event) }
+
+		case "slash_commands":
+			var cmd slashCommand
+			if err := json.Unmarshal(env.Payload, &cmd); err != nil {
+				log.Printf("[Slack] Invalid slash command payload: %v", err)
+				continue
+			}
+			work <- func() { a.handleSlashCommand(ctx, &cmd) }
+
+		case "interactive":
+			var actions blockActions
+			if err := json.Unmarshal(env.Payload, &actions); err != nil {
+				log.Printf("[Slack] Invalid interactive payload: %v", err)
+				continue
+			}
+			if actions.Type == "block_actions" {
+				work <- func() { a.handleBlockActions(ctx, &actions) }
+			}
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Supervisor defaults, used when SupervisorOptions leaves a field zero
+const (
+	DefaultSupervisorInterval    = 30 * time.Second
+	DefaultSupervisorGrace       = 5 * time.Minute
+	DefaultSupervisorBackoff     = 10 * time.Second
+	DefaultSupervisorMaxBackoff  = 10 * time.Minute
+	DefaultSupervisorMaxRestarts = 5
+)
+
+// Supervisor event kinds
+const (
+	SupervisorStatusChange = "status_change"  // The adapter's status changed
+	SupervisorRestarted    = "restarted"      // The adapter was restarted
+	SupervisorRestartFail  = "restart_failed" // The replacement adapter failed to start
+	SupervisorGaveUp       = "gave_up"        // The restart budget is used up
+	SupervisorRecovered    = "recovered"      // Healthy again after restarts
+)
+
+// notRunning is the status reported for configured adapters that failed to
+// start and so have no instance to ask
+const notRunning = StatusError
+
+// SupervisorOptions controls how unhealthy adapters are restarted
+type SupervisorOptions struct {
+	Interval    time.Duration // How often adapter health is checked
+	Backoff     time.Duration // Delay after the first restart, doubled after each further restart
+	MaxBackoff  time.Duration // Upper bound on the delay between restarts
+	MaxRestarts int           // Restarts without recovering before the supervisor gives up
+
+	// Grace is how long an initializing or reconnecting adapter may try to
+	// recover on its own, and how long a restarted adapter must stay healthy
+	// before its restarts stop counting against MaxRestarts
+	Grace time.Duration
+}
+
+// SupervisorEvent describes a status transition or restart of an adapter
+type SupervisorEvent struct {
+	Kind      string
+	AdapterID string
+	OldStatus StatusCode
+	NewStatus StatusCode
+	Restarts  int // Restarts since the adapter last recovered
+	Message   string
+	Err       error
+}
+
+// SupervisedAdapter is the supervisor's view of one adapter
+type SupervisedAdapter struct {
+	ID                  string     `json:"id"`
+	Type                string     `json:"type"`
+	Status              StatusCode `json:"status"`
+	Healthy             bool       `json:"healthy"`
+	Restarts            int        `json:"restarts"`             // Restarts since the gateway started
+	ConsecutiveRestarts int        `json:"consecutive_restarts"` // Restarts since the adapter last recovered
+	LastRestart         *time.Time `json:"last_restart,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	GaveUp              bool       `json:"gave_up"`
+}
+
+// supervisedState tracks one adapter between health checks
+type supervisedState struct {
+	status         StatusCode
+	healthy        bool
+	healthySince   time.Time
+	unhealthySince time.Time
+	consecutive    int
+	restarts       int
+	lastRestart    time.Time
+	nextRestart    time.Time
+	lastError      string
+	gaveUp         bool
+}
+
+// Supervisor polls adapter health and restarts adapters that stay unhealthy,
+// backing off exponentially between restarts. After MaxRestarts restarts
+// without recovering it gives up on the adapter until the adapter becomes
+// healthy again on its own or is restarted by hand.
+type Supervisor struct {
+	manager *Manager
+	opts    SupervisorOptions
+	publish func(SupervisorEvent)
+
+	mutex  sync.Mutex
+	states map[string]*supervisedState
+}
+
+// NewSupervisor creates a supervisor for the manager's adapters. publish,
+// if not nil, is called for every status transition and restart.
+func NewSupervisor(manager *Manager, opts SupervisorOptions, publish func(SupervisorEvent)) *Supervisor {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultSupervisorInterval
+	}
+	if opts.Grace <= 0 {
+		opts.Grace = DefaultSupervisorGrace
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultSupervisorBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultSupervisorMaxBackoff
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = opts.Backoff
+	}
+	if opts.MaxRestarts <= 0 {
+		opts.MaxRestarts = DefaultSupervisorMaxRestarts
+	}
+	if publish == nil {
+		publish = func(SupervisorEvent) {}
+	}
+	return &Supervisor{
+		manager: manager,
+		opts:    opts,
+		publish: publish,
+		states:  make(map[string]*supervisedState),
+	}
+}
+
+// Run checks adapter health every interval until ctx is cancelled
+func (s *Supervisor) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Check(time.Now())
+		}
+	}
+}
+
+// Check runs one round of health checks and restarts
+func (s *Supervisor) Check(now time.Time) {
+	for _, id := range s.supervisedIDs() {
+		s.checkAdapter(id, now)
+	}
+	s.forgetRemoved()
+}
+
+// supervisedIDs returns the configured adapters, leaving out the per-client
+// TUI adapters, which go offline whenever their client disconnects
+func (s *Supervisor) supervisedIDs() []string {
+	s.manager.mutex.RLock()
+	defer s.manager.mutex.RUnlock()
+
+	var ids []string
+	for id, config := range s.manager.configs {
+		if config.Type != "tui" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// forgetRemoved drops state for adapters that were removed from the manager
+func (s *Supervisor) forgetRemoved() {
+	s.manager.mutex.RLock()
+	defer s.manager.mutex.RUnlock()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id := range s.states {
+		if _, configured := s.manager.configs[id]; !configured {
+			delete(s.states, id)
+		}
+	}
+}
+
+// checkAdapter records an adapter's status and restarts it if it is due
+func (s *Supervisor) checkAdapter(id string, now time.Time) {
+	status, healthy := notRunning, false
+	if adapter, running := s.manager.GetAdapter(id); running {
+		status, healthy = adapter.Status().Status, adapter.IsHealthy()
+	}
+
+	s.mutex.Lock()
+	state, known := s.states[id]
+	if !known {
+		state = &supervisedState{status: status, healthy: true}
+		s.states[id] = state
+	}
+	var events []SupervisorEvent
+
+	if known && status != state.status {
+		events = append(events, SupervisorEvent{
+			Kind:      SupervisorStatusChange,
+			AdapterID: id,
+			OldStatus: state.status,
+			NewStatus: status,
+			Restarts:  state.consecutive,
+			Message:   fmt.Sprintf("Channel %s changed from %s to %s", id, state.status, status),
+		})
+	}
+	state.status = status
+
+	if healthy {
+		if !state.healthy {
+			state.healthy = true
+			state.healthySince = now
+		}
+		state.gaveUp = false
+		// Only an adapter that stays up has recovered; one that keeps
+		// failing soon after each restart still uses up the budget
+		if state.consecutive > 0 && now.Sub(state.healthySince) >= s.opts.Grace {
+			events = append(events, SupervisorEvent{
+				Kind:      SupervisorRecovered,
+				AdapterID: id,
+				NewStatus: status,
+				Restarts:  state.consecutive,
+				Message:   fmt.Sprintf("Channel %s recovered after %d restarts", id, state.consecutive),
+			})
+			state.consecutive = 0
+			state.nextRestart = time.Time{}
+		}
+		s.mutex.Unlock()
+		s.emit(events)
+		return
+	}
+
+	if state.healthy {
+		state.healthy = false
+		state.unhealthySince = now
+	}
+
+	// Adapters retry on their own while initializing or reconnecting
+	recovering := status == StatusInitializing || status == StatusReconnecting
+	due := !state.gaveUp && !now.Before(state.nextRestart) &&
+		(!recovering || now.Sub(state.unhealthySince) >= s.opts.Grace)
+	if due && state.consecutive >= s.opts.MaxRestarts {
+		state.gaveUp = true
+		due = false
+		events = append(events, SupervisorEvent{
+			Kind:      SupervisorGaveUp,
+			AdapterID: id,
+			NewStatus: status,
+			Restarts:  state.consecutive,
+			Message:   fmt.Sprintf("Channel %s is still %s after %d restarts; giving up", id, status, state.consecutive),
+		})
+	}
+	s.mutex.Unlock()
+	s.emit(events)
+
+	if due {
+		s.restart(id, status, now)
+	}
+}
+
+// restart replaces an adapter and records the attempt
+func (s *Supervisor) restart(id string, status StatusCode, now time.Time) {
+	log.Printf("[ChannelSupervisor] Channel %s is %s, restarting", id, status)
+	err := s.manager.RestartAdapter(id)
+
+	s.mutex.Lock()
+	state := s.states[id]
+	state.consecutive++
+	state.restarts++
+	state.lastRestart = now
+	state.nextRestart = now.Add(s.backoff(state.consecutive))
+	event := SupervisorEvent{
+		Kind:      SupervisorRestarted,
+		AdapterID: id,
+		OldStatus: status,
+		Restarts:  state.consecutive,
+		Message:   fmt.Sprintf("Restarted channel %s (restart %d of %d)", id, state.consecutive, s.opts.MaxRestarts),
+	}
+	if err != nil {
+		state.lastError = err.Error()
+		event.Kind = SupervisorRestartFail
+		event.Err = err
+		event.Message = fmt.Sprintf("Failed to restart channel %s (restart %d of %d): %v", id, state.consecutive, s.opts.MaxRestarts, err)
+	}
+	s.mutex.Unlock()
+
+	if err != nil {
+		log.Printf("[ChannelSupervisor] %s", event.Message)
+	}
+	s.emit([]SupervisorEvent{event})
+}
+
+// backoff returns the delay after the given number of consecutive restarts
+func (s *Supervisor) backoff(restarts int) time.Duration {
+	delay := s.opts.Backoff
+	for i := 1; i < restarts && delay < s.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.opts.MaxBackoff {
+		delay = s.opts.MaxBackoff
+	}
+	return delay
+}
+
+func (s *Supervisor) emit(events []SupervisorEvent) {
+	for _, event := range events {
+		if event.Kind == SupervisorGaveUp {
+			log.Printf("[ChannelSupervisor] %s", event.Message)
+		}
+		s.publish(event)
+	}
+}
+
+// Adapters returns the supervisor's view of each supervised adapter
+func (s *Supervisor) Adapters() []SupervisedAdapter {
+	ids := s.supervisedIDs()
+
+	result := make([]SupervisedAdapter, 0, len(ids))
+	for _, id := range ids {
+		info := SupervisedAdapter{ID: id, Status: notRunning}
+		s.manager.mutex.RLock()
+		info.Type = s.manager.configs[id].Type
+		adapter, running := s.manager.adapters[id]
+		s.manager.mutex.RUnlock()
+		if running {
+			info.Status, info.Healthy = adapter.Status().Status, adapter.IsHealthy()
+		}
+
+		s.mutex.Lock()
+		if state, ok := s.states[id]; ok {
+			info.Restarts = state.restarts
+			info.ConsecutiveRestarts = state.consecutive
+			info.LastError = state.lastError
+			info.GaveUp = state.gaveUp
+			if !state.lastRestart.IsZero() {
+				lastRestart := state.lastRestart
+				info.LastRestart = &lastRestart
+			}
+		}
+		s.mutex.Unlock()
+
+		result = append(result, info)
+	}
+	return result
+}
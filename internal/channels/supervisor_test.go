@@ -0,0 +1,274 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"conduit/pkg/protocol"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAdapter reports whatever status its factory gave it
+type testAdapter struct {
+	id       string
+	mu       sync.Mutex
+	status   StatusCode
+	stopped  bool
+	incoming chan *protocol.IncomingMessage
+	startErr error
+}
+
+func (a *testAdapter) ID() string                      { return a.id }
+func (a *testAdapter) Name() string                    { return a.id }
+func (a *testAdapter) Type() string                    { return "test" }
+func (a *testAdapter) Start(ctx context.Context) error { return a.startErr }
+
+func (a *testAdapter) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopped = true
+	close(a.incoming)
+	return nil
+}
+
+func (a *testAdapter) SendMessage(msg *protocol.OutgoingMessage) error { return nil }
+
+func (a *testAdapter) ReceiveMessages() <-chan *protocol.IncomingMessage { return a.incoming }
+
+func (a *testAdapter) Status() ChannelStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return ChannelStatus{Status: a.status, Details: map[string]interface{}{}}
+}
+
+func (a *testAdapter) IsHealthy() bool {
+	return a.Status().Status == StatusOnline
+}
+
+func (a *testAdapter) setStatus(status StatusCode) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.status = status
+}
+
+// testFactory creates testAdapters with a configurable initial status
+type testFactory struct {
+	mu       sync.Mutex
+	status   StatusCode
+	startErr error
+	created  []*testAdapter
+}
+
+func (f *testFactory) GetSupportedTypes() []string          { return []string{"test"} }
+func (f *testFactory) SupportsType(adapterType string) bool { return adapterType == "test" }
+
+func (f *testFactory) CreateAdapter(config ChannelConfig) (ChannelAdapter, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a := &testAdapter{
+		id:       config.ID,
+		status:   f.status,
+		incoming: make(chan *protocol.IncomingMessage, 1),
+		startErr: f.startErr,
+	}
+	f.created = append(f.created, a)
+	return a, nil
+}
+
+func (f *testFactory) set(status StatusCode, startErr error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status = status
+	f.startErr = startErr
+}
+
+func (f *testFactory) instances() []*testAdapter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*testAdapter(nil), f.created...)
+}
+
+func (f *testFactory) latest() *testAdapter {
+	instances := f.instances()
+	return instances[len(instances)-1]
+}
+
+// eventLog collects supervisor events
+type eventLog struct {
+	mu     sync.Mutex
+	events []SupervisorEvent
+}
+
+func (l *eventLog) publish(e SupervisorEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, e)
+}
+
+func (l *eventLog) kinds() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var kinds []string
+	for _, e := range l.events {
+		kinds = append(kinds, e.Kind)
+	}
+	return kinds
+}
+
+func startSupervised(t *testing.T, factory *testFactory, opts SupervisorOptions) (*Manager, *Supervisor, *eventLog) {
+	t.Helper()
+	m := NewManager()
+	m.RegisterFactory(factory)
+	require.NoError(t, m.Start(context.Background(), []ChannelConfig{{ID: "bot", Type: "test", Enabled: true}}))
+	t.Cleanup(func() { m.Stop() })
+
+	events := &eventLog{}
+	return m, NewSupervisor(m, opts, events.publish), events
+}
+
+func TestRestartAdapter_CreatesNewInstance(t *testing.T) {
+	factory := &testFactory{status: StatusOnline}
+	m, _, _ := startSupervised(t, factory, SupervisorOptions{})
+	first := factory.latest()
+
+	require.NoError(t, m.RestartAdapter("bot"))
+
+	require.Len(t, factory.instances(), 2)
+	assert.True(t, first.stopped)
+	adapter, ok := m.GetAdapter("bot")
+	require.True(t, ok)
+	assert.Same(t, factory.latest(), adapter)
+
+	// Messages from the new instance are forwarded
+	factory.latest().incoming <- &protocol.IncomingMessage{Text: "hello"}
+	select {
+	case msg := <-m.ReceiveMessages():
+		assert.Equal(t, "hello", msg.Text)
+	case <-time.After(2 * time.Second):
+		t.Fatal("message from restarted adapter was not forwarded")
+	}
+
+	assert.Error(t, m.RestartAdapter("unknown"))
+}
+
+func TestSupervisor_RestartsWithBackoffAndBudget(t *testing.T) {
+	factory := &testFactory{status: StatusOnline}
+	_, supervisor, events := startSupervised(t, factory, SupervisorOptions{
+		Backoff:     10 * time.Second,
+		MaxBackoff:  15 * time.Second,
+		MaxRestarts: 3,
+	})
+
+	now := time.Now()
+	supervisor.Check(now)
+	assert.Len(t, factory.instances(), 1)
+
+	factory.set(StatusError, nil)
+	factory.latest().setStatus(StatusError)
+
+	supervisor.Check(now.Add(time.Second))
+	assert.Len(t, factory.instances(), 2, "an adapter in error is restarted at once")
+
+	supervisor.Check(now.Add(5 * time.Second))
+	assert.Len(t, factory.instances(), 2, "the next restart waits for the backoff")
+
+	supervisor.Check(now.Add(11 * time.Second))
+	assert.Len(t, factory.instances(), 3)
+
+	supervisor.Check(now.Add(20 * time.Second))
+	assert.Len(t, factory.instances(), 3, "the backoff doubles")
+
+	supervisor.Check(now.Add(27 * time.Second))
+	assert.Len(t, factory.instances(), 4)
+
+	supervisor.Check(now.Add(time.Hour))
+	supervisor.Check(now.Add(2 * time.Hour))
+	assert.Len(t, factory.instances(), 4, "no restarts once the budget is used up")
+
+	assert.Equal(t, []string{
+		SupervisorStatusChange, SupervisorRestarted, SupervisorRestarted, SupervisorRestarted, SupervisorGaveUp,
+	}, events.kinds())
+
+	adapters := supervisor.Adapters()
+	require.Len(t, adapters, 1)
+	assert.Equal(t, "bot", adapters[0].ID)
+	assert.Equal(t, "test", adapters[0].Type)
+	assert.Equal(t, StatusError, adapters[0].Status)
+	assert.Equal(t, 3, adapters[0].Restarts)
+	assert.True(t, adapters[0].GaveUp)
+	require.NotNil(t, adapters[0].LastRestart)
+}
+
+func TestSupervisor_GraceForReconnectingAdapters(t *testing.T) {
+	factory := &testFactory{status: StatusReconnecting}
+	_, supervisor, _ := startSupervised(t, factory, SupervisorOptions{Grace: time.Minute})
+
+	now := time.Now()
+	supervisor.Check(now)
+	supervisor.Check(now.Add(30 * time.Second))
+	assert.Len(t, factory.instances(), 1, "a reconnecting adapter gets time to recover")
+
+	supervisor.Check(now.Add(61 * time.Second))
+	assert.Len(t, factory.instances(), 2)
+}
+
+func TestSupervisor_RecoveryResetsBudget(t *testing.T) {
+	factory := &testFactory{status: StatusError}
+	_, supervisor, events := startSupervised(t, factory, SupervisorOptions{
+		Backoff:     time.Second,
+		MaxRestarts: 1,
+		Grace:       time.Minute,
+	})
+
+	now := time.Now()
+	supervisor.Check(now)
+	require.Len(t, factory.instances(), 2)
+
+	// Up briefly, then down again: the earlier restart still counts
+	factory.latest().setStatus(StatusOnline)
+	supervisor.Check(now.Add(10 * time.Second))
+	factory.latest().setStatus(StatusError)
+	supervisor.Check(now.Add(20 * time.Second))
+	assert.Len(t, factory.instances(), 2)
+	assert.True(t, supervisor.Adapters()[0].GaveUp)
+
+	// Up for longer than the grace period counts as recovered
+	factory.latest().setStatus(StatusOnline)
+	supervisor.Check(now.Add(30 * time.Second))
+	supervisor.Check(now.Add(2 * time.Minute))
+	factory.latest().setStatus(StatusError)
+	supervisor.Check(now.Add(3 * time.Minute))
+	assert.Len(t, factory.instances(), 3)
+
+	assert.Contains(t, events.kinds(), SupervisorRecovered)
+	assert.Equal(t, 2, supervisor.Adapters()[0].Restarts)
+}
+
+func TestSupervisor_RestartsAdaptersThatFailedToStart(t *testing.T) {
+	factory := &testFactory{status: StatusOnline, startErr: errors.New("network unreachable")}
+	m, supervisor, events := startSupervised(t, factory, SupervisorOptions{})
+
+	_, running := m.GetAdapter("bot")
+	require.False(t, running)
+
+	now := time.Now()
+	supervisor.Check(now)
+	assert.Equal(t, []string{SupervisorRestartFail}, events.kinds())
+	assert.Contains(t, supervisor.Adapters()[0].LastError, "network unreachable")
+
+	factory.set(StatusOnline, nil)
+	supervisor.Check(now.Add(time.Minute))
+	_, running = m.GetAdapter("bot")
+	assert.True(t, running)
+	assert.Equal(t, []string{SupervisorRestartFail, SupervisorRestarted}, events.kinds())
+
+	// Removed adapters are no longer supervised
+	require.NoError(t, m.RemoveAdapter("bot"))
+	supervisor.Check(now.Add(2 * time.Minute))
+	assert.Empty(t, supervisor.Adapters())
+}
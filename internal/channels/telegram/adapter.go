@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -22,25 +25,6 @@ import (
 	"conduit/pkg/protocol"
 )
 
-// Patterns for sanitizing user-facing text
-var (
-	// Strip complete XML blocks: <claude_function_calls>...</claude_function_calls>
-	claudeFunctionCallsRe  = regexp.MustCompile(`(?s)<\s*claude_function_calls\s*>.*?</\s*claude_function_calls\s*>`)
-	claudeFunctionResultRe = regexp.MustCompile(`(?s)<\s*claude_function_result\s*>.*?</\s*claude_function_result\s*>`)
-	antmlFunctionCallsRe   = regexp.MustCompile(`(?s)<\s*antml:function_calls\s*>.*?</\s*antml:function_calls\s*>`)
-	antmlInvokeRe          = regexp.MustCompile(`(?s)<\s*antml:invoke[^>]*>.*?</\s*antml:invoke\s*>`)
-
-	// Strip standalone XML-like tags that shouldn't be visible to users
-	// Matches: <bash>, </bash>, <thinking>, <invoke name="...">, <parameter name="...">, etc.
-	xmlTagRe = regexp.MustCompile(`<\s*/?(?:bash|thinking|final|tool_call|invoke|parameter|claude_function_calls|claude_function_result|antml:function_calls|antml:invoke|antml:parameter)[^>]*>`)
-
-	// Strip [Tool Call: ...] and [Tool Result: ...] markers
-	toolMarkerRe = regexp.MustCompile(`\[Tool (?:Call|Result)[^\]]*\]`)
-
-	// Collapse multiple newlines into double newlines
-	multiNewlineRe = regexp.MustCompile(`\n{3,}`)
-)
-
 // botAPI abstracts the Telegram bot methods used by the adapter, enabling testing with mocks.
 type botAPI interface {
 	Start(ctx context.Context)
@@ -53,6 +37,8 @@ type botAPI interface {
 	AnswerCallbackQuery(ctx context.Context, params *bot.AnswerCallbackQueryParams) (bool, error)
 	SendChatAction(ctx context.Context, params *bot.SendChatActionParams) (bool, error)
 	SetMyCommands(ctx context.Context, params *bot.SetMyCommandsParams) (bool, error)
+	GetFile(ctx context.Context, params *bot.GetFileParams) (*models.File, error)
+	FileDownloadLink(f *models.File) string
 }
 
 // Adapter implements the ChannelAdapter interface for Telegram
@@ -70,6 +56,7 @@ type Adapter struct {
 	startTime  time.Time
 	msgCount   int64
 	pairingMgr *PairingManager
+	httpClient *http.Client // Used for attachment downloads
 }
 
 // TelegramConfig contains Telegram-specific configuration
@@ -78,6 +65,11 @@ type TelegramConfig struct {
 	WebhookMode bool   `json:"webhook_mode"`
 	WebhookURL  string `json:"webhook_url"`
 	Debug       bool   `json:"debug"`
+
+	// MediaDir is where incoming photos, documents and voice notes are
+	// stored, one subdirectory per session. Attachments are ignored if empty.
+	MediaDir      string `json:"media_dir"`
+	MaxDownloadMB int    `json:"max_download_mb"`
 }
 
 // Factory creates Telegram channel adapters
@@ -125,12 +117,22 @@ func (f *Factory) CreateAdapter(config channels.ChannelConfig) (channels.Channel
 		telegramConfig.Debug = debug
 	}
 
+	telegramConfig.MediaDir = config.MediaDir
+	if mediaDir, ok := config.Config["media_dir"].(string); ok && mediaDir != "" {
+		telegramConfig.MediaDir = mediaDir
+	}
+
+	if maxMB, ok := config.Config["max_download_mb"].(float64); ok {
+		telegramConfig.MaxDownloadMB = int(maxMB)
+	}
+
 	adapter := &Adapter{
-		id:       config.ID,
-		name:     config.Name,
-		config:   telegramConfig,
-		status:   channels.StatusInitializing,
-		incoming: make(chan *protocol.IncomingMessage, 100),
+		id:         config.ID,
+		name:       config.Name,
+		config:     telegramConfig,
+		status:     channels.StatusInitializing,
+		incoming:   make(chan *protocol.IncomingMessage, 100),
+		httpClient: &http.Client{Timeout: 2 * time.Minute},
 	}
 
 	// Initialize pairing manager if database is available
@@ -245,34 +247,6 @@ func (a *Adapter) Stop() error {
 	return nil
 }
 
-// sanitizeUserFacingText cleans up AI output before sending to users
-// Strips internal markers, XML-like tags, and normalizes whitespace
-func sanitizeUserFacingText(text string) string {
-	if text == "" {
-		return text
-	}
-
-	// First: Strip complete XML blocks with their content
-	cleaned := claudeFunctionCallsRe.ReplaceAllString(text, "")
-	cleaned = claudeFunctionResultRe.ReplaceAllString(cleaned, "")
-	cleaned = antmlFunctionCallsRe.ReplaceAllString(cleaned, "")
-	cleaned = antmlInvokeRe.ReplaceAllString(cleaned, "")
-
-	// Second: Strip any remaining standalone XML-like tags
-	cleaned = xmlTagRe.ReplaceAllString(cleaned, "")
-
-	// Strip tool call markers like [Tool Call: ...]
-	cleaned = toolMarkerRe.ReplaceAllString(cleaned, "")
-
-	// Collapse excessive newlines
-	cleaned = multiNewlineRe.ReplaceAllString(cleaned, "\n\n")
-
-	// Trim leading/trailing whitespace
-	cleaned = strings.TrimSpace(cleaned)
-
-	return cleaned
-}
-
 // Regex patterns for Telegram markdown conversion
 var (
 	// Headers: # Header -> *Header* (bold with single asterisk)
@@ -337,7 +311,7 @@ func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
 		photoParams := &bot.SendPhotoParams{
 			ChatID:    chatID,
 			Photo:     &models.InputFileUpload{Filename: filepath.Base(imagePath), Data: bytes.NewReader(fileData)},
-			Caption:   sanitizeUserFacingText(msg.Text),
+			Caption:   channels.SanitizeUserFacingText(msg.Text),
 			ParseMode: models.ParseModeMarkdownV1,
 		}
 
@@ -357,7 +331,7 @@ func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
 
 		_, err = a.bot.SendPhoto(a.ctx, photoParams)
 		if err != nil {
-			return fmt.Errorf("failed to send photo: %w", err)
+			return sendError("send photo", err)
 		}
 
 		log.Printf("[Telegram] Photo sent to chat %d (%s)", chatID, filepath.Base(imagePath))
@@ -370,7 +344,7 @@ func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
 	}
 
 	// Sanitize text before sending
-	sanitizedText := sanitizeUserFacingText(msg.Text)
+	sanitizedText := channels.SanitizeUserFacingText(msg.Text)
 
 	// Convert standard markdown to Telegram's limited markdown subset
 	telegramText := convertToTelegramMarkdown(sanitizedText)
@@ -397,6 +371,11 @@ func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
 		params.ParseMode = models.ParseMode(parseMode)
 	}
 
+	// Attach reply buttons; presses arrive as callback queries
+	if keyboard := inlineKeyboard(msg.Metadata); keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+
 	_, err = a.bot.SendMessage(a.ctx, params)
 	if err != nil {
 		// If HTML parsing fails, retry without formatting
@@ -406,11 +385,11 @@ func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
 			params.Text = sanitizedText // Use original sanitized text without HTML conversion
 			_, err = a.bot.SendMessage(a.ctx, params)
 			if err != nil {
-				return fmt.Errorf("failed to send message (plain text fallback): %w", err)
+				return sendError("send message (plain text fallback)", err)
 			}
 			log.Printf("[Telegram] Message sent as plain text fallback (%d chars)", len(sanitizedText))
 		} else {
-			return fmt.Errorf("failed to send message: %w", err)
+			return sendError("send message", err)
 		}
 	} else {
 		log.Printf("[Telegram] Message sent to chat (%d chars, sanitized from %d)", len(sanitizedText), len(msg.Text))
@@ -423,13 +402,49 @@ func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
 	return nil
 }
 
+// sendError wraps a failed send, reporting Telegram flood control as a rate
+// limit so queued messages wait instead of using up their retries
+func sendError(action string, err error) error {
+	wrapped := fmt.Errorf("failed to %s: %w", action, err)
+	var flood *bot.TooManyRequestsError
+	if errors.As(err, &flood) {
+		return &channels.RateLimitError{RetryAfter: time.Duration(flood.RetryAfter) * time.Second, Err: wrapped}
+	}
+	return wrapped
+}
+
+// inlineKeyboard converts the inline_keyboard metadata into Telegram reply
+// markup, or returns nil if there is none
+func inlineKeyboard(metadata map[string]string) *models.InlineKeyboardMarkup {
+	raw, ok := metadata[protocol.MetadataInlineKeyboard]
+	if !ok || raw == "" {
+		return nil
+	}
+
+	var rows [][]protocol.InlineButton
+	if err := json.Unmarshal([]byte(raw), &rows); err != nil {
+		log.Printf("[Telegram] Ignoring invalid inline keyboard: %v", err)
+		return nil
+	}
+
+	markup := &models.InlineKeyboardMarkup{}
+	for _, row := range rows {
+		var buttons []models.InlineKeyboardButton
+		for _, b := range row {
+			buttons = append(buttons, models.InlineKeyboardButton{Text: b.Text, CallbackData: b.Data})
+		}
+		markup.InlineKeyboard = append(markup.InlineKeyboard, buttons)
+	}
+	return markup
+}
+
 // SendMessageWithID sends a message and returns the message ID (for later editing)
 func (a *Adapter) SendMessageWithID(chatID int64, text string) (int, error) {
 	if a.bot == nil {
 		return 0, fmt.Errorf("bot not initialized")
 	}
 
-	sanitizedText := sanitizeUserFacingText(text)
+	sanitizedText := channels.SanitizeUserFacingText(text)
 	telegramText := convertToTelegramMarkdown(sanitizedText)
 
 	params := &bot.SendMessageParams{
@@ -458,7 +473,7 @@ func (a *Adapter) EditMessageText(chatID int64, messageID int, text string) erro
 		return fmt.Errorf("bot not initialized")
 	}
 
-	sanitizedText := sanitizeUserFacingText(text)
+	sanitizedText := channels.SanitizeUserFacingText(text)
 	telegramText := convertToTelegramMarkdown(sanitizedText)
 
 	params := &bot.EditMessageTextParams{
@@ -671,8 +686,8 @@ func (a *Adapter) handleUpdate(ctx context.Context, b *bot.Bot, update *models.U
 		})
 	}
 
-	// Handle photo messages
-	if update.Message != nil && len(update.Message.Photo) > 0 {
+	// Handle photo, document and voice/audio messages
+	if update.Message != nil && hasMedia(update.Message) {
 		userID := strconv.FormatInt(update.Message.Chat.ID, 10)
 		chatID := update.Message.Chat.ID
 
@@ -680,51 +695,17 @@ func (a *Adapter) handleUpdate(ctx context.Context, b *bot.Bot, update *models.U
 		if a.pairingMgr != nil {
 			isPaired, err := a.pairingMgr.HandlePairingForUser(ctx, b, userID, chatID)
 			if err != nil {
-				log.Printf("[Telegram] Error handling pairing for photo user %s: %v", userID, err)
-				return // Don't process the photo further
+				log.Printf("[Telegram] Error handling pairing for media user %s: %v", userID, err)
+				return // Don't process the media further
 			}
 
 			if !isPaired {
-				log.Printf("[Telegram] User %s is not paired, photo blocked", userID)
-				return // User not paired, photo was handled by pairing system
+				log.Printf("[Telegram] User %s is not paired, media blocked", userID)
+				return // User not paired, media was handled by pairing system
 			}
 		}
 
-		caption := update.Message.Caption
-		if caption == "" {
-			caption = "[Photo]"
-		}
-
-		incomingMsg := &protocol.IncomingMessage{
-			BaseMessage: protocol.BaseMessage{
-				Type:      protocol.TypeIncomingMessage,
-				ID:        a.generateMessageID(),
-				Timestamp: time.Now(),
-			},
-			ChannelID:  a.id,
-			SessionKey: fmt.Sprintf("telegram_%d", update.Message.Chat.ID),
-			UserID:     strconv.FormatInt(update.Message.Chat.ID, 10),
-			Text:       caption,
-			Metadata: map[string]string{
-				"type":            "photo",
-				"message_id":      strconv.Itoa(update.Message.ID),
-				"chat_type":       string(update.Message.Chat.Type),
-				"from_first_name": update.Message.From.FirstName,
-				"from_last_name":  update.Message.From.LastName,
-				"from_username":   update.Message.From.Username,
-				"photo_count":     strconv.Itoa(len(update.Message.Photo)),
-			},
-		}
-
-		select {
-		case a.incoming <- incomingMsg:
-			a.mutex.Lock()
-			a.msgCount++
-			a.mutex.Unlock()
-			log.Printf("[Telegram] Received photo from %s", update.Message.From.FirstName)
-		default:
-			log.Printf("[Telegram] Warning: incoming message channel is full, dropping photo")
-		}
+		a.handleMediaMessage(ctx, update.Message)
 	}
 }
 
@@ -0,0 +1,254 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// incomingFile describes a file attached to a Telegram message
+type incomingFile struct {
+	kind       string // Metadata "type": photo, document, voice, audio
+	fileID     string
+	size       int64
+	filename   string
+	mediaType  string
+	attachKind string
+	label      string // Placeholder text when there is no caption
+}
+
+// hasMedia reports whether a message carries a photo, document or audio
+func hasMedia(msg *models.Message) bool {
+	return len(msg.Photo) > 0 || msg.Document != nil || msg.Voice != nil || msg.Audio != nil
+}
+
+// messageFile extracts the downloadable file from a message. For photos the
+// largest available size is used.
+func messageFile(msg *models.Message) *incomingFile {
+	prefix := strconv.Itoa(msg.ID)
+
+	switch {
+	case len(msg.Photo) > 0:
+		largest := msg.Photo[0]
+		for _, p := range msg.Photo[1:] {
+			if p.Width*p.Height > largest.Width*largest.Height {
+				largest = p
+			}
+		}
+		return &incomingFile{
+			kind:       "photo",
+			fileID:     largest.FileID,
+			size:       int64(largest.FileSize),
+			filename:   prefix + "_photo.jpg",
+			mediaType:  "image/jpeg",
+			attachKind: protocol.AttachmentImage,
+			label:      "[Photo]",
+		}
+
+	case msg.Document != nil:
+		doc := msg.Document
+		name := doc.FileName
+		if name == "" {
+			name = "document"
+		}
+		attachKind := protocol.AttachmentDocument
+		if isImageMediaType(doc.MimeType) {
+			attachKind = protocol.AttachmentImage
+		}
+		return &incomingFile{
+			kind:       "document",
+			fileID:     doc.FileID,
+			size:       doc.FileSize,
+			filename:   prefix + "_" + name,
+			mediaType:  doc.MimeType,
+			attachKind: attachKind,
+			label:      fmt.Sprintf("[Document: %s]", name),
+		}
+
+	case msg.Voice != nil:
+		mediaType := msg.Voice.MimeType
+		if mediaType == "" {
+			mediaType = "audio/ogg"
+		}
+		return &incomingFile{
+			kind:       "voice",
+			fileID:     msg.Voice.FileID,
+			size:       msg.Voice.FileSize,
+			filename:   prefix + "_voice" + extensionFor(mediaType, ".ogg"),
+			mediaType:  mediaType,
+			attachKind: protocol.AttachmentAudio,
+			label:      fmt.Sprintf("[Voice message, %ds]", msg.Voice.Duration),
+		}
+
+	case msg.Audio != nil:
+		audio := msg.Audio
+		name := audio.FileName
+		if name == "" {
+			name = "audio" + extensionFor(audio.MimeType, ".mp3")
+		}
+		title := audio.Title
+		if title == "" {
+			title = name
+		}
+		return &incomingFile{
+			kind:       "audio",
+			fileID:     audio.FileID,
+			size:       audio.FileSize,
+			filename:   prefix + "_" + name,
+			mediaType:  audio.MimeType,
+			attachKind: protocol.AttachmentAudio,
+			label:      fmt.Sprintf("[Audio: %s]", title),
+		}
+	}
+
+	return nil
+}
+
+// handleMediaMessage downloads the file attached to msg into the session's
+// media directory and forwards the message with an attachment reference.
+// If the download fails the message is still forwarded so the agent knows
+// something was sent.
+func (a *Adapter) handleMediaMessage(ctx context.Context, msg *models.Message) {
+	file := messageFile(msg)
+	if file == nil {
+		return
+	}
+
+	sessionKey := fmt.Sprintf("telegram_%d", msg.Chat.ID)
+	text := msg.Caption
+	if text == "" {
+		text = file.label
+	}
+
+	metadata := map[string]string{
+		"type":       file.kind,
+		"message_id": strconv.Itoa(msg.ID),
+		"chat_type":  string(msg.Chat.Type),
+	}
+	if msg.From != nil {
+		metadata["from_first_name"] = msg.From.FirstName
+		metadata["from_last_name"] = msg.From.LastName
+		metadata["from_username"] = msg.From.Username
+	}
+	if len(msg.Photo) > 0 {
+		metadata["photo_count"] = strconv.Itoa(len(msg.Photo))
+	}
+
+	var attachments []protocol.Attachment
+	attachment, err := a.downloadAttachment(ctx, sessionKey, file)
+	if err != nil {
+		log.Printf("[Telegram] Failed to download %s from chat %d: %v", file.kind, msg.Chat.ID, err)
+		metadata["attachment_error"] = err.Error()
+		text += "\n[Attachment could not be downloaded]"
+	} else {
+		attachments = append(attachments, *attachment)
+	}
+
+	incomingMsg := &protocol.IncomingMessage{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeIncomingMessage,
+			ID:        a.generateMessageID(),
+			Timestamp: time.Now(),
+		},
+		ChannelID:   a.id,
+		SessionKey:  sessionKey,
+		UserID:      strconv.FormatInt(msg.Chat.ID, 10),
+		Text:        text,
+		Attachments: attachments,
+		Metadata:    metadata,
+	}
+
+	select {
+	case a.incoming <- incomingMsg:
+		a.mutex.Lock()
+		a.msgCount++
+		a.mutex.Unlock()
+		log.Printf("[Telegram] Received %s from chat %d (%d attachments)", file.kind, msg.Chat.ID, len(attachments))
+	default:
+		log.Printf("[Telegram] Warning: incoming message channel is full, dropping %s", file.kind)
+	}
+}
+
+// downloadAttachment fetches a file through the Bot API and stores it
+func (a *Adapter) downloadAttachment(ctx context.Context, sessionKey string, file *incomingFile) (*protocol.Attachment, error) {
+	if a.config.MediaDir == "" {
+		return nil, fmt.Errorf("media directory not configured")
+	}
+
+	maxBytes := int64(channels.DefaultMaxAttachmentBytes)
+	if a.config.MaxDownloadMB > 0 {
+		maxBytes = int64(a.config.MaxDownloadMB) * 1024 * 1024
+	}
+	if file.size > maxBytes {
+		return nil, fmt.Errorf("file is %d bytes, limit is %d", file.size, maxBytes)
+	}
+
+	tgFile, err := a.bot.GetFile(ctx, &bot.GetFileParams{FileID: file.fileID})
+	if err != nil {
+		return nil, fmt.Errorf("getFile failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.bot.FileDownloadLink(tgFile), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		// The URL contains the bot token, so don't include it in the error
+		return nil, fmt.Errorf("download failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+
+	path, size, sniffed, err := channels.SaveAttachment(a.config.MediaDir, sessionKey, file.filename, resp.Body, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType := file.mediaType
+	if mediaType == "" {
+		mediaType = sniffed
+	}
+
+	return &protocol.Attachment{
+		Type:      file.attachKind,
+		Path:      path,
+		MediaType: mediaType,
+		Filename:  filepath.Base(path),
+		Size:      size,
+	}, nil
+}
+
+// isImageMediaType reports whether a document is an image that models can view
+func isImageMediaType(mediaType string) bool {
+	switch mediaType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// extensionFor returns a file extension for mediaType, or fallback
+func extensionFor(mediaType, fallback string) string {
+	if mediaType == "audio/ogg" {
+		return ".ogg"
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return fallback
+}
@@ -0,0 +1,144 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"conduit/pkg/protocol"
+)
+
+// newMediaTestAdapter returns an adapter whose bot serves files from a test server
+func newMediaTestAdapter(t *testing.T, files map[string]string) (*Adapter, *mockBot) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path[1:]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	mb := &mockBot{files: map[string]*models.File{}, fileBaseURL: server.URL}
+	for path := range files {
+		mb.files["id-"+path] = &models.File{FileID: "id-" + path, FilePath: path}
+	}
+
+	adapter := newTestAdapter(mb)
+	t.Cleanup(adapter.cancel)
+	adapter.incoming = make(chan *protocol.IncomingMessage, 10)
+	adapter.httpClient = server.Client()
+	adapter.config.MediaDir = t.TempDir()
+	return adapter, mb
+}
+
+func receive(t *testing.T, a *Adapter) *protocol.IncomingMessage {
+	t.Helper()
+	select {
+	case msg := <-a.incoming:
+		return msg
+	default:
+		t.Fatal("expected an incoming message")
+		return nil
+	}
+}
+
+func TestHandleUpdate_PhotoDownloadsLargestSize(t *testing.T) {
+	adapter, _ := newMediaTestAdapter(t, map[string]string{
+		"photos/small.jpg": "small",
+		"photos/large.jpg": "large-photo-bytes",
+	})
+
+	adapter.handleUpdate(context.Background(), nil, &models.Update{Message: &models.Message{
+		ID:      7,
+		Chat:    models.Chat{ID: 42, Type: "private"},
+		From:    &models.User{FirstName: "Ada"},
+		Caption: "what is this?",
+		Photo: []models.PhotoSize{
+			{FileID: "id-photos/small.jpg", Width: 90, Height: 90},
+			{FileID: "id-photos/large.jpg", Width: 1280, Height: 960},
+		},
+	}})
+
+	msg := receive(t, adapter)
+	assert.Equal(t, "what is this?", msg.Text)
+	assert.Equal(t, "photo", msg.Metadata["type"])
+	require.Len(t, msg.Attachments, 1)
+
+	att := msg.Attachments[0]
+	assert.Equal(t, protocol.AttachmentImage, att.Type)
+	assert.Equal(t, "image/jpeg", att.MediaType)
+	assert.Equal(t, filepath.Join(adapter.config.MediaDir, "telegram_42", "7_photo.jpg"), att.Path)
+
+	data, err := os.ReadFile(att.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "large-photo-bytes", string(data))
+}
+
+func TestHandleUpdate_DocumentAndVoice(t *testing.T) {
+	adapter, _ := newMediaTestAdapter(t, map[string]string{
+		"docs/report.pdf": "%PDF-1.4",
+		"voice/note.oga":  "OggS",
+	})
+
+	adapter.handleUpdate(context.Background(), nil, &models.Update{Message: &models.Message{
+		ID:   8,
+		Chat: models.Chat{ID: 42},
+		Document: &models.Document{
+			FileID:   "id-docs/report.pdf",
+			FileName: "report.pdf",
+			MimeType: "application/pdf",
+		},
+	}})
+
+	doc := receive(t, adapter)
+	assert.Equal(t, "[Document: report.pdf]", doc.Text)
+	require.Len(t, doc.Attachments, 1)
+	assert.Equal(t, protocol.AttachmentDocument, doc.Attachments[0].Type)
+	assert.Equal(t, "application/pdf", doc.Attachments[0].MediaType)
+	assert.Equal(t, "8_report.pdf", doc.Attachments[0].Filename)
+
+	adapter.handleUpdate(context.Background(), nil, &models.Update{Message: &models.Message{
+		ID:    9,
+		Chat:  models.Chat{ID: 42},
+		Voice: &models.Voice{FileID: "id-voice/note.oga", Duration: 3},
+	}})
+
+	voice := receive(t, adapter)
+	assert.Equal(t, "[Voice message, 3s]", voice.Text)
+	assert.Equal(t, "voice", voice.Metadata["type"])
+	require.Len(t, voice.Attachments, 1)
+	assert.Equal(t, protocol.AttachmentAudio, voice.Attachments[0].Type)
+	assert.Equal(t, "audio/ogg", voice.Attachments[0].MediaType)
+	assert.Equal(t, "9_voice.ogg", voice.Attachments[0].Filename)
+}
+
+func TestHandleUpdate_OversizedFileStillForwarded(t *testing.T) {
+	adapter, _ := newMediaTestAdapter(t, map[string]string{"docs/huge.bin": "x"})
+	adapter.config.MaxDownloadMB = 1
+
+	adapter.handleUpdate(context.Background(), nil, &models.Update{Message: &models.Message{
+		ID:   10,
+		Chat: models.Chat{ID: 42},
+		Document: &models.Document{
+			FileID:   "id-docs/huge.bin",
+			FileName: "huge.bin",
+			FileSize: 5 * 1024 * 1024,
+		},
+	}})
+
+	msg := receive(t, adapter)
+	assert.Empty(t, msg.Attachments)
+	assert.Contains(t, msg.Text, "could not be downloaded")
+	assert.NotEmpty(t, msg.Metadata["attachment_error"])
+}
@@ -7,12 +7,14 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"conduit/internal/channels"
 	"conduit/pkg/protocol"
 )
 
@@ -24,6 +26,8 @@ type mockBot struct {
 	sendPhotoResp    *models.Message
 	sendMessageErr   error
 	sendPhotoErr     error
+	files            map[string]*models.File
+	fileBaseURL      string
 }
 
 func (m *mockBot) Start(ctx context.Context)        {}
@@ -71,6 +75,17 @@ func (m *mockBot) SetMyCommands(ctx context.Context, params *bot.SetMyCommandsPa
 	return true, nil
 }
 
+func (m *mockBot) GetFile(ctx context.Context, params *bot.GetFileParams) (*models.File, error) {
+	if f, ok := m.files[params.FileID]; ok {
+		return f, nil
+	}
+	return nil, fmt.Errorf("file not found: %s", params.FileID)
+}
+
+func (m *mockBot) FileDownloadLink(f *models.File) string {
+	return m.fileBaseURL + "/" + f.FilePath
+}
+
 func newTestAdapter(mb *mockBot) *Adapter {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Adapter{
@@ -261,6 +276,21 @@ func TestSendMessage_PhotoSendError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to send photo")
 }
 
+func TestSendMessage_RateLimited(t *testing.T) {
+	mb := &mockBot{
+		sendMessageErr: &bot.TooManyRequestsError{Message: "Too Many Requests", RetryAfter: 7},
+	}
+	adapter := newTestAdapter(mb)
+	defer adapter.cancel()
+
+	err := adapter.SendMessage(&protocol.OutgoingMessage{UserID: "12345", Text: "hello"})
+
+	require.Error(t, err)
+	wait, limited := channels.RetryAfter(err)
+	assert.True(t, limited)
+	assert.Equal(t, 7*time.Second, wait)
+}
+
 func TestSendMessage_InvalidChatID(t *testing.T) {
 	mb := &mockBot{}
 	adapter := newTestAdapter(mb)
@@ -277,3 +307,26 @@ func TestSendMessage_InvalidChatID(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid chat ID")
 }
+
+func TestSendMessage_InlineKeyboard(t *testing.T) {
+	mb := &mockBot{}
+	adapter := newTestAdapter(mb)
+	defer adapter.cancel()
+
+	err := adapter.SendMessage(&protocol.OutgoingMessage{
+		UserID: "12345",
+		Text:   "Approve?",
+		Metadata: map[string]string{
+			protocol.MetadataInlineKeyboard: `[[{"text":"Approve","data":"/approve ab12"},{"text":"Deny","data":"/deny ab12"}]]`,
+		},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, mb.sendMessageCalls, 1)
+	markup, ok := mb.sendMessageCalls[0].ReplyMarkup.(*models.InlineKeyboardMarkup)
+	require.True(t, ok, "expected an inline keyboard")
+	require.Len(t, markup.InlineKeyboard, 1)
+	require.Len(t, markup.InlineKeyboard[0], 2)
+	assert.Equal(t, "/approve ab12", markup.InlineKeyboard[0][0].CallbackData)
+	assert.Equal(t, "Deny", markup.InlineKeyboard[0][1].Text)
+}
@@ -0,0 +1,73 @@
+package channels
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Patterns for sanitizing user-facing text
+var (
+	// Strip complete XML blocks: <claude_function_calls>...</claude_function_calls>
+	claudeFunctionCallsRe  = regexp.MustCompile(`(?s)<\s*claude_function_calls\s*>.*?</\s*claude_function_calls\s*>`)
+	claudeFunctionResultRe = regexp.MustCompile(`(?s)<\s*claude_function_result\s*>.*?</\s*claude_function_result\s*>`)
+	antmlFunctionCallsRe   = regexp.MustCompile(`(?s)<\s*antml:function_calls\s*>.*?</\s*antml:function_calls\s*>`)
+	antmlInvokeRe          = regexp.MustCompile(`(?s)<\s*antml:invoke[^>]*>.*?</\s*antml:invoke\s*>`)
+
+	// Strip standalone XML-like tags that shouldn't be visible to users
+	// Matches: <bash>, </bash>, <thinking>, <invoke name="...">, <parameter name="...">, etc.
+	xmlTagRe = regexp.MustCompile(`<\s*/?(?:bash|thinking|final|tool_call|invoke|parameter|claude_function_calls|claude_function_result|antml:function_calls|antml:invoke|antml:parameter)[^>]*>`)
+
+	// Strip [Tool Call: ...] and [Tool Result: ...] markers
+	toolMarkerRe = regexp.MustCompile(`\[Tool (?:Call|Result)[^\]]*\]`)
+
+	// Collapse multiple newlines into double newlines
+	multiNewlineRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// SanitizeUserFacingText cleans up AI output before sending it to chat users.
+// Strips internal markers, XML-like tags, and normalizes whitespace
+func SanitizeUserFacingText(text string) string {
+	if text == "" {
+		return text
+	}
+
+	// First: Strip complete XML blocks with their content
+	cleaned := claudeFunctionCallsRe.ReplaceAllString(text, "")
+	cleaned = claudeFunctionResultRe.ReplaceAllString(cleaned, "")
+	cleaned = antmlFunctionCallsRe.ReplaceAllString(cleaned, "")
+	cleaned = antmlInvokeRe.ReplaceAllString(cleaned, "")
+
+	// Second: Strip any remaining standalone XML-like tags
+	cleaned = xmlTagRe.ReplaceAllString(cleaned, "")
+
+	// Strip tool call markers like [Tool Call: ...]
+	cleaned = toolMarkerRe.ReplaceAllString(cleaned, "")
+
+	// Collapse excessive newlines
+	cleaned = multiNewlineRe.ReplaceAllString(cleaned, "\n\n")
+
+	// Trim leading/trailing whitespace
+	cleaned = strings.TrimSpace(cleaned)
+
+	return cleaned
+}
+
+// MaxSessionKeyLength is the longest session key a channel accepts from
+// outside the gateway
+const MaxSessionKeyLength = 128
+
+// ValidSessionKey reports whether a session key chosen outside the gateway
+// is short and free of separators, whitespace and control characters
+func ValidSessionKey(key string) bool {
+	if key == "" || len(key) > MaxSessionKeyLength {
+		return false
+	}
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
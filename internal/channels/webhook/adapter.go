@@ -0,0 +1,462 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+// maxBodyBytes limits the size of inbound requests
+const maxBodyBytes = 1 << 20
+
+// Defaults for optional settings
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBackoffMs = 1000
+	defaultMaxBackoff     = 30 * time.Second
+	defaultTimeoutSeconds = 10
+	defaultMaxSkewSeconds = 300
+)
+
+// InboundMessage is the JSON body of a request to /webhooks/<adapter id>
+type InboundMessage struct {
+	// ID is an optional sender-side message ID, echoed in metadata
+	ID string `json:"id,omitempty"`
+	// UserID identifies the sender; replies are addressed to it
+	UserID string `json:"user_id"`
+	// SessionKey selects a conversation of this adapter; it is stored as
+	// "webhook_<adapter>_<session_key>" (default "webhook_<adapter>_<user_id>")
+	SessionKey string            `json:"session_key,omitempty"`
+	Text       string            `json:"text"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// OutboundMessage is the JSON body POSTed to the callback URL
+type OutboundMessage struct {
+	ID         string            `json:"id"`
+	ChannelID  string            `json:"channel_id"`
+	SessionKey string            `json:"session_key,omitempty"`
+	UserID     string            `json:"user_id"`
+	Text       string            `json:"text"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`
+}
+
+// Adapter implements the ChannelAdapter interface for generic HTTP webhooks:
+// signed inbound POSTs become incoming messages and replies are POSTed to a
+// callback URL
+type Adapter struct {
+	id        string
+	name      string
+	config    WebhookConfig
+	client    *http.Client
+	status    channels.StatusCode
+	statusMsg string
+	incoming  chan *protocol.IncomingMessage
+	ctx       context.Context
+	cancel    context.CancelFunc
+	mutex     sync.RWMutex
+	startTime time.Time
+
+	received  int64
+	delivered int64
+	failed    int64
+	lastError string
+}
+
+// WebhookConfig contains webhook-specific configuration
+type WebhookConfig struct {
+	// Secret verifies inbound signatures
+	Secret string `json:"secret"`
+
+	// CallbackURL receives outgoing messages. Without it the channel is
+	// inbound only and sending fails.
+	CallbackURL string `json:"callback_url"`
+	// CallbackSecret signs outgoing requests (default: Secret)
+	CallbackSecret string `json:"callback_secret"`
+	// Headers are added to every callback request, e.g. for auth
+	Headers map[string]string `json:"headers"`
+
+	MaxRetries     int `json:"max_retries"`
+	RetryBackoffMs int `json:"retry_backoff_ms"`
+	TimeoutSeconds int `json:"timeout_seconds"`
+	MaxSkewSeconds int `json:"max_skew_seconds"`
+}
+
+// Factory creates webhook channel adapters
+type Factory struct{}
+
+// NewFactory creates a new webhook adapter factory
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// SupportsType returns whether this factory supports the given adapter type
+func (f *Factory) SupportsType(adapterType string) bool {
+	return adapterType == "webhook"
+}
+
+// GetSupportedTypes returns the adapter types this factory supports
+func (f *Factory) GetSupportedTypes() []string {
+	return []string{"webhook"}
+}
+
+// CreateAdapter creates a new webhook adapter instance
+func (f *Factory) CreateAdapter(config channels.ChannelConfig) (channels.ChannelAdapter, error) {
+	webhookConfig := WebhookConfig{
+		MaxRetries:     defaultMaxRetries,
+		RetryBackoffMs: defaultRetryBackoffMs,
+		TimeoutSeconds: defaultTimeoutSeconds,
+		MaxSkewSeconds: defaultMaxSkewSeconds,
+	}
+
+	if secret, ok := config.Config["secret"].(string); ok && secret != "" {
+		webhookConfig.Secret = secret
+	} else {
+		return nil, fmt.Errorf("secret is required for webhook adapter")
+	}
+
+	if callbackURL, ok := config.Config["callback_url"].(string); ok && callbackURL != "" {
+		if !strings.HasPrefix(callbackURL, "http://") && !strings.HasPrefix(callbackURL, "https://") {
+			return nil, fmt.Errorf("callback_url must be an http or https URL")
+		}
+		webhookConfig.CallbackURL = callbackURL
+	}
+
+	webhookConfig.CallbackSecret = webhookConfig.Secret
+	if secret, ok := config.Config["callback_secret"].(string); ok && secret != "" {
+		webhookConfig.CallbackSecret = secret
+	}
+
+	if headers, ok := config.Config["headers"].(map[string]interface{}); ok {
+		webhookConfig.Headers = make(map[string]string)
+		for k, v := range headers {
+			if s, ok := v.(string); ok {
+				webhookConfig.Headers[k] = s
+			}
+		}
+	}
+
+	if v, ok := config.Config["max_retries"].(float64); ok && v >= 0 {
+		webhookConfig.MaxRetries = int(v)
+	}
+	if v, ok := config.Config["retry_backoff_ms"].(float64); ok && v > 0 {
+		webhookConfig.RetryBackoffMs = int(v)
+	}
+	if v, ok := config.Config["timeout_seconds"].(float64); ok && v > 0 {
+		webhookConfig.TimeoutSeconds = int(v)
+	}
+	if v, ok := config.Config["max_skew_seconds"].(float64); ok && v > 0 {
+		webhookConfig.MaxSkewSeconds = int(v)
+	}
+
+	return &Adapter{
+		id:       config.ID,
+		name:     config.Name,
+		config:   webhookConfig,
+		client:   &http.Client{Timeout: time.Duration(webhookConfig.TimeoutSeconds) * time.Second},
+		status:   channels.StatusInitializing,
+		incoming: make(chan *protocol.IncomingMessage, 100),
+	}, nil
+}
+
+// ID returns the adapter's unique identifier
+func (a *Adapter) ID() string {
+	return a.id
+}
+
+// Name returns the adapter's human-readable name
+func (a *Adapter) Name() string {
+	return a.name
+}
+
+// Type returns the adapter type
+func (a *Adapter) Type() string {
+	return "webhook"
+}
+
+// Start begins accepting inbound requests
+func (a *Adapter) Start(ctx context.Context) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.ctx, a.cancel = context.WithCancel(ctx)
+	a.startTime = time.Now()
+	a.status = channels.StatusOnline
+	a.statusMsg = fmt.Sprintf("Accepting requests on /webhooks/%s", a.id)
+
+	log.Printf("[Webhook] Adapter started: %s", a.Name())
+	return nil
+}
+
+// Stop gracefully shuts down the adapter
+func (a *Adapter) Stop() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.cancel != nil {
+		a.cancel()
+	}
+
+	a.status = channels.StatusOffline
+	a.statusMsg = "Adapter stopped"
+
+	// Close incoming message channel
+	close(a.incoming)
+
+	log.Printf("[Webhook] Adapter stopped: %s", a.Name())
+	return nil
+}
+
+// ServeWebhook accepts a signed InboundMessage and queues it for the gateway.
+// Replies are delivered asynchronously to the callback URL.
+func (a *Adapter) ServeWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+
+	maxSkew := time.Duration(a.config.MaxSkewSeconds) * time.Second
+	if err := Verify(a.config.Secret, r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader), body, maxSkew, time.Now()); err != nil {
+		log.Printf("[Webhook] Rejected request to %s: %v", a.id, err)
+		writeError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+
+	var in InboundMessage
+	if err := json.Unmarshal(body, &in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if in.UserID == "" || strings.TrimSpace(in.Text) == "" {
+		writeError(w, http.StatusBadRequest, "user_id and text are required")
+		return
+	}
+
+	if in.SessionKey != "" && !channels.ValidSessionKey(in.SessionKey) {
+		writeError(w, http.StatusBadRequest, "session_key may only contain letters, digits, '-', '_' and '.'")
+		return
+	}
+
+	// Callers only reach sessions of this adapter, never another channel's
+	sessionKey := fmt.Sprintf("webhook_%s_%s", a.id, in.UserID)
+	if in.SessionKey != "" {
+		sessionKey = fmt.Sprintf("webhook_%s_%s", a.id, in.SessionKey)
+	}
+	metadata := make(map[string]string, len(in.Metadata)+1)
+	for k, v := range in.Metadata {
+		metadata[k] = v
+	}
+	if in.ID != "" {
+		metadata["message_id"] = in.ID
+	}
+
+	msg := &protocol.IncomingMessage{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeIncomingMessage,
+			ID:        a.generateMessageID(),
+			Timestamp: time.Now(),
+		},
+		ChannelID:  a.id,
+		SessionKey: sessionKey,
+		UserID:     in.UserID,
+		Text:       in.Text,
+		Metadata:   metadata,
+	}
+
+	if err := a.forward(msg); err != nil {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"id":          msg.ID,
+		"session_key": sessionKey,
+	})
+}
+
+// forward queues a message for the gateway without blocking
+func (a *Adapter) forward(msg *protocol.IncomingMessage) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if err := channels.Forward(a.ctx, a.incoming, msg); err != nil {
+		return err
+	}
+	a.received++
+	// Privacy-safe logging - no message content
+	log.Printf("[Webhook] Received message for session %s (%d chars)", msg.SessionKey, len(msg.Text))
+	return nil
+}
+
+// SendMessage POSTs a signed OutboundMessage to the callback URL. Network
+// errors, 429 and 5xx responses are retried with exponential backoff,
+// honouring Retry-After; other 4xx responses fail immediately.
+func (a *Adapter) SendMessage(msg *protocol.OutgoingMessage) error {
+	if a.ctx == nil {
+		return fmt.Errorf("adapter not started")
+	}
+	if a.config.CallbackURL == "" {
+		return fmt.Errorf("webhook adapter %s has no callback_url configured", a.id)
+	}
+
+	deliveryID := msg.ID
+	if deliveryID == "" {
+		deliveryID = a.generateMessageID()
+	}
+	body, err := json.Marshal(OutboundMessage{
+		ID:         deliveryID,
+		ChannelID:  a.id,
+		SessionKey: msg.SessionKey,
+		UserID:     msg.UserID,
+		Text:       channels.SanitizeUserFacingText(msg.Text),
+		Metadata:   msg.Metadata,
+		Timestamp:  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	backoff := time.Duration(a.config.RetryBackoffMs) * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		retryAfter, retryable, err := a.deliver(deliveryID, body)
+		if err == nil {
+			a.mutex.Lock()
+			a.delivered++
+			a.mutex.Unlock()
+			log.Printf("[Webhook] Delivered %s to callback (%d bytes, attempt %d)", deliveryID, len(body), attempt+1)
+			return nil
+		}
+
+		if !retryable || attempt >= a.config.MaxRetries {
+			a.mutex.Lock()
+			a.failed++
+			a.lastError = err.Error()
+			a.mutex.Unlock()
+			return fmt.Errorf("webhook delivery failed after %d attempts: %w", attempt+1, err)
+		}
+
+		wait := backoff
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		if wait > defaultMaxBackoff {
+			wait = defaultMaxBackoff
+		}
+		log.Printf("[Webhook] Delivery of %s failed: %v (retrying in %v)", deliveryID, err, wait)
+
+		select {
+		case <-a.ctx.Done():
+			return fmt.Errorf("webhook delivery cancelled: %w", err)
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+// deliver makes one callback attempt. It returns whether a failure may be
+// retried and any delay requested by the receiver.
+func (a *Adapter) deliver(deliveryID string, body []byte) (retryAfter time.Duration, retryable bool, err error) {
+	req, err := http.NewRequestWithContext(a.ctx, http.MethodPost, a.config.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	for k, v := range a.config.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(a.config.CallbackSecret, timestamp, body))
+	req.Header.Set(DeliveryHeader, deliveryID)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return 0, false, nil
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+	err = fmt.Errorf("callback returned status %d", resp.StatusCode)
+	return retryAfter, resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// ReceiveMessages returns the channel for incoming messages
+func (a *Adapter) ReceiveMessages() <-chan *protocol.IncomingMessage {
+	return a.incoming
+}
+
+// Status returns the current adapter status
+func (a *Adapter) Status() channels.ChannelStatus {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	details := map[string]interface{}{
+		"uptime_seconds":     time.Since(a.startTime).Seconds(),
+		"received":           a.received,
+		"delivered":          a.delivered,
+		"failed_deliveries":  a.failed,
+		"callback_enabled":   a.config.CallbackURL != "",
+		"inbound_queue_size": len(a.incoming),
+	}
+	if a.lastError != "" {
+		details["last_delivery_error"] = a.lastError
+	}
+
+	return channels.ChannelStatus{
+		Status:    a.status,
+		Message:   a.statusMsg,
+		Details:   details,
+		Timestamp: time.Now(),
+	}
+}
+
+// IsHealthy returns whether the adapter is functioning properly
+func (a *Adapter) IsHealthy() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.status == channels.StatusOnline
+}
+
+// generateMessageID creates a unique message ID
+func (a *Adapter) generateMessageID() string {
+	return fmt.Sprintf("webhook_%s_%s", a.id, uuid.New().String()[:8])
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,225 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"conduit/internal/channels"
+	"conduit/pkg/protocol"
+)
+
+const testSecret = "s3cret"
+
+func newTestAdapter(t *testing.T, extra map[string]interface{}) *Adapter {
+	cfg := map[string]interface{}{"secret": testSecret, "retry_backoff_ms": float64(1)}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	adapter, err := NewFactory().CreateAdapter(channels.ChannelConfig{
+		ID: "ci", Type: "webhook", Name: "CI", Enabled: true, Config: cfg,
+	})
+	require.NoError(t, err)
+	a := adapter.(*Adapter)
+	require.NoError(t, a.Start(context.Background()))
+	t.Cleanup(func() { a.Stop() })
+	return a
+}
+
+// signedRequest builds an inbound request signed at the given time
+func signedRequest(t *testing.T, secret string, at time.Time, v interface{}) *http.Request {
+	body, err := json.Marshal(v)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/ci", bytes.NewReader(body))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(at.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(secret, at.Unix(), body))
+	return req
+}
+
+func TestFactory_CreateAdapter(t *testing.T) {
+	f := NewFactory()
+	assert.True(t, f.SupportsType("webhook"))
+
+	_, err := f.CreateAdapter(channels.ChannelConfig{ID: "ci", Config: map[string]interface{}{}})
+	assert.Error(t, err, "secret is required")
+
+	_, err = f.CreateAdapter(channels.ChannelConfig{ID: "ci", Config: map[string]interface{}{
+		"secret": "x", "callback_url": "ftp://example.com",
+	}})
+	assert.Error(t, err)
+}
+
+func TestServeWebhook_AcceptsSignedMessage(t *testing.T) {
+	a := newTestAdapter(t, nil)
+
+	rec := httptest.NewRecorder()
+	a.ServeWebhook(rec, signedRequest(t, testSecret, time.Now(), InboundMessage{
+		ID: "build-42", UserID: "ci-bot", Text: "build failed", Metadata: map[string]string{"repo": "conduit"},
+	}))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var resp map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "webhook_ci_ci-bot", resp["session_key"])
+
+	msg := <-a.ReceiveMessages()
+	assert.Equal(t, "ci", msg.ChannelID)
+	assert.Equal(t, "ci-bot", msg.UserID)
+	assert.Equal(t, "build failed", msg.Text)
+	assert.Equal(t, "conduit", msg.Metadata["repo"])
+	assert.Equal(t, "build-42", msg.Metadata["message_id"])
+}
+
+func TestServeWebhook_Rejections(t *testing.T) {
+	a := newTestAdapter(t, nil)
+	valid := InboundMessage{UserID: "ci-bot", Text: "hi"}
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"wrong secret", signedRequest(t, "other", time.Now(), valid), http.StatusUnauthorized},
+		{"stale timestamp", signedRequest(t, testSecret, time.Now().Add(-time.Hour), valid), http.StatusUnauthorized},
+		{"unsigned", httptest.NewRequest(http.MethodPost, "/webhooks/ci", bytes.NewReader([]byte(`{}`))), http.StatusUnauthorized},
+		{"missing text", signedRequest(t, testSecret, time.Now(), InboundMessage{UserID: "ci-bot"}), http.StatusBadRequest},
+		{"wrong method", httptest.NewRequest(http.MethodGet, "/webhooks/ci", nil), http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			a.ServeWebhook(rec, tt.req)
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+	assert.Empty(t, a.ReceiveMessages())
+}
+
+func TestServeWebhook_SessionKeyStaysInAdapterNamespace(t *testing.T) {
+	a := newTestAdapter(t, nil)
+
+	rec := httptest.NewRecorder()
+	a.ServeWebhook(rec, signedRequest(t, testSecret, time.Now(), InboundMessage{
+		UserID: "ci-bot", SessionKey: "telegram_12345", Text: "what did we talk about?",
+	}))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	msg := <-a.ReceiveMessages()
+	assert.Equal(t, "webhook_ci_telegram_12345", msg.SessionKey)
+
+	for _, key := range []string{"../telegram_12345", "a:b", "a/b", "a b", "a\nb", "a\x00b", strings.Repeat("a", 200)} {
+		rec := httptest.NewRecorder()
+		a.ServeWebhook(rec, signedRequest(t, testSecret, time.Now(), InboundMessage{
+			UserID: "ci-bot", SessionKey: key, Text: "hi",
+		}))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "session key %q", key)
+	}
+	assert.Empty(t, a.ReceiveMessages())
+}
+
+// callbackServer records deliveries and answers with the given statuses in turn
+type callbackServer struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (c *callbackServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, r)
+	c.bodies = append(c.bodies, body)
+	status := http.StatusOK
+	if len(c.statuses) > 0 {
+		status, c.statuses = c.statuses[0], c.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestSendMessage_SignsCallback(t *testing.T) {
+	cb := &callbackServer{}
+	server := httptest.NewServer(cb)
+	defer server.Close()
+
+	a := newTestAdapter(t, map[string]interface{}{
+		"callback_url":    server.URL,
+		"callback_secret": "outbound",
+		"headers":         map[string]interface{}{"Authorization": "Bearer abc"},
+	})
+
+	require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{
+		BaseMessage: protocol.BaseMessage{ID: "out-1"},
+		SessionKey:  "webhook_ci_ci-bot",
+		UserID:      "ci-bot",
+		Text:        "Looking into it",
+	}))
+
+	require.Len(t, cb.requests, 1)
+	req, body := cb.requests[0], cb.bodies[0]
+	assert.Equal(t, "Bearer abc", req.Header.Get("Authorization"))
+	assert.Equal(t, "out-1", req.Header.Get(DeliveryHeader))
+	assert.NoError(t, Verify("outbound", req.Header.Get(SignatureHeader), req.Header.Get(TimestampHeader), body, time.Minute, time.Now()))
+
+	var out OutboundMessage
+	require.NoError(t, json.Unmarshal(body, &out))
+	assert.Equal(t, "ci", out.ChannelID)
+	assert.Equal(t, "ci-bot", out.UserID)
+	assert.Equal(t, "Looking into it", out.Text)
+}
+
+func TestSendMessage_Retries(t *testing.T) {
+	cb := &callbackServer{statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests}}
+	server := httptest.NewServer(cb)
+	defer server.Close()
+
+	a := newTestAdapter(t, map[string]interface{}{"callback_url": server.URL})
+	require.NoError(t, a.SendMessage(&protocol.OutgoingMessage{BaseMessage: protocol.BaseMessage{ID: "out-1"}, UserID: "u", Text: "hi"}))
+
+	require.Len(t, cb.requests, 3)
+	for _, req := range cb.requests {
+		assert.Equal(t, "out-1", req.Header.Get(DeliveryHeader), "delivery ID is stable across retries")
+	}
+	assert.Equal(t, int64(1), a.Status().Details["delivered"])
+}
+
+func TestSendMessage_GivesUp(t *testing.T) {
+	t.Run("client error is not retried", func(t *testing.T) {
+		cb := &callbackServer{statuses: []int{http.StatusBadRequest}}
+		server := httptest.NewServer(cb)
+		defer server.Close()
+
+		a := newTestAdapter(t, map[string]interface{}{"callback_url": server.URL})
+		assert.Error(t, a.SendMessage(&protocol.OutgoingMessage{UserID: "u", Text: "hi"}))
+		assert.Len(t, cb.requests, 1)
+	})
+
+	t.Run("retry budget", func(t *testing.T) {
+		cb := &callbackServer{statuses: []int{500, 500, 500, 500}}
+		server := httptest.NewServer(cb)
+		defer server.Close()
+
+		a := newTestAdapter(t, map[string]interface{}{"callback_url": server.URL, "max_retries": float64(2)})
+		assert.Error(t, a.SendMessage(&protocol.OutgoingMessage{UserID: "u", Text: "hi"}))
+		assert.Len(t, cb.requests, 3)
+		assert.Equal(t, int64(1), a.Status().Details["failed_deliveries"])
+		assert.Contains(t, a.Status().Details["last_delivery_error"], "status 500")
+	})
+
+	t.Run("no callback", func(t *testing.T) {
+		a := newTestAdapter(t, nil)
+		assert.Error(t, a.SendMessage(&protocol.OutgoingMessage{UserID: "u", Text: "hi"}))
+	})
+}
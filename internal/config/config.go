@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -14,24 +15,70 @@ import (
 
 // Config represents the gateway configuration
 type Config struct {
-	Port           int                  `json:"port"`
-	Timezone       string               `json:"timezone,omitempty"`
-	DataDir        string               `json:"data_dir,omitempty"`
-	SecretsFile    string               `json:"secrets_file,omitempty"`
-	Database       DatabaseConfig       `json:"database"`
-	Search         SearchDatabaseConfig `json:"search,omitempty"`
-	AI             AIConfig             `json:"ai"`
-	Agent          AgentConfig          `json:"agent"`
-	Workspace      WorkspaceConfig      `json:"workspace,omitempty"`
-	Skills         skills.SkillsConfig  `json:"skills,omitempty"`
-	Tools          ToolsConfig          `json:"tools"`
-	Channels       []ChannelConfig      `json:"channels"`
-	Debug          DebugConfig          `json:"debug,omitempty"`
-	RateLimiting   RateLimitingConfig   `json:"rateLimiting,omitempty"`
-	Heartbeat      HeartbeatConfig      `json:"heartbeat,omitempty"`
-	AgentHeartbeat AgentHeartbeatConfig `json:"agent_heartbeat,omitempty"`
-	SSH            SSHServerConfig      `json:"ssh,omitempty"`
-	Vector         VectorConfig         `json:"vector,omitempty"`
+	Port              int                     `json:"port"`
+	Timezone          string                  `json:"timezone,omitempty"`
+	DataDir           string                  `json:"data_dir,omitempty"`
+	SecretsFile       string                  `json:"secrets_file,omitempty"`
+	Database          DatabaseConfig          `json:"database"`
+	Search            SearchDatabaseConfig    `json:"search,omitempty"`
+	AI                AIConfig                `json:"ai"`
+	Agent             AgentConfig             `json:"agent"`
+	Workspace         WorkspaceConfig         `json:"workspace,omitempty"`
+	Skills            skills.SkillsConfig     `json:"skills,omitempty"`
+	Tools             ToolsConfig             `json:"tools"`
+	Channels          []ChannelConfig         `json:"channels"`
+	Debug             DebugConfig             `json:"debug,omitempty"`
+	RateLimiting      RateLimitingConfig      `json:"rateLimiting,omitempty"`
+	Heartbeat         HeartbeatConfig         `json:"heartbeat,omitempty"`
+	AgentHeartbeat    AgentHeartbeatConfig    `json:"agent_heartbeat,omitempty"`
+	SSH               SSHServerConfig         `json:"ssh,omitempty"`
+	Vector            VectorConfig            `json:"vector,omitempty"`
+	Sessions          SessionsConfig          `json:"sessions,omitempty"`
+	Outbox            OutboxConfig            `json:"outbox,omitempty"`
+	ChannelSupervisor ChannelSupervisorConfig `json:"channel_supervisor,omitempty"`
+	Scheduler         SchedulerConfig         `json:"scheduler,omitempty"`
+	Reload            *ReloadConfig           `json:"reload,omitempty"` // Live reload of the config file
+
+	path string // File the config was loaded from
+}
+
+// Policies for channel messages that arrive while their session is mid-turn
+const (
+	QueuePolicyQueue     = "queue"     // Run each message as its own turn, in order
+	QueuePolicyMerge     = "merge"     // Combine waiting messages into the next turn
+	QueuePolicyInterrupt = "interrupt" // Cancel the running turn and combine waiting messages into the next
+)
+
+// SessionsConfig controls how channel messages are processed per session
+type SessionsConfig struct {
+	QueuePolicy string `json:"queue_policy,omitempty"` // "queue" (default), "merge" or "interrupt"
+}
+
+// OutboxConfig controls retries of outgoing channel messages. Zero values
+// use the defaults.
+type OutboxConfig struct {
+	MaxAttempts         int `json:"max_attempts,omitempty"`          // Failed sends before a message is dead-lettered (default 8)
+	RetryBackoffSeconds int `json:"retry_backoff_seconds,omitempty"` // Delay before the first retry, doubled after each failure (default 5)
+	MaxBackoffSeconds   int `json:"max_backoff_seconds,omitempty"`   // Upper bound on the retry delay (default 600)
+	RetentionHours      int `json:"retention_hours,omitempty"`       // How long delivered messages are kept (default 24)
+}
+
+// ChannelSupervisorConfig controls automatic restarts of unhealthy channel
+// adapters. Zero values use the defaults.
+type ChannelSupervisorConfig struct {
+	Disabled          bool `json:"disabled,omitempty"`            // Never restart adapters automatically
+	IntervalSeconds   int  `json:"interval_seconds,omitempty"`    // How often adapter health is checked (default 30)
+	GraceSeconds      int  `json:"grace_seconds,omitempty"`       // Time a reconnecting adapter gets to recover on its own (default 300)
+	BackoffSeconds    int  `json:"backoff_seconds,omitempty"`     // Delay after the first restart, doubled after each (default 10)
+	MaxBackoffSeconds int  `json:"max_backoff_seconds,omitempty"` // Upper bound on the delay between restarts (default 600)
+	MaxRestarts       int  `json:"max_restarts,omitempty"`        // Restarts without recovering before giving up (default 5)
+}
+
+// SchedulerConfig controls the scheduled job run history. Zero values use
+// the defaults.
+type SchedulerConfig struct {
+	HistoryMaxRuns       int `json:"history_max_runs,omitempty"`       // Runs kept per job (default 100)
+	HistoryRetentionDays int `json:"history_retention_days,omitempty"` // How long runs are kept (default 30)
 }
 
 // VectorConfig holds configuration for the optional vector/semantic search service.
@@ -112,6 +159,45 @@ type AIConfig struct {
 	Providers       []ProviderConfig    `json:"providers"`
 	ModelAliases    map[string]string   `json:"model_aliases,omitempty"`
 	SmartRouting    *SmartRoutingConfig `json:"smart_routing,omitempty"`
+	Failover        *FailoverConfig     `json:"failover,omitempty"`
+	Vision          *VisionConfig       `json:"vision,omitempty"`
+	Compaction      *CompactionConfig   `json:"compaction,omitempty"`
+}
+
+// CompactionConfig controls how session history is fitted to the model's
+// context window. History beyond HistoryShare of the window is summarized
+// into a stored session summary; Provider and Model select the summarizer
+// and default to the default provider and its model.
+type CompactionConfig struct {
+	Disabled         bool    `json:"disabled,omitempty"`      // Fall back to a fixed recent-message window
+	HistoryShare     float64 `json:"history_share,omitempty"` // Share of the context window for history (default 0.5)
+	MaxMessages      int     `json:"max_messages,omitempty"`  // Most recent messages considered per request (default 500)
+	Provider         string  `json:"provider,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	SummaryMaxTokens int     `json:"summary_max_tokens,omitempty"` // Summary length limit (default 1024)
+}
+
+// VisionConfig selects the model used by the Image tool. Provider defaults
+// to the default provider and Model to that provider's configured model.
+type VisionConfig struct {
+	Provider   string  `json:"provider,omitempty"`
+	Model      string  `json:"model,omitempty"`
+	MaxBytesMB float64 `json:"max_bytes_mb,omitempty"` // Upper bound for images sent to the model (default 5)
+	MaxTokens  int     `json:"max_tokens,omitempty"`   // Response token limit (default 1024)
+}
+
+// FailoverConfig controls provider failover in the AI router.
+// Providers in Chain are tried in order after the requested provider fails;
+// each provider is retried on 429/5xx responses before moving on, and a
+// provider whose consecutive errors reach FailureThreshold is skipped until
+// CooldownSeconds have passed.
+type FailoverConfig struct {
+	Chain            []string `json:"chain"`
+	MaxRetries       int      `json:"max_retries,omitempty"`       // Retries per provider on 429/5xx (default 2)
+	RetryBackoffMs   int      `json:"retry_backoff_ms,omitempty"`  // Initial backoff, doubled per retry (default 500)
+	MaxBackoffMs     int      `json:"max_backoff_ms,omitempty"`    // Backoff cap, also caps Retry-After (default 10000)
+	FailureThreshold int      `json:"failure_threshold,omitempty"` // Consecutive errors that open the circuit (default 5)
+	CooldownSeconds  int      `json:"cooldown_seconds,omitempty"`  // How long an open circuit skips the provider (default 60)
 }
 
 // SmartRoutingConfig holds configuration for intelligent model routing.
@@ -142,11 +228,12 @@ func DefaultModelAliases() map[string]string {
 
 // ProviderConfig contains settings for a specific AI provider
 type ProviderConfig struct {
-	Name   string      `json:"name"`
-	Type   string      `json:"type"`              // "anthropic", "openai", etc.
-	APIKey string      `json:"api_key,omitempty"` // Legacy API key
-	Model  string      `json:"model"`
-	Auth   *AuthConfig `json:"auth,omitempty"` // OAuth configuration
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`              // "anthropic", "openai", "local"
+	APIKey  string      `json:"api_key,omitempty"` // Legacy API key
+	Model   string      `json:"model"`
+	BaseURL string      `json:"base_url,omitempty"` // Endpoint for "local" providers (Ollama / OpenAI-compatible)
+	Auth    *AuthConfig `json:"auth,omitempty"`     // OAuth configuration
 }
 
 // AuthConfig contains OAuth authentication settings
@@ -188,12 +275,43 @@ type ToolsConfig struct {
 	MaxToolChains int                               `json:"max_tool_chains,omitempty"` // Maximum tool calls in a chain before stopping
 	Sandbox       SandboxConfig                     `json:"sandbox"`
 	Services      map[string]map[string]interface{} `json:"services,omitempty"`
+	Approval      *ApprovalConfig                   `json:"approval,omitempty"` // Human approval for matching tool calls
+}
+
+// Actions an approval rule can take on a matching tool call
+const (
+	ApprovalActionAsk  = "ask"  // Prompt the user and wait for a decision
+	ApprovalActionDeny = "deny" // Refuse without asking
+)
+
+// ApprovalConfig holds the rules that pause tool calls for a human decision
+type ApprovalConfig struct {
+	TimeoutSeconds int            `json:"timeout_seconds,omitempty"` // How long to wait for an answer before denying (default 120)
+	Rules          []ApprovalRule `json:"rules"`
+}
+
+// ApprovalRule matches tool calls by tool name and argument. The first
+// matching rule decides.
+type ApprovalRule struct {
+	Tool   string `json:"tool"`             // Tool name, or "*" for any tool
+	Arg    string `json:"arg,omitempty"`    // Argument the patterns test; empty tests all arguments as JSON
+	Match  string `json:"match,omitempty"`  // Regexp the argument must match (default: any value)
+	Unless string `json:"unless,omitempty"` // Regexp that exempts an otherwise matching value
+	Action string `json:"action,omitempty"` // "ask" (default) or "deny"
 }
 
 // SandboxConfig contains sandboxing settings for tool execution
 type SandboxConfig struct {
 	WorkspaceDir string   `json:"workspace_dir"`
-	AllowedPaths []string `json:"allowed_paths"`
+	AllowedPaths []string `json:"allowed_paths"` // Writable from Bash commands; everything else is read-only
+
+	// Bash command isolation
+	Backend        string `json:"backend,omitempty"`         // "auto" (default), "bubblewrap", "namespaces" or "none"
+	Network        bool   `json:"network,omitempty"`         // Allow network access from commands
+	CPUSeconds     int    `json:"cpu_seconds,omitempty"`     // CPU time per command (default 60)
+	MemoryMB       int    `json:"memory_mb,omitempty"`       // Address space per command (default 2048)
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // Wall-clock time per command (default 120)
+	MaxOutputKB    int    `json:"max_output_kb,omitempty"`   // Output kept per command (default 256)
 }
 
 // ChannelConfig contains settings for channel adapters
@@ -354,6 +472,7 @@ func Load(path string) (*Config, error) {
 			return nil, fmt.Errorf("failed to save default config: %w", err)
 		}
 		fmt.Printf("Created default configuration at %s\n", path)
+		cfg.path = path
 		return cfg, nil
 	}
 
@@ -387,9 +506,15 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	cfg.path = path
 	return &cfg, nil
 }
 
+// Path returns the file the config was loaded from, or "" if it was built in code
+func (c *Config) Path() string {
+	return c.path
+}
+
 // Save saves the configuration to a file
 func (c *Config) Save(path string) error {
 	data, err := json.MarshalIndent(c, "", "  ")
@@ -413,6 +538,7 @@ func (c *Config) expandEnvVars() error {
 	// Expand AI provider settings
 	for i := range c.AI.Providers {
 		c.AI.Providers[i].APIKey = os.ExpandEnv(c.AI.Providers[i].APIKey)
+		c.AI.Providers[i].BaseURL = os.ExpandEnv(c.AI.Providers[i].BaseURL)
 
 		// Expand OAuth configuration if present
 		if c.AI.Providers[i].Auth != nil {
@@ -479,6 +605,59 @@ func (c *Config) Validate() error {
 		fmt.Printf("WARNING: max_tool_chains is set to %d, which may be too low for complex tasks. Consider using 25 or higher.\n", c.Tools.MaxToolChains)
 	}
 
+	// Validate session queue policy
+	switch c.Sessions.QueuePolicy {
+	case "", QueuePolicyQueue, QueuePolicyMerge, QueuePolicyInterrupt:
+	default:
+		return fmt.Errorf("invalid sessions.queue_policy '%s': must be queue, merge or interrupt", c.Sessions.QueuePolicy)
+	}
+
+	// Validate outbox retry settings
+	if c.Outbox.MaxAttempts < 0 || c.Outbox.RetryBackoffSeconds < 0 || c.Outbox.MaxBackoffSeconds < 0 || c.Outbox.RetentionHours < 0 {
+		return fmt.Errorf("outbox settings must not be negative")
+	}
+
+	// Validate channel supervisor settings
+	if cs := c.ChannelSupervisor; cs.IntervalSeconds < 0 || cs.GraceSeconds < 0 || cs.BackoffSeconds < 0 || cs.MaxBackoffSeconds < 0 || cs.MaxRestarts < 0 {
+		return fmt.Errorf("channel_supervisor settings must not be negative")
+	}
+
+	// Validate scheduler history retention
+	if c.Scheduler.HistoryMaxRuns < 0 || c.Scheduler.HistoryRetentionDays < 0 {
+		return fmt.Errorf("scheduler settings must not be negative")
+	}
+
+	// Validate Bash sandbox backend
+	switch c.Tools.Sandbox.Backend {
+	case "", "auto", "bubblewrap", "namespaces", "none":
+	default:
+		return fmt.Errorf("invalid tools.sandbox.backend '%s': must be auto, bubblewrap, namespaces or none", c.Tools.Sandbox.Backend)
+	}
+
+	// Validate tool approval rules
+	if ac := c.Tools.Approval; ac != nil {
+		for i, rule := range ac.Rules {
+			if rule.Tool == "" {
+				return fmt.Errorf("tools.approval.rules[%d]: tool is required", i)
+			}
+			switch rule.Action {
+			case "", ApprovalActionAsk, ApprovalActionDeny:
+			default:
+				return fmt.Errorf("tools.approval.rules[%d]: invalid action '%s': must be ask or deny", i, rule.Action)
+			}
+			for _, pattern := range []string{rule.Match, rule.Unless} {
+				if _, err := regexp.Compile(pattern); err != nil {
+					return fmt.Errorf("tools.approval.rules[%d]: invalid pattern: %w", i, err)
+				}
+			}
+		}
+	}
+
+	// Validate history compaction
+	if cc := c.AI.Compaction; cc != nil && (cc.HistoryShare < 0 || cc.HistoryShare > 1) {
+		return fmt.Errorf("ai.compaction.history_share must be between 0 and 1")
+	}
+
 	// Validate timezone if set
 	if c.Timezone != "" {
 		if _, err := time.LoadLocation(c.Timezone); err != nil {
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Update is a partial configuration change that a running gateway can apply
+// without restarting. Fields left nil are not changed.
+type Update struct {
+	ModelAliases   map[string]string     `json:"model_aliases,omitempty"`   // Merged into ai.model_aliases; an empty value removes the alias
+	RateLimiting   *RateLimitingUpdate   `json:"rateLimiting,omitempty"`    // Merged into rateLimiting
+	EnabledTools   []string              `json:"enabled_tools,omitempty"`   // Replaces tools.enabled_tools
+	Channels       map[string]bool       `json:"channels,omitempty"`        // Channel name -> enabled
+	AgentHeartbeat *AgentHeartbeatUpdate `json:"agent_heartbeat,omitempty"` // Heartbeat schedule
+}
+
+// RateLimitingUpdate changes rate limits. Zero tier fields are left as they are.
+type RateLimitingUpdate struct {
+	Enabled       *bool                `json:"enabled,omitempty"`
+	Anonymous     *RateLimitTierConfig `json:"anonymous,omitempty"`
+	Authenticated *RateLimitTierConfig `json:"authenticated,omitempty"`
+}
+
+// AgentHeartbeatUpdate changes the agent heartbeat schedule
+type AgentHeartbeatUpdate struct {
+	Enabled         *bool `json:"enabled,omitempty"`
+	IntervalMinutes *int  `json:"interval_minutes,omitempty"`
+}
+
+// ReloadConfig controls reloading the config file while the gateway runs
+type ReloadConfig struct {
+	Watch           bool `json:"watch"`                      // Poll the config file and apply changes
+	IntervalSeconds int  `json:"interval_seconds,omitempty"` // Poll interval (default 5)
+}
+
+// DecodeUpdate parses a JSON update, rejecting settings that cannot be
+// changed at runtime
+func DecodeUpdate(data []byte) (*Update, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var u Update
+	if err := dec.Decode(&u); err != nil {
+		return nil, fmt.Errorf("invalid configuration update: %w", err)
+	}
+	if u.IsEmpty() {
+		return nil, fmt.Errorf("configuration update is empty (updatable: model_aliases, rateLimiting, enabled_tools, channels, agent_heartbeat)")
+	}
+	return &u, nil
+}
+
+// ParseUpdate converts a decoded JSON object (such as tool arguments) into an Update
+func ParseUpdate(m map[string]interface{}) (*Update, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration update: %w", err)
+	}
+	return DecodeUpdate(data)
+}
+
+// IsEmpty reports whether the update changes nothing
+func (u *Update) IsEmpty() bool {
+	return u.ModelAliases == nil && u.RateLimiting == nil && u.EnabledTools == nil &&
+		u.Channels == nil && u.AgentHeartbeat == nil
+}
+
+// Sections lists the config keys the update touches, for logs and audit records
+func (u *Update) Sections() []string {
+	var sections []string
+	if u.ModelAliases != nil {
+		sections = append(sections, "ai.model_aliases")
+	}
+	if u.RateLimiting != nil {
+		sections = append(sections, "rateLimiting")
+	}
+	if u.EnabledTools != nil {
+		sections = append(sections, "tools.enabled_tools")
+	}
+	if u.Channels != nil {
+		sections = append(sections, "channels")
+	}
+	if u.AgentHeartbeat != nil {
+		sections = append(sections, "agent_heartbeat")
+	}
+	return sections
+}
+
+// Apply returns a copy of c with the update applied and validated. c is not modified.
+func (u *Update) Apply(c *Config) (*Config, error) {
+	next := *c
+	if err := u.applyTo(&next); err != nil {
+		return nil, err
+	}
+	if err := next.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return &next, nil
+}
+
+// applyTo changes c in place, cloning any map or slice it touches so that a
+// shallow copy of another Config can be updated safely
+func (u *Update) applyTo(c *Config) error {
+	if u.ModelAliases != nil {
+		aliases := make(map[string]string, len(c.AI.ModelAliases)+len(u.ModelAliases))
+		for k, v := range c.AI.ModelAliases {
+			aliases[k] = v
+		}
+		for k, v := range u.ModelAliases {
+			if strings.TrimSpace(k) == "" {
+				return fmt.Errorf("model alias name cannot be empty")
+			}
+			if v == "" {
+				delete(aliases, k)
+			} else {
+				aliases[k] = v
+			}
+		}
+		c.AI.ModelAliases = aliases
+	}
+
+	if rl := u.RateLimiting; rl != nil {
+		if rl.Enabled != nil {
+			c.RateLimiting.Enabled = *rl.Enabled
+		}
+		mergeRateLimitTier(&c.RateLimiting.Anonymous, rl.Anonymous)
+		mergeRateLimitTier(&c.RateLimiting.Authenticated, rl.Authenticated)
+	}
+
+	if u.EnabledTools != nil {
+		c.Tools.EnabledTools = append([]string{}, u.EnabledTools...)
+	}
+
+	if u.Channels != nil {
+		channels := append([]ChannelConfig{}, c.Channels...)
+		for name, enabled := range u.Channels {
+			found := false
+			for i := range channels {
+				if channels[i].Name == name {
+					channels[i].Enabled = enabled
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("unknown channel %q", name)
+			}
+		}
+		c.Channels = channels
+	}
+
+	if hb := u.AgentHeartbeat; hb != nil {
+		if hb.Enabled != nil {
+			c.AgentHeartbeat.Enabled = *hb.Enabled
+		}
+		if hb.IntervalMinutes != nil {
+			c.AgentHeartbeat.IntervalMinutes = *hb.IntervalMinutes
+		}
+	}
+
+	return nil
+}
+
+// mergeRateLimitTier copies the non-zero fields of update into tier
+func mergeRateLimitTier(tier *RateLimitTierConfig, update *RateLimitTierConfig) {
+	if update == nil {
+		return
+	}
+	if update.WindowSeconds != 0 {
+		tier.WindowSeconds = update.WindowSeconds
+	}
+	if update.MaxRequests != 0 {
+		tier.MaxRequests = update.MaxRequests
+	}
+}
+
+// Diff returns the runtime-updatable changes between two configurations,
+// or nil when none of those settings differ. Used when the config file is
+// reloaded; other settings still need a restart.
+func Diff(old, next *Config) *Update {
+	u := &Update{}
+
+	if !reflect.DeepEqual(old.AI.ModelAliases, next.AI.ModelAliases) {
+		u.ModelAliases = make(map[string]string)
+		for k := range old.AI.ModelAliases {
+			if _, ok := next.AI.ModelAliases[k]; !ok {
+				u.ModelAliases[k] = ""
+			}
+		}
+		for k, v := range next.AI.ModelAliases {
+			if old.AI.ModelAliases[k] != v {
+				u.ModelAliases[k] = v
+			}
+		}
+	}
+
+	if old.RateLimiting.Enabled != next.RateLimiting.Enabled ||
+		old.RateLimiting.Anonymous != next.RateLimiting.Anonymous ||
+		old.RateLimiting.Authenticated != next.RateLimiting.Authenticated {
+		enabled := next.RateLimiting.Enabled
+		anonymous := next.RateLimiting.Anonymous
+		authenticated := next.RateLimiting.Authenticated
+		u.RateLimiting = &RateLimitingUpdate{
+			Enabled:       &enabled,
+			Anonymous:     &anonymous,
+			Authenticated: &authenticated,
+		}
+	}
+
+	if !sameStringSet(old.Tools.EnabledTools, next.Tools.EnabledTools) {
+		u.EnabledTools = append([]string{}, next.Tools.EnabledTools...)
+	}
+
+	for _, ch := range next.Channels {
+		for _, prev := range old.Channels {
+			if prev.Name == ch.Name && prev.Enabled != ch.Enabled {
+				if u.Channels == nil {
+					u.Channels = make(map[string]bool)
+				}
+				u.Channels[ch.Name] = ch.Enabled
+			}
+		}
+	}
+
+	if old.AgentHeartbeat.Enabled != next.AgentHeartbeat.Enabled ||
+		old.AgentHeartbeat.IntervalMinutes != next.AgentHeartbeat.IntervalMinutes {
+		enabled := next.AgentHeartbeat.Enabled
+		interval := next.AgentHeartbeat.IntervalMinutes
+		u.AgentHeartbeat = &AgentHeartbeatUpdate{Enabled: &enabled, IntervalMinutes: &interval}
+	}
+
+	if u.IsEmpty() {
+		return nil
+	}
+	return u
+}
+
+// sameStringSet reports whether a and b hold the same strings in any order
+func sameStringSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	return reflect.DeepEqual(x, y)
+}
+
+// SaveUpdate writes an update back to the config file at path. The file is
+// re-read without expanding environment variables or secrets so that
+// ${VAR} placeholders are preserved.
+func SaveUpdate(path string, u *Update) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw Config
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if err := u.applyTo(&raw); err != nil {
+		return err
+	}
+	return raw.Save(path)
+}
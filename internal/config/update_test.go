@@ -0,0 +1,184 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseUpdate(t *testing.T) {
+	u, err := ParseUpdate(map[string]interface{}{
+		"model_aliases":   map[string]interface{}{"fast": "claude-haiku-4-5"},
+		"enabled_tools":   []interface{}{"Read", "Write"},
+		"channels":        map[string]interface{}{"telegram": false},
+		"agent_heartbeat": map[string]interface{}{"interval_minutes": 15},
+	})
+	if err != nil {
+		t.Fatalf("ParseUpdate failed: %v", err)
+	}
+	if u.ModelAliases["fast"] != "claude-haiku-4-5" || len(u.EnabledTools) != 2 || u.Channels["telegram"] {
+		t.Errorf("Unexpected update: %+v", u)
+	}
+	if u.AgentHeartbeat == nil || u.AgentHeartbeat.IntervalMinutes == nil || *u.AgentHeartbeat.IntervalMinutes != 15 {
+		t.Errorf("Expected heartbeat interval 15, got %+v", u.AgentHeartbeat)
+	}
+	if u.AgentHeartbeat.Enabled != nil {
+		t.Error("Expected unset heartbeat fields to stay nil")
+	}
+
+	tests := []struct {
+		name   string
+		update map[string]interface{}
+		errMsg string
+	}{
+		{"not runtime-updatable", map[string]interface{}{"port": 9000}, "unknown field"},
+		{"unknown nested field", map[string]interface{}{"agent_heartbeat": map[string]interface{}{"timezone": "UTC"}}, "unknown field"},
+		{"wrong type", map[string]interface{}{"enabled_tools": "Read"}, "invalid configuration update"},
+		{"empty", map[string]interface{}{}, "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseUpdate(tt.update)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestUpdateApply(t *testing.T) {
+	cfg := Default()
+	cfg.AI.ModelAliases = map[string]string{"haiku": "old-haiku", "opus": "old-opus"}
+	enabled := false
+	interval := 10
+
+	next, err := (&Update{
+		ModelAliases: map[string]string{"haiku": "new-haiku", "opus": ""},
+		RateLimiting: &RateLimitingUpdate{Anonymous: &RateLimitTierConfig{MaxRequests: 5}},
+		EnabledTools: []string{"Read"},
+		Channels:     map[string]bool{"telegram": true},
+		AgentHeartbeat: &AgentHeartbeatUpdate{
+			Enabled:         &enabled,
+			IntervalMinutes: &interval,
+		},
+	}).Apply(cfg)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if next.AI.ModelAliases["haiku"] != "new-haiku" {
+		t.Errorf("Expected alias to be replaced, got %v", next.AI.ModelAliases)
+	}
+	if _, ok := next.AI.ModelAliases["opus"]; ok {
+		t.Error("Expected empty value to remove the alias")
+	}
+	if next.RateLimiting.Anonymous.MaxRequests != 5 || next.RateLimiting.Anonymous.WindowSeconds != cfg.RateLimiting.Anonymous.WindowSeconds {
+		t.Errorf("Expected only max requests to change, got %+v", next.RateLimiting.Anonymous)
+	}
+	if len(next.Tools.EnabledTools) != 1 || next.Tools.EnabledTools[0] != "Read" {
+		t.Errorf("Expected enabled tools to be replaced, got %v", next.Tools.EnabledTools)
+	}
+	if !next.Channels[0].Enabled || next.AgentHeartbeat.Enabled || next.AgentHeartbeat.IntervalMinutes != 10 {
+		t.Error("Expected channel and heartbeat changes to apply")
+	}
+
+	// The original config is untouched
+	if cfg.AI.ModelAliases["haiku"] != "old-haiku" || cfg.Channels[0].Enabled || len(cfg.Tools.EnabledTools) != 4 {
+		t.Error("Apply must not modify the original config")
+	}
+}
+
+func TestUpdateApply_Invalid(t *testing.T) {
+	cfg := Default()
+	cfg.AgentHeartbeat.Enabled = true
+	tooLong := 120
+	negative := -1
+
+	tests := []struct {
+		name   string
+		update *Update
+		errMsg string
+	}{
+		{"unknown channel", &Update{Channels: map[string]bool{"missing": true}}, "unknown channel"},
+		{"heartbeat interval", &Update{AgentHeartbeat: &AgentHeartbeatUpdate{IntervalMinutes: &tooLong}}, "cannot exceed 60 minutes"},
+		{"rate limit", &Update{RateLimiting: &RateLimitingUpdate{Authenticated: &RateLimitTierConfig{MaxRequests: negative}}}, "invalid authenticated rate limiting"},
+		{"empty alias name", &Update{ModelAliases: map[string]string{" ": "model"}}, "cannot be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.update.Apply(cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := Default()
+	if u := Diff(old, Default()); u != nil {
+		t.Errorf("Expected no changes between identical configs, got %+v", u)
+	}
+
+	next := Default()
+	delete(next.AI.ModelAliases, "haiku")
+	next.AI.ModelAliases["fast"] = "model-x"
+	next.Tools.EnabledTools = []string{"web_search", "exec", "write", "read"} // Same set, new order
+	next.Channels[0].Enabled = true
+	next.Port = 9999 // Not runtime-updatable
+
+	u := Diff(old, next)
+	if u == nil {
+		t.Fatal("Expected changes")
+	}
+	if v, ok := u.ModelAliases["haiku"]; !ok || v != "" {
+		t.Errorf("Expected removed alias to diff as empty, got %v", u.ModelAliases)
+	}
+	if u.ModelAliases["fast"] != "model-x" || len(u.ModelAliases) != 2 {
+		t.Errorf("Expected only changed aliases, got %v", u.ModelAliases)
+	}
+	if u.EnabledTools != nil {
+		t.Error("Expected reordered tools not to count as a change")
+	}
+	if !u.Channels["telegram"] || u.RateLimiting != nil || u.AgentHeartbeat != nil {
+		t.Errorf("Unexpected update: %+v", u)
+	}
+}
+
+func TestSaveUpdate_PreservesPlaceholders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	raw := `{"port": 18789, "tools": {"max_tool_chains": 25}, "ai": {"providers": [{"name": "a", "type": "anthropic", "api_key": "${TEST_SAVE_UPDATE_KEY}"}]},
+		"channels": [{"name": "telegram", "type": "telegram", "enabled": false, "config": {"bot_token": "${TEST_SAVE_UPDATE_TOKEN}"}}]}`
+	if err := os.WriteFile(path, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_SAVE_UPDATE_KEY", "secret-key")
+	t.Setenv("TEST_SAVE_UPDATE_TOKEN", "secret-token")
+
+	if err := SaveUpdate(path, &Update{Channels: map[string]bool{"telegram": true}}); err != nil {
+		t.Fatalf("SaveUpdate failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-") {
+		t.Error("Expected secrets not to be written to the file")
+	}
+	if !strings.Contains(string(data), "${TEST_SAVE_UPDATE_TOKEN}") {
+		t.Error("Expected placeholders to be preserved")
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !cfg.Channels[0].Enabled {
+		t.Error("Expected the saved update to be loaded")
+	}
+	if cfg.Path() != path {
+		t.Errorf("Expected Path %q, got %q", path, cfg.Path())
+	}
+}
@@ -156,6 +156,144 @@ func GetMigrations() []Migration {
 				SELECT id, session_key, role, content FROM messages;
 			`,
 		},
+		{
+			Version: 5,
+			Name:    "add_message_content_blocks",
+			SQL: `
+				-- JSON array of multimodal content blocks (images, documents); empty for text-only messages
+				ALTER TABLE messages ADD COLUMN content_blocks TEXT NOT NULL DEFAULT '';
+			`,
+		},
+		{
+			Version: 6,
+			Name:    "add_message_tool_turns",
+			SQL: `
+				-- JSON array of tool calls requested by an assistant message
+				ALTER TABLE messages ADD COLUMN tool_calls TEXT NOT NULL DEFAULT '';
+				-- For role 'tool' messages, the ID of the tool call this result answers
+				ALTER TABLE messages ADD COLUMN tool_call_id TEXT NOT NULL DEFAULT '';
+				CREATE INDEX IF NOT EXISTS idx_messages_tool_call_id ON messages(tool_call_id) WHERE tool_call_id != '';
+			`,
+		},
+		{
+			Version: 7,
+			Name:    "create_session_summaries_table",
+			SQL: `
+				-- Running summary of session history compacted out of the context window
+				CREATE TABLE IF NOT EXISTS session_summaries (
+					session_key TEXT PRIMARY KEY,
+					content TEXT NOT NULL,
+					through_message_id TEXT NOT NULL, -- Last message covered by the summary
+					message_count INTEGER NOT NULL DEFAULT 0, -- Messages summarized so far
+					updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+					FOREIGN KEY (session_key) REFERENCES sessions (key) ON DELETE CASCADE
+				);
+			`,
+		},
+		{
+			Version: 8,
+			Name:    "add_auth_token_scopes",
+			SQL: `
+				-- JSON array of scopes; tokens created before scopes existed keep full access
+				ALTER TABLE auth_tokens ADD COLUMN scopes TEXT NOT NULL DEFAULT '["admin"]';
+				-- JSON arrays of channel ID and session key patterns the token is limited to; empty allows all
+				ALTER TABLE auth_tokens ADD COLUMN channels TEXT NOT NULL DEFAULT '[]';
+				ALTER TABLE auth_tokens ADD COLUMN sessions TEXT NOT NULL DEFAULT '[]';
+			`,
+		},
+		{
+			Version: 9,
+			Name:    "create_config_audit_table",
+			SQL: `
+				-- Runtime configuration changes applied through the Gateway tool, the API or a reload
+				CREATE TABLE IF NOT EXISTS config_audit (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+					source TEXT NOT NULL, -- tool, api, sighup or file_watch
+					actor TEXT NOT NULL DEFAULT '', -- Token client or channel user that made the change
+					changes TEXT NOT NULL, -- JSON of the requested update
+					persisted INTEGER NOT NULL DEFAULT 0, -- Whether the change was written to the config file
+					error TEXT NOT NULL DEFAULT '' -- Why the change failed, empty on success
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_config_audit_timestamp ON config_audit (timestamp);
+			`,
+		},
+		{
+			Version: 10,
+			Name:    "create_slack_pairings_table",
+			SQL: `
+				-- Pairing codes for Slack users, same shape as telegram_pairings
+				CREATE TABLE IF NOT EXISTS slack_pairings (
+					code VARCHAR(36) PRIMARY KEY,
+					user_id TEXT NOT NULL,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					expires_at TIMESTAMP NOT NULL,
+					is_active BOOLEAN DEFAULT 1,
+					metadata TEXT DEFAULT '{}'
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_slack_pairings_user_id ON slack_pairings (user_id);
+				CREATE INDEX IF NOT EXISTS idx_slack_pairings_is_active ON slack_pairings (is_active);
+			`,
+		},
+		{
+			Version: 11,
+			Name:    "create_channel_outbox_table",
+			SQL: `
+				-- Outgoing channel messages, kept until delivered or dead-lettered
+				CREATE TABLE IF NOT EXISTS channel_outbox (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					channel_id TEXT NOT NULL,
+					user_id TEXT NOT NULL,
+					session_key TEXT DEFAULT '',
+					message TEXT NOT NULL,
+					status TEXT NOT NULL DEFAULT 'pending',
+					attempts INTEGER NOT NULL DEFAULT 0,
+					next_attempt_at TIMESTAMP NOT NULL,
+					last_error TEXT DEFAULT '',
+					created_at TIMESTAMP NOT NULL,
+					updated_at TIMESTAMP NOT NULL
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_channel_outbox_due ON channel_outbox (status, next_attempt_at);
+				CREATE INDEX IF NOT EXISTS idx_channel_outbox_channel_id ON channel_outbox (channel_id);
+			`,
+		},
+		{
+			Version: 12,
+			Name:    "create_job_runs_table",
+			SQL: `
+				-- One row per execution of a scheduled job
+				CREATE TABLE IF NOT EXISTS job_runs (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					job_id TEXT NOT NULL,
+					job_name TEXT DEFAULT '',
+					triggered_by TEXT NOT NULL DEFAULT 'schedule',
+					status TEXT NOT NULL DEFAULT 'running',
+					error TEXT DEFAULT '',
+					model TEXT DEFAULT '',
+					prompt_tokens INTEGER NOT NULL DEFAULT 0,
+					completion_tokens INTEGER NOT NULL DEFAULT 0,
+					total_tokens INTEGER NOT NULL DEFAULT 0,
+					output TEXT DEFAULT '',
+					started_at TIMESTAMP NOT NULL,
+					finished_at TIMESTAMP,
+					duration_ms INTEGER NOT NULL DEFAULT 0
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_job_runs_job_id ON job_runs (job_id, started_at);
+				CREATE INDEX IF NOT EXISTS idx_job_runs_started_at ON job_runs (started_at);
+			`,
+		},
+		{
+			Version: 13,
+			Name:    "add_job_runs_attempt",
+			SQL: `
+				-- Retried runs record each attempt as its own row
+				ALTER TABLE job_runs ADD COLUMN attempt INTEGER NOT NULL DEFAULT 1;
+			`,
+		},
 	}
 }
 
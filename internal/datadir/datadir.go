@@ -19,6 +19,7 @@ const (
 	sshSubdir       = "ssh"
 	databaseSubdir  = "data"
 	workspaceSubdir = "workspace"
+	mediaSubdir     = "media"
 )
 
 // DataDir provides a single source of truth for all data-directory paths.
@@ -61,6 +62,9 @@ func (d *DataDir) DatabaseDir() string { return filepath.Join(d.root, databaseSu
 // WorkspaceDir returns {root}/workspace/.
 func (d *DataDir) WorkspaceDir() string { return filepath.Join(d.root, workspaceSubdir) }
 
+// MediaDir returns {root}/media/, where channel attachments are stored.
+func (d *DataDir) MediaDir() string { return filepath.Join(d.root, mediaSubdir) }
+
 // FilePath returns the full path to a file directly inside the root directory.
 func (d *DataDir) FilePath(filename string) string {
 	return filepath.Join(d.root, filename)
@@ -84,6 +88,7 @@ func (d *DataDir) subdirectories() []string {
 		d.SSHDir(),
 		d.DatabaseDir(),
 		d.WorkspaceDir(),
+		d.MediaDir(),
 	}
 }
 
@@ -124,6 +124,7 @@ func TestDataDir_Subdirectories(t *testing.T) {
 	assert.Equal(t, filepath.Join(root, "ssh"), dd.SSHDir())
 	assert.Equal(t, filepath.Join(root, "data"), dd.DatabaseDir())
 	assert.Equal(t, filepath.Join(root, "workspace"), dd.WorkspaceDir())
+	assert.Equal(t, filepath.Join(root, "media"), dd.MediaDir())
 }
 
 func TestDataDir_FilePaths(t *testing.T) {
@@ -159,6 +160,7 @@ func TestDataDir_EnsureDirs(t *testing.T) {
 		dd.SSHDir(),
 		dd.DatabaseDir(),
 		dd.WorkspaceDir(),
+		dd.MediaDir(),
 	} {
 		info, err := os.Stat(dir)
 		require.NoError(t, err, "dir should exist: %s", dir)
@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"conduit/internal/tools"
+	"conduit/pkg/protocol"
+)
+
+// channelApprovalPrompter asks the sender of msg to approve a tool call.
+// Adapters with buttons (Telegram) show Approve/Deny; elsewhere the user
+// replies with the command in the text.
+func (g *Gateway) channelApprovalPrompter(msg *protocol.IncomingMessage) tools.ApprovalPrompter {
+	return func(ctx context.Context, req tools.ApprovalRequest) error {
+		keyboard, err := json.Marshal([][]protocol.InlineButton{{
+			{Text: "✅ Approve", Data: "/approve " + req.ID},
+			{Text: "❌ Deny", Data: "/deny " + req.ID},
+		}})
+		if err != nil {
+			return err
+		}
+
+		return g.channelManager.SendMessage(&protocol.OutgoingMessage{
+			BaseMessage: protocol.BaseMessage{
+				Type:      protocol.TypeOutgoingMessage,
+				ID:        fmt.Sprintf("approval_%s", req.ID),
+				Timestamp: time.Now(),
+			},
+			ChannelID:  msg.ChannelID,
+			SessionKey: msg.SessionKey,
+			UserID:     msg.UserID,
+			Text:       formatApprovalPrompt(req),
+			Metadata: map[string]string{
+				protocol.MetadataInlineKeyboard: string(keyboard),
+			},
+		})
+	}
+}
+
+// formatApprovalPrompt renders an approval request for chat channels
+func formatApprovalPrompt(req tools.ApprovalRequest) string {
+	return fmt.Sprintf("🔐 *Approval needed*\n\n```\n%s\n```\nRule: %s\n\nReply /approve %s or /deny %s within %s.",
+		req.Summary(), req.Reason, req.ID, req.ID, time.Until(req.ExpiresAt).Round(time.Second))
+}
+
+// approvalRequestMessage converts an approval request for WebSocket and TUI clients
+func approvalRequestMessage(req tools.ApprovalRequest, requestID string) *protocol.ApprovalRequest {
+	return &protocol.ApprovalRequest{
+		BaseMessage: protocol.BaseMessage{
+			Type:      protocol.TypeApprovalRequest,
+			ID:        fmt.Sprintf("ar_%d", time.Now().UnixNano()),
+			Timestamp: time.Now(),
+		},
+		SessionKey: req.SessionKey,
+		RequestID:  requestID,
+		ApprovalID: req.ID,
+		ToolName:   req.ToolName,
+		Summary:    req.Summary(),
+		Reason:     req.Reason,
+		ExpiresAt:  req.ExpiresAt,
+	}
+}
+
+// isApprovalCommand reports whether text is /approve or /deny
+func isApprovalCommand(text string) bool {
+	fields := strings.Fields(text)
+	return len(fields) > 0 && (fields[0] == "/approve" || fields[0] == "/deny")
+}
+
+// answerApproval handles /approve [id] and /deny [id] for a session and
+// returns the reply. Without an ID the oldest waiting request is answered.
+func answerApproval(approvals *tools.ApprovalMiddleware, sessionKey, text string) string {
+	if approvals == nil {
+		return "Tool approval is not enabled."
+	}
+
+	fields := strings.Fields(text)
+	approved := fields[0] == "/approve"
+
+	var id string
+	if len(fields) > 1 {
+		id = fields[1]
+	} else {
+		pending := approvals.Pending(sessionKey)
+		if len(pending) == 0 {
+			return "Nothing is waiting for approval."
+		}
+		id = pending[0].ID
+	}
+
+	if err := approvals.Resolve(id, sessionKey, approved); err != nil {
+		return "That approval request has expired or was already answered."
+	}
+	if approved {
+		return "✅ Approved."
+	}
+	return "❌ Denied."
+}
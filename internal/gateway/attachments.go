@@ -0,0 +1,70 @@
+package gateway
+
+import (
+	"fmt"
+	"strings"
+
+	"conduit/internal/sessions"
+	"conduit/pkg/protocol"
+)
+
+// attachmentBlocks converts channel attachments into session content blocks.
+// Images and PDF/text documents are passed to the model directly; anything
+// the model cannot read natively (audio, office files) becomes a text note
+// with the stored path so the agent can process it with tools.
+func attachmentBlocks(attachments []protocol.Attachment) []sessions.ContentBlock {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	blocks := make([]sessions.ContentBlock, 0, len(attachments))
+	for _, att := range attachments {
+		switch {
+		case att.Type == protocol.AttachmentImage && isModelImageType(att.MediaType):
+			blocks = append(blocks, sessions.ContentBlock{
+				Type:      sessions.BlockTypeImage,
+				MediaType: att.MediaType,
+				Path:      att.Path,
+				Filename:  att.Filename,
+			})
+		case att.Type == protocol.AttachmentDocument && isModelDocumentType(att.MediaType):
+			blocks = append(blocks, sessions.ContentBlock{
+				Type:      sessions.BlockTypeDocument,
+				MediaType: att.MediaType,
+				Path:      att.Path,
+				Filename:  att.Filename,
+			})
+		default:
+			blocks = append(blocks, sessions.ContentBlock{
+				Type: sessions.BlockTypeText,
+				Text: attachmentNote(att),
+			})
+		}
+	}
+	return blocks
+}
+
+// attachmentNote describes an attachment the model cannot read directly
+func attachmentNote(att protocol.Attachment) string {
+	mediaType := att.MediaType
+	if mediaType == "" {
+		mediaType = "unknown type"
+	}
+	return fmt.Sprintf("[%s attachment %q (%s, %d bytes) saved at %s]", att.Type, att.Filename, mediaType, att.Size, att.Path)
+}
+
+// isModelImageType reports whether an image can be sent to vision models
+func isModelImageType(mediaType string) bool {
+	switch mediaType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// isModelDocumentType reports whether a document can be sent to models as-is
+func isModelDocumentType(mediaType string) bool {
+	return mediaType == "application/pdf" ||
+		strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json"
+}
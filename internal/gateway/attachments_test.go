@@ -0,0 +1,43 @@
+package gateway
+
+import (
+	"strings"
+	"testing"
+
+	"conduit/internal/sessions"
+	"conduit/pkg/protocol"
+)
+
+func TestAttachmentBlocks(t *testing.T) {
+	blocks := attachmentBlocks([]protocol.Attachment{
+		{Type: protocol.AttachmentImage, Path: "/m/s/1_photo.jpg", MediaType: "image/jpeg", Filename: "1_photo.jpg"},
+		{Type: protocol.AttachmentDocument, Path: "/m/s/2_report.pdf", MediaType: "application/pdf", Filename: "2_report.pdf"},
+		{Type: protocol.AttachmentDocument, Path: "/m/s/3_deck.pptx", MediaType: "application/vnd.ms-powerpoint", Filename: "3_deck.pptx", Size: 2048},
+		{Type: protocol.AttachmentAudio, Path: "/m/s/4_voice.ogg", MediaType: "audio/ogg", Filename: "4_voice.ogg", Size: 512},
+	})
+
+	if len(blocks) != 4 {
+		t.Fatalf("Expected 4 blocks, got %d", len(blocks))
+	}
+
+	if blocks[0].Type != sessions.BlockTypeImage || blocks[0].Path != "/m/s/1_photo.jpg" {
+		t.Errorf("Expected image block, got %+v", blocks[0])
+	}
+	if blocks[1].Type != sessions.BlockTypeDocument || blocks[1].MediaType != "application/pdf" {
+		t.Errorf("Expected document block, got %+v", blocks[1])
+	}
+
+	// Formats the model can't read become notes pointing at the stored file
+	for _, b := range blocks[2:] {
+		if b.Type != sessions.BlockTypeText {
+			t.Errorf("Expected text note, got %+v", b)
+		}
+	}
+	if !strings.Contains(blocks[3].Text, "/m/s/4_voice.ogg") || !strings.Contains(blocks[3].Text, "audio attachment") {
+		t.Errorf("Unexpected audio note: %s", blocks[3].Text)
+	}
+
+	if attachmentBlocks(nil) != nil {
+		t.Error("Expected nil blocks without attachments")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"time"
 
+	"conduit/internal/channels"
+	"conduit/internal/monitoring"
 	"conduit/internal/sessions"
 	"conduit/internal/tools/types"
 	"conduit/pkg/protocol"
@@ -28,6 +30,42 @@ func (g *Gateway) SendMessage(ctx context.Context, channelID, userID, content st
 	return g.channelManager.SendMessage(outgoingMsg)
 }
 
+// publishChannelEvent records a channel supervisor event in the diagnostics
+// event store
+func (g *Gateway) publishChannelEvent(e channels.SupervisorEvent) {
+	if g.eventStore == nil {
+		return
+	}
+
+	source := "channel:" + e.AdapterID
+	var event *monitoring.HeartbeatEvent
+	switch e.Kind {
+	case channels.SupervisorStatusChange:
+		event = monitoring.NewStatusChangeEvent(string(e.OldStatus), string(e.NewStatus), source)
+		if e.NewStatus == channels.StatusOnline {
+			event.Severity = monitoring.SeverityInfo
+		}
+	case channels.SupervisorRestarted:
+		event = monitoring.NewSystemEvent(monitoring.SeverityWarning, e.Message, source)
+	case channels.SupervisorRestartFail:
+		event = monitoring.NewSystemEvent(monitoring.SeverityError, e.Message, source)
+	case channels.SupervisorGaveUp:
+		event = monitoring.NewSystemEvent(monitoring.SeverityCritical, e.Message, source)
+	default:
+		event = monitoring.NewSystemEvent(monitoring.SeverityInfo, e.Message, source)
+	}
+	event.AddMetadata("channel", e.AdapterID)
+	event.AddMetadata("action", e.Kind)
+	event.AddMetadata("restarts", e.Restarts)
+	if e.Err != nil {
+		event.AddMetadata("error", e.Err.Error())
+	}
+
+	if err := g.eventStore.Store(event); err != nil {
+		log.Printf("[ChannelSupervisor] Failed to store event: %v", err)
+	}
+}
+
 // GetChannelStatusMap implements the ChannelSender interface for rich error messages
 func (g *Gateway) GetChannelStatusMap() map[string]string {
 	if g.channelManager == nil {
@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"conduit/internal/channels"
+	"conduit/internal/channels/webhook"
+)
+
+func TestHandleChannelWebhook_RoutesToAdapter(t *testing.T) {
+	manager := channels.NewManager()
+	manager.RegisterFactory(webhook.NewFactory())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := manager.Start(ctx, []channels.ChannelConfig{{
+		ID: "ci", Type: "webhook", Name: "ci", Enabled: true,
+		Config: map[string]interface{}{"secret": "s3cret"},
+	}})
+	if err != nil {
+		t.Fatalf("failed to start channels: %v", err)
+	}
+	defer manager.Stop()
+
+	g := &Gateway{channelManager: manager}
+
+	body := []byte(`{"user_id": "ci-bot", "text": "deploy finished"}`)
+	now := time.Now().Unix()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/ci", bytes.NewReader(body))
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(now, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign("s3cret", now, body))
+	rec := httptest.NewRecorder()
+	g.handleChannelWebhook(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	select {
+	case msg := <-manager.ReceiveMessages():
+		if msg.ChannelID != "ci" || msg.Text != "deploy finished" {
+			t.Errorf("unexpected message: %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("message was not forwarded to the channel manager")
+	}
+
+	rec = httptest.NewRecorder()
+	g.handleChannelWebhook(rec, httptest.NewRequest(http.MethodPost, "/webhooks/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown channel, got %d", rec.Code)
+	}
+}
@@ -16,6 +16,9 @@ import (
 // handleCommand handles slash commands and returns true if handled
 func (g *Gateway) handleCommand(ctx context.Context, msg *protocol.IncomingMessage, session *sessions.Session) bool {
 	text := strings.TrimSpace(msg.Text)
+	if !isGatewayCommand(text) {
+		return false
+	}
 
 	// Check for /reset command
 	if text == "/reset" || text == "/new" || strings.HasPrefix(text, "/reset ") || strings.HasPrefix(text, "/new ") {
@@ -78,14 +81,32 @@ func (g *Gateway) handleCommand(ctx context.Context, msg *protocol.IncomingMessa
 		return true
 	}
 
+	// Check for /approve and /deny (tool approval answers)
+	if isApprovalCommand(text) {
+		g.sendCommandResponse(msg, answerApproval(g.approvals, session.Key, text))
+		return true
+	}
+
 	return false
 }
 
+// conversationMessageCount counts the user and assistant messages, leaving
+// out stored tool calls and results
+func conversationMessageCount(messages []sessions.Message) int {
+	count := 0
+	for _, m := range messages {
+		if (m.Role == "user" || m.Role == "assistant") && !m.IsToolTurn() {
+			count++
+		}
+	}
+	return count
+}
+
 // handleStatusCommand shows session status
 func (g *Gateway) handleStatusCommand(msg *protocol.IncomingMessage, session *sessions.Session) {
 	// Get message count for this session
 	messages, _ := g.sessions.GetMessages(session.Key, 1000)
-	msgCount := len(messages)
+	msgCount := conversationMessageCount(messages)
 
 	currentModel := session.Context["model"]
 	if currentModel == "" {
@@ -119,6 +140,7 @@ func (g *Gateway) handleHelpCommand(msg *protocol.IncomingMessage) {
 /model - View/switch model
 /context - Show context window usage
 /stop - Stop current operation
+/approve, /deny - Answer a tool approval request
 
 _Conduit Go Gateway_`
 
@@ -128,6 +150,12 @@ _Conduit Go Gateway_`
 // getModelAliases returns the configured model aliases, falling back to defaults
 // if the config has none.
 func (g *Gateway) getModelAliases() map[string]string {
+	g.configMu.RLock()
+	defer g.configMu.RUnlock()
+	return g.getModelAliasesLocked()
+}
+
+func (g *Gateway) getModelAliasesLocked() map[string]string {
 	if len(g.config.AI.ModelAliases) > 0 {
 		return g.config.AI.ModelAliases
 	}
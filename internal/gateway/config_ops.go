@@ -0,0 +1,449 @@
+package gateway
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"conduit/internal/channels"
+	"conduit/internal/config"
+	"conduit/internal/middleware"
+	"conduit/internal/tools/types"
+)
+
+// Sources of runtime configuration changes, recorded in the audit trail
+const (
+	configSourceTool      = "tool"
+	configSourceAPI       = "api"
+	configSourceSignal    = "sighup"
+	configSourceFileWatch = "file_watch"
+)
+
+// defaultConfigWatchInterval is how often the config file is checked for changes
+const defaultConfigWatchInterval = 5 * time.Second
+
+// rateLimitConfig converts the rate limiting config for the middleware
+func rateLimitConfig(rl config.RateLimitingConfig) middleware.RateLimitConfig {
+	cfg := middleware.RateLimitConfig{
+		Enabled:                rl.Enabled,
+		CleanupIntervalSeconds: rl.CleanupIntervalSeconds,
+	}
+	cfg.Anonymous.WindowSeconds = rl.Anonymous.WindowSeconds
+	cfg.Anonymous.MaxRequests = rl.Anonymous.MaxRequests
+	cfg.Authenticated.WindowSeconds = rl.Authenticated.WindowSeconds
+	cfg.Authenticated.MaxRequests = rl.Authenticated.MaxRequests
+	return cfg
+}
+
+// applyConfigUpdate validates an update against the running configuration,
+// applies it without a restart and, if persist is set, writes it back to the
+// config file. Every attempt is recorded in the audit trail.
+func (g *Gateway) applyConfigUpdate(u *config.Update, source, actor string, persist bool) error {
+	g.configMu.Lock()
+	changes, errs, err := g.applyConfigUpdateLocked(u, persist)
+	g.configMu.Unlock()
+
+	// Adapters connect to their services when started, so they are started
+	// and stopped without holding the config lock
+	for _, change := range changes {
+		if err := g.setChannelRunning(change); err != nil {
+			errs = append(errs, fmt.Sprintf("channel %s: %v", change.name, err))
+		}
+	}
+	// The config change stands even if an adapter or job fails to start,
+	// as it would at startup; the failures are reported to the caller
+	if err == nil && len(errs) > 0 {
+		err = fmt.Errorf("configuration updated, but %s", strings.Join(errs, "; "))
+	}
+
+	g.configAudit.record(source, actor, u, persist && err == nil, err)
+	if err != nil {
+		log.Printf("[Config] Update from %s failed: %v", source, err)
+		return err
+	}
+
+	log.Printf("[Config] Applied update from %s: %s (persisted: %v)", source, strings.Join(u.Sections(), ", "), persist)
+	return nil
+}
+
+// channelChange is a channel to start or stop after a config update
+type channelChange struct {
+	name    string
+	enabled bool
+	config  channels.ChannelConfig
+}
+
+// applyConfigUpdateLocked updates the running configuration. It returns the
+// channels to start or stop once the lock is released and the parts of the
+// update that failed to take effect; err means nothing was applied.
+func (g *Gateway) applyConfigUpdateLocked(u *config.Update, persist bool) (changes []channelChange, errs []string, err error) {
+	next, err := u.Apply(g.config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Write the file first so a failed save leaves the running config unchanged
+	if persist {
+		path := g.config.Path()
+		if path == "" {
+			return nil, nil, fmt.Errorf("cannot persist: gateway was not started from a config file")
+		}
+		if err := config.SaveUpdate(path, u); err != nil {
+			return nil, nil, fmt.Errorf("failed to persist configuration: %w", err)
+		}
+	}
+
+	if u.ModelAliases != nil {
+		g.config.AI.ModelAliases = next.AI.ModelAliases
+		if g.agentSystem != nil {
+			g.agentSystem.SetModelAliases(next.AI.ModelAliases)
+		}
+	}
+
+	if u.RateLimiting != nil {
+		g.config.RateLimiting = next.RateLimiting
+		if g.rateLimitMiddleware != nil {
+			g.rateLimitMiddleware.Update(rateLimitConfig(next.RateLimiting))
+		}
+	}
+
+	if u.EnabledTools != nil {
+		g.config.Tools.EnabledTools = next.Tools.EnabledTools
+		if g.tools != nil {
+			g.tools.SetEnabledTools(next.Tools.EnabledTools)
+			if g.agentSystem != nil {
+				g.agentSystem.SetTools(agentTools(g.tools, g.skillsManager))
+			}
+		}
+	}
+
+	if u.Channels != nil {
+		g.config.Channels = next.Channels
+
+		names := make([]string, 0, len(u.Channels))
+		for name := range u.Channels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			change := channelChange{name: name, enabled: u.Channels[name]}
+			if change.enabled {
+				found := false
+				for _, ch := range g.config.Channels {
+					if ch.Name == name {
+						change.config = toChannelConfig(ch, g.mediaDir())
+						found = true
+					}
+				}
+				if !found {
+					errs = append(errs, fmt.Sprintf("channel %s: unknown channel", name))
+					continue
+				}
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	if u.AgentHeartbeat != nil {
+		g.config.AgentHeartbeat = next.AgentHeartbeat
+		if err := g.rescheduleAgentHeartbeat(); err != nil {
+			errs = append(errs, fmt.Sprintf("agent heartbeat: %v", err))
+		}
+	}
+
+	return changes, errs, nil
+}
+
+// setChannelRunning starts or stops a configured channel's adapter
+func (g *Gateway) setChannelRunning(change channelChange) error {
+	// Adapters are only started once the gateway is running
+	if g.channelManager == nil || g.ctx == nil {
+		return nil
+	}
+
+	_, running := g.channelManager.GetAdapter(change.name)
+	if !change.enabled {
+		// Also forgets adapters that failed to start, so they are not restarted
+		if err := g.channelManager.RemoveAdapter(change.name); err != nil && running {
+			return err
+		}
+		return nil
+	}
+	if running {
+		return nil
+	}
+	return g.channelManager.CreateAdapter(change.config)
+}
+
+// rescheduleAgentHeartbeat replaces the agent heartbeat job to match the
+// config. Heartbeat jobs users created are left alone.
+func (g *Gateway) rescheduleAgentHeartbeat() error {
+	if g.heartbeatIntegration == nil || g.scheduler == nil {
+		return nil
+	}
+	if _, err := g.scheduler.GetJob(agentHeartbeatJobID); err == nil {
+		if err := g.scheduler.RemoveJob(agentHeartbeatJobID); err != nil {
+			return err
+		}
+	}
+	if err := g.initializeAgentHeartbeat(g.config); err != nil {
+		return err
+	}
+	g.updateHeartbeatJobMetrics()
+	return nil
+}
+
+// currentConfiguration returns the runtime-visible configuration
+func (g *Gateway) currentConfiguration() map[string]interface{} {
+	g.configMu.RLock()
+	defer g.configMu.RUnlock()
+
+	channelList := make([]map[string]interface{}, 0, len(g.config.Channels))
+	for _, ch := range g.config.Channels {
+		running := false
+		if g.channelManager != nil {
+			_, running = g.channelManager.GetAdapter(ch.Name)
+		}
+		channelList = append(channelList, map[string]interface{}{
+			"name":    ch.Name,
+			"type":    ch.Type,
+			"enabled": ch.Enabled,
+			"running": running,
+		})
+	}
+
+	return map[string]interface{}{
+		"ai":            g.config.AI,
+		"workspace":     g.config.Workspace,
+		"model_aliases": g.getModelAliasesLocked(),
+		"rateLimiting":  g.config.RateLimiting,
+		"enabled_tools": g.config.Tools.EnabledTools,
+		"channels":      channelList,
+		"agent_heartbeat": map[string]interface{}{
+			"enabled":          g.config.AgentHeartbeat.Enabled,
+			"interval_minutes": g.config.AgentHeartbeat.IntervalMinutes,
+		},
+		"config_path": g.config.Path(),
+	}
+}
+
+// ReloadConfig re-reads the config file and applies any runtime-updatable
+// changes. Other settings still need a restart.
+func (g *Gateway) ReloadConfig() error {
+	return g.reloadConfig(configSourceSignal)
+}
+
+func (g *Gateway) reloadConfig(source string) error {
+	g.configMu.RLock()
+	current := *g.config
+	g.configMu.RUnlock()
+
+	path := current.Path()
+	if path == "" {
+		return fmt.Errorf("gateway was not started from a config file")
+	}
+	// config.Load writes a default file when none exists, which must not
+	// happen on reload
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to reload config: %w", err)
+	}
+
+	next, err := config.Load(path)
+	if err != nil {
+		log.Printf("[Config] Reload from %s failed: %v", source, err)
+		return fmt.Errorf("failed to reload config: %w", err)
+	}
+
+	u := config.Diff(&current, next)
+	if u == nil {
+		log.Printf("[Config] Reloaded %s: no runtime-updatable changes", path)
+		return nil
+	}
+	return g.applyConfigUpdate(u, source, "", false)
+}
+
+// watchConfigFile polls the config file and reloads it when it changes
+func (g *Gateway) watchConfigFile(ctx context.Context) {
+	path := g.config.Path()
+	if path == "" {
+		return
+	}
+
+	interval := defaultConfigWatchInterval
+	if g.config.Reload.IntervalSeconds > 0 {
+		interval = time.Duration(g.config.Reload.IntervalSeconds) * time.Second
+	}
+
+	var lastMod time.Time
+	var lastSize int64
+	if info, err := os.Stat(path); err == nil {
+		lastMod, lastSize = info.ModTime(), info.Size()
+	}
+
+	log.Printf("[Config] Watching %s for changes (every %s)", path, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if info.ModTime().Equal(lastMod) && info.Size() == lastSize {
+				continue
+			}
+			lastMod, lastSize = info.ModTime(), info.Size()
+
+			if err := g.reloadConfig(configSourceFileWatch); err != nil {
+				log.Printf("[Config] Reload after file change failed: %v", err)
+			}
+		}
+	}
+}
+
+// configActor identifies who made a change from a tool call's request context
+func configActor(ctx context.Context) string {
+	channelID, userID := types.RequestChannelID(ctx), types.RequestUserID(ctx)
+	if channelID == "" && userID == "" {
+		return ""
+	}
+	return channelID + ":" + userID
+}
+
+// handleConfig serves GET (current configuration) and POST/PATCH (partial
+// update) on /api/config. Pass ?persist=true to write the update to the file.
+func (g *Gateway) handleConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, g.currentConfiguration())
+
+	case http.MethodPost, http.MethodPatch:
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		u, err := config.DecodeUpdate(body)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		persist, _ := strconv.ParseBool(r.URL.Query().Get("persist"))
+		var actor string
+		if authInfo := middleware.GetAuthInfo(r.Context()); authInfo != nil {
+			actor = authInfo.ClientName
+		}
+
+		if err := g.applyConfigUpdate(u, configSourceAPI, actor, persist); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, g.currentConfiguration())
+
+	default:
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleConfigAudit lists recent runtime configuration changes, newest first
+func (g *Gateway) handleConfigAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	limit := 50
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+
+	entries, err := g.configAudit.recent(limit)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"entries": entries})
+}
+
+// configAuditLog records runtime configuration changes in the gateway database
+type configAuditLog struct {
+	db *sql.DB
+}
+
+// configAuditEntry is one recorded configuration change
+type configAuditEntry struct {
+	ID        int64           `json:"id"`
+	Timestamp time.Time       `json:"timestamp"`
+	Source    string          `json:"source"`
+	Actor     string          `json:"actor,omitempty"`
+	Changes   json.RawMessage `json:"changes"`
+	Persisted bool            `json:"persisted"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// record stores an attempted change. Failures to write the audit trail are
+// logged rather than failing the change.
+func (a *configAuditLog) record(source, actor string, u *config.Update, persisted bool, applyErr error) {
+	if a == nil || a.db == nil {
+		return
+	}
+
+	changes, err := json.Marshal(u)
+	if err != nil {
+		log.Printf("[Config] Failed to encode audit record: %v", err)
+		return
+	}
+	var errText string
+	if applyErr != nil {
+		errText = applyErr.Error()
+	}
+
+	if _, err := a.db.Exec(
+		`INSERT INTO config_audit (timestamp, source, actor, changes, persisted, error) VALUES (?, ?, ?, ?, ?, ?)`,
+		time.Now().UTC(), source, actor, string(changes), persisted, errText,
+	); err != nil {
+		log.Printf("[Config] Failed to write audit record: %v", err)
+	}
+}
+
+// recent returns up to limit entries, newest first
+func (a *configAuditLog) recent(limit int) ([]configAuditEntry, error) {
+	entries := []configAuditEntry{}
+	if a == nil || a.db == nil {
+		return entries, nil
+	}
+
+	rows, err := a.db.Query(
+		`SELECT id, timestamp, source, actor, changes, persisted, error FROM config_audit ORDER BY id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config audit: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e configAuditEntry
+		var changes string
+		if err := rows.Scan(&e.ID, &e.Timestamp, &e.Source, &e.Actor, &changes, &e.Persisted, &e.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan config audit: %w", err)
+		}
+		e.Changes = json.RawMessage(changes)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
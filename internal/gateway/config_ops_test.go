@@ -0,0 +1,213 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"conduit/internal/config"
+	"conduit/internal/middleware"
+	"conduit/internal/sessions"
+	"conduit/internal/tools"
+)
+
+// newConfigTestGateway returns a gateway whose config was loaded from a file
+// in a temporary directory
+func newConfigTestGateway(t *testing.T) *Gateway {
+	t.Helper()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config.json")
+	if err := config.Default().Save(path); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	sessionStore, err := sessions.NewStore(filepath.Join(dir, "gateway.db"))
+	if err != nil {
+		t.Fatalf("Failed to create session store: %v", err)
+	}
+	t.Cleanup(func() { sessionStore.Close() })
+
+	rateLimiter := middleware.NewRateLimitMiddleware(middleware.RateLimitMiddlewareConfig{
+		Config: rateLimitConfig(cfg.RateLimiting),
+	})
+	t.Cleanup(rateLimiter.Stop)
+
+	return &Gateway{
+		config:              cfg,
+		sessions:            sessionStore,
+		tools:               tools.NewRegistry(cfg.Tools),
+		rateLimitMiddleware: rateLimiter,
+		configAudit:         &configAuditLog{db: sessionStore.DB()},
+	}
+}
+
+func TestUpdateConfiguration_AppliesLive(t *testing.T) {
+	g := newConfigTestGateway(t)
+
+	err := g.UpdateConfiguration(context.Background(), map[string]interface{}{
+		"model_aliases": map[string]interface{}{"fast": "test-fast-model"},
+		"rateLimiting":  map[string]interface{}{"anonymous": map[string]interface{}{"maxRequests": 7}},
+		"enabled_tools": []interface{}{"read"},
+	}, false)
+	if err != nil {
+		t.Fatalf("UpdateConfiguration failed: %v", err)
+	}
+
+	if g.getModelAliases()["fast"] != "test-fast-model" {
+		t.Errorf("Expected alias to be live, got %v", g.getModelAliases())
+	}
+	if got := g.rateLimitMiddleware.Config().Anonymous.MaxRequests; got != 7 {
+		t.Errorf("Expected rate limiter to use 7 requests, got %d", got)
+	}
+	help := g.tools.GetToolHelp("write")
+	if _, disabled := help["error"]; !disabled {
+		t.Error("Expected write to be disabled")
+	}
+
+	cfg, err := g.GetConfiguration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tools, _ := cfg["enabled_tools"].([]string); len(tools) != 1 || tools[0] != "read" {
+		t.Errorf("Expected GetConfiguration to show the update, got %v", cfg["enabled_tools"])
+	}
+
+	// Not persisted unless asked
+	data, _ := os.ReadFile(g.config.Path())
+	if strings.Contains(string(data), "test-fast-model") {
+		t.Error("Expected the config file to be unchanged")
+	}
+}
+
+func TestUpdateConfiguration_RejectsInvalid(t *testing.T) {
+	g := newConfigTestGateway(t)
+
+	tests := []map[string]interface{}{
+		{"port": 1},
+		{"agent_heartbeat": map[string]interface{}{"enabled": true, "interval_minutes": 0}},
+		{"channels": map[string]interface{}{"nope": true}},
+	}
+	for _, update := range tests {
+		if err := g.UpdateConfiguration(context.Background(), update, false); err == nil {
+			t.Errorf("Expected %v to be rejected", update)
+		}
+	}
+	if g.config.AgentHeartbeat.IntervalMinutes == 0 {
+		t.Error("Expected a rejected update to leave the config unchanged")
+	}
+}
+
+func TestUpdateConfiguration_PersistAndAudit(t *testing.T) {
+	g := newConfigTestGateway(t)
+
+	if err := g.DisableChannel(context.Background(), "telegram"); err != nil {
+		t.Fatalf("DisableChannel failed: %v", err)
+	}
+	err := g.UpdateConfiguration(context.Background(), map[string]interface{}{
+		"model_aliases": map[string]interface{}{"fast": "test-fast-model"},
+	}, true)
+	if err != nil {
+		t.Fatalf("UpdateConfiguration failed: %v", err)
+	}
+	g.UpdateConfiguration(context.Background(), map[string]interface{}{"port": 1}, false)
+
+	saved, err := config.Load(g.config.Path())
+	if err != nil {
+		t.Fatalf("Failed to reload saved config: %v", err)
+	}
+	if saved.AI.ModelAliases["fast"] != "test-fast-model" {
+		t.Error("Expected the persisted alias in the config file")
+	}
+
+	entries, err := g.configAudit.recent(10)
+	if err != nil {
+		t.Fatalf("Failed to read audit trail: %v", err)
+	}
+	// The invalid update fails before it is decoded, so only two are recorded
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 audit entries, got %d", len(entries))
+	}
+	if !entries[0].Persisted || entries[0].Source != configSourceTool || !strings.Contains(string(entries[0].Changes), "test-fast-model") {
+		t.Errorf("Unexpected newest entry: %+v", entries[0])
+	}
+	if entries[1].Persisted || !strings.Contains(string(entries[1].Changes), `"telegram":false`) {
+		t.Errorf("Unexpected oldest entry: %+v", entries[1])
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	g := newConfigTestGateway(t)
+
+	cfg, err := config.Load(g.config.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Tools.EnabledTools = []string{"read", "web_search"}
+	cfg.Port = 1234 // Needs a restart, so it is ignored
+	if err := cfg.Save(g.config.Path()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if len(g.config.Tools.EnabledTools) != 2 || g.config.Port != 18789 {
+		t.Errorf("Expected only enabled tools to reload, got tools %v port %d", g.config.Tools.EnabledTools, g.config.Port)
+	}
+
+	entries, _ := g.configAudit.recent(1)
+	if len(entries) != 1 || entries[0].Source != configSourceSignal {
+		t.Errorf("Expected a sighup audit entry, got %+v", entries)
+	}
+}
+
+func TestHandleConfig(t *testing.T) {
+	g := newConfigTestGateway(t)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/config", strings.NewReader(`{"rateLimiting": {"enabled": false}}`))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.AuthContextKey, &middleware.AuthInfo{ClientName: "ops"}))
+	w := httptest.NewRecorder()
+	g.handleConfig(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if rl, _ := resp["rateLimiting"].(map[string]interface{}); rl["enabled"] != false {
+		t.Errorf("Expected rate limiting disabled in response, got %v", resp["rateLimiting"])
+	}
+	if g.rateLimitMiddleware.Config().Enabled {
+		t.Error("Expected the rate limiter to be disabled")
+	}
+
+	w = httptest.NewRecorder()
+	g.handleConfig(w, httptest.NewRequest(http.MethodPost, "/api/config", strings.NewReader(`{"workspace": {}}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a non-updatable setting, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	g.handleConfigAudit(w, httptest.NewRequest(http.MethodGet, "/api/config/audit", nil))
+	var audit struct {
+		Entries []configAuditEntry `json:"entries"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&audit); err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.Entries) != 1 || audit.Entries[0].Actor != "ops" || audit.Entries[0].Source != configSourceAPI {
+		t.Errorf("Unexpected audit entries: %+v", audit.Entries)
+	}
+}
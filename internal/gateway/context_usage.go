@@ -54,6 +54,8 @@ func formatContextUsage(session *sessions.Session) string {
 		model,
 	)
 
+	result += formatHistoryUsage(session)
+
 	// Add a warning if getting close to the limit
 	if projectedPct >= 80 {
 		result += "\n\nWarning: Context window is nearly full. Consider using /reset to start fresh."
@@ -64,6 +66,32 @@ func formatContextUsage(session *sessions.Session) string {
 	return result
 }
 
+// formatHistoryUsage describes how much history was sent live and how much
+// has been compacted into the session summary, or "" before the first request
+func formatHistoryUsage(session *sessions.Session) string {
+	liveStr := session.Context[ai.ContextKeyHistoryLiveMessages]
+	if liveStr == "" {
+		return ""
+	}
+
+	live, _ := strconv.Atoi(liveStr)
+	liveTokens, _ := strconv.Atoi(session.Context[ai.ContextKeyHistoryLiveTokens])
+	summarized, _ := strconv.Atoi(session.Context[ai.ContextKeyHistorySummarizedMessages])
+	summaryTokens, _ := strconv.Atoi(session.Context[ai.ContextKeyHistorySummaryTokens])
+	budget, _ := strconv.Atoi(session.Context[ai.ContextKeyHistoryBudgetTokens])
+
+	result := fmt.Sprintf("\n\nHistory\n"+
+		"Live:              %s messages (~%s tokens)\n"+
+		"Summarized:        %s messages (~%s token summary)",
+		formatNumber(live), formatNumber(liveTokens),
+		formatNumber(summarized), formatNumber(summaryTokens),
+	)
+	if budget > 0 {
+		result += fmt.Sprintf("\nHistory budget:    %s tokens", formatNumber(budget))
+	}
+	return result
+}
+
 // formatStatusResponse builds the full /status response including session info,
 // cost data, context window usage, and global usage stats.
 func formatStatusResponse(session *sessions.Session, messageCount int, usageTracker *ai.UsageTracker) string {
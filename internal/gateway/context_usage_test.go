@@ -124,3 +124,38 @@ func TestFormatNumber(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatContextUsage_History(t *testing.T) {
+	session := &sessions.Session{
+		Key: "test-session-history",
+		Context: map[string]string{
+			"model":                                "claude-sonnet-4-20250514",
+			"last_prompt_tokens":                   "30000",
+			"last_completion_tokens":               "500",
+			"last_total_tokens":                    "30500",
+			ai.ContextKeyHistoryLiveMessages:       "24",
+			ai.ContextKeyHistoryLiveTokens:         "18200",
+			ai.ContextKeyHistorySummarizedMessages: "130",
+			ai.ContextKeyHistorySummaryTokens:      "900",
+			ai.ContextKeyHistoryBudgetTokens:       "100000",
+		},
+	}
+
+	result := formatContextUsage(session)
+
+	if !strings.Contains(result, "24 messages (~18,200 tokens)") {
+		t.Errorf("Expected live history, got:\n%s", result)
+	}
+	if !strings.Contains(result, "130 messages (~900 token summary)") {
+		t.Errorf("Expected summarized history, got:\n%s", result)
+	}
+	if !strings.Contains(result, "100,000 tokens") {
+		t.Errorf("Expected history budget, got:\n%s", result)
+	}
+
+	// Sessions from before compaction stats were recorded omit the section
+	delete(session.Context, ai.ContextKeyHistoryLiveMessages)
+	if strings.Contains(formatContextUsage(session), "History") {
+		t.Error("Expected no history section without stats")
+	}
+}
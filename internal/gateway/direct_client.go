@@ -27,6 +27,7 @@ type DirectClientConfig struct {
 	Sessions     *sessions.Store
 	AI           *ai.Router
 	Tools        *tools.Registry
+	Approvals    *tools.ApprovalMiddleware
 	Metrics      monitoring.MetricsCollectorInterface
 	ModelAliases map[string]string
 	AgentName    string
@@ -193,6 +194,10 @@ func (c *DirectClient) streamChatWithID(session *sessions.Session, text, request
 	// Create cancellable context
 	reqCtx, cancel := context.WithCancel(c.ctx)
 	reqCtx = types.WithRequestContext(reqCtx, session.ChannelID, c.userID, session.Key)
+	reqCtx = tools.WithApprovalPrompter(reqCtx, func(ctx context.Context, req tools.ApprovalRequest) error {
+		c.send(tui.ApprovalRequestMsg{ApprovalRequest: *approvalRequestMessage(req, requestID)})
+		return nil
+	})
 
 	// Track active request for /stop
 	c.activeRequestsMu.Lock()
@@ -316,7 +321,8 @@ func (c *DirectClient) streamChatWithID(session *sessions.Session, text, request
 		SessionCost:      sessionCost,
 	})
 
-	// Save assistant message
+	// Save tool turns and assistant message
+	persistToolTurns(c.sessions, session.Key, convResponse)
 	if responseContent != "" {
 		if _, err := c.sessions.AddMessage(session.Key, "assistant", responseContent, nil); err != nil {
 			log.Printf("[DirectClient] Error saving AI message: %v", err)
@@ -389,6 +395,7 @@ func (c *DirectClient) handleCommand(sessionKey, text string) {
 			"/model [alias] - View/switch model\n" +
 			"/context - Show context window usage\n" +
 			"/stop - Stop current operation\n" +
+			"/approve, /deny - Answer a tool approval request\n" +
 			"/quit - Exit TUI\n\n" +
 			"Alt+Enter: Insert new line"
 		sendResponse(help)
@@ -471,6 +478,13 @@ func (c *DirectClient) handleCommand(sessionKey, text string) {
 			sendResponse("No active operation to stop.")
 		}
 
+	case isApprovalCommand(text):
+		if sessionKey == "" {
+			sendResponse("No active session.")
+			return
+		}
+		sendResponse(answerApproval(c.config.Approvals, sessionKey, text))
+
 	default:
 		command := strings.Fields(text)[0]
 		sendResponse(fmt.Sprintf("Unknown command: %s\nType /help for available commands.", command))
@@ -533,6 +547,9 @@ func (c *DirectClient) SwitchSession(key string) error {
 	messages, _ := c.sessions.GetMessages(session.Key, 100)
 	var history []protocol.MessageInfo
 	for _, m := range messages {
+		if m.IsToolTurn() {
+			continue
+		}
 		history = append(history, protocol.MessageInfo{
 			Role:      m.Role,
 			Content:   m.Content,
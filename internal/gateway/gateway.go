@@ -19,9 +19,16 @@ import (
 	"conduit/internal/ai"
 	"conduit/internal/auth"
 	"conduit/internal/channels"
+	"conduit/internal/channels/discord"
+	"conduit/internal/channels/email"
+	"conduit/internal/channels/matrix"
+	"conduit/internal/channels/process"
+	"conduit/internal/channels/slack"
 	"conduit/internal/channels/telegram"
 	tuiAdapter "conduit/internal/channels/tui"
+	"conduit/internal/channels/webhook"
 	"conduit/internal/config"
+	"conduit/internal/datadir"
 	"conduit/internal/fts"
 	"conduit/internal/heartbeat"
 	"conduit/internal/middleware"
@@ -47,6 +54,8 @@ import (
 // Gateway represents the core Conduit gateway
 type Gateway struct {
 	config           *config.Config
+	configMu         sync.RWMutex    // Guards the runtime-updatable parts of config
+	configAudit      *configAuditLog // Records runtime configuration changes
 	sessions         *sessions.Store
 	ai               *ai.Router
 	agentSystem      *agent.ConduitAgentWithIntegration
@@ -54,6 +63,7 @@ type Gateway struct {
 	workspaceContext *workspace.WorkspaceContext
 	skillsManager    *skills.Manager
 	channelManager   *channels.Manager
+	channelWatch     *channels.Supervisor // Restarts unhealthy adapters; nil if disabled
 	scheduler        scheduler.SchedulerInterface
 
 	// Authentication
@@ -80,6 +90,10 @@ type Gateway struct {
 	// Active request tracking for /stop
 	activeRequests   map[string]context.CancelFunc // sessionKey -> cancel function
 	activeRequestsMu sync.RWMutex
+	turns            *sessionQueue // Serializes channel message turns per session
+
+	// Human approval for tool calls (nil when no rules are configured)
+	approvals *tools.ApprovalMiddleware
 
 	// FTS5 full-text search
 	ftsIndexer  *fts.Indexer
@@ -100,9 +114,10 @@ type Gateway struct {
 // Client represents a WebSocket client connection
 type Client struct {
 	ID         string
-	Role       string // "client" or "node"
-	UserID     string // user identity for session scoping
-	SessionKey string // active session key for this client
+	Role       string               // "client" or "node"
+	UserID     string               // user identity for session scoping
+	SessionKey string               // active session key for this client
+	Auth       *middleware.AuthInfo // token scopes and restrictions (nil: unrestricted)
 	Conn       *websocket.Conn
 	Send       chan []byte
 }
@@ -212,6 +227,17 @@ func New(cfg *config.Config) (*Gateway, error) {
 		maxToolChains = 25 // Default fallback
 	}
 	executionEngine := tools.NewExecutionEngine(toolsRegistry, 4, 60*time.Second, maxToolChains)
+
+	// Pause matching tool calls for a human decision
+	var approvals *tools.ApprovalMiddleware
+	if ac := cfg.Tools.Approval; ac != nil && len(ac.Rules) > 0 {
+		approvals, err = tools.NewApprovalMiddleware(*ac)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create approval middleware: %w", err)
+		}
+		executionEngine.AddMiddleware(approvals)
+		log.Printf("Tool approval enabled with %d rules", len(ac.Rules))
+	}
 	executionAdapter := tools.NewExecutionEngineAdapter(executionEngine)
 
 	// Initialize AI router with agent system AND execution engine
@@ -231,6 +257,14 @@ func New(cfg *config.Config) (*Gateway, error) {
 	// Create auth middleware (skip health monitoring endpoints)
 	authMiddleware := middleware.NewAuthMiddleware(authStorage, middleware.AuthMiddlewareConfig{
 		SkipPaths: []string{"/health", "/metrics", "/diagnostics", "/prometheus"},
+		PathScopes: map[string]string{
+			"/api/channels/status": auth.ScopeChannelsRead,
+			"/api/vector/search":   auth.ScopeVectorRead,
+			"/api/vector/status":   auth.ScopeVectorRead,
+			"/api/vector/index":    auth.ScopeVectorWrite,
+			"/api/vector/delete":   auth.ScopeVectorWrite,
+		},
+		DefaultScope: auth.ScopeAdmin,
 		OnAuthError: func(r *http.Request, err middleware.AuthError) {
 			log.Printf("[Auth] Authentication failed: %s %s (code: %d)",
 				r.Method, r.URL.Path, err.Code)
@@ -242,24 +276,7 @@ func New(cfg *config.Config) (*Gateway, error) {
 
 	// Create rate limiting middleware
 	rateLimitMiddleware := middleware.NewRateLimitMiddleware(middleware.RateLimitMiddlewareConfig{
-		Config: middleware.RateLimitConfig{
-			Enabled: cfg.RateLimiting.Enabled,
-			Anonymous: struct {
-				WindowSeconds int `json:"windowSeconds"`
-				MaxRequests   int `json:"maxRequests"`
-			}{
-				WindowSeconds: cfg.RateLimiting.Anonymous.WindowSeconds,
-				MaxRequests:   cfg.RateLimiting.Anonymous.MaxRequests,
-			},
-			Authenticated: struct {
-				WindowSeconds int `json:"windowSeconds"`
-				MaxRequests   int `json:"maxRequests"`
-			}{
-				WindowSeconds: cfg.RateLimiting.Authenticated.WindowSeconds,
-				MaxRequests:   cfg.RateLimiting.Authenticated.MaxRequests,
-			},
-			CleanupIntervalSeconds: cfg.RateLimiting.CleanupIntervalSeconds,
-		},
+		Config: rateLimitConfig(cfg.RateLimiting),
 		OnRateLimitExceeded: func(r *http.Request, identifier string, isAnonymous bool) {
 			log.Printf("[Gateway] Rate limit exceeded: %s %s (identifier: %s, type: %s)",
 				r.Method, r.URL.Path, identifier, map[bool]string{true: "anonymous_ip", false: "authenticated_client"}[isAnonymous])
@@ -311,6 +328,8 @@ func New(cfg *config.Config) (*Gateway, error) {
 		eventStore:          eventStore,
 		clients:             make(map[string]*Client),
 		activeRequests:      make(map[string]context.CancelFunc),
+		approvals:           approvals,
+		configAudit:         &configAuditLog{db: sessionStore.DB()},
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				// TODO: Implement proper origin checking
@@ -320,9 +339,33 @@ func New(cfg *config.Config) (*Gateway, error) {
 		},
 	}
 
+	gw.turns = newSessionQueue(cfg.Sessions.QueuePolicy, gw.handleIncomingMessage, gw.reportQueueDepth)
+
 	// Initialize channel manager and register factories
 	gw.channelManager = channels.NewManager()
+	gw.channelManager.SetOutbox(channels.NewOutbox(sessionStore.DB(), channels.OutboxOptions{
+		MaxAttempts: cfg.Outbox.MaxAttempts,
+		Backoff:     time.Duration(cfg.Outbox.RetryBackoffSeconds) * time.Second,
+		MaxBackoff:  time.Duration(cfg.Outbox.MaxBackoffSeconds) * time.Second,
+		Retention:   time.Duration(cfg.Outbox.RetentionHours) * time.Hour,
+	}))
+	if !cfg.ChannelSupervisor.Disabled {
+		cs := cfg.ChannelSupervisor
+		gw.channelWatch = channels.NewSupervisor(gw.channelManager, channels.SupervisorOptions{
+			Interval:    time.Duration(cs.IntervalSeconds) * time.Second,
+			Backoff:     time.Duration(cs.BackoffSeconds) * time.Second,
+			MaxBackoff:  time.Duration(cs.MaxBackoffSeconds) * time.Second,
+			MaxRestarts: cs.MaxRestarts,
+			Grace:       time.Duration(cs.GraceSeconds) * time.Second,
+		}, gw.publishChannelEvent)
+	}
 	gw.channelManager.RegisterFactory(telegram.NewFactoryWithDB(sessionStore.DB()))
+	gw.channelManager.RegisterFactory(discord.NewFactory())
+	gw.channelManager.RegisterFactory(slack.NewFactoryWithDB(sessionStore.DB()))
+	gw.channelManager.RegisterFactory(webhook.NewFactory())
+	gw.channelManager.RegisterFactory(process.NewFactory())
+	gw.channelManager.RegisterFactory(email.NewFactory())
+	gw.channelManager.RegisterFactory(matrix.NewFactory())
 	gw.channelManager.RegisterFactory(tuiAdapter.NewFactory(nil)) // TUI factory for dynamic adapter creation
 
 	// Now inject dependencies into tools registry to break the cycle
@@ -456,32 +499,13 @@ func New(cfg *config.Config) (*Gateway, error) {
 		Gateway:       gw, // Gateway implements GatewayService interface
 		Searcher:      ftsSearcher,
 		VectorSearch:  vectorSearch,
+		Vision:        aiRouter, // Router sends Image tool requests to a vision-capable model
 		SchemaBuilder: schemaBuilder,
 	}
 	toolsRegistry.SetServices(toolServices)
 
 	// NOW convert tools to AI format (after SetServices registered them)
-	aiTools := convertToolsToAIFormat(toolsRegistry)
-
-	// Add skills-generated tools if available
-	if skillsManager != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		if skillTools, err := skillsManager.GenerateTools(ctx); err == nil {
-			log.Printf("Adding %d skills-generated tools", len(skillTools))
-			for _, skillTool := range skillTools {
-				aiTool := ai.Tool{
-					Name:        skillTool.Name(),
-					Description: skillTool.Description(),
-					Parameters:  skillTool.Parameters(),
-				}
-				aiTools = append(aiTools, aiTool)
-			}
-		} else {
-			log.Printf("WARNING: Failed to generate skills tools: %v", err)
-		}
-	}
+	aiTools := agentTools(toolsRegistry, skillsManager)
 
 	// Update agent with the now-registered tools
 	agentSystem.SetTools(aiTools)
@@ -491,7 +515,13 @@ func New(cfg *config.Config) (*Gateway, error) {
 	if workspaceDir == "" {
 		workspaceDir = "./workspace"
 	}
-	gw.scheduler = scheduler.New(workspaceDir, gw.executeScheduledJob)
+	sched := scheduler.New(workspaceDir, gw.executeScheduledJob)
+	sched.SetLocation(cfg.GetLocation())
+	sched.SetHistory(scheduler.NewHistory(sessionStore.DB(), scheduler.HistoryOptions{
+		MaxRunsPerJob: cfg.Scheduler.HistoryMaxRuns,
+		Retention:     time.Duration(cfg.Scheduler.HistoryRetentionDays) * 24 * time.Hour,
+	}))
+	gw.scheduler = sched
 
 	// Initialize heartbeat integration
 	gw.heartbeatIntegration = heartbeat.NewGatewayIntegration(workspaceDir, sessionStore, aiRouter, gw.scheduler, gw, metricsCollector)
@@ -554,10 +584,16 @@ func (g *Gateway) executeScheduledJob(ctx context.Context, job *scheduler.Job) e
 	}
 
 	// Execute the job command as an AI prompt
+	report := scheduler.ReportFromContext(ctx)
+	report.SetModel(model)
 	response, err := g.ai.GenerateResponseWithTools(ctx, session, job.Command, "", model)
 	if err != nil {
 		return fmt.Errorf("AI execution failed: %w", err)
 	}
+	if usage := response.GetUsage(); usage != nil {
+		report.AddUsage(usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+	}
+	report.SetOutput(response.GetContent())
 
 	// If there's a target, send the result there
 	if job.Target != "" {
@@ -617,6 +653,33 @@ func convertToolsToAIFormat(registry *tools.Registry) []ai.Tool {
 	return aiTools
 }
 
+// agentTools returns the enabled registry tools plus any skills-generated tools
+func agentTools(registry *tools.Registry, skillsManager *skills.Manager) []ai.Tool {
+	aiTools := convertToolsToAIFormat(registry)
+
+	// Add skills-generated tools if available
+	if skillsManager != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if skillTools, err := skillsManager.GenerateTools(ctx); err == nil {
+			log.Printf("Adding %d skills-generated tools", len(skillTools))
+			for _, skillTool := range skillTools {
+				aiTool := ai.Tool{
+					Name:        skillTool.Name(),
+					Description: skillTool.Description(),
+					Parameters:  skillTool.Parameters(),
+				}
+				aiTools = append(aiTools, aiTool)
+			}
+		} else {
+			log.Printf("WARNING: Failed to generate skills tools: %v", err)
+		}
+	}
+
+	return aiTools
+}
+
 // createInternalToken generates an authentication token for internal services
 // (e.g., the integrated SSH server) that connect back to the gateway via WebSocket.
 func (g *Gateway) createInternalToken(clientName string) (string, error) {
@@ -653,10 +716,15 @@ func (g *Gateway) Start(ctx context.Context) error {
 	// WebSocket endpoint with custom authentication and rate limiting
 	mux.Handle("/ws", g.rateLimitMiddleware.Wrap(http.HandlerFunc(g.handleWebSocket)))
 
+	// Inbound channel webhooks authenticate requests themselves (e.g. HMAC signatures)
+	mux.Handle("/webhooks/", g.rateLimitMiddleware.Wrap(http.HandlerFunc(g.handleChannelWebhook)))
+
 	// Protected API endpoints - wrapped with auth middleware and rate limiting
 	// Order: auth middleware first (sets context), then rate limiting (uses context), then handler
 	mux.Handle("/api/channels/status", g.authMiddleware.Wrap(g.rateLimitMiddleware.Wrap(http.HandlerFunc(g.handleChannelStatus))))
 	mux.Handle("/api/test/message", g.authMiddleware.Wrap(g.rateLimitMiddleware.Wrap(http.HandlerFunc(g.handleTestMessage))))
+	mux.Handle("/api/config", g.authMiddleware.Wrap(g.rateLimitMiddleware.Wrap(http.HandlerFunc(g.handleConfig))))
+	mux.Handle("/api/config/audit", g.authMiddleware.Wrap(g.rateLimitMiddleware.Wrap(http.HandlerFunc(g.handleConfigAudit))))
 
 	// Vector API endpoints (registered unconditionally; handlers return 503 when disabled)
 	vectorAPI := &VectorAPI{vectorService: g.vectorService}
@@ -675,6 +743,11 @@ func (g *Gateway) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to start channels: %w", err)
 	}
 
+	// Reload the config file when it changes, if enabled
+	if g.config.Reload != nil && g.config.Reload.Watch {
+		go g.watchConfigFile(ctx)
+	}
+
 	// Start scheduler
 	if g.scheduler != nil {
 		if err := g.scheduler.Start(); err != nil {
@@ -745,6 +818,7 @@ func (g *Gateway) Start(ctx context.Context) error {
 					Sessions:     g.sessions,
 					AI:           g.ai,
 					Tools:        g.tools,
+					Approvals:    g.approvals,
 					Metrics:      g.metricsCollector,
 					ModelAliases: g.getModelAliases(),
 					AgentName:    g.config.Agent.Name,
@@ -861,6 +935,7 @@ func (g *Gateway) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		ID:     fmt.Sprintf("client_%d", time.Now().UnixNano()),
 		Role:   authResult.AuthInfo.ClientName, // Store authenticated client name
 		UserID: authResult.AuthInfo.ClientName, // Default user identity from auth
+		Auth:   authResult.AuthInfo,
 		Conn:   conn,
 		Send:   make(chan []byte, 256),
 	}
@@ -908,6 +983,23 @@ func (g *Gateway) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	go g.handleClientRead(g.ctx, client)
 }
 
+// handleChannelWebhook routes /webhooks/<adapter id> to adapters that accept
+// inbound HTTP requests
+func (g *Gateway) handleChannelWebhook(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/webhooks/")
+	adapter, ok := g.channelManager.GetAdapter(id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "unknown channel")
+		return
+	}
+	webhookAdapter, ok := adapter.(channels.WebhookAdapter)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "channel does not accept webhooks")
+		return
+	}
+	webhookAdapter.ServeWebhook(w, r)
+}
+
 // handleChannelStatus provides channel status information
 func (g *Gateway) handleChannelStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
@@ -917,6 +1009,15 @@ func (g *Gateway) handleChannelStatus(w http.ResponseWriter, r *http.Request) {
 
 	status := g.channelManager.GetStatus()
 
+	// Only show channels the token is limited to
+	if authInfo := middleware.GetAuthInfo(r.Context()); authInfo != nil {
+		for id := range status {
+			if !authInfo.AllowsChannel(id) {
+				delete(status, id)
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
@@ -973,6 +1074,10 @@ func (g *Gateway) handleClientRead(ctx context.Context, client *Client) {
 			continue
 		}
 
+		if !g.authorizeClientMessage(client, parsed) {
+			continue
+		}
+
 		switch msg := parsed.(type) {
 		case *protocol.ChatMessage:
 			go g.handleWebSocketChat(ctx, client, msg)
@@ -1020,26 +1125,47 @@ func (g *Gateway) startChannels(ctx context.Context) error {
 	// Convert config channels to channel configs
 	var channelConfigs []channels.ChannelConfig
 
+	mediaDir := g.mediaDir()
+
 	for _, chConfig := range g.config.Channels {
-		channelConfig := channels.ChannelConfig{
-			ID:      chConfig.Name,
-			Type:    chConfig.Type,
-			Name:    chConfig.Name,
-			Enabled: chConfig.Enabled,
-			Config:  chConfig.Config,
-		}
-		channelConfigs = append(channelConfigs, channelConfig)
+		channelConfigs = append(channelConfigs, toChannelConfig(chConfig, mediaDir))
 	}
 
 	// Start channel manager
 	if err := g.channelManager.Start(ctx, channelConfigs); err != nil {
 		return fmt.Errorf("failed to start channel manager: %w", err)
 	}
+	if g.channelWatch != nil {
+		go g.channelWatch.Run(ctx)
+	}
 
 	log.Println("Channel manager started")
 	return nil
 }
 
+// mediaDir returns the root for channel attachments, which are stored under
+// the data directory, one folder per session
+func (g *Gateway) mediaDir() string {
+	dd, err := datadir.New(g.config.DataDir)
+	if err != nil {
+		log.Printf("Warning: cannot resolve media directory, attachments disabled: %v", err)
+		return ""
+	}
+	return dd.MediaDir()
+}
+
+// toChannelConfig converts a configured channel to a channel manager config
+func toChannelConfig(ch config.ChannelConfig, mediaDir string) channels.ChannelConfig {
+	return channels.ChannelConfig{
+		ID:       ch.Name,
+		Type:     ch.Type,
+		Name:     ch.Name,
+		Enabled:  ch.Enabled,
+		Config:   ch.Config,
+		MediaDir: mediaDir,
+	}
+}
+
 // stopChannels stops the channel manager
 func (g *Gateway) stopChannels() {
 	if err := g.channelManager.Stop(); err != nil {
@@ -1054,7 +1180,13 @@ func (g *Gateway) processMessages(ctx context.Context) {
 	for {
 		select {
 		case msg := <-g.channelManager.ReceiveMessages():
-			go g.handleIncomingMessage(ctx, msg)
+			// Decided here, in arrival order, so ordinary messages are
+			// queued in the order they were sent
+			if isGatewayCommand(msg.Text) {
+				go g.handleCommandMessage(ctx, msg)
+				continue
+			}
+			g.turns.Enqueue(ctx, msg)
 
 		case <-ctx.Done():
 			return
@@ -1062,7 +1194,28 @@ func (g *Gateway) processMessages(ctx context.Context) {
 	}
 }
 
-// handleIncomingMessage processes a single incoming message
+// handleCommandMessage runs a gateway slash command immediately, outside the
+// session queue
+func (g *Gateway) handleCommandMessage(ctx context.Context, msg *protocol.IncomingMessage) {
+	session, err := g.sessions.GetOrCreateSession(msg.UserID, msg.ChannelID)
+	if err != nil {
+		log.Printf("Error getting session: %v", err)
+		return
+	}
+
+	g.handleCommand(ctx, msg, session)
+}
+
+// reportQueueDepth publishes the number of messages waiting for their session
+func (g *Gateway) reportQueueDepth(depth int) {
+	g.sessions.UpdateQueueDepth(depth)
+	if g.metricsCollector != nil {
+		g.metricsCollector.UpdateQueueDepth(depth)
+	}
+}
+
+// handleIncomingMessage processes a single incoming message. Channel messages
+// arrive here through the session queue, one turn per session at a time.
 func (g *Gateway) handleIncomingMessage(ctx context.Context, msg *protocol.IncomingMessage) {
 	log.Printf("Processing message from %s (%d chars)", msg.ChannelID, len(msg.Text))
 
@@ -1084,7 +1237,7 @@ func (g *Gateway) handleIncomingMessage(ctx context.Context, msg *protocol.Incom
 	}
 
 	// Add user message to session
-	_, err = g.sessions.AddMessage(session.Key, "user", msg.Text, msg.Metadata)
+	_, err = g.sessions.AddMessageWithBlocks(session.Key, "user", msg.Text, attachmentBlocks(msg.Attachments), msg.Metadata)
 	if err != nil {
 		log.Printf("Error saving user message: %v", err)
 		return
@@ -1114,6 +1267,7 @@ func (g *Gateway) handleIncomingMessage(ctx context.Context, msg *protocol.Incom
 		// Create cancellable context for this request
 		reqCtx, cancel := context.WithCancel(ctx)
 		reqCtx = types.WithRequestContext(reqCtx, msg.ChannelID, msg.UserID, session.Key)
+		reqCtx = tools.WithApprovalPrompter(reqCtx, g.channelApprovalPrompter(msg))
 
 		// Track this request so /stop can cancel it
 		g.activeRequestsMu.Lock()
@@ -1144,7 +1298,7 @@ func (g *Gateway) handleIncomingMessage(ctx context.Context, msg *protocol.Incom
 
 		// Check if adapter supports streaming
 		adapter, _ := g.channelManager.GetAdapter(msg.ChannelID)
-		streamingAdapter, supportsStreaming := adapter.(channels.StreamingAdapter)
+		streamer, supportsStreaming := newMessageStreamer(adapter, msg.UserID)
 
 		var convResponse ai.ConversationResponse
 		var err error
@@ -1152,12 +1306,8 @@ func (g *Gateway) handleIncomingMessage(ctx context.Context, msg *protocol.Incom
 		streamingUsed := false
 
 		if supportsStreaming {
-			// Use streaming mode
-			chatID, _ := strconv.ParseInt(msg.UserID, 10, 64)
-
-			// Send placeholder message
-			placeholderMsgID, sendErr := streamingAdapter.SendMessageWithID(chatID, "...")
-			if sendErr != nil {
+			// Use streaming mode, starting with a placeholder message
+			if sendErr := streamer.send("..."); sendErr != nil {
 				log.Printf("[Streaming] Failed to send placeholder: %v", sendErr)
 				supportsStreaming = false // Fall back to non-streaming
 			} else {
@@ -1182,7 +1332,7 @@ func (g *Gateway) handleIncomingMessage(ctx context.Context, msg *protocol.Incom
 						(len(currentText)-len(currentText) > 100) // Every 100 chars
 
 					if shouldEdit && len(currentText) > 0 {
-						if editErr := streamingAdapter.EditMessageText(chatID, placeholderMsgID, currentText); editErr != nil {
+						if editErr := streamer.edit(currentText); editErr != nil {
 							log.Printf("[Streaming] Edit failed: %v", editErr)
 						}
 						lastEditTime = time.Now()
@@ -1200,7 +1350,7 @@ func (g *Gateway) handleIncomingMessage(ctx context.Context, msg *protocol.Incom
 					if isSilentResponse(finalContent) {
 						log.Printf("[Streaming] Silent response pattern detected, deleting placeholder")
 						// Delete the placeholder message since we don't want to show this
-						if deleteErr := streamingAdapter.DeleteMessage(chatID, placeholderMsgID); deleteErr != nil {
+						if deleteErr := streamer.remove(); deleteErr != nil {
 							log.Printf("[Streaming] Failed to delete placeholder: %v", deleteErr)
 						}
 						streamingUsed = true
@@ -1210,7 +1360,7 @@ func (g *Gateway) handleIncomingMessage(ctx context.Context, msg *protocol.Incom
 							log.Printf("[Streaming] Tool execution detected: streamed=%d chars, final=%d chars",
 								streamedLength, len(finalContent))
 						}
-						streamingAdapter.EditMessageText(chatID, placeholderMsgID, finalContent)
+						streamer.edit(finalContent)
 						streamingUsed = true
 					} else if streamedLength > 0 {
 						// Fallback to streamed text if no final content
@@ -1218,12 +1368,12 @@ func (g *Gateway) handleIncomingMessage(ctx context.Context, msg *protocol.Incom
 						// Also check streamed text for silent patterns
 						if isSilentResponse(streamedText) {
 							log.Printf("[Streaming] Silent response in streamed text, deleting placeholder")
-							if deleteErr := streamingAdapter.DeleteMessage(chatID, placeholderMsgID); deleteErr != nil {
+							if deleteErr := streamer.remove(); deleteErr != nil {
 								log.Printf("[Streaming] Failed to delete placeholder: %v", deleteErr)
 							}
 						} else {
 							log.Printf("[Streaming] Using streamed text only: %d chars", streamedLength)
-							streamingAdapter.EditMessageText(chatID, placeholderMsgID, streamedText)
+							streamer.edit(streamedText)
 						}
 						streamingUsed = true
 					}
@@ -1293,6 +1443,12 @@ func (g *Gateway) handleIncomingMessage(ctx context.Context, msg *protocol.Incom
 			_ = g.sessions.SetSessionContext(session.Key, "last_completion_tokens", strconv.Itoa(usage.CompletionTokens))
 			_ = g.sessions.SetSessionContext(session.Key, "last_total_tokens", strconv.Itoa(usage.TotalTokens))
 		}
+		if provider := ai.ResponseProvider(convResponse); provider != "" {
+			_ = g.sessions.SetSessionContext(session.Key, "last_provider", provider)
+		}
+
+		// Tools ran even when the reply is silent, so record them first
+		persistToolTurns(g.sessions, session.Key, convResponse)
 
 		// Check for silent response tokens (NO_REPLY, HEARTBEAT_OK)
 		if responseContent == "" || isSilentResponse(responseContent) {
@@ -1390,6 +1546,10 @@ func (g *Gateway) handleTestMessage(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Error creating session: %v", err), http.StatusInternalServerError)
 		return
 	}
+	if authInfo := middleware.GetAuthInfo(r.Context()); authInfo != nil && !authInfo.AllowsSession(session.Key, session.ChannelID) {
+		http.Error(w, "Token does not grant access to this session", http.StatusForbidden)
+		return
+	}
 
 	// Add user message to session
 	_, err = g.sessions.AddMessage(session.Key, "user", req.Message, nil)
@@ -1414,6 +1574,7 @@ func (g *Gateway) handleTestMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Add AI response to session
+	persistToolTurns(g.sessions, session.Key, convResponse)
 	_, err = g.sessions.AddMessage(session.Key, "assistant", convResponse.GetContent(), nil)
 	if err != nil {
 		log.Printf("Error saving AI message: %v", err)
@@ -1426,6 +1587,7 @@ func (g *Gateway) handleTestMessage(w http.ResponseWriter, r *http.Request) {
 		"response": convResponse.GetContent(),
 		"usage":    convResponse.GetUsage(),
 		"steps":    convResponse.GetSteps(),
+		"provider": ai.ResponseProvider(convResponse),
 	})
 }
 
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"time"
 
+	"conduit/internal/channels"
 	"conduit/internal/monitoring"
 	"conduit/internal/version"
 )
@@ -252,6 +253,7 @@ func (g *Gateway) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
 		Filter     monitoring.EventFilter       `json:"filter"`
 		Timestamp  time.Time                    `json:"timestamp"`
 		SystemInfo map[string]interface{}       `json:"system_info"`
+		Channels   []channels.SupervisedAdapter `json:"channels,omitempty"`
 	}{
 		Events:    events,
 		Count:     len(events),
@@ -263,6 +265,11 @@ func (g *Gateway) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
+	// Add channel health and restart counts
+	if g.channelWatch != nil {
+		response.Channels = g.channelWatch.Adapters()
+	}
+
 	// Add current system state to response
 	if g.gatewayMetrics != nil {
 		response.SystemInfo["status"] = g.gatewayMetrics.Status
@@ -357,4 +364,25 @@ func (g *Gateway) handlePrometheusMetrics(w http.ResponseWriter, r *http.Request
 	fmt.Fprintf(w, "# HELP conduit_status Gateway status (1=healthy, 0=unhealthy)\n")
 	fmt.Fprintf(w, "# TYPE conduit_status gauge\n")
 	fmt.Fprintf(w, "conduit_status{status=\"%s\"} %d\n", metrics.Status, statusValue)
+
+	// Per-channel health and supervisor restarts
+	if g.channelWatch != nil {
+		adapters := g.channelWatch.Adapters()
+
+		fmt.Fprintf(w, "# HELP conduit_channel_up Channel adapter health (1=healthy, 0=unhealthy)\n")
+		fmt.Fprintf(w, "# TYPE conduit_channel_up gauge\n")
+		for _, a := range adapters {
+			up := 0
+			if a.Healthy {
+				up = 1
+			}
+			fmt.Fprintf(w, "conduit_channel_up{channel=\"%s\",type=\"%s\"} %d\n", a.ID, a.Type, up)
+		}
+
+		fmt.Fprintf(w, "# HELP conduit_channel_restarts_total Automatic channel adapter restarts\n")
+		fmt.Fprintf(w, "# TYPE conduit_channel_restarts_total counter\n")
+		for _, a := range adapters {
+			fmt.Fprintf(w, "conduit_channel_restarts_total{channel=\"%s\",type=\"%s\"} %d\n", a.ID, a.Type, a.Restarts)
+		}
+	}
 }
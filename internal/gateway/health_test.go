@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"conduit/internal/channels"
+	"conduit/internal/channels/webhook"
 	"conduit/internal/config"
 	"conduit/internal/middleware"
 	"conduit/internal/monitoring"
@@ -572,3 +575,65 @@ func TestRateLimitingOnHealthEndpoints(t *testing.T) {
 	// Cleanup
 	rateLimitMiddleware.Stop()
 }
+
+func TestChannelSupervisorReporting(t *testing.T) {
+	gw := createTestGateway(t)
+
+	manager := channels.NewManager()
+	manager.RegisterFactory(webhook.NewFactory())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := manager.Start(ctx, []channels.ChannelConfig{{
+		ID: "ci", Type: "webhook", Name: "ci", Enabled: true,
+		Config: map[string]interface{}{"secret": "s3cret"},
+	}})
+	if err != nil {
+		t.Fatalf("failed to start channels: %v", err)
+	}
+	defer manager.Stop()
+
+	gw.channelManager = manager
+	gw.channelWatch = channels.NewSupervisor(manager, channels.SupervisorOptions{}, gw.publishChannelEvent)
+
+	if err := manager.RestartAdapter("ci"); err != nil {
+		t.Fatalf("failed to restart channel: %v", err)
+	}
+	gw.publishChannelEvent(channels.SupervisorEvent{
+		Kind:      channels.SupervisorRestarted,
+		AdapterID: "ci",
+		Restarts:  1,
+		Message:   "Restarted channel ci",
+	})
+
+	rr := httptest.NewRecorder()
+	gw.handlePrometheusMetrics(rr, httptest.NewRequest(http.MethodGet, "/prometheus", nil))
+	body := rr.Body.String()
+	for _, metric := range []string{
+		`conduit_channel_up{channel="ci",type="webhook"}`,
+		`conduit_channel_restarts_total{channel="ci",type="webhook"} 0`,
+	} {
+		if !strings.Contains(body, metric) {
+			t.Errorf("Expected %s in Prometheus output", metric)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	gw.handleDiagnostics(rr, httptest.NewRequest(http.MethodGet, "/diagnostics", nil))
+	var diagnostics struct {
+		Channels []channels.SupervisedAdapter `json:"channels"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &diagnostics); err != nil {
+		t.Fatalf("Failed to decode diagnostics: %v", err)
+	}
+	if len(diagnostics.Channels) != 1 || diagnostics.Channels[0].ID != "ci" {
+		t.Errorf("Expected the ci channel in diagnostics, got %+v", diagnostics.Channels)
+	}
+
+	events, err := gw.eventStore.Query(monitoring.EventFilter{Source: "channel:ci"})
+	if err != nil {
+		t.Fatalf("Failed to query events: %v", err)
+	}
+	if len(events) != 1 || events[0].Severity != monitoring.SeverityWarning || events[0].Metadata["action"] != channels.SupervisorRestarted {
+		t.Errorf("Expected one restart warning for channel ci, got %+v", events)
+	}
+}
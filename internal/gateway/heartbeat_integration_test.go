@@ -104,6 +104,45 @@ func TestInitializeAgentHeartbeat(t *testing.T) {
 	}
 }
 
+func TestRescheduleAgentHeartbeat_KeepsUserJobs(t *testing.T) {
+	mockSched := newMockScheduler()
+	mockSched.jobs["heartbeat_123"] = &scheduler.Job{ID: "heartbeat_123", Command: "heartbeat", Schedule: "0 0 9 * * *"}
+
+	gateway := &Gateway{
+		config:               &config.Config{AgentHeartbeat: config.AgentHeartbeatConfig{Enabled: true, IntervalMinutes: 5}},
+		scheduler:            mockSched,
+		metricsCollector:     newMockMetricsCollector(),
+		heartbeatIntegration: newMockHeartbeatIntegration(mockSched),
+	}
+	if err := gateway.initializeAgentHeartbeat(gateway.config); err != nil {
+		t.Fatalf("initializeAgentHeartbeat failed: %v", err)
+	}
+
+	gateway.config.AgentHeartbeat.IntervalMinutes = 15
+	if err := gateway.rescheduleAgentHeartbeat(); err != nil {
+		t.Fatalf("rescheduleAgentHeartbeat failed: %v", err)
+	}
+
+	if _, ok := mockSched.jobs["heartbeat_123"]; !ok {
+		t.Error("Expected the user's heartbeat job to survive a config reload")
+	}
+	job, ok := mockSched.jobs[agentHeartbeatJobID]
+	if !ok || job.Schedule != "0 */15 * * * *" {
+		t.Errorf("Expected the agent heartbeat to be rescheduled every 15 minutes, got %+v", job)
+	}
+	if len(mockSched.jobs) != 2 {
+		t.Errorf("Expected 2 jobs, got %d", len(mockSched.jobs))
+	}
+
+	gateway.config.AgentHeartbeat.Enabled = false
+	if err := gateway.rescheduleAgentHeartbeat(); err != nil {
+		t.Fatalf("rescheduleAgentHeartbeat failed: %v", err)
+	}
+	if _, ok := mockSched.jobs[agentHeartbeatJobID]; ok || len(mockSched.jobs) != 1 {
+		t.Errorf("Expected only the agent heartbeat to be removed, got %v", mockSched.jobs)
+	}
+}
+
 func TestUpdateHeartbeatJobMetrics(t *testing.T) {
 	mockMetrics := newMockMetricsCollector()
 	mockSched := newMockScheduler()
@@ -211,6 +250,10 @@ func (m *mockScheduler) RunNow(jobID string) error {
 	return nil
 }
 
+func (m *mockScheduler) JobHistory(jobID string, limit int) ([]*scheduler.Run, error) {
+	return nil, nil
+}
+
 func (m *mockScheduler) Status() map[string]interface{} {
 	return map[string]interface{}{
 		"total_jobs": len(m.jobs),
@@ -311,8 +354,12 @@ func (m *mockHeartbeatIntegration) ExecuteHeartbeat(ctx context.Context, job *sc
 }
 
 func (m *mockHeartbeatIntegration) ScheduleHeartbeatJob(schedule, target, model string, enabled bool) error {
+	return m.ScheduleHeartbeatJobWithID(fmt.Sprintf("heartbeat_%d", time.Now().UnixNano()), schedule, target, model, enabled)
+}
+
+func (m *mockHeartbeatIntegration) ScheduleHeartbeatJobWithID(id, schedule, target, model string, enabled bool) error {
 	job := &scheduler.Job{
-		ID:       fmt.Sprintf("heartbeat_%d", time.Now().UnixNano()),
+		ID:       id,
 		Name:     "Heartbeat Task Execution",
 		Schedule: schedule,
 		Type:     scheduler.JobTypeGo,
@@ -425,7 +472,7 @@ func TestHeartbeatTargetConfiguration(t *testing.T) {
 			expectedTarget: "telegram:111111111",
 		},
 		{
-			name: "non-telegram target",
+			name: "email target",
 			alertTargets: []config.AlertTarget{
 				{
 					Type: "email",
@@ -434,6 +481,31 @@ func TestHeartbeatTargetConfiguration(t *testing.T) {
 					},
 				},
 			},
+			expectedTarget: "email:test@example.com",
+		},
+		{
+			name: "email target on a named channel",
+			alertTargets: []config.AlertTarget{
+				{
+					Type: "email",
+					Config: map[string]string{
+						"channel": "ops-mail",
+						"to":      "oncall@example.com",
+					},
+				},
+			},
+			expectedTarget: "ops-mail:oncall@example.com",
+		},
+		{
+			name: "unsupported target",
+			alertTargets: []config.AlertTarget{
+				{
+					Type: "slack",
+					Config: map[string]string{
+						"channel": "#alerts",
+					},
+				},
+			},
 			expectedTarget: "",
 		},
 	}
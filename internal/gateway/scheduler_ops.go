@@ -27,6 +27,15 @@ func (g *Gateway) ScheduleJob(job *types.SchedulerJob) error {
 		Target:   job.Target,
 		Enabled:  job.Enabled,
 		OneShot:  job.OneShot,
+
+		Timeout:      job.Timeout,
+		MaxRetries:   job.MaxRetries,
+		RetryBackoff: job.RetryBackoff,
+		Concurrency:  job.Concurrency,
+
+		MisfirePolicy: job.MisfirePolicy,
+		MisfireWindow: job.MisfireWindow,
+		Timezone:      job.Timezone,
 	}
 
 	return g.scheduler.AddJob(schedJob)
@@ -59,6 +68,15 @@ func (g *Gateway) ListJobs() []*types.SchedulerJob {
 			Target:   job.Target,
 			Enabled:  job.Enabled,
 			OneShot:  job.OneShot,
+
+			Timeout:      job.Timeout,
+			MaxRetries:   job.MaxRetries,
+			RetryBackoff: job.RetryBackoff,
+			Concurrency:  job.Concurrency,
+
+			MisfirePolicy: job.MisfirePolicy,
+			MisfireWindow: job.MisfireWindow,
+			Timezone:      job.Timezone,
 		}
 	}
 	return result
@@ -88,6 +106,38 @@ func (g *Gateway) RunJobNow(jobID string) error {
 	return g.scheduler.RunNow(jobID)
 }
 
+// GetJobHistory returns a job's recorded runs, newest first
+func (g *Gateway) GetJobHistory(jobID string, limit int) ([]*types.SchedulerJobRun, error) {
+	if g.scheduler == nil {
+		return nil, fmt.Errorf("scheduler not initialized")
+	}
+
+	runs, err := g.scheduler.JobHistory(jobID, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.SchedulerJobRun, len(runs))
+	for i, run := range runs {
+		result[i] = &types.SchedulerJobRun{
+			ID:               run.ID,
+			JobID:            run.JobID,
+			TriggeredBy:      run.TriggeredBy,
+			Attempt:          run.Attempt,
+			Status:           run.Status,
+			Error:            run.Error,
+			Model:            run.Model,
+			PromptTokens:     run.PromptTokens,
+			CompletionTokens: run.CompletionTokens,
+			TotalTokens:      run.TotalTokens,
+			Output:           run.Output,
+			StartedAt:        run.StartedAt,
+			FinishedAt:       run.FinishedAt,
+			DurationMs:       run.DurationMs,
+		}
+	}
+	return result, nil
+}
+
 // GetSchedulerStatus returns scheduler status
 func (g *Gateway) GetSchedulerStatus() map[string]interface{} {
 	if g.scheduler == nil {
@@ -122,6 +172,10 @@ func (g *Gateway) RemoveHeartbeatJobs() error {
 	return g.heartbeatIntegration.RemoveHeartbeatJobs()
 }
 
+// agentHeartbeatJobID is the job created from the agent_heartbeat config.
+// Heartbeat jobs created through the Cron tool have other IDs.
+const agentHeartbeatJobID = "agent_heartbeat_main"
+
 // initializeAgentHeartbeat sets up automatic agent heartbeat jobs based on configuration
 func (g *Gateway) initializeAgentHeartbeat(cfg *config.Config) error {
 	if !cfg.AgentHeartbeat.Enabled {
@@ -137,15 +191,30 @@ func (g *Gateway) initializeAgentHeartbeat(cfg *config.Config) error {
 	if len(cfg.AgentHeartbeat.AlertTargets) > 0 {
 		// Format: "telegram:chat_id" or similar
 		firstTarget := cfg.AgentHeartbeat.AlertTargets[0]
-		if firstTarget.Type == "telegram" {
+		switch firstTarget.Type {
+		case "telegram":
 			if chatID, exists := firstTarget.Config["chat_id"]; exists {
 				target = fmt.Sprintf("telegram:%s", chatID)
 			}
+		case "email":
+			// Delivered by the email channel adapter, "email" unless the
+			// target names another channel ID
+			address := firstTarget.Config["to"]
+			if address == "" {
+				address = firstTarget.Config["address"]
+			}
+			channelID := firstTarget.Config["channel"]
+			if channelID == "" {
+				channelID = "email"
+			}
+			if address != "" {
+				target = fmt.Sprintf("%s:%s", channelID, address)
+			}
 		}
 	}
 
 	// Create the main agent heartbeat job
-	jobID := "agent_heartbeat_main"
+	jobID := agentHeartbeatJobID
 
 	// Check if job already exists (avoid duplicates on restart)
 	existingJobs := g.scheduler.ListJobs()
@@ -157,7 +226,10 @@ func (g *Gateway) initializeAgentHeartbeat(cfg *config.Config) error {
 	}
 
 	// Schedule the heartbeat job
-	if err := g.ScheduleHeartbeatJob(cronSchedule, target, "", true); err != nil {
+	if g.heartbeatIntegration == nil {
+		return fmt.Errorf("heartbeat integration not available")
+	}
+	if err := g.heartbeatIntegration.ScheduleHeartbeatJobWithID(jobID, cronSchedule, target, "", true); err != nil {
 		return fmt.Errorf("failed to schedule agent heartbeat job: %w", err)
 	}
 
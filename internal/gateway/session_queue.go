@@ -0,0 +1,181 @@
+package gateway
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"conduit/internal/config"
+	"conduit/pkg/protocol"
+)
+
+// turnHandler processes one conversation turn
+type turnHandler func(ctx context.Context, msg *protocol.IncomingMessage)
+
+// sessionQueue runs the turns of each session one at a time, in arrival
+// order, so messages to the same session never race on history or on
+// activeRequests. Messages that arrive mid-turn wait according to the
+// configured policy:
+//   - queue: each message runs as its own turn
+//   - merge: everything waiting is combined into the next turn
+//   - interrupt: the running turn is cancelled and everything waiting is
+//     combined into the next turn
+type sessionQueue struct {
+	policy  string
+	handle  turnHandler
+	onDepth func(depth int) // Receives the number of waiting messages
+
+	mu       sync.Mutex
+	sessions map[string]*sessionTurns
+	depth    int
+}
+
+// sessionTurns is the work for one session
+type sessionTurns struct {
+	pending []*protocol.IncomingMessage
+	running bool
+	cancel  context.CancelFunc // Cancels the running turn
+}
+
+func newSessionQueue(policy string, handle turnHandler, onDepth func(int)) *sessionQueue {
+	if policy == "" {
+		policy = config.QueuePolicyQueue
+	}
+	return &sessionQueue{
+		policy:   policy,
+		handle:   handle,
+		onDepth:  onDepth,
+		sessions: make(map[string]*sessionTurns),
+	}
+}
+
+// turnQueueKey identifies the session a message belongs to. Sessions are
+// looked up by user and channel, so the pair serves as the key without a
+// database round trip.
+func turnQueueKey(msg *protocol.IncomingMessage) string {
+	return msg.ChannelID + "\x00" + msg.UserID
+}
+
+// Enqueue schedules msg after any running turn for its session
+func (q *sessionQueue) Enqueue(ctx context.Context, msg *protocol.IncomingMessage) {
+	key := turnQueueKey(msg)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	turns, ok := q.sessions[key]
+	if !ok {
+		turns = &sessionTurns{}
+		q.sessions[key] = turns
+	}
+	turns.pending = append(turns.pending, msg)
+	q.depth++
+	q.reportDepth()
+
+	if turns.running {
+		if q.policy == config.QueuePolicyInterrupt && turns.cancel != nil {
+			log.Printf("[SessionQueue] New message from %s interrupts the running turn", msg.UserID)
+			turns.cancel()
+		}
+		return
+	}
+
+	turns.running = true
+	go q.run(ctx, key, turns)
+}
+
+// Depth returns the number of messages waiting across all sessions
+func (q *sessionQueue) Depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.depth
+}
+
+// run processes a session's turns until none are waiting
+func (q *sessionQueue) run(ctx context.Context, key string, turns *sessionTurns) {
+	for {
+		q.mu.Lock()
+		if len(turns.pending) == 0 || ctx.Err() != nil {
+			q.depth -= len(turns.pending)
+			q.reportDepth()
+			delete(q.sessions, key)
+			q.mu.Unlock()
+			return
+		}
+
+		var msg *protocol.IncomingMessage
+		if q.policy == config.QueuePolicyQueue {
+			msg = turns.pending[0]
+			turns.pending = turns.pending[1:]
+			q.depth--
+		} else {
+			msg = mergeMessages(turns.pending)
+			q.depth -= len(turns.pending)
+			turns.pending = nil
+		}
+		q.reportDepth()
+
+		turnCtx, cancel := context.WithCancel(ctx)
+		turns.cancel = cancel
+		q.mu.Unlock()
+
+		q.handle(turnCtx, msg)
+
+		q.mu.Lock()
+		turns.cancel = nil
+		q.mu.Unlock()
+		cancel()
+	}
+}
+
+// reportDepth publishes the waiting count; callers hold q.mu
+func (q *sessionQueue) reportDepth() {
+	if q.onDepth != nil {
+		q.onDepth(q.depth)
+	}
+}
+
+// mergeMessages combines messages into one turn: texts joined in order,
+// attachments concatenated, and routing fields taken from the latest
+func mergeMessages(msgs []*protocol.IncomingMessage) *protocol.IncomingMessage {
+	if len(msgs) == 1 {
+		return msgs[0]
+	}
+
+	merged := *msgs[len(msgs)-1]
+	merged.Metadata = make(map[string]string, len(merged.Metadata)+1)
+	for k, v := range msgs[len(msgs)-1].Metadata {
+		merged.Metadata[k] = v
+	}
+	merged.Metadata["merged_messages"] = strconv.Itoa(len(msgs))
+
+	texts := make([]string, 0, len(msgs))
+	merged.Attachments = nil
+	for _, m := range msgs {
+		if m.Text != "" {
+			texts = append(texts, m.Text)
+		}
+		merged.Attachments = append(merged.Attachments, m.Attachments...)
+	}
+	merged.Text = strings.Join(texts, "\n\n")
+	return &merged
+}
+
+// isGatewayCommand reports whether a message is one of the slash commands
+// handleCommand answers. Those skip the session queue so /stop can reach a
+// running turn; any other text, including unknown commands, is queued.
+func isGatewayCommand(text string) bool {
+	text = strings.TrimSpace(text)
+	switch text {
+	case "/stop", "/commands":
+		return true
+	}
+	for _, name := range []string{"/reset", "/new", "/status", "/help", "/model", "/context"} {
+		if text == name || strings.HasPrefix(text, name+" ") {
+			return true
+		}
+	}
+	return isApprovalCommand(text)
+}
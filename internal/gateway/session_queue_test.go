@@ -0,0 +1,208 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"conduit/internal/config"
+	"conduit/pkg/protocol"
+)
+
+// recordingHandler records turns and blocks each one until released
+type recordingHandler struct {
+	mu      sync.Mutex
+	texts   []string
+	active  int
+	overlap bool
+	started chan string
+	release chan struct{}
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{
+		started: make(chan string, 10),
+		release: make(chan struct{}, 10),
+	}
+}
+
+func (h *recordingHandler) handle(ctx context.Context, msg *protocol.IncomingMessage) {
+	h.mu.Lock()
+	h.texts = append(h.texts, msg.Text)
+	h.active++
+	if h.active > 1 {
+		h.overlap = true
+	}
+	h.mu.Unlock()
+
+	h.started <- msg.Text
+	select {
+	case <-h.release:
+	case <-ctx.Done():
+	}
+
+	h.mu.Lock()
+	h.active--
+	h.mu.Unlock()
+}
+
+// activeSessions returns how many sessions have queued or running turns
+func (q *sessionQueue) activeSessions() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.sessions)
+}
+
+func waitStarted(t *testing.T, h *recordingHandler) string {
+	t.Helper()
+	select {
+	case text := <-h.started:
+		return text
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a turn to start")
+		return ""
+	}
+}
+
+func queueMsg(userID, text string) *protocol.IncomingMessage {
+	return &protocol.IncomingMessage{ChannelID: "telegram", UserID: userID, Text: text}
+}
+
+func TestSessionQueue_RunsTurnsInOrder(t *testing.T) {
+	h := newRecordingHandler()
+	var depthMu sync.Mutex
+	var depths []int
+	q := newSessionQueue(config.QueuePolicyQueue, h.handle, func(d int) {
+		depthMu.Lock()
+		depths = append(depths, d)
+		depthMu.Unlock()
+	})
+
+	ctx := context.Background()
+	q.Enqueue(ctx, queueMsg("u1", "first"))
+	if got := waitStarted(t, h); got != "first" {
+		t.Fatalf("Expected first turn, got %q", got)
+	}
+
+	q.Enqueue(ctx, queueMsg("u1", "second"))
+	q.Enqueue(ctx, queueMsg("u1", "third"))
+	if q.Depth() != 2 {
+		t.Errorf("Expected 2 waiting messages, got %d", q.Depth())
+	}
+
+	for _, want := range []string{"second", "third"} {
+		h.release <- struct{}{}
+		if got := waitStarted(t, h); got != want {
+			t.Errorf("Expected %q next, got %q", want, got)
+		}
+	}
+	h.release <- struct{}{}
+
+	// Wait for the worker to drain
+	deadline := time.Now().Add(2 * time.Second)
+	for q.Depth() != 0 || q.activeSessions() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("queue did not drain")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	h.mu.Lock()
+	if h.overlap {
+		t.Error("Turns for one session overlapped")
+	}
+	h.mu.Unlock()
+	depthMu.Lock()
+	defer depthMu.Unlock()
+	if depths[len(depths)-1] != 0 {
+		t.Errorf("Expected final depth 0, got %v", depths)
+	}
+}
+
+func TestSessionQueue_SessionsRunConcurrently(t *testing.T) {
+	h := newRecordingHandler()
+	q := newSessionQueue(config.QueuePolicyQueue, h.handle, nil)
+
+	q.Enqueue(context.Background(), queueMsg("u1", "from u1"))
+	q.Enqueue(context.Background(), queueMsg("u2", "from u2"))
+
+	// Both start without either being released
+	waitStarted(t, h)
+	waitStarted(t, h)
+	h.release <- struct{}{}
+	h.release <- struct{}{}
+}
+
+func TestSessionQueue_MergePolicy(t *testing.T) {
+	h := newRecordingHandler()
+	q := newSessionQueue(config.QueuePolicyMerge, h.handle, nil)
+
+	ctx := context.Background()
+	q.Enqueue(ctx, queueMsg("u1", "first"))
+	waitStarted(t, h)
+
+	q.Enqueue(ctx, queueMsg("u1", "second"))
+	q.Enqueue(ctx, queueMsg("u1", "third"))
+	h.release <- struct{}{}
+
+	if got := waitStarted(t, h); got != "second\n\nthird" {
+		t.Errorf("Expected merged turn, got %q", got)
+	}
+	h.release <- struct{}{}
+}
+
+func TestSessionQueue_InterruptPolicy(t *testing.T) {
+	h := newRecordingHandler()
+	q := newSessionQueue(config.QueuePolicyInterrupt, h.handle, nil)
+
+	ctx := context.Background()
+	q.Enqueue(ctx, queueMsg("u1", "long task"))
+	waitStarted(t, h)
+
+	// The running turn is cancelled without being released
+	q.Enqueue(ctx, queueMsg("u1", "never mind"))
+	if got := waitStarted(t, h); got != "never mind" {
+		t.Errorf("Expected interrupting turn, got %q", got)
+	}
+	h.release <- struct{}{}
+}
+
+func TestMergeMessages(t *testing.T) {
+	msgs := []*protocol.IncomingMessage{
+		{ChannelID: "telegram", UserID: "u1", Text: "look at this", Metadata: map[string]string{"message_id": "1"}},
+		{ChannelID: "telegram", UserID: "u1", Attachments: []protocol.Attachment{{Type: protocol.AttachmentImage, Path: "/m/1.jpg"}}, Metadata: map[string]string{"message_id": "2"}},
+		{ChannelID: "telegram", UserID: "u1", Text: "and this", Attachments: []protocol.Attachment{{Type: protocol.AttachmentDocument, Path: "/m/2.pdf"}}, Metadata: map[string]string{"message_id": "3"}},
+	}
+
+	merged := mergeMessages(msgs)
+	if merged.Text != "look at this\n\nand this" {
+		t.Errorf("Unexpected merged text %q", merged.Text)
+	}
+	if len(merged.Attachments) != 2 {
+		t.Errorf("Expected 2 attachments, got %d", len(merged.Attachments))
+	}
+	if merged.Metadata["message_id"] != "3" || merged.Metadata["merged_messages"] != "3" {
+		t.Errorf("Unexpected metadata %v", merged.Metadata)
+	}
+	if _, ok := msgs[2].Metadata["merged_messages"]; ok {
+		t.Error("Merging must not modify the original message")
+	}
+
+	if mergeMessages(msgs[:1]) != msgs[0] {
+		t.Error("Expected a single message to pass through unchanged")
+	}
+}
+
+func TestIsGatewayCommand(t *testing.T) {
+	for _, text := range []string{" /stop", "/status", "/model opus", "/approve 3", "/commands"} {
+		if !isGatewayCommand(text) {
+			t.Errorf("Expected %q to be a gateway command", text)
+		}
+	}
+	for _, text := range []string{"hello /stop", "/stopper", "/summarize this", "/commands please", "/"} {
+		if isGatewayCommand(text) {
+			t.Errorf("Expected %q to be queued as a message", text)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"conduit/internal/config"
 	"conduit/internal/version"
 )
 
@@ -49,27 +50,29 @@ func (g *Gateway) GetChannelStatus() (map[string]interface{}, error) {
 	return result, nil
 }
 
-// EnableChannel enables a channel
+// EnableChannel enables a configured channel and starts its adapter
 func (g *Gateway) EnableChannel(ctx context.Context, channelID string) error {
-	return fmt.Errorf("enable channel not yet implemented")
+	return g.applyConfigUpdate(&config.Update{Channels: map[string]bool{channelID: true}}, configSourceTool, configActor(ctx), false)
 }
 
-// DisableChannel disables a channel
+// DisableChannel disables a configured channel and stops its adapter
 func (g *Gateway) DisableChannel(ctx context.Context, channelID string) error {
-	return fmt.Errorf("disable channel not yet implemented")
+	return g.applyConfigUpdate(&config.Update{Channels: map[string]bool{channelID: false}}, configSourceTool, configActor(ctx), false)
 }
 
 // GetConfiguration returns current configuration
 func (g *Gateway) GetConfiguration() (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"ai":        g.config.AI,
-		"workspace": g.config.Workspace,
-	}, nil
+	return g.currentConfiguration(), nil
 }
 
-// UpdateConfiguration updates configuration
-func (g *Gateway) UpdateConfiguration(ctx context.Context, config map[string]interface{}) error {
-	return fmt.Errorf("configuration update not yet implemented")
+// UpdateConfiguration applies a partial configuration update without a
+// restart, optionally writing it back to the config file
+func (g *Gateway) UpdateConfiguration(ctx context.Context, update map[string]interface{}, persist bool) error {
+	u, err := config.ParseUpdate(update)
+	if err != nil {
+		return err
+	}
+	return g.applyConfigUpdate(u, configSourceTool, configActor(ctx), persist)
 }
 
 // GetMetrics returns gateway metrics
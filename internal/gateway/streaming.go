@@ -0,0 +1,69 @@
+package gateway
+
+import (
+	"strconv"
+
+	"conduit/internal/channels"
+)
+
+// messageStreamer edits a single placeholder message as a response streams in
+type messageStreamer interface {
+	send(text string) error
+	edit(text string) error
+	remove() error
+}
+
+// newMessageStreamer returns a streamer for adapters that can edit messages,
+// or false if the adapter can't stream to chatID
+func newMessageStreamer(adapter channels.ChannelAdapter, chatID string) (messageStreamer, bool) {
+	switch a := adapter.(type) {
+	case channels.StreamingAdapter:
+		id, _ := strconv.ParseInt(chatID, 10, 64)
+		return &intMessageStreamer{adapter: a, chatID: id}, true
+	case channels.TextStreamingAdapter:
+		return &textMessageStreamer{adapter: a, chatID: chatID}, true
+	}
+	return nil, false
+}
+
+// intMessageStreamer streams through a StreamingAdapter (Telegram, Discord)
+type intMessageStreamer struct {
+	adapter   channels.StreamingAdapter
+	chatID    int64
+	messageID int
+}
+
+func (s *intMessageStreamer) send(text string) error {
+	id, err := s.adapter.SendMessageWithID(s.chatID, text)
+	s.messageID = id
+	return err
+}
+
+func (s *intMessageStreamer) edit(text string) error {
+	return s.adapter.EditMessageText(s.chatID, s.messageID, text)
+}
+
+func (s *intMessageStreamer) remove() error {
+	return s.adapter.DeleteMessage(s.chatID, s.messageID)
+}
+
+// textMessageStreamer streams through a TextStreamingAdapter (Slack)
+type textMessageStreamer struct {
+	adapter   channels.TextStreamingAdapter
+	chatID    string
+	messageID string
+}
+
+func (s *textMessageStreamer) send(text string) error {
+	id, err := s.adapter.SendStreamingMessage(s.chatID, text)
+	s.messageID = id
+	return err
+}
+
+func (s *textMessageStreamer) edit(text string) error {
+	return s.adapter.EditStreamingMessage(s.chatID, s.messageID, text)
+}
+
+func (s *textMessageStreamer) remove() error {
+	return s.adapter.DeleteStreamingMessage(s.chatID, s.messageID)
+}
@@ -57,6 +57,7 @@ func (g *Gateway) SpawnSubAgentWithCallback(ctx context.Context, task, agentId,
 		log.Printf("[SubAgent] Completed: %s", session.Key)
 
 		// Store the result
+		persistToolTurns(g.sessions, session.Key, response)
 		_, _ = g.sessions.AddMessage(session.Key, "assistant", response.GetContent(), nil)
 
 		// Announce result if requested
@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"log"
+
+	"conduit/internal/ai"
+	"conduit/internal/sessions"
+)
+
+// persistToolTurns stores the tool calls and results from a response so later
+// requests replay the full exchange. Call it before saving the final
+// assistant reply to keep history in order.
+func persistToolTurns(store *sessions.Store, sessionKey string, resp ai.ConversationResponse) {
+	for _, turn := range ai.ResponseToolTurns(resp) {
+		var err error
+		switch {
+		case turn.Role == "assistant" && len(turn.ToolCalls) > 0:
+			_, err = store.AddToolCallMessage(sessionKey, turn.Content, turn.ToolCalls)
+		case turn.Role == "tool":
+			_, err = store.AddToolResultMessage(sessionKey, turn.ToolCallID, turn.Content)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Printf("[Gateway] Failed to save tool turn for session %s: %v", sessionKey, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"path/filepath"
+	"testing"
+
+	"conduit/internal/ai"
+	"conduit/internal/sessions"
+)
+
+func TestPersistToolTurns(t *testing.T) {
+	store, err := sessions.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	session, err := store.GetOrCreateSession("user1", "test")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	resp := &ai.SimpleConversationResponse{
+		Content: "done",
+		ToolTurns: []ai.ChatMessage{
+			{Role: "assistant", ToolCalls: []ai.ToolCall{{ID: "call_1", Name: "Read", Args: map[string]interface{}{"path": "a.txt"}}}},
+			{Role: "tool", ToolCallID: "call_1", Content: "contents"},
+		},
+	}
+	persistToolTurns(store, session.Key, resp)
+
+	messages, err := store.GetMessages(session.Key, 0)
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 stored tool turns, got %d", len(messages))
+	}
+	if len(messages[0].ToolCalls) != 1 || messages[0].ToolCalls[0].Name != "Read" {
+		t.Errorf("Unexpected tool call message: %+v", messages[0])
+	}
+	if messages[1].Role != "tool" || messages[1].ToolCallID != "call_1" {
+		t.Errorf("Unexpected tool result message: %+v", messages[1])
+	}
+
+	// Responses without tool turns store nothing
+	persistToolTurns(store, session.Key, &ai.SimpleConversationResponse{Content: "plain"})
+	if messages, _ := store.GetMessages(session.Key, 0); len(messages) != 2 {
+		t.Errorf("Expected no new messages, got %d", len(messages))
+	}
+}
+
+func TestConversationMessageCount(t *testing.T) {
+	messages := []sessions.Message{
+		{Role: "user", Content: "read a.txt"},
+		{Role: "assistant", ToolCalls: []sessions.ToolCall{{ID: "call_1", Name: "Read"}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "contents"},
+		{Role: "assistant", Content: "It says contents."},
+		{Role: "system", Content: "note"},
+	}
+	if got := conversationMessageCount(messages); got != 2 {
+		t.Errorf("Expected 2 user and assistant messages, got %d", got)
+	}
+}
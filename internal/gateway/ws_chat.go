@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"conduit/internal/ai"
+	"conduit/internal/auth"
 	"conduit/internal/channels"
 	"conduit/internal/sessions"
 	"conduit/internal/tools"
@@ -46,6 +47,42 @@ func (g *Gateway) sendErrorToClient(client *Client, sessionKey, code, message st
 	})
 }
 
+// authorizeClientMessage checks the client's token grants the scope a
+// message needs, sending an error if not. Session restrictions are checked
+// by the handlers once the session is known.
+func (g *Gateway) authorizeClientMessage(client *Client, msg interface{}) bool {
+	if client.Auth == nil {
+		return true
+	}
+
+	var scope, sessionKey string
+	switch m := msg.(type) {
+	case *protocol.ChatMessage:
+		scope, sessionKey = auth.ScopeChat, m.SessionKey
+	case *protocol.CommandMessage:
+		scope, sessionKey = auth.ScopeChat, m.SessionKey
+	case *protocol.SessionSwitch:
+		scope = auth.ScopeChat
+		if m.Action == "list" {
+			scope = auth.ScopeSessionsRead
+		}
+	default:
+		return true
+	}
+
+	if client.Auth.HasScope(scope) {
+		return true
+	}
+	log.Printf("[WS Auth] Client %s (%s) lacks scope %s", client.ID, client.Auth.ClientName, scope)
+	g.sendErrorToClient(client, sessionKey, "forbidden", fmt.Sprintf("Token lacks the %s scope", scope))
+	return false
+}
+
+// allowsSession reports whether the client's token may use session
+func (c *Client) allowsSession(session *sessions.Session) bool {
+	return c.Auth == nil || c.Auth.AllowsSession(session.Key, session.ChannelID)
+}
+
 // handleWebSocketChat processes a chat message from a WebSocket client
 func (g *Gateway) handleWebSocketChat(ctx context.Context, client *Client, msg *protocol.ChatMessage) {
 	log.Printf("WebSocket chat from %s: %d chars (session: %s)", client.ID, len(msg.Text), msg.SessionKey)
@@ -86,6 +123,11 @@ func (g *Gateway) handleWebSocketChat(ctx context.Context, client *Client, msg *
 		return
 	}
 
+	if !client.allowsSession(session) {
+		g.sendErrorToClient(client, session.Key, "forbidden", "Token does not grant access to this session")
+		return
+	}
+
 	// Update client's active session
 	client.SessionKey = session.Key
 
@@ -163,6 +205,12 @@ func (g *Gateway) handleWebSocketChat(ctx context.Context, client *Client, msg *
 		})
 	})
 
+	// Send approval requests for tool calls to the client
+	reqCtx = tools.WithApprovalPrompter(reqCtx, func(ctx context.Context, req tools.ApprovalRequest) error {
+		g.sendToClient(client, approvalRequestMessage(req, requestID))
+		return nil
+	})
+
 	// Get model override from session context
 	modelOverride := session.Context["model"]
 
@@ -278,7 +326,8 @@ func (g *Gateway) handleWebSocketChat(ctx context.Context, client *Client, msg *
 		SessionCost:      sessionCost,
 	})
 
-	// Save assistant message to session
+	// Save tool turns and assistant message to session
+	persistToolTurns(g.sessions, session.Key, convResponse)
 	if responseContent != "" {
 		_, err = g.sessions.AddMessage(session.Key, "assistant", responseContent, nil)
 		if err != nil {
@@ -302,6 +351,13 @@ func (g *Gateway) handleWebSocketCommand(ctx context.Context, client *Client, ms
 		commandText = "/" + commandText
 	}
 
+	if sessionKey != "" {
+		if session, err := g.sessions.GetSession(sessionKey); err == nil && !client.allowsSession(session) {
+			g.sendErrorToClient(client, sessionKey, "forbidden", "Token does not grant access to this session")
+			return
+		}
+	}
+
 	g.handleWebSocketCommandFromChat(ctx, client, sessionKey, commandText)
 }
 
@@ -355,6 +411,13 @@ func (g *Gateway) handleWebSocketCommandFromChat(ctx context.Context, client *Cl
 		messages, _ := g.sessions.GetMessages(session.Key, 1000)
 		sendResponse(formatStatusResponse(session, len(messages), g.ai.GetUsageTracker()))
 
+	case isApprovalCommand(text):
+		if sessionKey == "" {
+			sendResponse("No active session.")
+			return
+		}
+		sendResponse(answerApproval(g.approvals, sessionKey, text))
+
 	case text == "/help" || text == "/commands":
 		help := "Available Commands:\n\n" +
 			"/reset - Clear conversation history\n" +
@@ -363,6 +426,7 @@ func (g *Gateway) handleWebSocketCommandFromChat(ctx context.Context, client *Cl
 			"/model [alias] - View/switch model\n" +
 			"/context - Show context window usage\n" +
 			"/stop - Stop current operation\n" +
+			"/approve, /deny - Answer a tool approval request\n" +
 			"/quit - Exit TUI"
 		sendResponse(help)
 
@@ -470,6 +534,10 @@ func (g *Gateway) handleWebSocketSessionSwitch(client *Client, msg *protocol.Ses
 	case "create":
 		// Create a new session
 		channelID := fmt.Sprintf("tui_%s_%d", userID, time.Now().UnixNano())
+		if client.Auth != nil && (len(client.Auth.Sessions) > 0 || !client.Auth.AllowsChannel(channelID)) {
+			g.sendErrorToClient(client, "", "forbidden", "Token may not create sessions")
+			return
+		}
 		session, err := g.sessions.GetOrCreateSession(userID, channelID)
 		if err != nil {
 			g.sendErrorToClient(client, "", "session_error", fmt.Sprintf("Failed to create session: %v", err))
@@ -501,6 +569,10 @@ func (g *Gateway) handleWebSocketSessionSwitch(client *Client, msg *protocol.Ses
 			g.sendErrorToClient(client, "", "session_error", fmt.Sprintf("Session not found: %v", err))
 			return
 		}
+		if !client.allowsSession(session) {
+			g.sendErrorToClient(client, session.Key, "forbidden", "Token does not grant access to this session")
+			return
+		}
 
 		client.SessionKey = session.Key
 
@@ -508,6 +580,9 @@ func (g *Gateway) handleWebSocketSessionSwitch(client *Client, msg *protocol.Ses
 		messages, _ := g.sessions.GetMessages(session.Key, 100)
 		var history []protocol.MessageInfo
 		for _, m := range messages {
+			if m.IsToolTurn() {
+				continue
+			}
 			history = append(history, protocol.MessageInfo{
 				Role:      m.Role,
 				Content:   m.Content,
@@ -538,6 +613,10 @@ func (g *Gateway) handleWebSocketSessionSwitch(client *Client, msg *protocol.Ses
 
 		var sessionInfos []protocol.SessionInfo
 		for _, s := range sessions {
+			if !client.allowsSession(&s) {
+				continue
+			}
+
 			// Determine origin tag from channel ID
 			origin := "TUI"
 			if strings.HasPrefix(s.ChannelID, "telegram") {
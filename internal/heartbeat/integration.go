@@ -71,6 +71,7 @@ func (g *GatewayIntegration) ExecuteHeartbeat(ctx context.Context, job *schedule
 
 	log.Printf("[HeartbeatIntegration] Heartbeat completed: status=%s, actions=%d",
 		result.Status, len(result.Actions))
+	scheduler.ReportFromContext(ctx).SetOutput(fmt.Sprintf("[%s] %s", result.Status, result.Message))
 
 	// Report success to metrics collector (even if result processing fails)
 	if g.metricsCollector != nil {
@@ -323,7 +324,8 @@ func (g *GatewayIntegration) sendToTarget(ctx context.Context, target, message s
 		userID = target
 	}
 
-	return g.channelSender.SendMessage(ctx, channelID, userID, message, nil)
+	// The subject is used by channels that have one, e.g. email
+	return g.channelSender.SendMessage(ctx, channelID, userID, message, map[string]string{"subject": "Conduit heartbeat alert"})
 }
 
 // shouldSendOKStatus determines if HEARTBEAT_OK status should be sent to target
@@ -341,12 +343,18 @@ func (g *GatewayIntegration) shouldSendOKStatus(job *scheduler.Job) bool {
 
 // ScheduleHeartbeatJob schedules a new heartbeat job in the scheduler
 func (g *GatewayIntegration) ScheduleHeartbeatJob(schedule, target, model string, enabled bool) error {
+	return g.ScheduleHeartbeatJobWithID(fmt.Sprintf("heartbeat_%d", time.Now().UnixNano()), schedule, target, model, enabled)
+}
+
+// ScheduleHeartbeatJobWithID schedules a heartbeat job under a fixed ID, so
+// its owner can find and replace it later
+func (g *GatewayIntegration) ScheduleHeartbeatJobWithID(id, schedule, target, model string, enabled bool) error {
 	if g.scheduler == nil {
 		return fmt.Errorf("scheduler not available")
 	}
 
 	job := &scheduler.Job{
-		ID:       fmt.Sprintf("heartbeat_%d", time.Now().UnixNano()),
+		ID:       id,
 		Name:     "Heartbeat Task Execution",
 		Schedule: schedule,
 		Type:     scheduler.JobTypeGo,
@@ -370,10 +378,15 @@ type gatewayAIExecutor struct {
 
 // ExecutePrompt executes an AI prompt using the gateway's AI router
 func (g *gatewayAIExecutor) ExecutePrompt(ctx context.Context, session *sessions.Session, prompt, model string) (AIResponse, error) {
+	report := scheduler.ReportFromContext(ctx)
+	report.SetModel(model)
 	response, err := g.aiRouter.GenerateResponseWithTools(ctx, session, prompt, "", model)
 	if err != nil {
 		return nil, err
 	}
+	if usage := response.GetUsage(); usage != nil {
+		report.AddUsage(usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+	}
 
 	return &aiResponseAdapter{response: response}, nil
 }
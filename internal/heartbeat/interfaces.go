@@ -16,6 +16,9 @@ type HeartbeatIntegrationInterface interface {
 	// ScheduleHeartbeatJob schedules a new heartbeat job in the scheduler
 	ScheduleHeartbeatJob(schedule, target, model string, enabled bool) error
 
+	// ScheduleHeartbeatJobWithID schedules a heartbeat job under a fixed ID
+	ScheduleHeartbeatJobWithID(id, schedule, target, model string, enabled bool) error
+
 	// GetHeartbeatJobCount returns the number of active heartbeat jobs
 	GetHeartbeatJobCount() int
 
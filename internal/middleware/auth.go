@@ -29,6 +29,12 @@ type AuthInfo struct {
 	ExpiresAt *time.Time
 	// Metadata contains additional token metadata
 	Metadata map[string]string
+	// Scopes lists what the token may do (see auth.KnownScopes)
+	Scopes []string
+	// Channels limits the token to matching channel IDs (empty allows all)
+	Channels []string
+	// Sessions limits the token to matching session keys (empty allows all)
+	Sessions []string
 	// Source indicates where the token was extracted from
 	Source auth.TokenSource
 	// AuthenticatedAt is when this request was authenticated
@@ -44,6 +50,37 @@ func GetAuthInfo(ctx context.Context) *AuthInfo {
 	return nil
 }
 
+// HasScope reports whether the token grants scope
+func (a *AuthInfo) HasScope(scope string) bool {
+	return auth.HasScope(a.Scopes, scope)
+}
+
+// AllowsChannel reports whether the token may act on a channel
+func (a *AuthInfo) AllowsChannel(channelID string) bool {
+	return auth.MatchesRestriction(a.Channels, channelID)
+}
+
+// AllowsSession reports whether the token may use a session, which must
+// match both its session and channel restrictions
+func (a *AuthInfo) AllowsSession(sessionKey, channelID string) bool {
+	return auth.MatchesRestriction(a.Sessions, sessionKey) && a.AllowsChannel(channelID)
+}
+
+// newAuthInfo builds the context auth info for a validated token
+func newAuthInfo(tokenInfo *auth.TokenInfo, source auth.TokenSource) *AuthInfo {
+	return &AuthInfo{
+		TokenID:         tokenInfo.TokenID,
+		ClientName:      tokenInfo.ClientName,
+		ExpiresAt:       tokenInfo.ExpiresAt,
+		Metadata:        tokenInfo.Metadata,
+		Scopes:          tokenInfo.Scopes,
+		Channels:        tokenInfo.Channels,
+		Sessions:        tokenInfo.Sessions,
+		Source:          source,
+		AuthenticatedAt: time.Now(),
+	}
+}
+
 // IsAuthenticated checks if the request context contains valid auth info
 func IsAuthenticated(ctx context.Context) bool {
 	return GetAuthInfo(ctx) != nil
@@ -81,20 +118,31 @@ var (
 		Error:   "forbidden",
 		Message: "Access denied",
 	}
+	ErrInsufficientScope = AuthError{
+		Code:    http.StatusForbidden,
+		Error:   "insufficient_scope",
+		Message: "Token does not grant access to this resource",
+	}
 )
 
 // AuthMiddleware provides HTTP authentication middleware
 type AuthMiddleware struct {
-	storage     *auth.TokenStorage
-	extractor   *auth.TokenExtractor
-	skipPaths   map[string]bool
-	onAuthError func(r *http.Request, err AuthError)
+	storage      *auth.TokenStorage
+	extractor    *auth.TokenExtractor
+	skipPaths    map[string]bool
+	pathScopes   map[string]string
+	defaultScope string
+	onAuthError  func(r *http.Request, err AuthError)
 }
 
 // AuthMiddlewareConfig contains configuration for AuthMiddleware
 type AuthMiddlewareConfig struct {
 	// SkipPaths is a list of paths that don't require authentication
 	SkipPaths []string
+	// PathScopes maps a path to the scope a token needs to reach it
+	PathScopes map[string]string
+	// DefaultScope is required for paths not in PathScopes (empty: any valid token)
+	DefaultScope string
 	// OnAuthError is called when authentication fails (for logging/metrics)
 	OnAuthError func(r *http.Request, err AuthError)
 }
@@ -107,10 +155,12 @@ func NewAuthMiddleware(storage *auth.TokenStorage, config AuthMiddlewareConfig)
 	}
 
 	return &AuthMiddleware{
-		storage:     storage,
-		extractor:   auth.NewTokenExtractor(),
-		skipPaths:   skipPaths,
-		onAuthError: config.OnAuthError,
+		storage:      storage,
+		extractor:    auth.NewTokenExtractor(),
+		skipPaths:    skipPaths,
+		pathScopes:   config.PathScopes,
+		defaultScope: config.DefaultScope,
+		onAuthError:  config.OnAuthError,
 	}
 }
 
@@ -153,13 +203,14 @@ func (m *AuthMiddleware) Wrap(next http.Handler) http.Handler {
 		}
 
 		// Create auth info for context
-		authInfo := &AuthInfo{
-			TokenID:         tokenInfo.TokenID,
-			ClientName:      tokenInfo.ClientName,
-			ExpiresAt:       tokenInfo.ExpiresAt,
-			Metadata:        tokenInfo.Metadata,
-			Source:          extracted.Source,
-			AuthenticatedAt: time.Now(),
+		authInfo := newAuthInfo(tokenInfo, extracted.Source)
+
+		// Check the token grants the scope this path needs
+		if scope := m.requiredScope(r.URL.Path); scope != "" && !authInfo.HasScope(scope) {
+			log.Printf("[Auth] Insufficient scope: client=%s path=%s needs=%s",
+				tokenInfo.ClientName, r.URL.Path, scope)
+			m.sendError(w, r, ErrInsufficientScope)
+			return
 		}
 
 		// Log successful authentication (without token details)
@@ -172,6 +223,14 @@ func (m *AuthMiddleware) Wrap(next http.Handler) http.Handler {
 	})
 }
 
+// requiredScope returns the scope needed for path
+func (m *AuthMiddleware) requiredScope(path string) string {
+	if scope, ok := m.pathScopes[path]; ok {
+		return scope
+	}
+	return m.defaultScope
+}
+
 // WrapFunc wraps an http.HandlerFunc with authentication
 func (m *AuthMiddleware) WrapFunc(next http.HandlerFunc) http.HandlerFunc {
 	return m.Wrap(next).ServeHTTP
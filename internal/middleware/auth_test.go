@@ -42,7 +42,10 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 			expires_at DATETIME,
 			last_used_at DATETIME,
 			is_active BOOLEAN DEFAULT 1,
-			metadata TEXT DEFAULT '{}'
+			metadata TEXT DEFAULT '{}',
+			scopes TEXT NOT NULL DEFAULT '["admin"]',
+			channels TEXT NOT NULL DEFAULT '[]',
+			sessions TEXT NOT NULL DEFAULT '[]'
 		)
 	`)
 	if err != nil {
@@ -74,6 +77,21 @@ func createTestToken(t *testing.T, storage *auth.TokenStorage, clientName string
 	return resp.Token
 }
 
+// createScopedTestToken creates a token with the given scopes and returns the raw token
+func createScopedTestToken(t *testing.T, storage *auth.TokenStorage, clientName string, scopes ...string) string {
+	t.Helper()
+
+	resp, err := storage.CreateToken(auth.CreateTokenRequest{
+		ClientName: clientName,
+		Scopes:     scopes,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test token: %v", err)
+	}
+
+	return resp.Token
+}
+
 func TestAuthMiddleware_ValidToken(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -558,3 +576,82 @@ func TestAuthMiddleware_SecurityHeaders(t *testing.T) {
 		t.Errorf("Content-Type = %q, want %q", h, "application/json")
 	}
 }
+
+func TestAuthMiddleware_Scopes(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	storage := auth.NewTokenStorage(db)
+	chatToken := createScopedTestToken(t, storage, "chat-client", auth.ScopeChat)
+	vectorToken := createScopedTestToken(t, storage, "indexer", auth.ScopeVectorWrite)
+	adminToken := createScopedTestToken(t, storage, "admin", auth.ScopeAdmin)
+
+	m := NewAuthMiddleware(storage, AuthMiddlewareConfig{
+		PathScopes: map[string]string{
+			"/api/vector/search": auth.ScopeVectorRead,
+			"/api/vector/delete": auth.ScopeVectorWrite,
+		},
+		DefaultScope: auth.ScopeAdmin,
+	})
+	handler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name  string
+		token string
+		path  string
+		want  int
+	}{
+		{"chat token denied vector search", chatToken, "/api/vector/search", http.StatusForbidden},
+		{"write scope covers read", vectorToken, "/api/vector/search", http.StatusOK},
+		{"write scope allows delete", vectorToken, "/api/vector/delete", http.StatusOK},
+		{"unlisted path needs default scope", vectorToken, "/api/test/message", http.StatusForbidden},
+		{"admin reaches everything", adminToken, "/api/test/message", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("Status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusForbidden {
+				var body map[string]string
+				json.NewDecoder(rec.Body).Decode(&body)
+				if body["error"] != "insufficient_scope" {
+					t.Errorf("error = %q, want %q", body["error"], "insufficient_scope")
+				}
+			}
+		})
+	}
+}
+
+func TestAuthInfo_Restrictions(t *testing.T) {
+	info := &AuthInfo{
+		Scopes:   []string{auth.ScopeChat},
+		Channels: []string{"tui_*"},
+		Sessions: []string{"sess_a", "sess_b"},
+	}
+
+	if !info.AllowsChannel("tui_alice") || info.AllowsChannel("telegram") {
+		t.Error("Channel restriction not applied")
+	}
+	if !info.AllowsSession("sess_a", "tui_alice") {
+		t.Error("Expected allowed session on allowed channel to pass")
+	}
+	if info.AllowsSession("sess_c", "tui_alice") {
+		t.Error("Expected session outside the restriction to be denied")
+	}
+	if info.AllowsSession("sess_a", "telegram") {
+		t.Error("Expected session on a disallowed channel to be denied")
+	}
+	if !(&AuthInfo{}).AllowsSession("any", "any") {
+		t.Error("Expected unrestricted token to allow every session")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"conduit/internal/ratelimit"
@@ -58,6 +59,7 @@ func DefaultRateLimitConfig() RateLimitConfig {
 
 // RateLimitMiddleware provides HTTP rate limiting
 type RateLimitMiddleware struct {
+	mu                   sync.RWMutex // Guards the limiters and config, which Update replaces
 	anonymousLimiter     *ratelimit.SlidingWindow
 	authenticatedLimiter *ratelimit.SlidingWindow
 	config               RateLimitConfig
@@ -72,48 +74,83 @@ type RateLimitMiddlewareConfig struct {
 
 // NewRateLimitMiddleware creates a new rate limiting middleware
 func NewRateLimitMiddleware(config RateLimitMiddlewareConfig) *RateLimitMiddleware {
-	if !config.Config.Enabled {
-		// Return disabled middleware that allows all requests
-		return &RateLimitMiddleware{
-			config:              config.Config,
-			onRateLimitExceeded: config.OnRateLimitExceeded,
-		}
+	m := &RateLimitMiddleware{
+		config:              config.Config,
+		onRateLimitExceeded: config.OnRateLimitExceeded,
+	}
+	m.anonymousLimiter, m.authenticatedLimiter = newLimiters(config.Config)
+	return m
+}
+
+// newLimiters creates the sliding window limiters for config, or nils when
+// rate limiting is disabled
+func newLimiters(config RateLimitConfig) (anonymous, authenticated *ratelimit.SlidingWindow) {
+	if !config.Enabled {
+		// Disabled middleware allows all requests
+		return nil, nil
 	}
 
 	// Create sliding window limiters
-	anonymousWindow := time.Duration(config.Config.Anonymous.WindowSeconds) * time.Second
-	authenticatedWindow := time.Duration(config.Config.Authenticated.WindowSeconds) * time.Second
-	cleanupInterval := time.Duration(config.Config.CleanupIntervalSeconds) * time.Second
+	anonymousWindow := time.Duration(config.Anonymous.WindowSeconds) * time.Second
+	authenticatedWindow := time.Duration(config.Authenticated.WindowSeconds) * time.Second
+	cleanupInterval := time.Duration(config.CleanupIntervalSeconds) * time.Second
 
 	// Default cleanup interval to 60 seconds if not specified
 	if cleanupInterval <= 0 {
 		cleanupInterval = 60 * time.Second
 	}
 
-	anonymousLimiter := ratelimit.NewSlidingWindow(
+	anonymous = ratelimit.NewSlidingWindow(
 		anonymousWindow,
-		config.Config.Anonymous.MaxRequests,
+		config.Anonymous.MaxRequests,
 		cleanupInterval,
 	)
 
-	authenticatedLimiter := ratelimit.NewSlidingWindow(
+	authenticated = ratelimit.NewSlidingWindow(
 		authenticatedWindow,
-		config.Config.Authenticated.MaxRequests,
+		config.Authenticated.MaxRequests,
 		cleanupInterval,
 	)
 
-	return &RateLimitMiddleware{
-		anonymousLimiter:     anonymousLimiter,
-		authenticatedLimiter: authenticatedLimiter,
-		config:               config.Config,
-		onRateLimitExceeded:  config.OnRateLimitExceeded,
+	return anonymous, authenticated
+}
+
+// Update replaces the rate limits while the server runs. Request counts
+// start over under the new limits.
+func (m *RateLimitMiddleware) Update(config RateLimitConfig) {
+	anonymous, authenticated := newLimiters(config)
+
+	m.mu.Lock()
+	oldAnonymous, oldAuthenticated := m.anonymousLimiter, m.authenticatedLimiter
+	m.anonymousLimiter, m.authenticatedLimiter = anonymous, authenticated
+	m.config = config
+	m.mu.Unlock()
+
+	if oldAnonymous != nil {
+		oldAnonymous.Stop()
+	}
+	if oldAuthenticated != nil {
+		oldAuthenticated.Stop()
 	}
+	log.Printf("[RateLimit] Rate limits updated (enabled: %v)", config.Enabled)
+}
+
+// Config returns the current rate limiting configuration
+func (m *RateLimitMiddleware) Config() RateLimitConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config
 }
 
 // Wrap wraps an http.Handler with rate limiting
 func (m *RateLimitMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !m.config.Enabled {
+		m.mu.RLock()
+		config := m.config
+		anonymousLimiter, authenticatedLimiter := m.anonymousLimiter, m.authenticatedLimiter
+		m.mu.RUnlock()
+
+		if !config.Enabled {
 			// Rate limiting disabled, pass through
 			next.ServeHTTP(w, r)
 			return
@@ -133,16 +170,16 @@ func (m *RateLimitMiddleware) Wrap(next http.Handler) http.Handler {
 			// Authenticated request - use client-based limiting
 			identifier = authInfo.ClientName
 			isAnonymous = false
-			allowed, remaining, resetTime, retryAfter = m.authenticatedLimiter.Allow(identifier)
+			allowed, remaining, resetTime, retryAfter = authenticatedLimiter.Allow(identifier)
 		} else {
 			// Anonymous request - use IP-based limiting
 			identifier = extractClientIP(r)
 			isAnonymous = true
-			allowed, remaining, resetTime, retryAfter = m.anonymousLimiter.Allow(identifier)
+			allowed, remaining, resetTime, retryAfter = anonymousLimiter.Allow(identifier)
 		}
 
 		// Add rate limit headers to response
-		m.setRateLimitHeaders(w, allowed, remaining, resetTime, retryAfter, isAnonymous)
+		setRateLimitHeaders(w, config, allowed, remaining, resetTime, retryAfter, isAnonymous)
 
 		if !allowed {
 			// Rate limit exceeded
@@ -171,13 +208,13 @@ func (m *RateLimitMiddleware) WrapFunc(next http.HandlerFunc) http.HandlerFunc {
 }
 
 // setRateLimitHeaders sets standard rate limiting HTTP headers
-func (m *RateLimitMiddleware) setRateLimitHeaders(w http.ResponseWriter, allowed bool, remaining int, resetTime time.Time, retryAfter int, isAnonymous bool) {
+func setRateLimitHeaders(w http.ResponseWriter, config RateLimitConfig, allowed bool, remaining int, resetTime time.Time, retryAfter int, isAnonymous bool) {
 	// Determine limit based on request type
 	var limit int
 	if isAnonymous {
-		limit = m.config.Anonymous.MaxRequests
+		limit = config.Anonymous.MaxRequests
 	} else {
-		limit = m.config.Authenticated.MaxRequests
+		limit = config.Authenticated.MaxRequests
 	}
 
 	// Set standard rate limit headers
@@ -295,6 +332,8 @@ func getIdentifierType(isAnonymous bool) string {
 
 // Stop stops the rate limiting middleware and cleans up resources
 func (m *RateLimitMiddleware) Stop() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	if m.anonymousLimiter != nil {
 		m.anonymousLimiter.Stop()
 	}
@@ -305,6 +344,8 @@ func (m *RateLimitMiddleware) Stop() {
 
 // GetStats returns statistics about rate limiting
 func (m *RateLimitMiddleware) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	if !m.config.Enabled {
 		return map[string]interface{}{
 			"enabled": false,
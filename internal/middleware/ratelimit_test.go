@@ -540,3 +540,50 @@ func TestSanitizeIdentifier(t *testing.T) {
 		}
 	}
 }
+
+func TestRateLimitMiddleware_Update(t *testing.T) {
+	config := DefaultRateLimitConfig()
+	config.Anonymous.MaxRequests = 1
+
+	middleware := NewRateLimitMiddleware(RateLimitMiddlewareConfig{Config: config})
+	defer middleware.Stop()
+
+	handler := middleware.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "192.168.1.100:12345"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	serve()
+	if w := serve(); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected second request to be limited, got %d", w.Code)
+	}
+
+	// Raising the limit applies to the existing handler
+	config.Anonymous.MaxRequests = 5
+	middleware.Update(config)
+	w := serve()
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected request to pass after update, got %d", w.Code)
+	}
+	if limit := w.Header().Get("X-RateLimit-Limit"); limit != "5" {
+		t.Errorf("Expected X-RateLimit-Limit: 5, got: %s", limit)
+	}
+
+	// Disabling removes the limit entirely
+	config.Enabled = false
+	middleware.Update(config)
+	for i := 0; i < 10; i++ {
+		if w := serve(); w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "" {
+			t.Fatalf("Expected unlimited requests when disabled, got %d", w.Code)
+		}
+	}
+	if middleware.Config().Enabled {
+		t.Error("Expected Config to reflect the update")
+	}
+}
@@ -84,13 +84,13 @@ func (a *WebSocketAuthenticator) Authenticate(r *http.Request) WebSocketAuthResu
 	}
 
 	// Build auth info
-	authInfo := &AuthInfo{
-		TokenID:         tokenInfo.TokenID,
-		ClientName:      tokenInfo.ClientName,
-		ExpiresAt:       tokenInfo.ExpiresAt,
-		Metadata:        tokenInfo.Metadata,
-		Source:          extracted.Source,
-		AuthenticatedAt: time.Now(),
+	authInfo := newAuthInfo(tokenInfo, extracted.Source)
+
+	// The WebSocket is for chatting and browsing sessions; tokens without
+	// either scope (e.g. vector-only) can't connect
+	if !authInfo.HasScope(auth.ScopeChat) && !authInfo.HasScope(auth.ScopeSessionsRead) {
+		log.Printf("[WS Auth] Insufficient scope: client=%s", tokenInfo.ClientName)
+		return WebSocketAuthResult{Error: &ErrInsufficientScope}
 	}
 
 	log.Printf("[WS Auth] Connection authenticated: client=%s source=%s",
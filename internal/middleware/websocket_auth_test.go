@@ -40,7 +40,10 @@ func setupWSTestDB(t *testing.T) (*sql.DB, func()) {
 			expires_at DATETIME,
 			last_used_at DATETIME,
 			is_active BOOLEAN DEFAULT 1,
-			metadata TEXT DEFAULT '{}'
+			metadata TEXT DEFAULT '{}',
+			scopes TEXT NOT NULL DEFAULT '["admin"]',
+			channels TEXT NOT NULL DEFAULT '[]',
+			sessions TEXT NOT NULL DEFAULT '[]'
 		)
 	`)
 	if err != nil {
@@ -144,6 +147,39 @@ func TestWebSocketAuthenticator_ValidToken(t *testing.T) {
 	}
 }
 
+func TestWebSocketAuthenticator_Scopes(t *testing.T) {
+	db, cleanup := setupWSTestDB(t)
+	defer cleanup()
+
+	storage := auth.NewTokenStorage(db)
+	authenticator := NewWebSocketAuthenticator(storage)
+
+	for _, tt := range []struct {
+		scopes   []string
+		wantAuth bool
+	}{
+		{[]string{auth.ScopeChat}, true},
+		{[]string{auth.ScopeSessionsRead}, true},
+		{[]string{auth.ScopeVectorWrite}, false},
+	} {
+		resp, err := storage.CreateToken(auth.CreateTokenRequest{ClientName: "ws-client", Scopes: tt.scopes})
+		if err != nil {
+			t.Fatalf("Failed to create test token: %v", err)
+		}
+
+		req := httptest.NewRequest("GET", "/ws", nil)
+		req.Header.Set("Authorization", "Bearer "+resp.Token)
+		result := authenticator.Authenticate(req)
+
+		if result.Authenticated != tt.wantAuth {
+			t.Errorf("scopes %v: Authenticated = %v, want %v", tt.scopes, result.Authenticated, tt.wantAuth)
+		}
+		if !tt.wantAuth && (result.Error == nil || result.Error.Code != http.StatusForbidden) {
+			t.Errorf("scopes %v: expected a forbidden error, got %+v", tt.scopes, result.Error)
+		}
+	}
+}
+
 func TestWebSocketAuthenticator_InvalidToken(t *testing.T) {
 	db, cleanup := setupWSTestDB(t)
 	defer cleanup()
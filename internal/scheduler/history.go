@@ -0,0 +1,292 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Run states
+const (
+	RunRunning     = "running"     // Still executing
+	RunSucceeded   = "success"     // The executor returned no error
+	RunFailed      = "failed"      // The executor returned an error
+	RunTimedOut    = "timeout"     // The attempt ran longer than the job's timeout
+	RunSkipped     = "skipped"     // Not run because the previous run was still going
+	RunMissed      = "missed"      // Due while the gateway was down and not made up
+	RunInterrupted = "interrupted" // The gateway stopped before the run finished
+)
+
+// What started a run
+const (
+	TriggerSchedule = "schedule" // The job's cron schedule fired
+	TriggerManual   = "manual"   // RunNow was called
+	TriggerMisfire  = "misfire"  // Making up a run missed while the gateway was down
+)
+
+// History defaults, used when HistoryOptions leaves a field zero
+const (
+	DefaultHistoryMaxRuns   = 100
+	DefaultHistoryRetention = 30 * 24 * time.Hour
+)
+
+// MaxOutputExcerpt is the longest output kept for a run, in bytes
+const MaxOutputExcerpt = 2000
+
+// HistoryOptions controls how long run history is kept
+type HistoryOptions struct {
+	MaxRunsPerJob int           // Runs kept per job; older runs are deleted
+	Retention     time.Duration // Runs older than this are deleted
+}
+
+// Run is one recorded execution of a job
+type Run struct {
+	ID               int64      `json:"id"`
+	JobID            string     `json:"job_id"`
+	JobName          string     `json:"job_name,omitempty"`
+	TriggeredBy      string     `json:"triggered_by"`
+	Attempt          int        `json:"attempt"` // 1 for the first attempt, 0 for skipped runs
+	Status           string     `json:"status"`
+	Error            string     `json:"error,omitempty"`
+	Model            string     `json:"model,omitempty"`
+	PromptTokens     int        `json:"prompt_tokens"`
+	CompletionTokens int        `json:"completion_tokens"`
+	TotalTokens      int        `json:"total_tokens"`
+	Output           string     `json:"output,omitempty"` // First MaxOutputExcerpt bytes of the output
+	StartedAt        time.Time  `json:"started_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	DurationMs       int64      `json:"duration_ms"`
+}
+
+// Duration returns how long the run took
+func (r *Run) Duration() time.Duration {
+	return time.Duration(r.DurationMs) * time.Millisecond
+}
+
+// RunReport collects what an executor reports about the run in progress.
+// Its methods do nothing on a nil report, so executors can call them
+// whether or not history is enabled.
+type RunReport struct {
+	mu               sync.Mutex
+	model            string
+	promptTokens     int
+	completionTokens int
+	totalTokens      int
+	output           string
+}
+
+type runReportKey struct{}
+
+// ReportFromContext returns the report for the run executing under ctx, or
+// nil outside a scheduled run
+func ReportFromContext(ctx context.Context) *RunReport {
+	report, _ := ctx.Value(runReportKey{}).(*RunReport)
+	return report
+}
+
+func withRunReport(ctx context.Context, report *RunReport) context.Context {
+	return context.WithValue(ctx, runReportKey{}, report)
+}
+
+// SetModel records the model the run used
+func (r *RunReport) SetModel(model string) {
+	if r == nil || model == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.model = model
+}
+
+// AddUsage adds the token usage of one model call to the run
+func (r *RunReport) AddUsage(promptTokens, completionTokens, totalTokens int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.promptTokens += promptTokens
+	r.completionTokens += completionTokens
+	r.totalTokens += totalTokens
+}
+
+// SetOutput records what the run produced
+func (r *RunReport) SetOutput(output string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.output = output
+}
+
+// apply copies the report into run
+func (r *RunReport) apply(run *Run) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run.Model = r.model
+	run.PromptTokens = r.promptTokens
+	run.CompletionTokens = r.completionTokens
+	run.TotalTokens = r.totalTokens
+	run.Output = excerpt(r.output, MaxOutputExcerpt)
+}
+
+// excerpt truncates s to at most max bytes without splitting a character
+func excerpt(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// History stores job runs in SQLite
+type History struct {
+	db   *sql.DB
+	opts HistoryOptions
+}
+
+// NewHistory creates a run history over a database migrated with the
+// job_runs table
+func NewHistory(db *sql.DB, opts HistoryOptions) *History {
+	if opts.MaxRunsPerJob <= 0 {
+		opts.MaxRunsPerJob = DefaultHistoryMaxRuns
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultHistoryRetention
+	}
+	return &History{db: db, opts: opts}
+}
+
+// Options returns the effective retention options
+func (h *History) Options() HistoryOptions {
+	return h.opts
+}
+
+const runColumns = `id, job_id, job_name, triggered_by, attempt, status, error, model, prompt_tokens,
+	completion_tokens, total_tokens, output, started_at, finished_at, duration_ms`
+
+// Start records the start of a run and sets its ID
+func (h *History) Start(run *Run) error {
+	run.Status = RunRunning
+	result, err := h.db.Exec(`
+		INSERT INTO job_runs (job_id, job_name, triggered_by, attempt, status, started_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, run.JobID, run.JobName, run.TriggeredBy, run.Attempt, run.Status, run.StartedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record job run: %w", err)
+	}
+	run.ID, err = result.LastInsertId()
+	return err
+}
+
+// Finish records the outcome of a started run
+func (h *History) Finish(run *Run) error {
+	var finishedAt interface{}
+	if run.FinishedAt != nil {
+		finishedAt = run.FinishedAt.UTC()
+	}
+	_, err := h.db.Exec(`
+		UPDATE job_runs SET status = ?, error = ?, model = ?, prompt_tokens = ?, completion_tokens = ?,
+			total_tokens = ?, output = ?, finished_at = ?, duration_ms = ?
+		WHERE id = ?
+	`, run.Status, run.Error, run.Model, run.PromptTokens, run.CompletionTokens,
+		run.TotalTokens, run.Output, finishedAt, run.DurationMs, run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update job run %d: %w", run.ID, err)
+	}
+	return nil
+}
+
+// MarkInterrupted marks runs still recorded as running, left behind when
+// the gateway stopped mid-run, as interrupted
+func (h *History) MarkInterrupted() (int64, error) {
+	result, err := h.db.Exec(`UPDATE job_runs SET status = ? WHERE status = ?`, RunInterrupted, RunRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark interrupted job runs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Get returns a run by ID
+func (h *History) Get(id int64) (*Run, error) {
+	runs, err := h.query(`SELECT `+runColumns+` FROM job_runs WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("job run %d not found", id)
+	}
+	return runs[0], nil
+}
+
+// List returns a job's runs, newest first. A limit of zero returns all runs.
+func (h *History) List(jobID string, limit int) ([]*Run, error) {
+	query := `SELECT ` + runColumns + ` FROM job_runs WHERE job_id = ? ORDER BY started_at DESC, id DESC`
+	args := []interface{}{jobID}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	return h.query(query, args...)
+}
+
+// Prune deletes runs older than the retention period and all but the
+// newest MaxRunsPerJob runs of each job
+func (h *History) Prune(now time.Time) (int64, error) {
+	expired, err := h.db.Exec(`DELETE FROM job_runs WHERE started_at < ? AND status != ?`,
+		now.Add(-h.opts.Retention).UTC(), RunRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune job runs: %w", err)
+	}
+	deleted, _ := expired.RowsAffected()
+
+	excess, err := h.db.Exec(`
+		DELETE FROM job_runs WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY job_id ORDER BY started_at DESC, id DESC) AS n
+				FROM job_runs
+			) WHERE n > ?
+		)
+	`, h.opts.MaxRunsPerJob)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to prune job runs: %w", err)
+	}
+	n, _ := excess.RowsAffected()
+	return deleted + n, nil
+}
+
+// query runs a select over runColumns and decodes the rows
+func (h *History) query(query string, args ...interface{}) ([]*Run, error) {
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*Run
+	for rows.Next() {
+		var r Run
+		var jobName, runErr, model, output sql.NullString
+		var finishedAt sql.NullTime
+		if err := rows.Scan(&r.ID, &r.JobID, &jobName, &r.TriggeredBy, &r.Attempt, &r.Status, &runErr, &model, &r.PromptTokens,
+			&r.CompletionTokens, &r.TotalTokens, &output, &r.StartedAt, &finishedAt, &r.DurationMs); err != nil {
+			return nil, fmt.Errorf("failed to scan job run: %w", err)
+		}
+		r.JobName = jobName.String
+		r.Error = runErr.String
+		r.Model = model.String
+		r.Output = output.String
+		if finishedAt.Valid {
+			r.FinishedAt = &finishedAt.Time
+		}
+		runs = append(runs, &r)
+	}
+	return runs, rows.Err()
+}
@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"conduit/internal/database"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestHistory(t *testing.T, opts HistoryOptions) *History {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := database.ConfigureDatabase(db); err != nil {
+		t.Fatalf("failed to configure database: %v", err)
+	}
+	return NewHistory(db, opts)
+}
+
+func TestScheduler_RecordsRuns(t *testing.T) {
+	history := newTestHistory(t, HistoryOptions{})
+
+	fail := false
+	s := New(t.TempDir(), func(ctx context.Context, job *Job) error {
+		report := ReportFromContext(ctx)
+		report.SetModel("claude-haiku")
+		report.AddUsage(100, 20, 120)
+		report.AddUsage(50, 10, 60)
+		report.SetOutput("Daily briefing: " + strings.Repeat("é", MaxOutputExcerpt))
+		if fail {
+			return errors.New("provider unavailable")
+		}
+		return nil
+	})
+	s.SetHistory(history)
+
+	job := &Job{ID: "briefing", Name: "Daily briefing", Schedule: "0 9 * * *", Type: JobTypeGo, Enabled: true}
+	s.executeJob(job, TriggerSchedule)
+	fail = true
+	s.executeJob(job, TriggerManual)
+
+	runs, err := s.JobHistory("briefing", 0)
+	if err != nil {
+		t.Fatalf("JobHistory failed: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+
+	failed, succeeded := runs[0], runs[1]
+	if failed.Status != RunFailed || failed.Error != "provider unavailable" || failed.TriggeredBy != TriggerManual {
+		t.Errorf("unexpected failed run: %+v", failed)
+	}
+	if succeeded.Status != RunSucceeded || succeeded.Error != "" || succeeded.TriggeredBy != TriggerSchedule {
+		t.Errorf("unexpected successful run: %+v", succeeded)
+	}
+	if succeeded.JobName != "Daily briefing" || succeeded.Model != "claude-haiku" {
+		t.Errorf("expected job name and model to be recorded, got %+v", succeeded)
+	}
+	if succeeded.PromptTokens != 150 || succeeded.CompletionTokens != 30 || succeeded.TotalTokens != 180 {
+		t.Errorf("expected token usage to add up, got %d/%d/%d",
+			succeeded.PromptTokens, succeeded.CompletionTokens, succeeded.TotalTokens)
+	}
+	if succeeded.FinishedAt == nil || succeeded.FinishedAt.Before(succeeded.StartedAt) {
+		t.Errorf("expected finish time after start time, got %v - %v", succeeded.StartedAt, succeeded.FinishedAt)
+	}
+	if len(succeeded.Output) > MaxOutputExcerpt || !strings.HasPrefix(succeeded.Output, "Daily briefing: é") {
+		t.Errorf("expected output excerpt of at most %d bytes, got %d", MaxOutputExcerpt, len(succeeded.Output))
+	}
+	if !strings.HasSuffix(succeeded.Output, "é") {
+		t.Error("expected the excerpt not to split a character")
+	}
+}
+
+func TestHistory_MarkInterrupted(t *testing.T) {
+	history := newTestHistory(t, HistoryOptions{})
+
+	run := &Run{JobID: "briefing", TriggeredBy: TriggerSchedule, StartedAt: time.Now()}
+	if err := history.Start(run); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	n, err := history.MarkInterrupted()
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 interrupted run, got %d (%v)", n, err)
+	}
+	got, err := history.Get(run.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != RunInterrupted || got.FinishedAt != nil {
+		t.Errorf("unexpected interrupted run: %+v", got)
+	}
+}
+
+func TestHistory_Prune(t *testing.T) {
+	history := newTestHistory(t, HistoryOptions{MaxRunsPerJob: 3, Retention: 24 * time.Hour})
+
+	now := time.Now()
+	record := func(jobID string, age time.Duration) {
+		t.Helper()
+		run := &Run{JobID: jobID, TriggeredBy: TriggerSchedule, StartedAt: now.Add(-age)}
+		if err := history.Start(run); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		run.Status = RunSucceeded
+		if err := history.Finish(run); err != nil {
+			t.Fatalf("Finish failed: %v", err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		record("frequent", time.Duration(i)*time.Minute)
+	}
+	record("daily", time.Hour)
+	record("daily", 48*time.Hour)
+
+	deleted, err := history.Prune(now)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("expected 3 runs deleted, got %d", deleted)
+	}
+
+	frequent, _ := history.List("frequent", 0)
+	if len(frequent) != 3 {
+		t.Fatalf("expected the newest 3 runs to be kept, got %d", len(frequent))
+	}
+	if !frequent[0].StartedAt.After(frequent[2].StartedAt) {
+		t.Error("expected runs newest first")
+	}
+
+	daily, _ := history.List("daily", 0)
+	if len(daily) != 1 {
+		t.Errorf("expected runs past the retention period to be deleted, got %d", len(daily))
+	}
+}
@@ -30,6 +30,9 @@ type SchedulerInterface interface {
 	// RunNow executes a job immediately
 	RunNow(jobID string) error
 
+	// JobHistory returns a job's recorded runs, newest first
+	JobHistory(jobID string, limit int) ([]*Run, error)
+
 	// Status returns scheduler status
 	Status() map[string]interface{}
 }
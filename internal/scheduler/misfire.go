@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Misfire policies for runs a job missed while the gateway was down
+const (
+	MisfireSkip    = "skip"     // Record the missed runs without running them
+	MisfireRunOnce = "run_once" // Run once on start, however many runs were missed
+	MisfireRunAll  = "run_all"  // Run every missed run, oldest first
+)
+
+// Misfire defaults
+const (
+	DefaultMisfireWindow = 24 * time.Hour
+	MaxMisfireRuns       = 100 // Most runs made up by run_all
+)
+
+// scheduleParser parses Go job schedules the way the cron runner does
+var scheduleParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// location returns the job's time zone, or def when it has none
+func (j *Job) location(def *time.Location) (*time.Location, error) {
+	if j.Timezone == "" {
+		return def, nil
+	}
+	loc, err := time.LoadLocation(j.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%s': %w", j.Timezone, err)
+	}
+	return loc, nil
+}
+
+// schedule parses the job's cron expression in its time zone, or in def
+// when it has none
+func (j *Job) schedule(def *time.Location) (cron.Schedule, error) {
+	schedule, err := scheduleParser.Parse(j.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := j.location(def)
+	if err != nil {
+		return nil, err
+	}
+	if spec, ok := schedule.(*cron.SpecSchedule); ok {
+		spec.Location = loc
+	}
+	return schedule, nil
+}
+
+// misfirePolicy returns the job's misfire policy
+func (j *Job) misfirePolicy() string {
+	if j.MisfirePolicy == "" {
+		return MisfireSkip
+	}
+	return j.MisfirePolicy
+}
+
+// missedRuns returns the times the job was due before now, counting from
+// its recorded next run (or its last run, or its creation) and leaving out
+// runs older than its misfire window
+func (j *Job) missedRuns(schedule cron.Schedule, now time.Time) []time.Time {
+	var from time.Time
+	switch {
+	case j.NextRun != nil:
+		from = j.NextRun.Add(-time.Nanosecond)
+	case j.LastRun != nil:
+		from = *j.LastRun
+	default:
+		from = j.CreatedAt
+	}
+	if from.IsZero() {
+		return nil
+	}
+
+	windowStart := now.Add(-parseDurationOr(j.MisfireWindow, DefaultMisfireWindow))
+	var missed []time.Time
+	for t := schedule.Next(from); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		if t.Before(windowStart) {
+			continue
+		}
+		missed = append(missed, t)
+		// Keep only the newest runs once there are too many to make up
+		if len(missed) > MaxMisfireRuns {
+			missed = missed[1:]
+		}
+	}
+	return missed
+}
+
+// catchUp applies the job's misfire policy to the runs it missed while the
+// gateway was down, returning how many to make up. Called by Start before
+// the job is scheduled again, which moves NextRun past the missed runs.
+func (s *Scheduler) catchUp(job *Job, now time.Time) int {
+	schedule, err := job.schedule(s.location)
+	if err != nil {
+		return 0
+	}
+	missed := job.missedRuns(schedule, now)
+	if len(missed) == 0 {
+		return 0
+	}
+
+	runs := len(missed)
+	switch job.misfirePolicy() {
+	case MisfireRunOnce:
+		runs = 1
+	case MisfireRunAll:
+	default:
+		log.Printf("[Scheduler] Job %s missed %d runs while the gateway was down, skipping them", job.ID, len(missed))
+		s.recordNotRun(job, TriggerMisfire, RunMissed,
+			fmt.Sprintf("missed %d runs, last due %s", len(missed), missed[len(missed)-1].Format(time.RFC3339)))
+		return 0
+	}
+	if job.OneShot {
+		runs = 1
+	}
+
+	log.Printf("[Scheduler] Job %s missed %d runs while the gateway was down, running %d now", job.ID, len(missed), runs)
+	return runs
+}
+
+// runMissed makes up a job's missed runs one after the other
+func (s *Scheduler) runMissed(job *Job, runs int) {
+	for i := 0; i < runs && s.ctx.Err() == nil; i++ {
+		s.executeJob(job, TriggerMisfire)
+	}
+}
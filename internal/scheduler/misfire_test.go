@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJob_ScheduleInTimezone(t *testing.T) {
+	job := &Job{Schedule: "0 0 9 * * *", Timezone: "America/New_York"}
+	schedule, err := job.schedule(time.Local)
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+
+	// 12:00 UTC on a summer day is 08:00 in New York (EDT)
+	from := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 7, 1, 13, 0, 0, 0, time.UTC)
+	if next := schedule.Next(from); !next.Equal(want) {
+		t.Errorf("expected next run at %v, got %v", want, next.UTC())
+	}
+
+	// In winter 09:00 EST is 14:00 UTC
+	from = time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	want = time.Date(2026, 1, 15, 14, 0, 0, 0, time.UTC)
+	if next := schedule.Next(from); !next.Equal(want) {
+		t.Errorf("expected next run at %v, got %v", want, next.UTC())
+	}
+}
+
+func TestJob_MissedRuns(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 30, 0, 0, time.UTC)
+	nextRun := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		job    *Job
+		missed int
+	}{
+		{"from next run", &Job{Schedule: "0 0 * * * *", NextRun: &nextRun}, 4},
+		{"within window", &Job{Schedule: "0 0 * * * *", NextRun: &nextRun, MisfireWindow: "90m"}, 2},
+		{"not yet due", &Job{Schedule: "0 0 * * * *", NextRun: &[]time.Time{now.Add(time.Minute)}[0]}, 0},
+		{"from last run", &Job{Schedule: "0 0 * * * *", LastRun: &[]time.Time{now.Add(-150 * time.Minute)}[0]}, 2},
+		{"never scheduled", &Job{Schedule: "0 0 * * * *"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := tt.job.schedule(time.Local)
+			if err != nil {
+				t.Fatalf("failed to parse schedule: %v", err)
+			}
+			if missed := tt.job.missedRuns(schedule, now); len(missed) != tt.missed {
+				t.Errorf("expected %d missed runs, got %d: %v", tt.missed, len(missed), missed)
+			}
+		})
+	}
+}
+
+// startWithMissedRuns starts a scheduler whose saved hourly job last fired
+// three runs ago, returning the number of executions after it settles
+func startWithMissedRuns(t *testing.T, policy string, history *History) (*Scheduler, int32) {
+	t.Helper()
+	dir := t.TempDir()
+
+	nextRun := time.Now().Truncate(time.Hour).Add(-2 * time.Hour)
+	data, _ := json.Marshal([]*Job{{
+		ID: "hourly", Schedule: "0 0 * * * *", Type: JobTypeGo, Enabled: true,
+		MisfirePolicy: policy, NextRun: &nextRun, CreatedAt: nextRun.Add(-time.Hour),
+	}})
+	if err := os.WriteFile(filepath.Join(dir, "cron_jobs.json"), data, 0644); err != nil {
+		t.Fatalf("failed to write jobs: %v", err)
+	}
+
+	var calls int32
+	s := New(dir, func(ctx context.Context, job *Job) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	s.SetHistory(history)
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	t.Cleanup(s.Stop)
+
+	time.Sleep(200 * time.Millisecond)
+	return s, atomic.LoadInt32(&calls)
+}
+
+func TestStart_MisfirePolicies(t *testing.T) {
+	for policy, want := range map[string]int32{MisfireSkip: 0, MisfireRunOnce: 1, MisfireRunAll: 3} {
+		t.Run(policy, func(t *testing.T) {
+			history := newTestHistory(t, HistoryOptions{})
+			s, calls := startWithMissedRuns(t, policy, history)
+
+			if calls != want {
+				t.Errorf("expected %d catch-up runs, got %d", want, calls)
+			}
+
+			runs, _ := history.List("hourly", 0)
+			if policy == MisfireSkip {
+				if len(runs) != 1 || runs[0].Status != RunMissed || runs[0].TriggeredBy != TriggerMisfire {
+					t.Errorf("expected one missed run to be recorded, got %+v", runs)
+				}
+			} else if int32(len(runs)) != want {
+				t.Errorf("expected %d recorded runs, got %d", want, len(runs))
+			}
+
+			job, _ := s.GetJob("hourly")
+			if job.NextRun == nil || !job.NextRun.After(time.Now()) {
+				t.Errorf("expected the next run to be rescheduled in the future, got %v", job.NextRun)
+			}
+		})
+	}
+}
+
+func TestAddJob_ValidatesMisfireAndTimezone(t *testing.T) {
+	s := New(t.TempDir(), nil)
+
+	for _, job := range []*Job{
+		{ID: "a", Schedule: "0 0 9 * * *", Type: JobTypeGo, MisfirePolicy: "catch_up"},
+		{ID: "b", Schedule: "0 0 9 * * *", Type: JobTypeGo, MisfireWindow: "0s"},
+		{ID: "c", Schedule: "0 0 9 * * *", Type: JobTypeGo, Timezone: "Mars/Olympus_Mons"},
+	} {
+		if err := s.AddJob(job); err == nil {
+			t.Errorf("expected job %s to be rejected", job.ID)
+		}
+	}
+
+	job := &Job{ID: "ok", Schedule: "0 0 9 * * *", Type: JobTypeGo, MisfirePolicy: MisfireRunAll, MisfireWindow: "6h", Timezone: "Asia/Tokyo"}
+	if err := s.AddJob(job); err != nil {
+		t.Fatalf("expected valid settings to be accepted: %v", err)
+	}
+	if job.NextRun == nil {
+		t.Fatal("expected the next run to be set")
+	}
+	if local := job.NextRun.In(time.FixedZone("JST", 9*3600)); local.Hour() != 9 || local.Minute() != 0 {
+		t.Errorf("expected the next run at 09:00 Tokyo time, got %v", local)
+	}
+}
+
+func TestSetLocation_DefaultsJobTimezone(t *testing.T) {
+	s := New(t.TempDir(), nil)
+	s.SetLocation(time.FixedZone("JST", 9*3600))
+
+	job := &Job{ID: "daily", Schedule: "0 30 7 * * *", Type: JobTypeGo}
+	if err := s.AddJob(job); err != nil {
+		t.Fatalf("failed to add job: %v", err)
+	}
+	if local := job.NextRun.In(time.FixedZone("JST", 9*3600)); local.Hour() != 7 || local.Minute() != 30 {
+		t.Errorf("expected the next run at 07:30 in the scheduler's time zone, got %v", local)
+	}
+}
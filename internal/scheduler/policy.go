@@ -0,0 +1,219 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Concurrency policies for a job that fires while its previous run is
+// still going
+const (
+	ConcurrencyAllow = "allow" // Start another run alongside it
+	ConcurrencySkip  = "skip"  // Record the run as skipped
+	ConcurrencyQueue = "queue" // Wait for the previous run to finish; one run waits at most
+)
+
+// Job setting defaults, used when a job leaves a field empty
+const (
+	DefaultJobTimeout      = 30 * time.Minute
+	DefaultJobRetryBackoff = 30 * time.Second
+	MaxJobRetryBackoff     = 30 * time.Minute
+)
+
+// validateSettings checks the job's timeout, retry, concurrency, misfire
+// and time zone settings
+func (j *Job) validateSettings() error {
+	for name, value := range map[string]string{"timeout": j.Timeout, "retry_backoff": j.RetryBackoff, "misfire_window": j.MisfireWindow} {
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid %s '%s': must be a positive duration such as 30s or 10m", name, value)
+		}
+	}
+	if j.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative")
+	}
+	switch j.Concurrency {
+	case "", ConcurrencyAllow, ConcurrencySkip, ConcurrencyQueue:
+	default:
+		return fmt.Errorf("invalid concurrency '%s': must be allow, skip or queue", j.Concurrency)
+	}
+	switch j.MisfirePolicy {
+	case "", MisfireSkip, MisfireRunOnce, MisfireRunAll:
+	default:
+		return fmt.Errorf("invalid misfire_policy '%s': must be skip, run_once or run_all", j.MisfirePolicy)
+	}
+	if _, err := j.location(time.UTC); err != nil {
+		return err
+	}
+	return nil
+}
+
+// timeout returns how long a single attempt may run
+func (j *Job) timeout() time.Duration {
+	return parseDurationOr(j.Timeout, DefaultJobTimeout)
+}
+
+// retryDelay returns the delay after the given failed attempt, doubling
+// from RetryBackoff up to MaxJobRetryBackoff
+func (j *Job) retryDelay(attempt int) time.Duration {
+	delay := parseDurationOr(j.RetryBackoff, DefaultJobRetryBackoff)
+	for i := 1; i < attempt && delay < MaxJobRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxJobRetryBackoff {
+		delay = MaxJobRetryBackoff
+	}
+	return delay
+}
+
+// concurrency returns the job's concurrency policy
+func (j *Job) concurrency() string {
+	if j.Concurrency == "" {
+		return ConcurrencyAllow
+	}
+	return j.Concurrency
+}
+
+func parseDurationOr(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// acquire claims the job's run slot according to its concurrency policy.
+// It returns false if the run should not go ahead; otherwise the caller
+// must call release when the run is over.
+func (s *Scheduler) acquire(job *Job) (release func(), ok bool) {
+	policy := job.concurrency()
+	if policy == ConcurrencyAllow {
+		return func() {}, true
+	}
+
+	s.mu.Lock()
+	slot, exists := s.slots[job.ID]
+	if !exists {
+		slot = make(chan struct{}, 1)
+		s.slots[job.ID] = slot
+	}
+	s.mu.Unlock()
+
+	release = func() { <-slot }
+	select {
+	case slot <- struct{}{}:
+		return release, true
+	default:
+		if policy == ConcurrencySkip {
+			return nil, false
+		}
+	}
+
+	// Only one run waits for the slot; runs due while it waits are skipped
+	s.mu.Lock()
+	if s.waiting[job.ID] {
+		s.mu.Unlock()
+		return nil, false
+	}
+	s.waiting[job.ID] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.waiting, job.ID)
+		s.mu.Unlock()
+	}()
+
+	select {
+	case slot <- struct{}{}:
+		return release, true
+	case <-s.ctx.Done():
+		return nil, false
+	}
+}
+
+// releaseWhenDone calls release once finished is closed, keeping a run slot
+// held past the end of the run while a timed-out executor is still going
+func releaseWhenDone(finished <-chan struct{}, release func()) {
+	if finished == nil {
+		release()
+		return
+	}
+	select {
+	case <-finished:
+		release()
+	default:
+		go func() {
+			<-finished
+			release()
+		}()
+	}
+}
+
+// attempt runs the executor once, giving up when the job's timeout expires
+// even if the executor ignores its context. The returned channel is closed
+// when the executor returns, which may be after attempt does.
+func (s *Scheduler) attempt(job *Job, report *RunReport) (finished <-chan struct{}, err error) {
+	timeout := job.timeout()
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		done <- s.executor(withRunReport(ctx, report), job)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			return exited, &TimeoutError{Timeout: timeout, Err: err}
+		}
+		return exited, err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return exited, &TimeoutError{Timeout: timeout}
+		}
+		return exited, ctx.Err()
+	}
+}
+
+// waitExited waits up to the job's timeout again for a timed-out attempt's
+// executor to return, reporting whether it did
+func (s *Scheduler) waitExited(job *Job, finished <-chan struct{}) bool {
+	select {
+	case <-finished:
+		return true
+	default:
+	}
+
+	grace := time.NewTimer(job.timeout())
+	defer grace.Stop()
+	select {
+	case <-finished:
+		return true
+	case <-grace.C:
+	case <-s.ctx.Done():
+	}
+	return false
+}
+
+// TimeoutError reports that an attempt ran longer than the job's timeout
+type TimeoutError struct {
+	Timeout time.Duration
+	Err     error // The executor's error, if it returned after the deadline
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("timed out after %v: %v", e.Timeout, e.Err)
+	}
+	return fmt.Sprintf("timed out after %v", e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,328 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler_RetriesFailedAttempts(t *testing.T) {
+	history := newTestHistory(t, HistoryOptions{})
+
+	var calls int32
+	s := New(t.TempDir(), func(ctx context.Context, job *Job) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("502 Bad Gateway")
+		}
+		return nil
+	})
+	s.SetHistory(history)
+
+	job := &Job{ID: "report", Type: JobTypeGo, Enabled: true, MaxRetries: 3, RetryBackoff: "10ms"}
+	s.executeJob(job, TriggerSchedule)
+
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+	if job.LastError != "" || job.RunCount != 1 {
+		t.Errorf("expected one successful run, got run count %d, last error %q", job.RunCount, job.LastError)
+	}
+
+	runs, _ := history.List("report", 0)
+	if len(runs) != 3 {
+		t.Fatalf("expected each attempt to be recorded, got %d runs", len(runs))
+	}
+	for i, want := range []struct {
+		attempt int
+		status  string
+	}{{3, RunSucceeded}, {2, RunFailed}, {1, RunFailed}} {
+		if runs[i].Attempt != want.attempt || runs[i].Status != want.status {
+			t.Errorf("run %d: expected attempt %d %s, got attempt %d %s",
+				i, want.attempt, want.status, runs[i].Attempt, runs[i].Status)
+		}
+	}
+}
+
+func TestScheduler_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	s := New(t.TempDir(), func(ctx context.Context, job *Job) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("quota exceeded")
+	})
+
+	job := &Job{ID: "report", Type: JobTypeGo, Enabled: true, MaxRetries: 1, RetryBackoff: "10ms"}
+	s.executeJob(job, TriggerSchedule)
+
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+	if job.LastError != "quota exceeded" {
+		t.Errorf("expected the last attempt's error, got %q", job.LastError)
+	}
+}
+
+func TestScheduler_TimesOutHungAttempts(t *testing.T) {
+	history := newTestHistory(t, HistoryOptions{})
+
+	hang := make(chan struct{})
+	defer close(hang)
+	s := New(t.TempDir(), func(ctx context.Context, job *Job) error {
+		<-hang // Ignores ctx, like a stuck provider call
+		return nil
+	})
+	s.SetHistory(history)
+
+	job := &Job{ID: "stuck", Type: JobTypeGo, Enabled: true, Timeout: "50ms"}
+	start := time.Now()
+	s.executeJob(job, TriggerManual)
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the attempt to be abandoned after its timeout, took %v", elapsed)
+	}
+	if job.LastError != "timed out after 50ms" {
+		t.Errorf("unexpected last error %q", job.LastError)
+	}
+
+	runs, _ := history.List("stuck", 0)
+	if len(runs) != 1 || runs[0].Status != RunTimedOut {
+		t.Errorf("expected a timed out run, got %+v", runs)
+	}
+}
+
+func TestScheduler_TimedOutAttemptKeepsSlot(t *testing.T) {
+	var calls, running, maxSeen int32
+	hang := make(chan struct{})
+	s := New(t.TempDir(), func(ctx context.Context, job *Job) error {
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&maxSeen) {
+			atomic.StoreInt32(&maxSeen, n)
+		}
+		defer atomic.AddInt32(&running, -1)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-hang // Ignores ctx, like a stuck provider call
+		}
+		return errors.New("502 Bad Gateway")
+	})
+
+	job := &Job{ID: "stuck", Type: JobTypeGo, Enabled: true, Timeout: "50ms", MaxRetries: 1, RetryBackoff: "10ms", Concurrency: ConcurrencySkip}
+	s.executeJob(job, TriggerSchedule)
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected no retry while the timed-out attempt is still running, got %d attempts", atomic.LoadInt32(&calls))
+	}
+
+	s.executeJob(job, TriggerSchedule)
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatal("expected the slot to stay taken until the timed-out attempt returns")
+	}
+
+	close(hang)
+	time.Sleep(50 * time.Millisecond)
+	s.executeJob(job, TriggerSchedule)
+	if calls, maxSeen := atomic.LoadInt32(&calls), atomic.LoadInt32(&maxSeen); calls != 3 || maxSeen != 1 {
+		t.Errorf("expected the next run and its retry once the attempt returned, got %d attempts with up to %d at once", calls, maxSeen)
+	}
+}
+
+func TestScheduler_RetryWaitsForTimedOutAttempt(t *testing.T) {
+	var calls, running, maxSeen int32
+	s := New(t.TempDir(), func(ctx context.Context, job *Job) error {
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&maxSeen) {
+			atomic.StoreInt32(&maxSeen, n)
+		}
+		defer atomic.AddInt32(&running, -1)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(150 * time.Millisecond) // Overruns its timeout, then stops
+		}
+		return nil
+	})
+
+	job := &Job{ID: "slow", Type: JobTypeGo, Enabled: true, Timeout: "100ms", MaxRetries: 1, RetryBackoff: "10ms"}
+	s.executeJob(job, TriggerSchedule)
+
+	if calls, maxSeen := atomic.LoadInt32(&calls), atomic.LoadInt32(&maxSeen); calls != 2 || maxSeen != 1 {
+		t.Errorf("expected the retry to start after the timed-out attempt stopped, got %d attempts with up to %d at once", calls, maxSeen)
+	}
+}
+
+func TestScheduler_InterruptedAttempt(t *testing.T) {
+	history := newTestHistory(t, HistoryOptions{})
+	started := make(chan struct{})
+	s := New(t.TempDir(), func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s.SetHistory(history)
+
+	job := &Job{ID: "long", Type: JobTypeGo, Enabled: true, MaxRetries: 2}
+	go func() {
+		<-started
+		s.cancel()
+	}()
+	s.executeJob(job, TriggerSchedule)
+
+	runs, _ := history.List("long", 0)
+	if len(runs) != 1 || runs[0].Status != RunInterrupted {
+		t.Errorf("expected one interrupted run, got %+v", runs)
+	}
+}
+
+// blockingExecutor runs until released, counting the runs in progress
+type blockingExecutor struct {
+	release chan struct{}
+	started chan struct{}
+	mu      sync.Mutex
+	running int
+	maxSeen int
+	runs    int
+}
+
+func newBlockingExecutor() *blockingExecutor {
+	return &blockingExecutor{release: make(chan struct{}), started: make(chan struct{}, 10)}
+}
+
+func (e *blockingExecutor) execute(ctx context.Context, job *Job) error {
+	e.mu.Lock()
+	e.running++
+	e.runs++
+	if e.running > e.maxSeen {
+		e.maxSeen = e.running
+	}
+	e.mu.Unlock()
+	e.started <- struct{}{}
+
+	<-e.release
+
+	e.mu.Lock()
+	e.running--
+	e.mu.Unlock()
+	return nil
+}
+
+func TestScheduler_ConcurrencySkip(t *testing.T) {
+	history := newTestHistory(t, HistoryOptions{})
+	executor := newBlockingExecutor()
+	s := New(t.TempDir(), executor.execute)
+	s.SetHistory(history)
+
+	job := &Job{ID: "sync", Type: JobTypeGo, Enabled: true, Concurrency: ConcurrencySkip}
+	done := make(chan struct{})
+	go func() {
+		s.executeJob(job, TriggerSchedule)
+		close(done)
+	}()
+	<-executor.started
+
+	s.executeJob(job, TriggerSchedule) // Returns at once
+	close(executor.release)
+	<-done
+
+	if executor.runs != 1 {
+		t.Errorf("expected the overlapping run to be skipped, got %d runs", executor.runs)
+	}
+	runs, _ := history.List("sync", 0)
+	statuses := map[string]int{}
+	for _, run := range runs {
+		statuses[run.Status]++
+	}
+	if statuses[RunSkipped] != 1 || statuses[RunSucceeded] != 1 {
+		t.Errorf("expected one skipped and one successful run, got %v", statuses)
+	}
+}
+
+func TestScheduler_ConcurrencyQueue(t *testing.T) {
+	executor := newBlockingExecutor()
+	s := New(t.TempDir(), executor.execute)
+
+	job := &Job{ID: "sync", Type: JobTypeGo, Enabled: true, Concurrency: ConcurrencyQueue}
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.executeJob(job, TriggerSchedule)
+		}()
+	}
+	<-executor.started
+
+	select {
+	case <-executor.started:
+		t.Fatal("expected the second run to wait for the first")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(executor.release)
+	wg.Wait()
+
+	if executor.runs != 2 || executor.maxSeen != 1 {
+		t.Errorf("expected 2 runs one after the other, got %d runs with up to %d at once", executor.runs, executor.maxSeen)
+	}
+}
+
+func TestScheduler_ConcurrencyQueueWaitsOnce(t *testing.T) {
+	history := newTestHistory(t, HistoryOptions{})
+	executor := newBlockingExecutor()
+	s := New(t.TempDir(), executor.execute)
+	s.SetHistory(history)
+
+	job := &Job{ID: "sync", Type: JobTypeGo, Enabled: true, Concurrency: ConcurrencyQueue}
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.executeJob(job, TriggerSchedule)
+		}()
+		if i == 0 {
+			<-executor.started
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	s.executeJob(job, TriggerSchedule) // Returns at once, a run is already waiting
+	close(executor.release)
+	wg.Wait()
+
+	if executor.runs != 2 {
+		t.Errorf("expected only one run to wait, got %d runs", executor.runs)
+	}
+	runs, _ := history.List("sync", 0)
+	statuses := map[string]int{}
+	for _, run := range runs {
+		statuses[run.Status]++
+	}
+	if statuses[RunSkipped] != 1 || statuses[RunSucceeded] != 2 {
+		t.Errorf("expected one skipped and two successful runs, got %v", statuses)
+	}
+}
+
+func TestAddJob_ValidatesSettings(t *testing.T) {
+	s := New(t.TempDir(), nil)
+
+	for _, job := range []*Job{
+		{ID: "a", Schedule: "0 0 9 * * *", Type: JobTypeGo, Timeout: "ten minutes"},
+		{ID: "b", Schedule: "0 0 9 * * *", Type: JobTypeGo, RetryBackoff: "-1s"},
+		{ID: "c", Schedule: "0 0 9 * * *", Type: JobTypeGo, MaxRetries: -1},
+		{ID: "d", Schedule: "0 0 9 * * *", Type: JobTypeGo, Concurrency: "parallel"},
+	} {
+		if err := s.AddJob(job); err == nil {
+			t.Errorf("expected job %s to be rejected", job.ID)
+		}
+	}
+
+	job := &Job{ID: "ok", Schedule: "0 0 9 * * *", Type: JobTypeGo, Timeout: "10m", MaxRetries: 2, RetryBackoff: "1m", Concurrency: ConcurrencyQueue}
+	if err := s.AddJob(job); err != nil {
+		t.Fatalf("expected valid settings to be accepted: %v", err)
+	}
+	if got := job.retryDelay(1); got != time.Minute {
+		t.Errorf("expected first retry after 1m, got %v", got)
+	}
+	if got := job.retryDelay(3); got != 4*time.Minute {
+		t.Errorf("expected the retry delay to double, got %v", got)
+	}
+	if got := job.retryDelay(10); got != MaxJobRetryBackoff {
+		t.Errorf("expected the retry delay to be capped, got %v", got)
+	}
+}
@@ -3,6 +3,7 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -28,21 +29,28 @@ type JobExecutor func(ctx context.Context, job *Job) error
 
 // Job represents a scheduled job
 type Job struct {
-	ID        string                 `json:"id"`
-	Name      string                 `json:"name,omitempty"`
-	Schedule  string                 `json:"schedule"`         // Cron expression (5 or 6 fields)
-	Type      JobType                `json:"type"`             // "go" or "system"
-	Command   string                 `json:"command"`          // For system: shell command. For go: prompt/task
-	Model     string                 `json:"model,omitempty"`  // For go jobs: AI model to use
-	Target    string                 `json:"target,omitempty"` // Channel/session to send output
-	Enabled   bool                   `json:"enabled"`
-	OneShot   bool                   `json:"oneshot,omitempty"`
-	CreatedAt time.Time              `json:"created_at"`
-	LastRun   *time.Time             `json:"last_run,omitempty"`
-	NextRun   *time.Time             `json:"next_run,omitempty"`
-	RunCount  int                    `json:"run_count"`
-	LastError string                 `json:"last_error,omitempty"`
-	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	ID            string                 `json:"id"`
+	Name          string                 `json:"name,omitempty"`
+	Schedule      string                 `json:"schedule"`         // Cron expression (5 or 6 fields)
+	Type          JobType                `json:"type"`             // "go" or "system"
+	Command       string                 `json:"command"`          // For system: shell command. For go: prompt/task
+	Model         string                 `json:"model,omitempty"`  // For go jobs: AI model to use
+	Target        string                 `json:"target,omitempty"` // Channel/session to send output
+	Enabled       bool                   `json:"enabled"`
+	OneShot       bool                   `json:"oneshot,omitempty"`
+	Timeout       string                 `json:"timeout,omitempty"`        // Per-attempt time limit, e.g. "10m" (default 30m)
+	MaxRetries    int                    `json:"max_retries,omitempty"`    // Further attempts after a failed one
+	RetryBackoff  string                 `json:"retry_backoff,omitempty"`  // Delay before the first retry, doubled after each (default 30s)
+	Concurrency   string                 `json:"concurrency,omitempty"`    // "allow" (default), "skip" or "queue"
+	MisfirePolicy string                 `json:"misfire_policy,omitempty"` // Runs missed while down: "skip" (default), "run_once" or "run_all"
+	MisfireWindow string                 `json:"misfire_window,omitempty"` // Oldest missed run still made up, e.g. "6h" (default 24h)
+	Timezone      string                 `json:"timezone,omitempty"`       // IANA time zone for the schedule, e.g. "Europe/Berlin" (default the gateway's)
+	CreatedAt     time.Time              `json:"created_at"`
+	LastRun       *time.Time             `json:"last_run,omitempty"`
+	NextRun       *time.Time             `json:"next_run,omitempty"`
+	RunCount      int                    `json:"run_count"`
+	LastError     string                 `json:"last_error,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 
 	// Internal: cron entry ID for Go jobs
 	entryID cron.EntryID
@@ -54,6 +62,10 @@ type Scheduler struct {
 	jobs          map[string]*Job
 	jobsFile      string
 	executor      JobExecutor
+	history       *History
+	location      *time.Location           // Time zone of schedules without their own
+	slots         map[string]chan struct{} // Run slots of jobs that skip or queue overlapping runs
+	waiting       map[string]bool          // Jobs with a queued run waiting for their slot
 	mu            sync.RWMutex
 	ctx           context.Context
 	cancel        context.CancelFunc
@@ -67,6 +79,9 @@ func New(workspaceDir string, executor JobExecutor) *Scheduler {
 	return &Scheduler{
 		cron:          cron.New(cron.WithSeconds()), // Support 6-field cron (with seconds)
 		jobs:          make(map[string]*Job),
+		location:      time.Local,
+		slots:         make(map[string]chan struct{}),
+		waiting:       make(map[string]bool),
 		jobsFile:      filepath.Join(workspaceDir, "cron_jobs.json"),
 		executor:      executor,
 		ctx:           ctx,
@@ -75,6 +90,18 @@ func New(workspaceDir string, executor JobExecutor) *Scheduler {
 	}
 }
 
+// SetHistory records every run of a Go job in h. Call before Start.
+func (s *Scheduler) SetHistory(h *History) {
+	s.history = h
+}
+
+// SetLocation sets the time zone schedules are read in when a job has none
+// of its own (time.Local by default). Call before Start.
+func (s *Scheduler) SetLocation(loc *time.Location) {
+	s.location = loc
+	s.cron = cron.New(cron.WithSeconds(), cron.WithLocation(loc))
+}
+
 // Start loads jobs and starts the scheduler
 func (s *Scheduler) Start() error {
 	// Load saved jobs
@@ -82,20 +109,46 @@ func (s *Scheduler) Start() error {
 		log.Printf("[Scheduler] Warning: failed to load jobs: %v", err)
 	}
 
-	// Schedule all enabled Go jobs
+	// Runs still marked running were cut short by the last shutdown
+	if s.history != nil {
+		if n, err := s.history.MarkInterrupted(); err != nil {
+			log.Printf("[Scheduler] Warning: %v", err)
+		} else if n > 0 {
+			log.Printf("[Scheduler] Marked %d unfinished runs as interrupted", n)
+		}
+	}
+
+	// Schedule all enabled Go jobs, first applying their misfire policy to
+	// the runs missed while the gateway was down
+	now := time.Now()
+	missed := make(map[*Job]int)
 	for _, job := range s.jobs {
 		if job.Enabled && job.Type == JobTypeGo {
+			if runs := s.catchUp(job, now); runs > 0 {
+				missed[job] = runs
+			}
 			if err := s.scheduleGoJob(job); err != nil {
 				log.Printf("[Scheduler] Failed to schedule job %s: %v", job.ID, err)
 			}
 		}
 	}
 
+	// Save the new next run times so the same runs aren't missed twice
+	s.mu.Lock()
+	if err := s.saveJobs(); err != nil {
+		log.Printf("[Scheduler] Warning: failed to save jobs: %v", err)
+	}
+	s.mu.Unlock()
+
 	// Start the cron scheduler
 	s.cron.Start()
 	log.Printf("[Scheduler] Started with %d jobs (%d Go, %d system)",
 		len(s.jobs), s.countByType(JobTypeGo), s.countByType(JobTypeSystem))
 
+	for job, runs := range missed {
+		go s.runMissed(job, runs)
+	}
+
 	return nil
 }
 
@@ -124,6 +177,9 @@ func (s *Scheduler) AddJob(job *Job) error {
 			return fmt.Errorf("invalid cron expression: %v", err)
 		}
 	}
+	if err := job.validateSettings(); err != nil {
+		return err
+	}
 
 	if job.Type == JobTypeGo {
 		if err := s.scheduleGoJob(job); err != nil {
@@ -158,6 +214,7 @@ func (s *Scheduler) RemoveJob(jobID string) error {
 	}
 
 	delete(s.jobs, jobID)
+	delete(s.slots, jobID)
 	return s.saveJobs()
 }
 
@@ -248,10 +305,19 @@ func (s *Scheduler) RunNow(jobID string) error {
 		return fmt.Errorf("job %s not found", jobID)
 	}
 
-	go s.executeJob(job)
+	go s.executeJob(job, TriggerManual)
 	return nil
 }
 
+// JobHistory returns a job's recorded runs, newest first. A limit of zero
+// returns all runs.
+func (s *Scheduler) JobHistory(jobID string, limit int) ([]*Run, error) {
+	if s.history == nil {
+		return nil, fmt.Errorf("job history is not enabled")
+	}
+	return s.history.List(jobID, limit)
+}
+
 // scheduleGoJob adds a job to the Go cron scheduler
 func (s *Scheduler) scheduleGoJob(job *Job) error {
 	// Remove existing entry if any
@@ -259,19 +325,17 @@ func (s *Scheduler) scheduleGoJob(job *Job) error {
 		s.cron.Remove(job.entryID)
 	}
 
-	entryID, err := s.cron.AddFunc(job.Schedule, func() {
-		s.executeJob(job)
-	})
+	schedule, err := job.schedule(s.location)
 	if err != nil {
 		return fmt.Errorf("failed to schedule job: %v", err)
 	}
-
-	job.entryID = entryID
+	job.entryID = s.cron.Schedule(schedule, cron.FuncJob(func() {
+		s.executeJob(job, TriggerSchedule)
+	}))
 
 	// Calculate next run time
-	entry := s.cron.Entry(entryID)
-	if !entry.Next.IsZero() {
-		job.NextRun = &entry.Next
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		job.NextRun = &next
 	}
 
 	log.Printf("[Scheduler] Scheduled Go job: %s (%s) - next run: %v", job.ID, job.Name, job.NextRun)
@@ -279,7 +343,20 @@ func (s *Scheduler) scheduleGoJob(job *Job) error {
 }
 
 // executeJob runs a job
-func (s *Scheduler) executeJob(job *Job) {
+func (s *Scheduler) executeJob(job *Job, trigger string) {
+	release, ok := s.acquire(job)
+	if !ok {
+		if s.ctx.Err() == nil {
+			log.Printf("[Scheduler] Skipping job %s: previous run still in progress", job.ID)
+			s.recordNotRun(job, trigger, RunSkipped, "previous run still in progress")
+		}
+		return
+	}
+	// The slot stays taken until the executor has returned, even when the
+	// run gave up on it after a timeout
+	var finished <-chan struct{}
+	defer func() { releaseWhenDone(finished, release) }()
+
 	log.Printf("[Scheduler] Executing job: %s (%s)", job.ID, job.Name)
 
 	now := time.Now()
@@ -288,9 +365,9 @@ func (s *Scheduler) executeJob(job *Job) {
 
 	var err error
 	if job.Type == JobTypeGo {
-		// Use the executor callback for Go jobs
+		// Use the executor callback for Go jobs, retrying failed attempts
 		if s.executor != nil {
-			err = s.executor(s.ctx, job)
+			finished, err = s.runAttempts(job, trigger)
 		}
 	} else if job.Type == JobTypeSystem {
 		// System jobs are run by crontab, not us
@@ -332,6 +409,97 @@ func (s *Scheduler) executeJob(job *Job) {
 	s.mu.Unlock()
 }
 
+// runAttempts runs a job until an attempt succeeds or its retries are used
+// up, recording each attempt. The returned channel is closed once the last
+// attempt's executor has returned.
+func (s *Scheduler) runAttempts(job *Job, trigger string) (finished <-chan struct{}, err error) {
+	for attempt := 1; ; attempt++ {
+		run := s.startRun(job, trigger, attempt, time.Now())
+		report := &RunReport{}
+		finished, err = s.attempt(job, report)
+		s.finishRun(run, report, err)
+
+		if err == nil || attempt > job.MaxRetries || s.ctx.Err() != nil {
+			return finished, err
+		}
+
+		// Never retry alongside a timed-out attempt that is still running
+		if !s.waitExited(job, finished) {
+			log.Printf("[Scheduler] Job %s attempt %d is still running after its timeout, not retrying", job.ID, attempt)
+			return finished, err
+		}
+
+		delay := job.retryDelay(attempt)
+		log.Printf("[Scheduler] Job %s attempt %d failed: %v; retrying in %v", job.ID, attempt, err, delay)
+		select {
+		case <-time.After(delay):
+		case <-s.ctx.Done():
+			return finished, err
+		}
+	}
+}
+
+// startRun records the start of an attempt, returning nil if history is off
+func (s *Scheduler) startRun(job *Job, trigger string, attempt int, started time.Time) *Run {
+	if s.history == nil {
+		return nil
+	}
+
+	run := &Run{JobID: job.ID, JobName: job.Name, TriggeredBy: trigger, Attempt: attempt, StartedAt: started}
+	if err := s.history.Start(run); err != nil {
+		log.Printf("[Scheduler] Warning: %v", err)
+		return nil
+	}
+	return run
+}
+
+// finishRun records the outcome of a run and prunes old history
+func (s *Scheduler) finishRun(run *Run, report *RunReport, err error) {
+	if run == nil {
+		return
+	}
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+	run.Status = RunSucceeded
+	if err != nil {
+		var timeout *TimeoutError
+		switch {
+		case errors.As(err, &timeout):
+			run.Status = RunTimedOut
+		case s.ctx.Err() != nil:
+			run.Status = RunInterrupted
+		default:
+			run.Status = RunFailed
+		}
+		run.Error = err.Error()
+	}
+	report.apply(run)
+
+	if err := s.history.Finish(run); err != nil {
+		log.Printf("[Scheduler] Warning: %v", err)
+	}
+	if _, err := s.history.Prune(finished); err != nil {
+		log.Printf("[Scheduler] Warning: %v", err)
+	}
+}
+
+// recordNotRun records a run that was skipped or missed, with the reason
+func (s *Scheduler) recordNotRun(job *Job, trigger, status, reason string) {
+	run := s.startRun(job, trigger, 0, time.Now())
+	if run == nil {
+		return
+	}
+
+	run.FinishedAt = &run.StartedAt
+	run.Status = status
+	run.Error = reason
+	if err := s.history.Finish(run); err != nil {
+		log.Printf("[Scheduler] Warning: %v", err)
+	}
+}
+
 // addSystemCrontab adds a job to the system crontab
 func (s *Scheduler) addSystemCrontab(job *Job) error {
 	// Get current crontab
@@ -0,0 +1,35 @@
+package sessions
+
+// Content block types for multimodal messages
+const (
+	BlockTypeText     = "text"
+	BlockTypeImage    = "image"
+	BlockTypeDocument = "document"
+)
+
+// ContentBlock is one part of a multimodal message. Binary payloads are
+// either inlined as base64 in Data or referenced by Path (preferred for
+// persisted media, so history rows stay small) or by a remote URL.
+type ContentBlock struct {
+	Type      string `json:"type"`                 // "text", "image", "document"
+	Text      string `json:"text,omitempty"`       // Text for text blocks
+	MediaType string `json:"media_type,omitempty"` // MIME type, e.g. "image/png", "application/pdf"
+	Data      string `json:"data,omitempty"`       // Base64-encoded bytes
+	Path      string `json:"path,omitempty"`       // Local file holding the bytes
+	URL       string `json:"url,omitempty"`        // Remote source
+	Filename  string `json:"filename,omitempty"`   // Original file name, if known
+}
+
+// ToolCall is a tool invocation requested by the assistant. Tool results
+// are stored as "tool" role messages whose ToolCallID matches ID.
+type ToolCall struct {
+	ID   string                 `json:"id"`
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"arguments"`
+}
+
+// IsToolTurn reports whether a message is part of a tool exchange rather
+// than conversation shown to the user
+func (m Message) IsToolTurn() bool {
+	return m.Role == "tool" || (len(m.ToolCalls) > 0 && m.Content == "")
+}
@@ -51,8 +51,11 @@ type Session struct {
 type Message struct {
 	ID         string            `json:"id"`
 	SessionKey string            `json:"session_key"`
-	Role       string            `json:"role"` // "user", "assistant", "system"
+	Role       string            `json:"role"` // "user", "assistant", "system", "tool"
 	Content    string            `json:"content"`
+	Blocks     []ContentBlock    `json:"blocks,omitempty"`       // Images and documents attached to the message
+	ToolCalls  []ToolCall        `json:"tool_calls,omitempty"`   // Tools requested by an assistant message
+	ToolCallID string            `json:"tool_call_id,omitempty"` // Call answered by a "tool" message
 	Timestamp  time.Time         `json:"timestamp"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
 }
@@ -315,14 +318,54 @@ func (s *Store) SaveSession(session *Session) error {
 
 // AddMessage adds a message to a session
 func (s *Store) AddMessage(sessionKey, role, content string, metadata map[string]string) (*Message, error) {
-	message := &Message{
-		ID:         uuid.New().String(),
+	return s.AddMessageWithBlocks(sessionKey, role, content, nil, metadata)
+}
+
+// AddMessageWithBlocks adds a message with multimodal content blocks to a
+// session. The blocks are stored alongside the text so later turns can
+// replay them.
+func (s *Store) AddMessageWithBlocks(sessionKey, role, content string, blocks []ContentBlock, metadata map[string]string) (*Message, error) {
+	return s.insertMessage(&Message{
 		SessionKey: sessionKey,
 		Role:       role,
 		Content:    content,
-		Timestamp:  time.Now(),
+		Blocks:     blocks,
 		Metadata:   metadata,
+	})
+}
+
+// AddToolCallMessage records an assistant turn that requested tools. Content
+// is any text the assistant produced alongside the calls.
+func (s *Store) AddToolCallMessage(sessionKey, content string, calls []ToolCall) (*Message, error) {
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("tool call message requires at least one call")
 	}
+	return s.insertMessage(&Message{
+		SessionKey: sessionKey,
+		Role:       "assistant",
+		Content:    content,
+		ToolCalls:  calls,
+	})
+}
+
+// AddToolResultMessage records the result of the tool call with the given ID
+func (s *Store) AddToolResultMessage(sessionKey, toolCallID, content string) (*Message, error) {
+	if toolCallID == "" {
+		return nil, fmt.Errorf("tool result message requires a tool call ID")
+	}
+	return s.insertMessage(&Message{
+		SessionKey: sessionKey,
+		Role:       "tool",
+		Content:    content,
+		ToolCallID: toolCallID,
+	})
+}
+
+// insertMessage assigns an ID and timestamp to message and stores it
+func (s *Store) insertMessage(message *Message) (*Message, error) {
+	message.ID = uuid.New().String()
+	message.Timestamp = time.Now()
+	sessionKey := message.SessionKey
 
 	if message.Metadata == nil {
 		message.Metadata = make(map[string]string)
@@ -333,9 +376,27 @@ func (s *Store) AddMessage(sessionKey, role, content string, metadata map[string
 		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	blocksJSON := ""
+	if len(message.Blocks) > 0 {
+		data, err := json.Marshal(message.Blocks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal content blocks: %w", err)
+		}
+		blocksJSON = string(data)
+	}
+
+	toolCallsJSON := ""
+	if len(message.ToolCalls) > 0 {
+		data, err := json.Marshal(message.ToolCalls)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal tool calls: %w", err)
+		}
+		toolCallsJSON = string(data)
+	}
+
 	_, err = s.db.Exec(`
-		INSERT INTO messages (id, session_key, role, content, timestamp, metadata)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO messages (id, session_key, role, content, timestamp, metadata, content_blocks, tool_calls, tool_call_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		message.ID,
 		message.SessionKey,
@@ -343,14 +404,18 @@ func (s *Store) AddMessage(sessionKey, role, content string, metadata map[string
 		message.Content,
 		message.Timestamp,
 		string(metadataJSON),
+		blocksJSON,
+		toolCallsJSON,
+		message.ToolCallID,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to save message: %w", err)
 	}
 
-	// Sync to search.db FTS5 index via callback (best-effort — don't fail the message insert)
-	if s.onMessageAdded != nil {
+	// Sync to search.db FTS5 index via callback (best-effort — don't fail the message insert).
+	// Tool output is not conversation text, so it stays out of the index.
+	if s.onMessageAdded != nil && message.Role != "tool" {
 		s.onMessageAdded(message.ID, message.SessionKey, message.Role, message.Content)
 	}
 
@@ -372,9 +437,9 @@ func (s *Store) GetMessages(sessionKey string, limit int) ([]Message, error) {
 	var query string
 	if limit > 0 {
 		query = fmt.Sprintf(`
-			SELECT id, session_key, role, content, timestamp, metadata
+			SELECT id, session_key, role, content, timestamp, metadata, content_blocks, tool_calls, tool_call_id
 			FROM (
-				SELECT id, session_key, role, content, timestamp, metadata
+				SELECT id, session_key, role, content, timestamp, metadata, content_blocks, tool_calls, tool_call_id
 				FROM messages
 				WHERE session_key = ?
 				ORDER BY timestamp DESC
@@ -384,7 +449,7 @@ func (s *Store) GetMessages(sessionKey string, limit int) ([]Message, error) {
 		`, limit)
 	} else {
 		query = `
-			SELECT id, session_key, role, content, timestamp, metadata
+			SELECT id, session_key, role, content, timestamp, metadata, content_blocks, tool_calls, tool_call_id
 			FROM messages
 			WHERE session_key = ?
 			ORDER BY timestamp ASC
@@ -402,6 +467,8 @@ func (s *Store) GetMessages(sessionKey string, limit int) ([]Message, error) {
 	for rows.Next() {
 		var message Message
 		var metadataJSON string
+		var blocksJSON string
+		var toolCallsJSON string
 
 		err := rows.Scan(
 			&message.ID,
@@ -410,6 +477,9 @@ func (s *Store) GetMessages(sessionKey string, limit int) ([]Message, error) {
 			&message.Content,
 			&message.Timestamp,
 			&metadataJSON,
+			&blocksJSON,
+			&toolCallsJSON,
+			&message.ToolCallID,
 		)
 
 		if err != nil {
@@ -421,6 +491,20 @@ func (s *Store) GetMessages(sessionKey string, limit int) ([]Message, error) {
 			message.Metadata = make(map[string]string)
 		}
 
+		// Parse content blocks JSON (empty for text-only messages)
+		if blocksJSON != "" {
+			if err := json.Unmarshal([]byte(blocksJSON), &message.Blocks); err != nil {
+				message.Blocks = nil
+			}
+		}
+
+		// Parse tool calls JSON (empty unless the assistant requested tools)
+		if toolCallsJSON != "" {
+			if err := json.Unmarshal([]byte(toolCallsJSON), &message.ToolCalls); err != nil {
+				message.ToolCalls = nil
+			}
+		}
+
 		messages = append(messages, message)
 	}
 
@@ -462,6 +546,11 @@ func (s *Store) ClearSessionMessages(sessionKey string) error {
 		return fmt.Errorf("failed to delete messages: %w", err)
 	}
 
+	// A summary of the deleted history no longer applies
+	if err := s.ClearSummary(sessionKey); err != nil {
+		return err
+	}
+
 	// Update the session's message count to 0
 	_, err = s.db.Exec(`
 		UPDATE sessions 
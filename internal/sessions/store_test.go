@@ -122,6 +122,167 @@ func TestAddAndRetrieveMessage(t *testing.T) {
 	}
 }
 
+func TestAddMessageWithBlocks(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	store, err := NewStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	session, err := store.GetOrCreateSession("user123", "channel456")
+	if err != nil {
+		t.Fatalf("Failed to get or create session: %v", err)
+	}
+
+	blocks := []ContentBlock{
+		{Type: BlockTypeImage, MediaType: "image/png", Path: "/tmp/media/photo.png"},
+		{Type: BlockTypeDocument, MediaType: "application/pdf", Filename: "report.pdf", Data: "JVBERi0="},
+	}
+
+	message, err := store.AddMessageWithBlocks(session.Key, "user", "what is this?", blocks, nil)
+	if err != nil {
+		t.Fatalf("Failed to add message: %v", err)
+	}
+	if len(message.Blocks) != 2 {
+		t.Errorf("Expected 2 blocks on returned message, got %d", len(message.Blocks))
+	}
+
+	// Plain messages carry no blocks
+	if _, err := store.AddMessage(session.Key, "assistant", "a chart", nil); err != nil {
+		t.Fatalf("Failed to add message: %v", err)
+	}
+
+	messages, err := store.GetMessages(session.Key, 0)
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(messages))
+	}
+
+	got := messages[0].Blocks
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 persisted blocks, got %d", len(got))
+	}
+	if got[0] != blocks[0] || got[1] != blocks[1] {
+		t.Errorf("Blocks did not round-trip: %+v", got)
+	}
+	if len(messages[1].Blocks) != 0 {
+		t.Errorf("Expected no blocks on plain message, got %+v", messages[1].Blocks)
+	}
+}
+
+func TestAddToolTurns(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	store, err := NewStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	session, err := store.GetOrCreateSession("user123", "channel456")
+	if err != nil {
+		t.Fatalf("Failed to get or create session: %v", err)
+	}
+
+	calls := []ToolCall{{ID: "call_1", Name: "WebSearch", Args: map[string]interface{}{"query": "go"}}}
+	if _, err := store.AddMessage(session.Key, "user", "search for go", nil); err != nil {
+		t.Fatalf("Failed to add message: %v", err)
+	}
+	if _, err := store.AddToolCallMessage(session.Key, "Searching", calls); err != nil {
+		t.Fatalf("Failed to add tool call message: %v", err)
+	}
+	if _, err := store.AddToolResultMessage(session.Key, "call_1", "3 results"); err != nil {
+		t.Fatalf("Failed to add tool result message: %v", err)
+	}
+
+	if _, err := store.AddToolCallMessage(session.Key, "", nil); err == nil {
+		t.Error("Expected error for tool call message without calls")
+	}
+	if _, err := store.AddToolResultMessage(session.Key, "", "orphan"); err == nil {
+		t.Error("Expected error for tool result without call ID")
+	}
+
+	messages, err := store.GetMessages(session.Key, 0)
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+
+	call := messages[1]
+	if call.Role != "assistant" || len(call.ToolCalls) != 1 {
+		t.Fatalf("Expected assistant tool call message, got %+v", call)
+	}
+	if call.ToolCalls[0].ID != "call_1" || call.ToolCalls[0].Name != "WebSearch" || call.ToolCalls[0].Args["query"] != "go" {
+		t.Errorf("Tool call did not round-trip: %+v", call.ToolCalls[0])
+	}
+
+	result := messages[2]
+	if result.Role != "tool" || result.ToolCallID != "call_1" || result.Content != "3 results" {
+		t.Errorf("Tool result did not round-trip: %+v", result)
+	}
+	if !result.IsToolTurn() || messages[0].IsToolTurn() {
+		t.Error("Expected only the tool result to be a hidden tool turn")
+	}
+	if messages[0].ToolCallID != "" || len(messages[0].ToolCalls) != 0 {
+		t.Errorf("Expected plain message without tool fields, got %+v", messages[0])
+	}
+}
+
+func TestSessionSummary(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	store, err := NewStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	session, err := store.GetOrCreateSession("user123", "channel456")
+	if err != nil {
+		t.Fatalf("Failed to get or create session: %v", err)
+	}
+
+	if summary, err := store.GetSummary(session.Key); err != nil || summary != nil {
+		t.Fatalf("Expected no summary, got %+v (err %v)", summary, err)
+	}
+
+	first, _ := store.AddMessage(session.Key, "user", "one", nil)
+	second, _ := store.AddMessage(session.Key, "assistant", "two", nil)
+	third, _ := store.AddMessage(session.Key, "user", "three", nil)
+
+	if err := store.SaveSummary(&Summary{SessionKey: session.Key, Content: "counted", ThroughMessageID: second.ID, MessageCount: 2}); err != nil {
+		t.Fatalf("Failed to save summary: %v", err)
+	}
+	if err := store.SaveSummary(&Summary{SessionKey: session.Key, Content: "no anchor"}); err == nil {
+		t.Error("Expected error for summary without a message ID")
+	}
+
+	summary, err := store.GetSummary(session.Key)
+	if err != nil || summary == nil {
+		t.Fatalf("Failed to get summary: %v", err)
+	}
+	if summary.Content != "counted" || summary.MessageCount != 2 || summary.UpdatedAt.IsZero() {
+		t.Errorf("Summary did not round-trip: %+v", summary)
+	}
+
+	messages, _ := store.GetMessages(session.Key, 0)
+	if after := MessagesAfter(messages, summary.ThroughMessageID); len(after) != 1 || after[0].ID != third.ID {
+		t.Errorf("Expected only the unsummarized message, got %+v", after)
+	}
+	if after := MessagesAfter(messages, "missing"); len(after) != 3 || after[0].ID != first.ID {
+		t.Errorf("Expected all messages when the anchor is gone, got %d", len(after))
+	}
+}
+
 func TestGetNonExistentSession(t *testing.T) {
 	tmpDir := t.TempDir()
 	dbPath := filepath.Join(tmpDir, "test.db")
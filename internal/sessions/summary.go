@@ -0,0 +1,80 @@
+package sessions
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Summary is the running summary of session history that has been compacted
+// out of the model's context window. Messages up to and including
+// ThroughMessageID are represented only by Content.
+type Summary struct {
+	SessionKey       string    `json:"session_key"`
+	Content          string    `json:"content"`
+	ThroughMessageID string    `json:"through_message_id"`
+	MessageCount     int       `json:"message_count"` // Messages summarized so far
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// GetSummary returns the session's summary, or nil if nothing has been
+// summarized yet
+func (s *Store) GetSummary(sessionKey string) (*Summary, error) {
+	summary := &Summary{SessionKey: sessionKey}
+	err := s.db.QueryRow(`
+		SELECT content, through_message_id, message_count, updated_at
+		FROM session_summaries
+		WHERE session_key = ?
+	`, sessionKey).Scan(&summary.Content, &summary.ThroughMessageID, &summary.MessageCount, &summary.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session summary: %w", err)
+	}
+	return summary, nil
+}
+
+// SaveSummary creates or replaces the session's summary
+func (s *Store) SaveSummary(summary *Summary) error {
+	if summary.ThroughMessageID == "" {
+		return fmt.Errorf("summary must reference the last summarized message")
+	}
+	summary.UpdatedAt = time.Now()
+
+	_, err := s.db.Exec(`
+		INSERT INTO session_summaries (session_key, content, through_message_id, message_count, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(session_key) DO UPDATE SET
+			content = excluded.content,
+			through_message_id = excluded.through_message_id,
+			message_count = excluded.message_count,
+			updated_at = excluded.updated_at
+	`, summary.SessionKey, summary.Content, summary.ThroughMessageID, summary.MessageCount, summary.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save session summary: %w", err)
+	}
+	return nil
+}
+
+// ClearSummary removes the session's summary
+func (s *Store) ClearSummary(sessionKey string) error {
+	if _, err := s.db.Exec(`DELETE FROM session_summaries WHERE session_key = ?`, sessionKey); err != nil {
+		return fmt.Errorf("failed to clear session summary: %w", err)
+	}
+	return nil
+}
+
+// MessagesAfter returns the messages that follow the given message, in
+// order. If the message is not present, all messages are returned.
+func MessagesAfter(messages []Message, messageID string) []Message {
+	if messageID == "" {
+		return messages
+	}
+	for i, msg := range messages {
+		if msg.ID == messageID {
+			return messages[i+1:]
+		}
+	}
+	return messages
+}
@@ -0,0 +1,285 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"conduit/internal/ai"
+	"conduit/internal/config"
+	"conduit/internal/tools/types"
+)
+
+// defaultApprovalTimeout is how long a call waits for a decision when
+// tools.approval.timeout_seconds is unset
+const defaultApprovalTimeout = 120 * time.Second
+
+// ApprovalRequest is a tool call waiting for a human decision
+type ApprovalRequest struct {
+	ID         string
+	ToolName   string
+	Args       map[string]interface{}
+	Reason     string // Which rule matched
+	SessionKey string
+	ChannelID  string
+	UserID     string
+	ExpiresAt  time.Time
+}
+
+// Summary renders the call for a prompt, e.g. `Bash: rm -rf build`
+func (r ApprovalRequest) Summary() string {
+	keys := make([]string, 0, len(r.Args))
+	for k := range r.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, r.Args[k]))
+	}
+	summary := r.ToolName + ": " + strings.Join(parts, " ")
+	if len(keys) == 1 {
+		summary = fmt.Sprintf("%s: %v", r.ToolName, r.Args[keys[0]])
+	}
+	if len(summary) > 500 {
+		summary = summary[:500] + "…"
+	}
+	return summary
+}
+
+// ApprovalPrompter delivers an approval request to the user who started the
+// conversation. The answer comes back through ApprovalMiddleware.Resolve.
+type ApprovalPrompter func(ctx context.Context, req ApprovalRequest) error
+
+// approvalPrompterKey is the context key for the approval prompter
+type approvalPrompterKey struct{}
+
+// WithApprovalPrompter returns a context whose tool calls can ask for approval
+func WithApprovalPrompter(ctx context.Context, p ApprovalPrompter) context.Context {
+	return context.WithValue(ctx, approvalPrompterKey{}, p)
+}
+
+func getApprovalPrompter(ctx context.Context) ApprovalPrompter {
+	p, _ := ctx.Value(approvalPrompterKey{}).(ApprovalPrompter)
+	return p
+}
+
+// approvalRule is a compiled config.ApprovalRule
+type approvalRule struct {
+	config.ApprovalRule
+	match  *regexp.Regexp
+	unless *regexp.Regexp
+}
+
+// pendingApproval is a request waiting in BeforeExecution
+type pendingApproval struct {
+	req      ApprovalRequest
+	decision chan bool
+}
+
+// ApprovalMiddleware pauses tool calls matching its rules until a human
+// approves or denies them. Calls with no way to ask (scheduled jobs,
+// heartbeats) and calls not answered in time are denied.
+type ApprovalMiddleware struct {
+	rules   []approvalRule
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*pendingApproval
+}
+
+// NewApprovalMiddleware compiles the approval rules
+func NewApprovalMiddleware(cfg config.ApprovalConfig) (*ApprovalMiddleware, error) {
+	m := &ApprovalMiddleware{
+		timeout: defaultApprovalTimeout,
+		pending: make(map[string]*pendingApproval),
+	}
+	if cfg.TimeoutSeconds > 0 {
+		m.timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+
+	for i, rule := range cfg.Rules {
+		compiled := approvalRule{ApprovalRule: rule}
+		var err error
+		if rule.Match != "" {
+			if compiled.match, err = regexp.Compile(rule.Match); err != nil {
+				return nil, fmt.Errorf("approval rule %d: %w", i, err)
+			}
+		}
+		if rule.Unless != "" {
+			if compiled.unless, err = regexp.Compile(rule.Unless); err != nil {
+				return nil, fmt.Errorf("approval rule %d: %w", i, err)
+			}
+		}
+		m.rules = append(m.rules, compiled)
+	}
+
+	return m, nil
+}
+
+// matchRule returns the first rule matching call, or nil
+func (m *ApprovalMiddleware) matchRule(call *ai.ToolCall) *approvalRule {
+	for i := range m.rules {
+		rule := &m.rules[i]
+		if rule.Tool != "*" && !strings.EqualFold(rule.Tool, call.Name) {
+			continue
+		}
+
+		var value string
+		if rule.Arg != "" {
+			v, ok := call.Args[rule.Arg]
+			if !ok {
+				continue
+			}
+			if s, isString := v.(string); isString {
+				value = s
+			} else {
+				value = fmt.Sprint(v)
+			}
+		} else {
+			data, _ := json.Marshal(call.Args)
+			value = string(data)
+		}
+
+		if rule.match != nil && !rule.match.MatchString(value) {
+			continue
+		}
+		if rule.unless != nil && rule.unless.MatchString(value) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// describe renders the rule for prompts and errors
+func (r *approvalRule) describe() string {
+	desc := "tool " + r.Tool
+	if r.Arg != "" {
+		desc += ", " + r.Arg
+	}
+	if r.Match != "" {
+		desc += " matching " + r.Match
+	}
+	if r.Unless != "" {
+		desc += " not matching " + r.Unless
+	}
+	return desc
+}
+
+func (m *ApprovalMiddleware) BeforeExecution(ctx context.Context, call *ai.ToolCall) error {
+	rule := m.matchRule(call)
+	if rule == nil {
+		return nil
+	}
+	if rule.Action == config.ApprovalActionDeny {
+		return fmt.Errorf("tool '%s' denied by approval policy (%s)", call.Name, rule.describe())
+	}
+
+	prompt := getApprovalPrompter(ctx)
+	if prompt == nil {
+		return fmt.Errorf("tool '%s' requires approval but no one can be asked in this context", call.Name)
+	}
+
+	req := ApprovalRequest{
+		ID:         newApprovalID(),
+		ToolName:   call.Name,
+		Args:       call.Args,
+		Reason:     rule.describe(),
+		SessionKey: types.RequestSessionKey(ctx),
+		ChannelID:  types.RequestChannelID(ctx),
+		UserID:     types.RequestUserID(ctx),
+		ExpiresAt:  time.Now().Add(m.timeout),
+	}
+	p := &pendingApproval{req: req, decision: make(chan bool, 1)}
+
+	m.mu.Lock()
+	m.pending[req.ID] = p
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.pending, req.ID)
+		m.mu.Unlock()
+	}()
+
+	log.Printf("[Approval] %s waiting for approval (%s) in session %s", call.Name, req.ID, req.SessionKey)
+	if err := prompt(ctx, req); err != nil {
+		return fmt.Errorf("tool '%s' requires approval but the prompt could not be sent: %w", call.Name, err)
+	}
+
+	timer := time.NewTimer(m.timeout)
+	defer timer.Stop()
+
+	select {
+	case approved := <-p.decision:
+		if !approved {
+			log.Printf("[Approval] %s denied (%s)", call.Name, req.ID)
+			return fmt.Errorf("tool '%s' was denied by the user", call.Name)
+		}
+		log.Printf("[Approval] %s approved (%s)", call.Name, req.ID)
+		return nil
+	case <-timer.C:
+		log.Printf("[Approval] %s timed out waiting for approval (%s)", call.Name, req.ID)
+		return fmt.Errorf("tool '%s' was not approved within %s", call.Name, m.timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *ApprovalMiddleware) AfterExecution(ctx context.Context, call *ai.ToolCall, result *ExecutionResult) error {
+	return nil
+}
+
+// Resolve answers a pending request. The answer must come from the session
+// the request was made in.
+func (m *ApprovalMiddleware) Resolve(id, sessionKey string, approved bool) error {
+	m.mu.Lock()
+	p, ok := m.pending[id]
+	if ok && p.req.SessionKey != sessionKey {
+		ok = false
+	}
+	if ok {
+		delete(m.pending, id)
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no pending approval %q for this session", id)
+	}
+	p.decision <- approved
+	return nil
+}
+
+// Pending returns the requests waiting in a session, oldest first
+func (m *ApprovalMiddleware) Pending(sessionKey string) []ApprovalRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var reqs []ApprovalRequest
+	for _, p := range m.pending {
+		if p.req.SessionKey == sessionKey {
+			reqs = append(reqs, p.req)
+		}
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].ExpiresAt.Before(reqs[j].ExpiresAt) })
+	return reqs
+}
+
+// newApprovalID returns a short random ID, small enough for Telegram
+// callback data
+func newApprovalID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"conduit/internal/ai"
+	"conduit/internal/config"
+	"conduit/internal/tools/types"
+)
+
+func newTestApprovals(t *testing.T, cfg config.ApprovalConfig) *ApprovalMiddleware {
+	t.Helper()
+	m, err := NewApprovalMiddleware(cfg)
+	if err != nil {
+		t.Fatalf("NewApprovalMiddleware failed: %v", err)
+	}
+	return m
+}
+
+// approvalContext returns a request context for session whose prompter
+// hands each request to the returned channel
+func approvalContext(session string) (context.Context, chan ApprovalRequest) {
+	prompts := make(chan ApprovalRequest, 1)
+	ctx := types.WithRequestContext(context.Background(), "test", "user1", session)
+	ctx = WithApprovalPrompter(ctx, func(ctx context.Context, req ApprovalRequest) error {
+		prompts <- req
+		return nil
+	})
+	return ctx, prompts
+}
+
+func TestApprovalMiddleware_RuleMatching(t *testing.T) {
+	m := newTestApprovals(t, config.ApprovalConfig{Rules: []config.ApprovalRule{
+		{Tool: "Bash", Arg: "command", Match: `\brm\b`},
+		{Tool: "Write", Arg: "path", Unless: `(^|/)memory/`},
+		{Tool: "Gateway", Arg: "action", Match: `^restart$`, Action: config.ApprovalActionDeny},
+	}})
+
+	tests := []struct {
+		name string
+		call ai.ToolCall
+		want string // Expected rule tool, or "" for no match
+	}{
+		{"rm command", ai.ToolCall{Name: "Bash", Args: map[string]interface{}{"command": "rm -rf build"}}, "Bash"},
+		{"tool name case-insensitive", ai.ToolCall{Name: "bash", Args: map[string]interface{}{"command": "rm x"}}, "Bash"},
+		{"harmless command", ai.ToolCall{Name: "Bash", Args: map[string]interface{}{"command": "ls -la"}}, ""},
+		{"word boundary", ai.ToolCall{Name: "Bash", Args: map[string]interface{}{"command": "git rmdir"}}, ""},
+		{"write outside memory", ai.ToolCall{Name: "Write", Args: map[string]interface{}{"path": "/etc/hosts"}}, "Write"},
+		{"write to memory", ai.ToolCall{Name: "Write", Args: map[string]interface{}{"path": "memory/notes.md"}}, ""},
+		{"missing arg", ai.ToolCall{Name: "Write", Args: map[string]interface{}{}}, ""},
+		{"deny rule", ai.ToolCall{Name: "Gateway", Args: map[string]interface{}{"action": "restart"}}, "Gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := m.matchRule(&tt.call)
+			got := ""
+			if rule != nil {
+				got = rule.Tool
+			}
+			if got != tt.want {
+				t.Errorf("matchRule(%s %v) = %q, want %q", tt.call.Name, tt.call.Args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApprovalMiddleware_DenyAction(t *testing.T) {
+	m := newTestApprovals(t, config.ApprovalConfig{Rules: []config.ApprovalRule{
+		{Tool: "*", Action: config.ApprovalActionDeny},
+	}})
+	ctx, prompts := approvalContext("s1")
+
+	err := m.BeforeExecution(ctx, &ai.ToolCall{Name: "Read", Args: map[string]interface{}{"path": "a"}})
+	if err == nil || !strings.Contains(err.Error(), "denied by approval policy") {
+		t.Errorf("Expected a policy denial, got %v", err)
+	}
+	if len(prompts) != 0 {
+		t.Error("Deny rules must not prompt")
+	}
+}
+
+func TestApprovalMiddleware_NoMatchPassesThrough(t *testing.T) {
+	m := newTestApprovals(t, config.ApprovalConfig{Rules: []config.ApprovalRule{{Tool: "Bash"}}})
+
+	if err := m.BeforeExecution(context.Background(), &ai.ToolCall{Name: "Read"}); err != nil {
+		t.Errorf("Expected unmatched call to run, got %v", err)
+	}
+}
+
+func TestApprovalMiddleware_NoPrompterDenies(t *testing.T) {
+	m := newTestApprovals(t, config.ApprovalConfig{Rules: []config.ApprovalRule{{Tool: "Bash"}}})
+
+	err := m.BeforeExecution(context.Background(), &ai.ToolCall{Name: "Bash", Args: map[string]interface{}{"command": "ls"}})
+	if err == nil || !strings.Contains(err.Error(), "no one can be asked") {
+		t.Errorf("Expected denial without a prompter, got %v", err)
+	}
+}
+
+func TestApprovalMiddleware_ApproveAndDeny(t *testing.T) {
+	m := newTestApprovals(t, config.ApprovalConfig{Rules: []config.ApprovalRule{{Tool: "Bash"}}})
+	call := &ai.ToolCall{Name: "Bash", Args: map[string]interface{}{"command": "rm -rf build"}}
+
+	for _, approved := range []bool{true, false} {
+		ctx, prompts := approvalContext("s1")
+		done := make(chan error, 1)
+		go func() { done <- m.BeforeExecution(ctx, call) }()
+
+		req := <-prompts
+		if req.SessionKey != "s1" || req.UserID != "user1" || req.Summary() != "Bash: rm -rf build" {
+			t.Errorf("Unexpected request: %+v (summary %q)", req, req.Summary())
+		}
+		if pending := m.Pending("s1"); len(pending) != 1 || pending[0].ID != req.ID {
+			t.Errorf("Expected the request to be pending, got %+v", pending)
+		}
+
+		if err := m.Resolve(req.ID, "s1", approved); err != nil {
+			t.Fatalf("Resolve failed: %v", err)
+		}
+		err := <-done
+		if approved && err != nil {
+			t.Errorf("Expected approved call to run, got %v", err)
+		}
+		if !approved && (err == nil || !strings.Contains(err.Error(), "denied by the user")) {
+			t.Errorf("Expected denied call to fail, got %v", err)
+		}
+		if len(m.Pending("s1")) != 0 {
+			t.Error("Expected no pending requests after the decision")
+		}
+	}
+}
+
+func TestApprovalMiddleware_SessionMismatch(t *testing.T) {
+	m := newTestApprovals(t, config.ApprovalConfig{Rules: []config.ApprovalRule{{Tool: "Bash"}}})
+	ctx, prompts := approvalContext("s1")
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- m.BeforeExecution(ctx, &ai.ToolCall{Name: "Bash"}) }()
+	req := <-prompts
+
+	if err := m.Resolve(req.ID, "s2", true); err == nil {
+		t.Error("Expected another session's answer to be rejected")
+	}
+	if len(m.Pending("s1")) != 1 {
+		t.Error("Expected the request to remain pending")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context cancellation, got %v", err)
+	}
+}
+
+func TestApprovalMiddleware_Timeout(t *testing.T) {
+	m := newTestApprovals(t, config.ApprovalConfig{Rules: []config.ApprovalRule{{Tool: "Bash"}}})
+	m.timeout = 20 * time.Millisecond
+	ctx, _ := approvalContext("s1")
+
+	err := m.BeforeExecution(ctx, &ai.ToolCall{Name: "Bash"})
+	if err == nil || !strings.Contains(err.Error(), "not approved within") {
+		t.Errorf("Expected a timeout denial, got %v", err)
+	}
+	if len(m.Pending("s1")) != 0 {
+		t.Error("Expected the expired request to be removed")
+	}
+}
+
+func TestNewApprovalMiddleware_InvalidRegexp(t *testing.T) {
+	if _, err := NewApprovalMiddleware(config.ApprovalConfig{Rules: []config.ApprovalRule{{Tool: "Bash", Match: "("}}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
@@ -43,8 +43,17 @@ func (t *GatewayTool) Parameters() map[string]interface{} {
 				"description": "Channel ID for channel operations (required for enable_channel/disable_channel)",
 			},
 			"config": map[string]interface{}{
-				"type":        "object",
-				"description": "Configuration updates for update_config action",
+				"type": "object",
+				"description": "Partial configuration update for update_config action. Supported keys: " +
+					"model_aliases (alias -> model, empty value removes), " +
+					"rateLimiting ({enabled, anonymous: {windowSeconds, maxRequests}, authenticated: {...}}), " +
+					"enabled_tools (list, replaces the current list), " +
+					"channels (channel name -> enabled), " +
+					"agent_heartbeat ({enabled, interval_minutes})",
+			},
+			"persist": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also write the update_config change to the config file (default false)",
 			},
 		},
 		"required": []string{"action"},
@@ -233,7 +242,9 @@ func (t *GatewayTool) updateConfig(ctx context.Context, args map[string]interfac
 		}, nil
 	}
 
-	err := t.services.Gateway.UpdateConfiguration(ctx, config)
+	persist, _ := args["persist"].(bool)
+
+	err := t.services.Gateway.UpdateConfiguration(ctx, config, persist)
 	if err != nil {
 		return &types.ToolResult{
 			Success: false,
@@ -247,6 +258,7 @@ func (t *GatewayTool) updateConfig(ctx context.Context, args map[string]interfac
 		Data: map[string]interface{}{
 			"action":    "update_config",
 			"config":    config,
+			"persisted": persist,
 			"timestamp": time.Now(),
 		},
 	}, nil
@@ -3,15 +3,21 @@ package tools
 import (
 	"context"
 	"fmt"
-	"os/exec"
+	"log"
 	"strings"
+	"sync"
 
+	"conduit/internal/tools/sandbox"
 	"conduit/internal/tools/types"
 )
 
 // ExecTool implements command execution functionality
 type ExecTool struct {
 	registry *Registry
+
+	runnerOnce sync.Once
+	runner     *sandbox.Runner
+	runnerErr  error
 }
 
 func (t *ExecTool) Name() string {
@@ -85,92 +91,138 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]interface{}) (*t
 			}), nil
 	}
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
-	cmd.Dir = cwd
+	runner, err := t.sandboxRunner()
+	if err != nil {
+		return types.NewErrorResult("sandbox_unavailable",
+			fmt.Sprintf("Commands cannot run in a sandbox on this host: %v", err)).
+			WithParameter("command", command).
+			WithSuggestions([]string{
+				"Install bubblewrap (bwrap) on the gateway host",
+				"Enable unprivileged user namespaces for the namespaces backend",
+				"Set tools.sandbox.backend to \"none\" to run commands without isolation",
+			}), nil
+	}
 
-	output, err := cmd.CombinedOutput()
+	res, err := runner.Run(ctx, sandbox.Request{Command: command, Dir: cwd})
 	if err != nil {
+		return types.NewErrorResult("sandbox_error",
+			fmt.Sprintf("Command could not be started: %v", err)).
+			WithParameter("command", command).
+			WithContext(map[string]interface{}{
+				"working_directory": cwd,
+				"sandbox_backend":   runner.Backend(),
+			}).
+			WithSuggestions([]string{
+				"Check that the working directory exists",
+				"Check sandbox restrictions",
+			}), nil
+	}
+
+	output := string(res.Output)
+	if res.Truncated {
+		output += fmt.Sprintf("\n[output truncated after %d bytes]", len(res.Output))
+	}
+
+	if res.TimedOut || res.ExitCode != 0 {
 		// Enhanced error categorization with detailed context
 		errorType := "command_failed"
-		suggestions := []string{"Check command syntax", "Verify the command exists"}
-
-		if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode := exitError.ExitCode()
-			errorType = "command_failed"
-
-			switch exitCode {
-			case 1:
-				suggestions = []string{
-					"Command executed but returned error status",
-					"Check command syntax and arguments",
-					"Review command output for error details",
-				}
-			case 126:
-				suggestions = []string{
-					"Command found but not executable",
-					"Check file permissions",
-					"Ensure the command has execute permissions",
-				}
-			case 127:
-				suggestions = []string{
-					"Command not found",
-					"Check if the command is installed",
-					"Verify the command path",
-				}
-			default:
-				suggestions = []string{
-					fmt.Sprintf("Command exited with code %d", exitCode),
-					"Check command output for error details",
-					"Review command syntax and arguments",
-				}
-			}
-		} else if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "context deadline") {
+		var suggestions []string
+		message := fmt.Sprintf("Command execution failed: exit status %d", res.ExitCode)
+
+		switch {
+		case res.TimedOut:
 			errorType = "timeout_error"
+			message = "Command execution timed out"
 			suggestions = []string{
 				"Command execution timed out",
 				"Try a simpler or faster command",
 				"Use commands that complete quickly",
 			}
-		} else if strings.Contains(err.Error(), "permission denied") {
-			errorType = "permission_denied"
+		case res.ExitCode == 1:
 			suggestions = []string{
-				"Insufficient permissions to execute command",
-				"Try a different command that doesn't require elevated privileges",
-				"Check sandbox restrictions",
+				"Command executed but returned error status",
+				"Check command syntax and arguments",
+				"Review command output for error details",
+			}
+		case res.ExitCode == 126:
+			suggestions = []string{
+				"Command found but not executable",
+				"Check file permissions",
+				"Ensure the command has execute permissions",
+			}
+		case res.ExitCode == 127:
+			suggestions = []string{
+				"Command not found",
+				"Check if the command is installed",
+				"Verify the command path",
+			}
+		case res.ExitCode < 0:
+			message = "Command execution failed: killed by signal"
+			suggestions = []string{
+				"The command was killed, possibly for exceeding its CPU or memory limit",
+				"Try a command that does less work",
+			}
+		default:
+			suggestions = []string{
+				fmt.Sprintf("Command exited with code %d", res.ExitCode),
+				"Check command output for error details",
+				"Review command syntax and arguments",
 			}
 		}
+		if strings.Contains(output, "Read-only file system") {
+			errorType = "permission_denied"
+			suggestions = append(suggestions,
+				"Only the allowed paths are writable in the sandbox",
+				"Write to the workspace directory or /tmp instead")
+		}
 
-		result := types.NewErrorResult(errorType,
-			fmt.Sprintf("Command execution failed: %v", err)).
+		result := types.NewErrorResult(errorType, message).
 			WithParameter("command", command).
 			WithContext(map[string]interface{}{
 				"working_directory": cwd,
-				"output":            string(output),
-				"error_detail":      err.Error(),
+				"output":            output,
 				"command_length":    len(command),
-				"exit_code":         getExitCode(err),
+				"exit_code":         res.ExitCode,
 				"has_output":        len(output) > 0,
+				"output_truncated":  res.Truncated,
+				"sandbox_backend":   res.Backend,
 			}).
 			WithSuggestions(suggestions)
 
 		// Also include the output in the content for failed commands
-		result.Content = string(output)
+		result.Content = output
 
 		return result, nil
 	}
 
 	return &types.ToolResult{
 		Success: true,
-		Content: string(output),
+		Content: output,
 		Data: map[string]interface{}{
 			"command":           command,
 			"working_directory": cwd,
-			"output_length":     len(output),
+			"output_length":     len(res.Output),
+			"output_truncated":  res.Truncated,
 			"exit_code":         0,
+			"sandbox_backend":   res.Backend,
 		},
 	}, nil
 }
 
+// sandboxRunner builds the command sandbox on first use, so a host without a
+// usable backend only fails Bash calls rather than gateway startup
+func (t *ExecTool) sandboxRunner() (*sandbox.Runner, error) {
+	t.runnerOnce.Do(func() {
+		t.runner, t.runnerErr = sandbox.New(t.registry.sandboxCfg)
+		if t.runnerErr != nil {
+			log.Printf("[ExecTool] Sandbox unavailable: %v", t.runnerErr)
+			return
+		}
+		log.Printf("[ExecTool] Running commands with the %s sandbox backend", t.runner.Backend())
+	})
+	return t.runner, t.runnerErr
+}
+
 // GetUsageExamples implements types.UsageExampleProvider for ExecTool.
 func (t *ExecTool) GetUsageExamples() []types.ToolExample {
 	return []types.ToolExample{
@@ -209,11 +261,3 @@ func (t *ExecTool) GetUsageExamples() []types.ToolExample {
 		},
 	}
 }
-
-// getExitCode extracts the exit code from an exec error
-func getExitCode(err error) int {
-	if exitError, ok := err.(*exec.ExitError); ok {
-		return exitError.ExitCode()
-	}
-	return -1
-}
@@ -74,6 +74,10 @@ type ConversationResponse struct {
 	Steps       int                `json:"steps"`
 	ToolResults []*ExecutionResult `json:"tool_results,omitempty"`
 	ChainDepth  int                `json:"chain_depth"`
+
+	// ToolTurns are the assistant tool calls and tool results exchanged
+	// during the flow, in order, for persisting to session history
+	ToolTurns []ai.ChatMessage `json:"tool_turns,omitempty"`
 }
 
 // NewExecutionEngine creates a new tool execution engine
@@ -103,10 +107,6 @@ func (e *ExecutionEngine) ExecuteToolCalls(ctx context.Context, calls []ai.ToolC
 		return nil, nil
 	}
 
-	// Add timeout to context
-	ctx, cancel := context.WithTimeout(ctx, e.timeout)
-	defer cancel()
-
 	results := make([]*ExecutionResult, len(calls))
 
 	if len(calls) == 1 {
@@ -158,8 +158,11 @@ func (e *ExecutionEngine) executeSingle(ctx context.Context, call ai.ToolCall) *
 		}
 	}
 
-	// Execute tool
-	result, err := e.registry.ExecuteTool(ctx, call.Name, call.Args)
+	// Execute tool. The timeout starts after middleware, so time spent
+	// waiting for approval doesn't count against the tool.
+	toolCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	result, err := e.registry.ExecuteTool(toolCtx, call.Name, call.Args)
+	cancel()
 	execResult.Result = result
 	execResult.Error = err
 	execResult.Duration = time.Since(start)
@@ -244,7 +247,7 @@ func (e *ExecutionEngine) HandleToolCallFlow(
 	for i, tc := range initialResp.ToolCalls {
 		log.Printf("[ExecutionEngine] Tool call %d: %s", i, tc.Name)
 	}
-	return e.handleToolCallFlowRecursive(ctx, provider, initialReq, initialResp, 0)
+	return e.handleToolCallFlowRecursive(ctx, provider, initialReq, initialResp, 0, len(initialReq.Messages))
 }
 
 // handleToolCallFlowRecursive handles tool chaining with depth limits
@@ -254,6 +257,7 @@ func (e *ExecutionEngine) handleToolCallFlowRecursive(
 	initialReq *ai.GenerateRequest,
 	initialResp *ai.GenerateResponse,
 	depth int,
+	baseLen int, // Messages in the original request; later ones are tool turns
 ) (*ConversationResponse, error) {
 	// Prevent infinite tool chains
 	if depth >= e.maxChains {
@@ -276,11 +280,12 @@ func (e *ExecutionEngine) handleToolCallFlowRecursive(
 			Usage:      &initialResp.Usage,
 			Steps:      depth + 1,
 			ChainDepth: depth,
+			ToolTurns:  toolTurns(initialReq.Messages, baseLen),
 		}, nil
 	}
 
 	// Start conversation history with initial request/response
-	conversationHistory := append(initialReq.Messages, ai.ChatMessage{
+	conversationHistory := append(initialReq.Messages[:len(initialReq.Messages):len(initialReq.Messages)], ai.ChatMessage{
 		Role:      "assistant",
 		Content:   initialResp.Content,
 		ToolCalls: initialResp.ToolCalls,
@@ -320,7 +325,7 @@ func (e *ExecutionEngine) handleToolCallFlowRecursive(
 	// Check for additional tool calls (tool chaining)
 	if len(finalResp.ToolCalls) > 0 {
 		// Recursive tool calling with depth tracking
-		return e.handleToolCallFlowRecursive(ctx, provider, finalReq, finalResp, depth+1)
+		return e.handleToolCallFlowRecursive(ctx, provider, finalReq, finalResp, depth+1, baseLen)
 	}
 
 	// No more tool calls - return final response
@@ -330,9 +335,18 @@ func (e *ExecutionEngine) handleToolCallFlowRecursive(
 		Steps:       2 + depth, // Initial + final + any recursive steps
 		ToolResults: toolResults,
 		ChainDepth:  depth,
+		ToolTurns:   toolTurns(conversationHistory, baseLen),
 	}, nil
 }
 
+// toolTurns returns a copy of the messages added after the original request
+func toolTurns(history []ai.ChatMessage, baseLen int) []ai.ChatMessage {
+	if baseLen >= len(history) {
+		return nil
+	}
+	return append([]ai.ChatMessage(nil), history[baseLen:]...)
+}
+
 // formatToolResultForAI formats tool results for AI consumption
 func (e *ExecutionEngine) formatToolResultForAI(result *ExecutionResult) string {
 	if result.Error != nil {
@@ -52,3 +52,8 @@ func (a *ConversationResponseAdapter) GetSteps() int {
 func (a *ConversationResponseAdapter) HasToolResults() bool {
 	return len(a.response.ToolResults) > 0
 }
+
+// GetToolTurns implements ai.ToolTurnReporter
+func (a *ConversationResponseAdapter) GetToolTurns() []ai.ChatMessage {
+	return a.response.ToolTurns
+}
@@ -512,3 +512,54 @@ func TestHandleToolCallFlow_ModelPropagation(t *testing.T) {
 		t.Fatalf("Expected model 'claude-sonnet-4-6' in follow-up request, got '%s'", followUpReq.Model)
 	}
 }
+
+func TestHandleToolCallFlow_ReturnsToolTurns(t *testing.T) {
+	registry := NewMockRegistry()
+	registry.AddTool(&MockTool{
+		name:       "test_tool",
+		parameters: map[string]interface{}{"type": "object"},
+		executeFunc: func(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+			return &ToolResult{Success: true, Content: "tool result"}, nil
+		},
+	})
+
+	engine := NewExecutionEngine(registry, 3, 30*time.Second, 10)
+
+	provider := ai.NewMockProvider("test")
+	provider.AddResponse("final answer", nil)
+
+	initialReq := &ai.GenerateRequest{
+		Messages: []ai.ChatMessage{{Role: "user", Content: "hello"}},
+		Tools:    []ai.Tool{{Name: "test_tool"}},
+	}
+	initialResp := &ai.GenerateResponse{
+		Content: "checking",
+		ToolCalls: []ai.ToolCall{
+			{ID: "call_1", Name: "test_tool", Args: map[string]interface{}{"q": "a"}},
+			{ID: "call_2", Name: "test_tool", Args: map[string]interface{}{"q": "b"}},
+		},
+	}
+
+	resp, err := engine.HandleToolCallFlow(context.Background(), provider, initialReq, initialResp)
+	if err != nil {
+		t.Fatalf("HandleToolCallFlow failed: %v", err)
+	}
+
+	turns := resp.ToolTurns
+	if len(turns) != 3 {
+		t.Fatalf("Expected 3 tool turns, got %d: %+v", len(turns), turns)
+	}
+	if turns[0].Role != "assistant" || turns[0].Content != "checking" || len(turns[0].ToolCalls) != 2 {
+		t.Errorf("Unexpected tool call turn: %+v", turns[0])
+	}
+	for i, id := range []string{"call_1", "call_2"} {
+		if turns[i+1].Role != "tool" || turns[i+1].ToolCallID != id {
+			t.Errorf("Expected result for %s, got %+v", id, turns[i+1])
+		}
+	}
+
+	// The original request must not be modified
+	if len(initialReq.Messages) != 1 {
+		t.Errorf("Expected request messages untouched, got %d", len(initialReq.Messages))
+	}
+}
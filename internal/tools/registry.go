@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"conduit/internal/config"
 	"conduit/internal/tools/communication"
@@ -22,6 +23,7 @@ import (
 type Registry struct {
 	tools        map[string]types.Tool
 	sandboxCfg   config.SandboxConfig
+	enabledMu    sync.RWMutex // Guards enabledTools, which can change at runtime
 	enabledTools map[string]bool
 	services     *types.ToolServices
 }
@@ -51,6 +53,25 @@ func NewRegistry(cfg config.ToolsConfig) *Registry {
 	return registry
 }
 
+// SetEnabledTools replaces the set of enabled tools
+func (r *Registry) SetEnabledTools(names []string) {
+	enabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		enabled[name] = true
+	}
+
+	r.enabledMu.Lock()
+	r.enabledTools = enabled
+	r.enabledMu.Unlock()
+}
+
+// isEnabled reports whether a tool is enabled
+func (r *Registry) isEnabled(name string) bool {
+	r.enabledMu.RLock()
+	defer r.enabledMu.RUnlock()
+	return r.enabledTools[name]
+}
+
 // SetServices sets the service dependencies and registers tools
 func (r *Registry) SetServices(services *types.ToolServices) {
 	r.services = services
@@ -181,7 +202,7 @@ func (r *Registry) registerAllTools() {
 // ExecuteTool executes a tool by name with the given arguments, including validation
 func (r *Registry) ExecuteTool(ctx context.Context, name string, args map[string]interface{}) (*types.ToolResult, error) {
 	// Check if tool is enabled
-	if !r.enabledTools[name] {
+	if !r.isEnabled(name) {
 		return types.NewErrorResult("tool_disabled", fmt.Sprintf("tool '%s' is not enabled", name)), nil
 	}
 
@@ -268,7 +289,7 @@ func (r *Registry) createValidationErrorResult(toolName string, validation *type
 func (r *Registry) GetAvailableTools() map[string]types.Tool {
 	available := make(map[string]types.Tool)
 	for name, tool := range r.tools {
-		if r.enabledTools[name] {
+		if r.isEnabled(name) {
 			available[name] = tool
 		}
 	}
@@ -311,7 +332,7 @@ func (r *Registry) GetToolSchemasWithContext(ctx context.Context) []map[string]i
 // GetToolHelp returns comprehensive help information for a specific tool including examples
 func (r *Registry) GetToolHelp(toolName string) map[string]interface{} {
 	tool, exists := r.tools[toolName]
-	if !exists || !r.enabledTools[toolName] {
+	if !exists || !r.isEnabled(toolName) {
 		return map[string]interface{}{
 			"error": fmt.Sprintf("Tool '%s' not found or not enabled", toolName),
 		}
@@ -321,7 +342,7 @@ func (r *Registry) GetToolHelp(toolName string) map[string]interface{} {
 		"name":        tool.Name(),
 		"description": tool.Description(),
 		"parameters":  tool.Parameters(),
-		"enabled":     r.enabledTools[toolName],
+		"enabled":     r.isEnabled(toolName),
 	}
 
 	// Add schema hints if available
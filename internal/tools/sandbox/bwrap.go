@@ -0,0 +1,27 @@
+package sandbox
+
+// bubblewrapArgs lays out the sandbox: the host root read-only, fresh /dev,
+// /proc and /tmp, the allowed paths bound writable, and every namespace
+// unshared except the network when it is granted. The seccomp program is
+// read from fd 3.
+func (r *Runner) bubblewrapArgs(dir string) []string {
+	args := []string{
+		"--die-with-parent",
+		"--new-session",
+		"--unshare-all",
+	}
+	if r.network {
+		args = append(args, "--share-net")
+	}
+	args = append(args,
+		"--cap-drop", "ALL",
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+	)
+	for _, p := range r.writable {
+		args = append(args, "--bind-try", p, p)
+	}
+	return append(args, "--chdir", dir, "--seccomp", "3")
+}
@@ -0,0 +1,38 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+func bubblewrapSupported() bool {
+	return true
+}
+
+func (r *Runner) bubblewrapCommand(ctx context.Context, dir, command string) (*exec.Cmd, func(), error) {
+	filter, err := r.seccompProgram()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The filter is a few hundred bytes, well under the pipe buffer, so it
+	// can be written before bwrap starts reading
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create seccomp pipe: %w", err)
+	}
+	_, err = pw.Write(filter)
+	pw.Close()
+	if err != nil {
+		pr.Close()
+		return nil, nil, fmt.Errorf("failed to write seccomp filter: %w", err)
+	}
+
+	args := append(r.bubblewrapArgs(dir), "--", "/bin/sh", "-c", r.limitScript(), "sh", command)
+	cmd := exec.CommandContext(ctx, r.bwrapPath, args...)
+	cmd.ExtraFiles = []*os.File{pr}
+	cmd.Env = sandboxEnv(dir)
+	return cmd, func() { pr.Close() }, nil
+}
@@ -0,0 +1,340 @@
+package sandbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// specEnv carries the helperSpec to a re-executed sandbox helper
+const specEnv = "CONDUIT_SANDBOX_SPEC"
+
+// initialized is set once Init has run in a normal (non-helper) process,
+// meaning re-executing /proc/self/exe reaches the helper
+var initialized bool
+
+// helperSpec is what the helper needs to build the sandbox and run the command
+type helperSpec struct {
+	Command     string   `json:"command"`
+	Dir         string   `json:"dir"`
+	Writable    []string `json:"writable"`
+	CPUSeconds  int      `json:"cpu_seconds"`
+	MemoryBytes int64    `json:"memory_bytes"`
+	Env         []string `json:"env"`
+}
+
+// Init turns the process into the sandbox helper when it was started as one
+// and never returns in that case. Otherwise it returns immediately and enables
+// the namespaces backend. Call it first thing in main, before any flags are
+// parsed or services started.
+func Init() {
+	raw, ok := os.LookupEnv(specEnv)
+	if !ok {
+		initialized = true
+		return
+	}
+
+	err := runHelper(raw)
+	fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+	os.Exit(helperFailureExit)
+}
+
+func namespacesSupported() bool {
+	if !initialized {
+		return false
+	}
+	_, err := os.Stat("/proc/self/ns/user")
+	return err == nil
+}
+
+// namespacesCommand re-executes this binary as the helper in new user, mount,
+// PID, IPC and UTS namespaces, plus a network namespace unless network access
+// is granted. The caller's uid and gid map to root inside so the helper can
+// set up mounts; it drops every capability before running the command.
+func (r *Runner) namespacesCommand(ctx context.Context, dir, command string) (*exec.Cmd, error) {
+	spec, err := json.Marshal(helperSpec{
+		Command:     command,
+		Dir:         dir,
+		Writable:    r.writable,
+		CPUSeconds:  r.cpuSeconds,
+		MemoryBytes: r.memoryBytes,
+		Env:         sandboxEnv(dir),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sandbox spec: %w", err)
+	}
+
+	flags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+		syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS)
+	if !r.network {
+		flags |= syscall.CLONE_NEWNET
+	}
+
+	cmd := exec.CommandContext(ctx, "/proc/self/exe")
+	cmd.Args = []string{"conduit-sandbox"}
+	cmd.Env = []string{specEnv + "=" + string(spec)}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:                 flags,
+		UidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+		GidMappingsEnableSetgroups: false,
+	}
+	return cmd, nil
+}
+
+// runHelper builds the sandbox from inside the new namespaces and execs the
+// command. Credentials, no_new_privs and seccomp are per-thread and carry
+// over exec from the calling thread, so everything runs on one OS thread.
+func runHelper(raw string) error {
+	runtime.LockOSThread()
+
+	var spec helperSpec
+	if err := json.Unmarshal([]byte(raw), &spec); err != nil {
+		return fmt.Errorf("invalid spec: %w", err)
+	}
+
+	if err := setupMounts(spec.Writable); err != nil {
+		return err
+	}
+	if err := unix.Chdir(spec.Dir); err != nil {
+		return fmt.Errorf("chdir %s: %w", spec.Dir, err)
+	}
+
+	limits := []struct {
+		resource int
+		value    uint64
+	}{
+		{unix.RLIMIT_CPU, uint64(spec.CPUSeconds)},
+		{unix.RLIMIT_AS, uint64(spec.MemoryBytes)},
+		{unix.RLIMIT_CORE, 0},
+	}
+	for _, l := range limits {
+		if err := unix.Setrlimit(l.resource, &unix.Rlimit{Cur: l.value, Max: l.value}); err != nil {
+			return fmt.Errorf("setrlimit %d: %w", l.resource, err)
+		}
+	}
+
+	if err := dropCapabilities(); err != nil {
+		return err
+	}
+	if err := installSeccomp(); err != nil {
+		return err
+	}
+
+	return unix.Exec("/bin/sh", []string{"sh", "-c", spec.Command}, spec.Env)
+}
+
+// setupMounts makes every mount read-only except the writable paths and a
+// private /tmp. Mounts are made private first so nothing propagates back to
+// the host.
+func setupMounts(writable []string) error {
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+
+	// Writable paths are opened before /tmp is replaced, so ones under /tmp
+	// can still be bound from the host
+	type bindSource struct {
+		path string
+		fd   int
+		dir  bool
+	}
+	var sources []bindSource
+	for _, p := range writable {
+		resolved, err := filepath.EvalSymlinks(p)
+		if err != nil {
+			continue // Missing paths are simply not writable
+		}
+		fd, err := unix.Open(resolved, unix.O_PATH|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("open %s: %w", resolved, err)
+		}
+		var st unix.Stat_t
+		if err := unix.Fstat(fd, &st); err != nil {
+			return fmt.Errorf("stat %s: %w", resolved, err)
+		}
+		sources = append(sources, bindSource{resolved, fd, st.Mode&unix.S_IFMT == unix.S_IFDIR})
+	}
+
+	if err := unix.Mount("tmpfs", "/tmp", "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=1777"); err != nil {
+		return fmt.Errorf("mount /tmp: %w", err)
+	}
+
+	keep := []string{"/tmp"}
+	for _, src := range sources {
+		if underAny(src.path, keep[:1]) {
+			if err := makeMountTarget(src.path, src.dir); err != nil {
+				return err
+			}
+		}
+		from := "/proc/self/fd/" + strconv.Itoa(src.fd)
+		if err := unix.Mount(from, src.path, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return fmt.Errorf("bind %s: %w", src.path, err)
+		}
+		unix.Close(src.fd)
+		keep = append(keep, src.path)
+	}
+
+	mounts, err := mountPoints()
+	if err != nil {
+		return err
+	}
+
+	// A /proc for the new PID namespace. The inherited one shows the
+	// gateway's processes, whose environ holds API keys and whose root
+	// links lead around the read-only mounts, so hosts that refuse a new
+	// procfs (containers with masked paths) get an empty /proc instead.
+	if err := unix.Mount("proc", "/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+		if err := unix.Mount("tmpfs", "/proc", "tmpfs", unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, "mode=0555"); err != nil {
+			return fmt.Errorf("mask /proc: %w", err)
+		}
+	} else if mounts, err = mountPoints(); err != nil {
+		return err
+	}
+	for _, mp := range mounts {
+		if underAny(mp, keep) {
+			continue
+		}
+		if err := remountReadOnly(mp); err != nil {
+			// Kernel filesystems under /proc, /sys and /dev may refuse a
+			// remount; they are not places a command can store data
+			if mp == "/" || !underAny(mp, []string{"/proc", "/sys", "/dev"}) {
+				return fmt.Errorf("remount %s read-only: %w", mp, err)
+			}
+		}
+	}
+	return nil
+}
+
+// makeMountTarget creates an empty file or directory to bind over inside the
+// sandbox's /tmp
+func makeMountTarget(path string, dir bool) error {
+	if dir {
+		return os.MkdirAll(path, 0o755)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// mountPoints lists the mount points visible in this mount namespace
+func mountPoints() ([]string, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, fmt.Errorf("read mountinfo: %w", err)
+	}
+	defer f.Close()
+
+	var points []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		points = append(points, unescapeMountPath(fields[4]))
+	}
+	return points, scanner.Err()
+}
+
+// unescapeMountPath decodes the octal escapes mountinfo uses for spaces,
+// tabs, newlines and backslashes
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// remountReadOnly remounts mp read-only. Flags like nosuid and nodev may be
+// locked by the parent namespace, so the current ones are carried over.
+func remountReadOnly(mp string) error {
+	var st unix.Statfs_t
+	if err := unix.Statfs(mp, &st); err != nil {
+		return err
+	}
+
+	flags := uintptr(unix.MS_REMOUNT | unix.MS_BIND | unix.MS_RDONLY)
+	for stFlag, msFlag := range map[int64]uintptr{
+		unix.ST_NOSUID:      unix.MS_NOSUID,
+		unix.ST_NODEV:       unix.MS_NODEV,
+		unix.ST_NOEXEC:      unix.MS_NOEXEC,
+		unix.ST_NOATIME:     unix.MS_NOATIME,
+		unix.ST_NODIRATIME:  unix.MS_NODIRATIME,
+		unix.ST_RELATIME:    unix.MS_RELATIME,
+		unix.ST_SYNCHRONOUS: unix.MS_SYNCHRONOUS,
+	} {
+		if int64(st.Flags)&stFlag != 0 {
+			flags |= msFlag
+		}
+	}
+	return unix.Mount("", mp, "", flags, "")
+}
+
+// underAny reports whether path is one of roots or inside one
+func underAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if path == root || strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// dropCapabilities empties the bounding and ambient sets, so the command
+// gains no capabilities when exec'd even though it runs as root inside the
+// user namespace
+func dropCapabilities() error {
+	for c := 0; c < 64; c++ {
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0); err != nil {
+			if err == unix.EINVAL {
+				break // Past the last capability this kernel knows
+			}
+			return fmt.Errorf("drop capability %d: %w", c, err)
+		}
+	}
+	_ = unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0)
+	return nil
+}
+
+func installSeccomp() error {
+	prog, err := seccompFilter()
+	if err != nil {
+		return err
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("set no_new_privs: %w", err)
+	}
+	fprog := unix.SockFprog{Len: uint16(len(prog)), Filter: &prog[0]}
+	if err := unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&fprog)), 0, 0); err != nil {
+		return fmt.Errorf("install seccomp filter: %w", err)
+	}
+	return nil
+}
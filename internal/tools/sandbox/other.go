@@ -0,0 +1,30 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+var errLinuxOnly = errors.New("sandboxing requires Linux")
+
+// Init is a no-op outside Linux, where only the none backend is available
+func Init() {}
+
+func namespacesSupported() bool {
+	return false
+}
+
+func bubblewrapSupported() bool {
+	return false
+}
+
+func (r *Runner) namespacesCommand(ctx context.Context, dir, command string) (*exec.Cmd, error) {
+	return nil, errLinuxOnly
+}
+
+func (r *Runner) bubblewrapCommand(ctx context.Context, dir, command string) (*exec.Cmd, func(), error) {
+	return nil, nil, errLinuxOnly
+}
@@ -0,0 +1,273 @@
+// Package sandbox runs shell commands for the Bash tool in an isolated
+// environment: a read-only view of the host filesystem with write access
+// only to the configured allowed paths, no network unless granted, and
+// limits on CPU time, memory, wall-clock time and output size.
+//
+// Two Linux backends are available. "bubblewrap" uses the bwrap binary when
+// it is installed. "namespaces" re-executes the current binary as a helper
+// inside new user, mount, PID, IPC, UTS and network namespaces; it requires
+// the binary to call Init at the start of main.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"conduit/internal/config"
+)
+
+// Backends selectable through tools.sandbox.backend
+const (
+	BackendAuto       = "auto"
+	BackendBubblewrap = "bubblewrap"
+	BackendNamespaces = "namespaces"
+	BackendNone       = "none" // No isolation; limits and the minimal environment still apply
+)
+
+// Limit defaults applied when tools.sandbox leaves them unset
+const (
+	DefaultCPUSeconds     = 60
+	DefaultMemoryMB       = 2048
+	DefaultTimeoutSeconds = 120
+	DefaultMaxOutputKB    = 256
+)
+
+// killGrace is how long Run waits for output after killing a timed-out
+// command before giving up on its pipes
+const killGrace = 2 * time.Second
+
+// helperFailureExit is the namespaces helper's exit status when it could not
+// build the sandbox, as distinct from the command's own status
+const helperFailureExit = 125
+
+// ErrUnavailable is returned when no isolating backend can run on this host
+var ErrUnavailable = errors.New("no sandbox backend available")
+
+// Request is a command to run
+type Request struct {
+	Command string // Passed to sh -c
+	Dir     string // Working directory
+}
+
+// Result is the outcome of a command. A non-zero exit is not an error.
+type Result struct {
+	Output    []byte // Combined stdout and stderr, capped at the output limit
+	Truncated bool   // Output exceeded the limit and was cut
+	ExitCode  int    // -1 if the command was killed by a signal
+	TimedOut  bool   // Killed after the wall-clock limit
+	Backend   string
+}
+
+// Runner executes commands with a fixed backend and limits
+type Runner struct {
+	backend      string
+	writable     []string
+	network      bool
+	cpuSeconds   int
+	memoryBytes  int64
+	timeout      time.Duration
+	maxOutput    int
+	bwrapPath    string
+	seccompOnce  sync.Once
+	seccompProg  []byte
+	seccompError error
+}
+
+// New builds a Runner from tools.sandbox. With the auto backend, bubblewrap
+// is preferred, then namespaces; if neither can run, ErrUnavailable is
+// returned rather than falling back to running commands unisolated.
+func New(cfg config.SandboxConfig) (*Runner, error) {
+	r := &Runner{
+		network:     cfg.Network,
+		cpuSeconds:  positiveOr(cfg.CPUSeconds, DefaultCPUSeconds),
+		memoryBytes: int64(positiveOr(cfg.MemoryMB, DefaultMemoryMB)) * 1024 * 1024,
+		timeout:     time.Duration(positiveOr(cfg.TimeoutSeconds, DefaultTimeoutSeconds)) * time.Second,
+		maxOutput:   positiveOr(cfg.MaxOutputKB, DefaultMaxOutputKB) * 1024,
+	}
+
+	for _, p := range cfg.AllowedPaths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			continue
+		}
+		r.writable = append(r.writable, abs)
+	}
+
+	backend := cfg.Backend
+	if backend == "" {
+		backend = BackendAuto
+	}
+
+	switch backend {
+	case BackendAuto:
+		if path, err := exec.LookPath("bwrap"); err == nil && bubblewrapSupported() {
+			r.backend, r.bwrapPath = BackendBubblewrap, path
+		} else if namespacesSupported() {
+			r.backend = BackendNamespaces
+		} else {
+			return nil, fmt.Errorf("%w: install bubblewrap, or call sandbox.Init in main to enable namespaces", ErrUnavailable)
+		}
+	case BackendBubblewrap:
+		path, err := exec.LookPath("bwrap")
+		if err != nil || !bubblewrapSupported() {
+			return nil, fmt.Errorf("%w: bwrap not found in PATH", ErrUnavailable)
+		}
+		r.backend, r.bwrapPath = BackendBubblewrap, path
+	case BackendNamespaces:
+		if !namespacesSupported() {
+			return nil, fmt.Errorf("%w: namespaces backend requires Linux and sandbox.Init in main", ErrUnavailable)
+		}
+		r.backend = BackendNamespaces
+	case BackendNone:
+		r.backend = BackendNone
+	default:
+		return nil, fmt.Errorf("unknown sandbox backend %q", backend)
+	}
+
+	return r, nil
+}
+
+// Backend returns the backend commands run under
+func (r *Runner) Backend() string {
+	return r.backend
+}
+
+// Run executes req, killing it once the wall-clock limit passes or ctx ends
+func (r *Runner) Run(ctx context.Context, req Request) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cmd, cleanup, err := r.command(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	output := &cappedBuffer{limit: r.maxOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	cmd.Cancel = func() error {
+		// Kill the whole process group, not just the shell
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = killGrace
+
+	runErr := cmd.Run()
+
+	result := &Result{
+		Output:    output.Bytes(),
+		Truncated: output.truncated,
+		Backend:   r.backend,
+		TimedOut:  errors.Is(ctx.Err(), context.DeadlineExceeded),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case runErr == nil:
+	case errors.As(runErr, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case errors.Is(runErr, exec.ErrWaitDelay):
+		result.ExitCode = -1
+	case ctx.Err() != nil:
+		result.ExitCode = -1
+	default:
+		return nil, fmt.Errorf("failed to start sandboxed command: %w", runErr)
+	}
+	if result.ExitCode == helperFailureExit && r.backend == BackendNamespaces {
+		return nil, fmt.Errorf("sandbox setup failed: %s", bytes.TrimSpace(result.Output))
+	}
+
+	return result, nil
+}
+
+// command builds the process for req under the runner's backend
+func (r *Runner) command(ctx context.Context, req Request) (*exec.Cmd, func(), error) {
+	dir := req.Dir
+	if dir == "" {
+		dir = "/"
+	}
+
+	switch r.backend {
+	case BackendBubblewrap:
+		return r.bubblewrapCommand(ctx, dir, req.Command)
+	case BackendNamespaces:
+		cmd, err := r.namespacesCommand(ctx, dir, req.Command)
+		return cmd, func() {}, err
+	default:
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", r.limitScript(), "sh", req.Command)
+		cmd.Dir = dir
+		cmd.Env = sandboxEnv(dir)
+		return cmd, func() {}, nil
+	}
+}
+
+// limitScript applies CPU and memory limits with the shell's ulimit before
+// running the command passed as $1. Setting both soft and hard limits means
+// the command cannot raise them again; if a limit can't be set the command
+// does not run.
+func (r *Runner) limitScript() string {
+	return "ulimit -t " + strconv.Itoa(r.cpuSeconds) +
+		" && ulimit -v " + strconv.FormatInt(r.memoryBytes/1024, 10) +
+		" && ulimit -c 0" +
+		` && exec /bin/sh -c "$1"`
+}
+
+// sandboxEnv is the environment for sandboxed commands. The gateway's own
+// environment holds API keys and is not passed through.
+func sandboxEnv(dir string) []string {
+	path := os.Getenv("PATH")
+	if path == "" {
+		path = "/usr/local/bin:/usr/bin:/bin"
+	}
+	env := []string{"PATH=" + path, "HOME=" + dir, "TMPDIR=/tmp", "TERM=dumb"}
+	for _, key := range []string{"LANG", "LC_ALL", "TZ"} {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+	return env
+}
+
+// cappedBuffer keeps the first limit bytes written and discards the rest,
+// so a noisy command can't exhaust memory. Writes never fail, which keeps
+// the command from blocking on a full pipe.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func positiveOr(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
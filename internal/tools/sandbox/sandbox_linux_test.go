@@ -0,0 +1,19 @@
+package sandbox
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestSeccompProgram(t *testing.T) {
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		t.Skip("no seccomp filter for this architecture")
+	}
+	prog, err := (&Runner{}).seccompProgram()
+	if err != nil {
+		t.Fatalf("seccompProgram failed: %v", err)
+	}
+	if len(prog) == 0 || len(prog)%8 != 0 {
+		t.Errorf("Expected whole 8-byte instructions, got %d bytes", len(prog))
+	}
+}
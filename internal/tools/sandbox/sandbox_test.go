@@ -0,0 +1,232 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"conduit/internal/config"
+)
+
+func TestMain(m *testing.M) {
+	// The namespaces backend re-executes the test binary as its helper
+	Init()
+	os.Exit(m.Run())
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{limit: 5}
+	for _, chunk := range []string{"abc", "defg", "hij"} {
+		if n, err := b.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q) = %d, %v; writes must always succeed", chunk, n, err)
+		}
+	}
+	if string(b.Bytes()) != "abcde" || !b.truncated {
+		t.Errorf("Expected truncated %q, got %q (truncated=%v)", "abcde", b.Bytes(), b.truncated)
+	}
+}
+
+func TestNew_UnknownBackend(t *testing.T) {
+	if _, err := New(config.SandboxConfig{Backend: "chroot"}); err == nil {
+		t.Error("Expected an error for an unknown backend")
+	}
+}
+
+func TestRunner_NoneBackend(t *testing.T) {
+	r, err := New(config.SandboxConfig{Backend: BackendNone, TimeoutSeconds: 1, MaxOutputKB: 1})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ctx := context.Background()
+
+	res, err := r.Run(ctx, Request{Command: "echo out; echo err >&2; exit 3", Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.ExitCode != 3 || !strings.Contains(string(res.Output), "out") || !strings.Contains(string(res.Output), "err") {
+		t.Errorf("Unexpected result: exit %d, output %q", res.ExitCode, res.Output)
+	}
+
+	res, err = r.Run(ctx, Request{Command: "head -c 5000 /dev/zero", Dir: "/"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(res.Output) != 1024 || !res.Truncated {
+		t.Errorf("Expected output capped at 1024 bytes, got %d (truncated=%v)", len(res.Output), res.Truncated)
+	}
+
+	res, err = r.Run(ctx, Request{Command: "sleep 10 & sleep 10", Dir: "/"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !res.TimedOut || res.ExitCode == 0 {
+		t.Errorf("Expected the command to time out, got %+v", res)
+	}
+
+	res, err = r.Run(ctx, Request{Command: "ulimit -t", Dir: "/"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.TrimSpace(string(res.Output)) != "60" {
+		t.Errorf("Expected the default CPU limit to apply, got %q", res.Output)
+	}
+}
+
+func TestRunner_NoneBackendEnvironment(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-secret")
+	r, err := New(config.SandboxConfig{Backend: BackendNone})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	res, err := r.Run(context.Background(), Request{Command: `echo "key=$ANTHROPIC_API_KEY home=$HOME"`, Dir: dir})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got, want := strings.TrimSpace(string(res.Output)), "key= home="+dir; got != want {
+		t.Errorf("Expected the gateway environment to be withheld, got %q, want %q", got, want)
+	}
+}
+
+func TestBubblewrapArgs(t *testing.T) {
+	r := &Runner{writable: []string{"/srv/workspace"}}
+	args := strings.Join(r.bubblewrapArgs("/srv/workspace"), " ")
+
+	for _, want := range []string{
+		"--unshare-all",
+		"--ro-bind / /",
+		"--tmpfs /tmp",
+		"--bind-try /srv/workspace /srv/workspace",
+		"--chdir /srv/workspace",
+		"--seccomp 3",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("Expected %q in bwrap args: %s", want, args)
+		}
+	}
+	if strings.Contains(args, "--share-net") {
+		t.Error("Network must not be shared unless granted")
+	}
+
+	r.network = true
+	if !strings.Contains(strings.Join(r.bubblewrapArgs("/"), " "), "--share-net") {
+		t.Error("Expected --share-net when network is granted")
+	}
+}
+
+// namespacesRunner returns a namespaces runner with dir writable, skipping
+// the test on hosts without user namespaces
+func namespacesRunner(t *testing.T, dir string, network bool) *Runner {
+	t.Helper()
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		t.Skip("namespaces backend needs Linux on amd64 or arm64")
+	}
+	r, err := New(config.SandboxConfig{
+		Backend:        BackendNamespaces,
+		AllowedPaths:   []string{dir},
+		Network:        network,
+		TimeoutSeconds: 10,
+	})
+	if err != nil {
+		t.Skipf("namespaces backend unavailable: %v", err)
+	}
+	if _, err := r.Run(context.Background(), Request{Command: "true", Dir: dir}); err != nil {
+		t.Skipf("namespaces backend unavailable: %v", err)
+	}
+	return r
+}
+
+func TestNamespaces_Filesystem(t *testing.T) {
+	allowed := t.TempDir()
+	outside := t.TempDir()
+	r := namespacesRunner(t, allowed, false)
+	ctx := context.Background()
+
+	res, err := r.Run(ctx, Request{Command: "echo hi > inside.txt && pwd", Dir: allowed})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.ExitCode != 0 || strings.TrimSpace(string(res.Output)) != allowed {
+		t.Fatalf("Expected write in allowed path to succeed, got exit %d: %s", res.ExitCode, res.Output)
+	}
+	if data, err := os.ReadFile(filepath.Join(allowed, "inside.txt")); err != nil || string(data) != "hi\n" {
+		t.Errorf("Expected the write to reach the host, got %q, %v", data, err)
+	}
+
+	res, err = r.Run(ctx, Request{Command: "echo hi > " + filepath.Join(outside, "x.txt"), Dir: allowed})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.ExitCode == 0 {
+		t.Error("Expected a write outside the allowed paths to fail")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "x.txt")); err == nil {
+		t.Error("File outside the allowed paths was created")
+	}
+
+	// /tmp is private to the command
+	res, err = r.Run(ctx, Request{Command: "echo hi > /tmp/conduit-sandbox-test", Dir: allowed})
+	if err != nil || res.ExitCode != 0 {
+		t.Fatalf("Expected /tmp to be writable, got %v: %s", err, res.Output)
+	}
+	if _, err := os.Stat("/tmp/conduit-sandbox-test"); err == nil {
+		os.Remove("/tmp/conduit-sandbox-test")
+		t.Error("Sandbox /tmp write reached the host")
+	}
+}
+
+func TestNamespaces_Isolation(t *testing.T) {
+	dir := t.TempDir()
+	r := namespacesRunner(t, dir, false)
+	ctx := context.Background()
+
+	res, err := r.Run(ctx, Request{Command: "tail -n +3 /proc/net/dev | cut -d: -f1", Dir: dir})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if ifaces := strings.Fields(string(res.Output)); len(ifaces) != 1 || ifaces[0] != "lo" {
+		t.Errorf("Expected only loopback without network access, got %v", ifaces)
+	}
+
+	t.Setenv("CONDUIT_SANDBOX_SECRET", "leak")
+	res, err = r.Run(ctx, Request{Command: `echo "[$CONDUIT_SANDBOX_SECRET]"`, Dir: dir})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.TrimSpace(string(res.Output)) != "[]" {
+		t.Errorf("Gateway environment leaked into the sandbox: %q", res.Output)
+	}
+
+	// The gateway's processes, and their environ and root links, are not visible
+	res, err = r.Run(ctx, Request{Command: "cat /proc/" + strconv.Itoa(os.Getpid()) + "/cmdline", Dir: dir})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.Contains(string(res.Output), filepath.Base(os.Args[0])) {
+		t.Errorf("Gateway process visible in the sandbox's /proc: %q", res.Output)
+	}
+
+	res, err = r.Run(ctx, Request{Command: "grep CapEff /proc/self/status", Dir: dir})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(string(res.Output), "0000000000000000") {
+		t.Errorf("Expected no effective capabilities, got %q", res.Output)
+	}
+
+	if _, err := exec.LookPath("unshare"); err == nil {
+		res, err = r.Run(ctx, Request{Command: "unshare -U true", Dir: dir})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.ExitCode == 0 {
+			t.Error("Expected creating namespaces to be blocked")
+		}
+	}
+}
@@ -36,6 +36,9 @@ Regular Actions:
 - Schedule a reminder: action=schedule, command="Remind Jeff to check email", delayMinutes=30
 - Daily report: action=schedule, schedule="0 9 * * *", command="Generate daily briefing", type="go"
 - System backup: action=schedule, schedule="0 2 * * *", command="/usr/local/bin/backup.sh", type="system"
+- Flaky report with retries: action=schedule, schedule="0 9 * * *", command="Summarize overnight alerts", timeout="5m", maxRetries=2, concurrency="skip"
+- Local morning briefing: action=schedule, schedule="0 8 * * *", command="Generate daily briefing", timezone="Europe/Berlin", misfirePolicy="run_once"
+- Recent runs of a job: action=history, jobId="a1b2c3d4", limit=5
 
 Heartbeat Management:
 - List heartbeat jobs: action=heartbeat_list
@@ -50,7 +53,7 @@ func (t *CronTool) Parameters() map[string]interface{} {
 		"properties": map[string]interface{}{
 			"action": map[string]interface{}{
 				"type":        "string",
-				"enum":        []string{"schedule", "list", "cancel", "run", "enable", "disable", "status", "heartbeat_list", "heartbeat_enable", "heartbeat_disable", "heartbeat_status"},
+				"enum":        []string{"schedule", "list", "cancel", "run", "enable", "disable", "status", "history", "heartbeat_list", "heartbeat_enable", "heartbeat_disable", "heartbeat_status"},
 				"description": "Cron operation to perform",
 			},
 			"schedule": map[string]interface{}{
@@ -81,7 +84,7 @@ func (t *CronTool) Parameters() map[string]interface{} {
 			},
 			"jobId": map[string]interface{}{
 				"type":        "string",
-				"description": "Job ID for cancel/run/enable/disable actions",
+				"description": "Job ID for cancel/run/enable/disable/history actions",
 			},
 			"oneshot": map[string]interface{}{
 				"type":        "boolean",
@@ -92,6 +95,40 @@ func (t *CronTool) Parameters() map[string]interface{} {
 				"type":        "integer",
 				"description": "Schedule to run in X minutes (alternative to cron expression)",
 			},
+			"timeout": map[string]interface{}{
+				"type":        "string",
+				"description": "Time limit for each attempt of a go job (e.g. '90s', '10m'; default 30m)",
+			},
+			"maxRetries": map[string]interface{}{
+				"type":        "integer",
+				"description": "Times to retry a go job after a failed or timed out attempt (default 0)",
+			},
+			"retryBackoff": map[string]interface{}{
+				"type":        "string",
+				"description": "Delay before the first retry, doubled after each (e.g. '1m'; default 30s)",
+			},
+			"concurrency": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"allow", "skip", "queue"},
+				"description": "What to do when a go job fires while its previous run is still going (default allow)",
+			},
+			"misfirePolicy": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"skip", "run_once", "run_all"},
+				"description": "What to do on startup with runs of a go job missed while the gateway was down (default skip)",
+			},
+			"misfireWindow": map[string]interface{}{
+				"type":        "string",
+				"description": "How far back missed runs are made up (e.g. '6h'; default 24h)",
+			},
+			"timezone": map[string]interface{}{
+				"type":        "string",
+				"description": "IANA time zone the schedule is read in, e.g. 'America/New_York' (default: the gateway's configured timezone)",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": "Number of runs to return for history (default 10)",
+			},
 		},
 		"required": []string{"action"},
 	}
@@ -115,6 +152,8 @@ func (t *CronTool) Execute(ctx context.Context, args map[string]interface{}) (*t
 		return t.disableJob(ctx, args)
 	case "status":
 		return t.getStatus(ctx, args)
+	case "history":
+		return t.getHistory(ctx, args)
 	case "heartbeat_list":
 		return t.listHeartbeatJobs(ctx, args)
 	case "heartbeat_enable":
@@ -149,11 +188,28 @@ func (t *CronTool) scheduleJob(ctx context.Context, args map[string]interface{})
 
 	var schedule string
 	oneshot := t.getBoolArg(args, "oneshot", false)
+	timezone := t.getStringArg(args, "timezone", "")
 
 	// Check if delayMinutes is provided (simple scheduling)
 	if delayMinutes := t.getIntArg(args, "delayMinutes", 0); delayMinutes > 0 {
 		// Convert minutes to a cron schedule for the target time
 		targetTime := time.Now().Add(time.Duration(delayMinutes) * time.Minute)
+		if timezone == "" && t.getStringArg(args, "jobType", "go") == "go" {
+			// Go jobs are read in the gateway's configured time zone, which
+			// may not be the process's, so pin the reminder to UTC
+			timezone = "UTC"
+		}
+		if tz := timezone; tz != "" {
+			// The expression is read in the job's time zone
+			loc, err := time.LoadLocation(tz)
+			if err != nil {
+				return &types.ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("invalid timezone '%s'", tz),
+				}, nil
+			}
+			targetTime = targetTime.In(loc)
+		}
 		schedule = fmt.Sprintf("%d %d %d %d *",
 			targetTime.Minute(), targetTime.Hour(), targetTime.Day(), int(targetTime.Month()))
 		oneshot = true // Delay-based schedules are always one-shot
@@ -187,6 +243,15 @@ func (t *CronTool) scheduleJob(ctx context.Context, args map[string]interface{})
 		Target:   t.getStringArg(args, "target", ""),
 		Enabled:  true,
 		OneShot:  oneshot,
+
+		Timeout:      t.getStringArg(args, "timeout", ""),
+		MaxRetries:   t.getIntArg(args, "maxRetries", 0),
+		RetryBackoff: t.getStringArg(args, "retryBackoff", ""),
+		Concurrency:  t.getStringArg(args, "concurrency", ""),
+
+		MisfirePolicy: t.getStringArg(args, "misfirePolicy", ""),
+		MisfireWindow: t.getStringArg(args, "misfireWindow", ""),
+		Timezone:      timezone,
 	}
 
 	// Default name for delay-based schedules
@@ -429,6 +494,71 @@ func (t *CronTool) getStatus(ctx context.Context, args map[string]interface{}) (
 	}, nil
 }
 
+func (t *CronTool) getHistory(ctx context.Context, args map[string]interface{}) (*types.ToolResult, error) {
+	if t.services.Gateway == nil {
+		return &types.ToolResult{
+			Success: false,
+			Error:   "gateway service not available",
+		}, nil
+	}
+
+	jobId := t.getStringArg(args, "jobId", "")
+	if jobId == "" {
+		return &types.ToolResult{
+			Success: false,
+			Error:   "jobId parameter is required",
+		}, nil
+	}
+
+	runs, err := t.services.Gateway.GetJobHistory(jobId, t.getIntArg(args, "limit", 10))
+	if err != nil {
+		return &types.ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to get job history: %v", err),
+		}, nil
+	}
+
+	if len(runs) == 0 {
+		return &types.ToolResult{
+			Success: true,
+			Content: fmt.Sprintf("No recorded runs for job %s.", jobId),
+			Data:    map[string]interface{}{"jobId": jobId, "count": 0},
+		}, nil
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Last %d run(s) of job %s:\n\n", len(runs), jobId))
+
+	for i, run := range runs {
+		duration := time.Duration(run.DurationMs) * time.Millisecond
+		attempt := ""
+		if run.Attempt > 1 {
+			attempt = fmt.Sprintf(", attempt %d", run.Attempt)
+		}
+		builder.WriteString(fmt.Sprintf("%d. %s - %s (%s, %s%s)\n", i+1,
+			run.StartedAt.Local().Format("2006-01-02 15:04"), run.Status, duration.Round(time.Millisecond), run.TriggeredBy, attempt))
+		if run.Model != "" || run.TotalTokens > 0 {
+			builder.WriteString(fmt.Sprintf("   Model: %s, tokens: %d\n", run.Model, run.TotalTokens))
+		}
+		if run.Error != "" {
+			builder.WriteString(fmt.Sprintf("   Error: %s\n", truncate(run.Error, 200)))
+		}
+		if run.Output != "" {
+			builder.WriteString(fmt.Sprintf("   Output: %s\n", truncate(strings.Join(strings.Fields(run.Output), " "), 200)))
+		}
+	}
+
+	return &types.ToolResult{
+		Success: true,
+		Content: builder.String(),
+		Data: map[string]interface{}{
+			"jobId": jobId,
+			"runs":  runs,
+			"count": len(runs),
+		},
+	}, nil
+}
+
 // Helper methods
 func (t *CronTool) getStringArg(args map[string]interface{}, key, defaultVal string) string {
 	if val, ok := args[key].(string); ok {
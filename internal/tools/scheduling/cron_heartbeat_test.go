@@ -13,6 +13,7 @@ var _ = context.Background
 // mockGatewayService implements the necessary parts of GatewayService for testing
 type mockGatewayService struct {
 	jobs []types.SchedulerJob
+	runs []*types.SchedulerJobRun
 }
 
 func (m *mockGatewayService) ListJobs() []*types.SchedulerJob {
@@ -57,6 +58,15 @@ func (m *mockGatewayService) GetHeartbeatJobCount() int {
 func (m *mockGatewayService) ScheduleJob(job *types.SchedulerJob) error { return nil }
 func (m *mockGatewayService) CancelJob(jobID string) error              { return nil }
 func (m *mockGatewayService) RunJobNow(jobID string) error              { return nil }
+func (m *mockGatewayService) GetJobHistory(jobID string, limit int) ([]*types.SchedulerJobRun, error) {
+	var result []*types.SchedulerJobRun
+	for _, run := range m.runs {
+		if run.JobID == jobID && (limit <= 0 || len(result) < limit) {
+			result = append(result, run)
+		}
+	}
+	return result, nil
+}
 func (m *mockGatewayService) GetSchedulerStatus() map[string]interface{} {
 	return map[string]interface{}{
 		"enabled":      true,
@@ -98,7 +108,7 @@ func (m *mockGatewayService) DisableChannel(ctx context.Context, channelID strin
 func (m *mockGatewayService) GetConfiguration() (map[string]interface{}, error) {
 	return nil, nil
 }
-func (m *mockGatewayService) UpdateConfiguration(ctx context.Context, config map[string]interface{}) error {
+func (m *mockGatewayService) UpdateConfiguration(ctx context.Context, config map[string]interface{}, persist bool) error {
 	return nil
 }
 func (m *mockGatewayService) GetMetrics() (map[string]interface{}, error) {
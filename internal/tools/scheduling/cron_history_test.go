@@ -0,0 +1,53 @@
+package scheduling
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"conduit/internal/tools/types"
+)
+
+func TestCronToolHistory(t *testing.T) {
+	started := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	mockGateway := &mockGatewayService{
+		runs: []*types.SchedulerJobRun{
+			{ID: 3, JobID: "briefing", TriggeredBy: "manual", Status: "failed", Error: "AI execution failed: timeout", StartedAt: started.Add(time.Hour), DurationMs: 30000},
+			{ID: 2, JobID: "briefing", TriggeredBy: "schedule", Status: "success", Model: "claude-haiku", TotalTokens: 1200, Output: "Good morning!\nThree meetings today.", StartedAt: started, DurationMs: 4200},
+			{ID: 1, JobID: "other", TriggeredBy: "schedule", Status: "success", StartedAt: started},
+		},
+	}
+	tool := NewCronTool(&types.ToolServices{Gateway: mockGateway})
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{
+		"action": "history",
+		"jobId":  "briefing",
+		"limit":  float64(5),
+	})
+	if err != nil {
+		t.Fatalf("Failed to execute history: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+
+	if result.Data["count"] != 2 {
+		t.Errorf("Expected 2 runs, got %v", result.Data["count"])
+	}
+	for _, want := range []string{"failed", "AI execution failed: timeout", "claude-haiku, tokens: 1200", "Good morning! Three meetings today.", "4.2s"} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("Expected %q in history output:\n%s", want, result.Content)
+		}
+	}
+
+	result, _ = tool.Execute(context.Background(), map[string]interface{}{"action": "history"})
+	if result.Success {
+		t.Error("Expected history without jobId to fail")
+	}
+
+	result, _ = tool.Execute(context.Background(), map[string]interface{}{"action": "history", "jobId": "unknown"})
+	if !result.Success || !strings.Contains(result.Content, "No recorded runs") {
+		t.Errorf("Expected no runs for an unknown job, got %+v", result)
+	}
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"conduit/internal/config"
 	"conduit/internal/fts"
@@ -30,7 +31,7 @@ type GatewayService interface {
 	EnableChannel(ctx context.Context, channelID string) error
 	DisableChannel(ctx context.Context, channelID string) error
 	GetConfiguration() (map[string]interface{}, error)
-	UpdateConfiguration(ctx context.Context, config map[string]interface{}) error
+	UpdateConfiguration(ctx context.Context, config map[string]interface{}, persist bool) error
 	GetMetrics() (map[string]interface{}, error)
 	GetVersion() string
 
@@ -41,6 +42,7 @@ type GatewayService interface {
 	EnableJob(jobID string) error
 	DisableJob(jobID string) error
 	RunJobNow(jobID string) error
+	GetJobHistory(jobID string, limit int) ([]*SchedulerJobRun, error)
 	GetSchedulerStatus() map[string]interface{}
 }
 
@@ -55,6 +57,33 @@ type SchedulerJob struct {
 	Target   string `json:"target,omitempty"`
 	Enabled  bool   `json:"enabled"`
 	OneShot  bool   `json:"oneshot,omitempty"`
+
+	Timeout      string `json:"timeout,omitempty"`       // Per-attempt time limit, e.g. "10m"
+	MaxRetries   int    `json:"max_retries,omitempty"`   // Further attempts after a failed one
+	RetryBackoff string `json:"retry_backoff,omitempty"` // Delay before the first retry
+	Concurrency  string `json:"concurrency,omitempty"`   // "allow", "skip" or "queue"
+
+	MisfirePolicy string `json:"misfire_policy,omitempty"` // "skip", "run_once" or "run_all"
+	MisfireWindow string `json:"misfire_window,omitempty"` // Oldest missed run still made up, e.g. "6h"
+	Timezone      string `json:"timezone,omitempty"`       // IANA time zone for the schedule
+}
+
+// SchedulerJobRun is one recorded execution of a job (mirrors scheduler.Run)
+type SchedulerJobRun struct {
+	ID               int64      `json:"id"`
+	JobID            string     `json:"job_id"`
+	TriggeredBy      string     `json:"triggered_by"`
+	Attempt          int        `json:"attempt"`
+	Status           string     `json:"status"`
+	Error            string     `json:"error,omitempty"`
+	Model            string     `json:"model,omitempty"`
+	PromptTokens     int        `json:"prompt_tokens"`
+	CompletionTokens int        `json:"completion_tokens"`
+	TotalTokens      int        `json:"total_tokens"`
+	Output           string     `json:"output,omitempty"`
+	StartedAt        time.Time  `json:"started_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	DurationMs       int64      `json:"duration_ms"`
 }
 
 // SearchService provides FTS5-backed full-text search over documents, messages, and beads.
@@ -81,6 +110,27 @@ type VectorService interface {
 	Close() error
 }
 
+// VisionRequest is an image analysis request for a vision-capable model
+type VisionRequest struct {
+	Image     []byte
+	MediaType string // e.g. "image/png"
+	Prompt    string
+	Model     string // Optional model override
+}
+
+// VisionResponse is the raw model output for a VisionRequest
+type VisionResponse struct {
+	Content  string
+	Provider string
+	Model    string
+}
+
+// VisionService sends images to a vision-capable model (implemented by ai.Router)
+type VisionService interface {
+	AnalyzeImage(ctx context.Context, req VisionRequest) (*VisionResponse, error)
+	MaxImageBytes() int64
+}
+
 // ToolServices provides access to services for tools (no direct gateway dependency)
 type ToolServices struct {
 	SessionStore  *sessions.Store
@@ -91,6 +141,7 @@ type ToolServices struct {
 	Gateway       GatewayService // Interface for gateway operations
 	Searcher      SearchService  // FTS5 full-text search
 	VectorSearch  VectorService  // Optional vector/semantic search
+	Vision        VisionService  // Optional vision model access for the Image tool
 
 	// Schema enhancement
 	SchemaBuilder *schema.Builder // For enhancing tool schemas with discovery data
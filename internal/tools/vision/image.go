@@ -1,9 +1,9 @@
 package vision
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -110,6 +110,13 @@ func (t *ImageTool) Execute(ctx context.Context, args map[string]interface{}) (*
 	extractText := t.getBoolArg(args, "extractText", false)
 	detectObjects := t.getBoolArg(args, "detectObjects", false)
 
+	// The vision backend may accept less than the requested limit
+	if t.services != nil && t.services.Vision != nil {
+		if limitMb := float64(t.services.Vision.MaxImageBytes()) / (1024 * 1024); limitMb > 0 && maxBytesMb > limitMb {
+			maxBytesMb = limitMb
+		}
+	}
+
 	// Load image data
 	imageData, metadata, err := t.loadImageData(ctx, image, maxBytesMb)
 	if err != nil {
@@ -312,31 +319,90 @@ func (t *ImageTool) loadFromFile(path string, maxBytes int64, metadata map[strin
 	return imageData, metadata, nil
 }
 
-// analyzeImage performs the actual image analysis
+// analyzeImage sends the image to the configured vision model and parses
+// its reply into a structured result
 func (t *ImageTool) analyzeImage(ctx context.Context, imageData []byte, prompt string, options map[string]interface{}) (*ImageAnalysisResult, error) {
-	// Placeholder implementation - would integrate with actual vision service
-	result := &ImageAnalysisResult{
-		Description: "Image analysis service not available. This is a placeholder response.",
-		Confidence:  0.0,
-		Metadata: map[string]interface{}{
-			"analyzed_at": time.Now(),
-			"service":     "placeholder",
-		},
+	if t.services == nil || t.services.Vision == nil {
+		return nil, fmt.Errorf("no vision model configured")
 	}
 
-	// Basic image format detection
-	if len(imageData) > 4 {
-		if bytes.Equal(imageData[:4], []byte{0xFF, 0xD8, 0xFF, 0xE0}) ||
-			bytes.Equal(imageData[:4], []byte{0xFF, 0xD8, 0xFF, 0xE1}) {
-			result.Description = "JPEG image detected. " + result.Description
-		} else if bytes.Equal(imageData[:8], []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}) {
-			result.Description = "PNG image detected. " + result.Description
-		}
+	mediaType := http.DetectContentType(imageData)
+	if !supportedImageTypes[mediaType] {
+		return nil, fmt.Errorf("unsupported image format: %s", mediaType)
+	}
+
+	model, _ := options["model"].(string)
+	extractText, _ := options["extractText"].(bool)
+	detectObjects, _ := options["detectObjects"].(bool)
+
+	resp, err := t.services.Vision.AnalyzeImage(ctx, types.VisionRequest{
+		Image:     imageData,
+		MediaType: mediaType,
+		Prompt:    buildVisionPrompt(prompt, extractText, detectObjects),
+		Model:     model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := parseAnalysisResponse(resp.Content)
+	result.Metadata = map[string]interface{}{
+		"analyzed_at": time.Now(),
+		"service":     resp.Provider,
+		"media_type":  mediaType,
+	}
+	if resp.Model != "" {
+		result.Metadata["model"] = resp.Model
 	}
 
 	return result, nil
 }
 
+// supportedImageTypes are the formats vision models accept
+var supportedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// buildVisionPrompt wraps the user's prompt with instructions to answer in
+// JSON matching ImageAnalysisResult
+func buildVisionPrompt(prompt string, extractText, detectObjects bool) string {
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\nRespond with only a JSON object with these fields:\n")
+	b.WriteString("- \"description\": your answer to the request above\n")
+	if extractText {
+		b.WriteString("- \"text\": all legible text in the image, verbatim (empty string if none)\n")
+	}
+	if detectObjects {
+		b.WriteString("- \"objects\": list of the distinct objects visible in the image\n")
+	}
+	b.WriteString("- \"confidence\": your confidence in the description, from 0 to 1\n")
+	return b.String()
+}
+
+// parseAnalysisResponse extracts the JSON result from a model reply. Replies
+// that are not valid JSON are used as the description as-is.
+func parseAnalysisResponse(content string) *ImageAnalysisResult {
+	content = strings.TrimSpace(content)
+
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start >= 0 && end > start {
+		var result ImageAnalysisResult
+		if err := json.Unmarshal([]byte(content[start:end+1]), &result); err == nil && result.Description != "" {
+			if result.Confidence < 0 || result.Confidence > 1 {
+				result.Confidence = 0
+			}
+			return &result
+		}
+	}
+
+	return &ImageAnalysisResult{Description: content}
+}
+
 // formatAnalysisResult formats the analysis result for display
 func (t *ImageTool) formatAnalysisResult(result *ImageAnalysisResult, prompt string) string {
 	var builder strings.Builder
@@ -0,0 +1,150 @@
+package vision
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"conduit/internal/tools/types"
+)
+
+// pngHeader is enough of a PNG for content type detection
+var pngHeader = []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A, 0, 0, 0, 0}
+
+// fakeVision records requests and returns a canned reply
+type fakeVision struct {
+	reply    string
+	err      error
+	maxBytes int64
+	requests []types.VisionRequest
+}
+
+func (f *fakeVision) AnalyzeImage(ctx context.Context, req types.VisionRequest) (*types.VisionResponse, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &types.VisionResponse{Content: f.reply, Provider: "fake", Model: "vision-1"}, nil
+}
+
+func (f *fakeVision) MaxImageBytes() int64 {
+	if f.maxBytes == 0 {
+		return 5 * 1024 * 1024
+	}
+	return f.maxBytes
+}
+
+func dataURL(data []byte) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+func TestImageTool_AnalyzeImage(t *testing.T) {
+	vision := &fakeVision{
+		reply: "Here you go:\n```json\n{\"description\": \"A stop sign\", \"text\": \"STOP\", \"objects\": [\"sign\", \"pole\"], \"confidence\": 0.9}\n```",
+	}
+	tool := NewImageTool(&types.ToolServices{Vision: vision})
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{
+		"image":         dataURL(pngHeader),
+		"prompt":        "What does the sign say?",
+		"extractText":   true,
+		"detectObjects": true,
+		"model":         "gpt-4o",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+
+	analysis := result.Data["result"].(*ImageAnalysisResult)
+	if analysis.Description != "A stop sign" || analysis.Text != "STOP" || len(analysis.Objects) != 2 || analysis.Confidence != 0.9 {
+		t.Errorf("Unexpected analysis: %+v", analysis)
+	}
+	if analysis.Metadata["service"] != "fake" || analysis.Metadata["source"] != "data_url" {
+		t.Errorf("Unexpected metadata: %v", analysis.Metadata)
+	}
+
+	if len(vision.requests) != 1 {
+		t.Fatalf("Expected 1 vision request, got %d", len(vision.requests))
+	}
+	req := vision.requests[0]
+	if req.MediaType != "image/png" || req.Model != "gpt-4o" {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+	if !strings.HasPrefix(req.Prompt, "What does the sign say?") || !strings.Contains(req.Prompt, `"text"`) || !strings.Contains(req.Prompt, `"objects"`) {
+		t.Errorf("Prompt missing instructions: %s", req.Prompt)
+	}
+	if !strings.Contains(result.Content, "**Text Found:** STOP") {
+		t.Errorf("Expected formatted text in content: %s", result.Content)
+	}
+}
+
+func TestImageTool_PlainTextReply(t *testing.T) {
+	tool := NewImageTool(&types.ToolServices{Vision: &fakeVision{reply: "Just a cat on a sofa."}})
+
+	result, _ := tool.Execute(context.Background(), map[string]interface{}{"image": dataURL(pngHeader)})
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+	if analysis := result.Data["result"].(*ImageAnalysisResult); analysis.Description != "Just a cat on a sofa." {
+		t.Errorf("Expected raw reply as description, got %q", analysis.Description)
+	}
+}
+
+func TestImageTool_Errors(t *testing.T) {
+	t.Run("no vision backend", func(t *testing.T) {
+		tool := NewImageTool(&types.ToolServices{})
+		result, _ := tool.Execute(context.Background(), map[string]interface{}{"image": dataURL(pngHeader)})
+		if result.Success || !strings.Contains(result.Error, "no vision model configured") {
+			t.Errorf("Expected configuration error, got %+v", result)
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		tool := NewImageTool(&types.ToolServices{Vision: &fakeVision{}})
+		result, _ := tool.Execute(context.Background(), map[string]interface{}{"image": dataURL([]byte("plain text"))})
+		if result.Success || !strings.Contains(result.Error, "unsupported image format") {
+			t.Errorf("Expected format error, got %+v", result)
+		}
+	})
+
+	t.Run("backend failure", func(t *testing.T) {
+		tool := NewImageTool(&types.ToolServices{Vision: &fakeVision{err: errors.New("overloaded")}})
+		result, _ := tool.Execute(context.Background(), map[string]interface{}{"image": dataURL(pngHeader)})
+		if result.Success || !strings.Contains(result.Error, "overloaded") {
+			t.Errorf("Expected backend error, got %+v", result)
+		}
+	})
+}
+
+func TestImageTool_MaxBytesLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.png")
+	data := append(append([]byte{}, pngHeader...), make([]byte, 2048)...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	// The tool argument limits the load
+	vision := &fakeVision{reply: "ok"}
+	tool := NewImageTool(&types.ToolServices{Vision: vision})
+	result, _ := tool.Execute(context.Background(), map[string]interface{}{"image": path, "maxBytesMb": 0.001})
+	if result.Success || !strings.Contains(result.Error, "exceeds limit") {
+		t.Errorf("Expected size error from maxBytesMb, got %+v", result)
+	}
+
+	// The backend limit caps a larger requested limit
+	vision.maxBytes = 1024
+	result, _ = tool.Execute(context.Background(), map[string]interface{}{"image": path, "maxBytesMb": 10.0})
+	if result.Success || !strings.Contains(result.Error, "exceeds limit") {
+		t.Errorf("Expected size error from backend limit, got %+v", result)
+	}
+	if len(vision.requests) != 0 {
+		t.Errorf("Expected oversized images not to reach the model, got %d requests", len(vision.requests))
+	}
+}
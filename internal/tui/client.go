@@ -325,6 +325,9 @@ func (c *WSClient) readPump() {
 		case *protocol.ToolEvent:
 			c.send(ToolEventMsg{ToolEvent: *msg})
 
+		case *protocol.ApprovalRequest:
+			c.send(ApprovalRequestMsg{ApprovalRequest: *msg})
+
 		case *protocol.CommandResponse:
 			c.send(CommandResponseMsg{
 				SessionKey: msg.SessionKey,
@@ -52,6 +52,11 @@ type ToolEventMsg struct {
 	protocol.ToolEvent
 }
 
+// ApprovalRequestMsg asks the user to approve a tool call
+type ApprovalRequestMsg struct {
+	protocol.ApprovalRequest
+}
+
 // CommandResponseMsg delivers the result of a slash command
 type CommandResponseMsg struct {
 	SessionKey string
@@ -78,6 +78,9 @@ type Model struct {
 
 	// Chat request correlation for stream routing
 	chatRequests map[string]int // requestID -> tab index
+
+	// Tool calls waiting for the user to approve or deny, oldest first
+	approvals []ApprovalRequestMsg
 }
 
 // NewModel creates the root TUI model
@@ -321,6 +324,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			s.SessionCost = msg.SessionCost
 		}
 
+	// Tool approval requests
+	case ApprovalRequestMsg:
+		m.approvals = append(m.approvals, msg)
+
 	// Tool event messages
 	case ToolEventMsg:
 		s, tabIdx := m.resolveTab(msg.RequestID, msg.SessionKey)
@@ -489,7 +496,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Re-subscribe to client messages after processing any client-originated message
 	switch msg.(type) {
 	case ConnectedMsg, StreamStartMsg, StreamDeltaMsg, StreamEndMsg,
-		ToolEventMsg, CommandResponseMsg, SessionListMsg,
+		ToolEventMsg, ApprovalRequestMsg, CommandResponseMsg, SessionListMsg,
 		SessionCreatedMsg, SessionSwitchedMsg, GatewayInfoMsg, ErrorMsg:
 		if m.connected {
 			cmds = append(cmds, m.client.ListenCmd())
@@ -518,7 +525,14 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 			m.client.Close()
 		}
 		return tea.Quit, true
+	}
 
+	// While a tool call awaits approval, keys answer it
+	if len(m.approvals) > 0 {
+		return m.handleApprovalKey(key), true
+	}
+
+	switch key {
 	case "ctrl+t":
 		// New session tab
 		idx := m.tabBar.AddTab("", "")
@@ -654,6 +668,7 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 						"/model [alias] - View/switch model\n"+
 						"/context - Show context window usage\n"+
 						"/stop - Stop current operation\n"+
+						"/approve, /deny - Answer a tool approval request\n"+
 						"/quit, /exit - Exit TUI\n\n"+
 						"Ctrl+T: New tab | Ctrl+W: Close tab\n"+
 						"Alt+Left/Right: Switch tabs\n"+
@@ -803,8 +818,12 @@ func (m Model) View() string {
 		sections = append(sections, chatView)
 	}
 
-	// Input area
-	sections = append(sections, m.styles.InputStyle.Width(m.width).Render(m.input.View()))
+	// Input area, or the approval prompt while a tool call waits
+	if len(m.approvals) > 0 {
+		sections = append(sections, m.approvalView())
+	} else {
+		sections = append(sections, m.styles.InputStyle.Width(m.width).Render(m.input.View()))
+	}
 
 	// Status bar
 	sections = append(sections, m.statusBar.View())
@@ -812,6 +831,51 @@ func (m Model) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
+// handleApprovalKey answers the oldest pending approval: y approves, n or
+// Esc denies. Other keys are ignored until it is answered.
+func (m *Model) handleApprovalKey(key string) tea.Cmd {
+	var command string
+	switch key {
+	case "y", "Y":
+		command = "/approve"
+	case "n", "N", "esc":
+		command = "/deny"
+	default:
+		return nil
+	}
+
+	req := m.approvals[0]
+	m.approvals = m.approvals[1:]
+	if m.client != nil {
+		m.client.SendCommand(req.SessionKey, command, req.ApprovalID)
+	}
+	return nil
+}
+
+// approvalView renders the oldest pending approval in place of the input
+func (m Model) approvalView() string {
+	req := m.approvals[0]
+	width := m.width - 2
+	if width < 20 {
+		width = 20
+	}
+
+	summary := strings.ReplaceAll(req.Summary, "\n", " ")
+	if len(summary) > width {
+		summary = summary[:width-1] + "…"
+	}
+
+	title := m.styles.Accent.Render("Approve " + req.ToolName + "?")
+	if len(m.approvals) > 1 {
+		title += m.styles.Muted.Render(fmt.Sprintf(" (%d more waiting)", len(m.approvals)-1))
+	}
+	keys := fmt.Sprintf("[y] approve  [n] deny  · %s · expires in %s",
+		req.Reason, time.Until(req.ExpiresAt).Round(time.Second))
+
+	return m.styles.ApprovalBox.Width(m.width).Render(
+		lipgloss.JoinVertical(lipgloss.Left, title, summary, m.styles.Muted.Render(keys)))
+}
+
 // SetSSHUser sets the SSH user for display in the status bar and chat views
 func (m *Model) SetSSHUser(user string) {
 	m.statusBar.SSHUser = user
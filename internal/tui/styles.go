@@ -42,7 +42,8 @@ type Styles struct {
 	StatusReconnecting lipgloss.Style
 
 	// Input
-	InputStyle lipgloss.Style
+	InputStyle  lipgloss.Style
+	ApprovalBox lipgloss.Style // Replaces the input while a tool call awaits approval
 
 	// Context usage (status bar)
 	ContextLow    lipgloss.Style // < 50%
@@ -160,6 +161,10 @@ func NewStyles(r *lipgloss.Renderer) Styles {
 			BorderTop(true).
 			BorderStyle(lipgloss.NormalBorder()).
 			BorderForeground(lipgloss.Color("238")),
+		ApprovalBox: r.NewStyle().
+			BorderTop(true).
+			BorderStyle(lipgloss.ThickBorder()).
+			BorderForeground(lipgloss.Color("214")),
 
 		// Context usage (status bar)
 		ContextLow: r.NewStyle().
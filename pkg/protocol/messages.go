@@ -34,6 +34,7 @@ const (
 	TypeCommandResponse MessageType = "command_response" // gateway -> client: response to slash command
 	TypeErrorResponse   MessageType = "error_response"   // gateway -> client: error notification
 	TypeGatewayInfo     MessageType = "gateway_info"     // gateway -> client: server metadata on connect
+	TypeApprovalRequest MessageType = "approval_request" // gateway -> client: tool call awaiting approval
 )
 
 // BaseMessage contains common fields for all protocol messages
@@ -46,11 +47,28 @@ type BaseMessage struct {
 // IncomingMessage represents a message received from a channel
 type IncomingMessage struct {
 	BaseMessage
-	ChannelID  string            `json:"channel_id"`
-	SessionKey string            `json:"session_key"`
-	UserID     string            `json:"user_id"`
-	Text       string            `json:"text"`
-	Metadata   map[string]string `json:"metadata,omitempty"`
+	ChannelID   string            `json:"channel_id"`
+	SessionKey  string            `json:"session_key"`
+	UserID      string            `json:"user_id"`
+	Text        string            `json:"text"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// Attachment types carried on incoming messages
+const (
+	AttachmentImage    = "image"
+	AttachmentDocument = "document"
+	AttachmentAudio    = "audio"
+)
+
+// Attachment references a file a user sent, already downloaded by the adapter
+type Attachment struct {
+	Type      string `json:"type"` // "image", "document", "audio"
+	Path      string `json:"path"` // Local path in the session's media directory
+	MediaType string `json:"media_type,omitempty"`
+	Filename  string `json:"filename,omitempty"`
+	Size      int64  `json:"size,omitempty"`
 }
 
 // OutgoingMessage represents a message to be sent through a channel
@@ -65,6 +83,18 @@ type OutgoingMessage struct {
 	Metadata   map[string]string `json:"metadata,omitempty"`
 }
 
+// MetadataInlineKeyboard is the OutgoingMessage metadata key holding reply
+// buttons: a JSON array of rows of InlineButton
+const MetadataInlineKeyboard = "inline_keyboard"
+
+// InlineButton is a reply button attached to an outgoing message. Adapters
+// that support buttons deliver a press as an incoming message whose text is
+// Data; others ignore the buttons.
+type InlineButton struct {
+	Text string `json:"text"`
+	Data string `json:"data"`
+}
+
 // ChannelStatus represents status information from a channel adapter
 type ChannelStatus struct {
 	BaseMessage
@@ -242,6 +272,19 @@ type GatewayInfo struct {
 	SkillCount    int               `json:"skill_count,omitempty"`
 }
 
+// ApprovalRequest asks the client to approve or deny a tool call. The client
+// answers with the /approve <id> or /deny <id> command.
+type ApprovalRequest struct {
+	BaseMessage
+	SessionKey string    `json:"session_key"`
+	RequestID  string    `json:"request_id,omitempty"`
+	ApprovalID string    `json:"approval_id"`
+	ToolName   string    `json:"tool_name"`
+	Summary    string    `json:"summary"`
+	Reason     string    `json:"reason,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
 // ParseMessage parses a JSON message into the appropriate struct
 func ParseMessage(data []byte) (interface{}, error) {
 	var base BaseMessage
@@ -376,6 +419,13 @@ func ParseMessage(data []byte) (interface{}, error) {
 		}
 		return &msg, nil
 
+	case TypeApprovalRequest:
+		var msg ApprovalRequest
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return nil, err
+		}
+		return &msg, nil
+
 	default:
 		return &base, nil
 	}
@@ -0,0 +1,25 @@
+package protocol
+
+// Process adapter protocol
+//
+// Out-of-process channel adapters exchange line-delimited JSON with the
+// gateway over stdin/stdout, one protocol message per line:
+//
+//   - gateway -> adapter: ChannelCommand (hello, ping, shutdown) and
+//     OutgoingMessage
+//   - adapter -> gateway: ChannelStatus and IncomingMessage
+//
+// The gateway starts with a hello command whose args carry
+// "protocol_version" and the adapter's "config". The adapter must answer
+// with a ChannelStatus whose details contain the same "protocol_version".
+// Every ping must be answered with a ChannelStatus. Stderr is logged.
+
+// ProcessProtocolVersion is the version of the process adapter protocol
+const ProcessProtocolVersion = 1
+
+// Commands sent to process adapters
+const (
+	ProcessCommandHello    = "hello"
+	ProcessCommandPing     = "ping"
+	ProcessCommandShutdown = "shutdown"
+)